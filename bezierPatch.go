@@ -0,0 +1,226 @@
+package main
+
+import "math"
+
+// BezierPatch is a bicubic Bezier surface given by 16 control points, row by row: points[4*i+j]
+// is in the row i along v and the column j along u. The patch is inside the box of its control
+// points, which is subdivided depth times to find the parts of the patch a ray may hit, then the
+// hits are found by Newton iteration from the middle of these parts. Tessellate turns the patch
+// into smooth triangles instead.
+type BezierPatch struct {
+	BaseShape
+	points [16]Tuple
+	depth  int
+}
+
+// NewBezierPatch returns a *BezierPatch of the control points, with Identity matrix as transform
+// and default material.
+func NewBezierPatch(points [16]Tuple) *BezierPatch {
+	return &BezierPatch{
+		BaseShape: NewBaseShape(),
+		points:    points,
+		depth:     4,
+	}
+}
+
+// bernstein returns the cubic Bernstein polynomials at t and their derivatives.
+func bernstein(t float64) ([4]float64, [4]float64) {
+	s := 1 - t
+	return [4]float64{s * s * s, 3 * t * s * s, 3 * t * t * s, t * t * t},
+		[4]float64{-3 * s * s, 3*s*s - 6*t*s, 6*t*s - 3*t*t, 3 * t * t}
+}
+
+// evaluate returns the point of the patch at (u, v) and its partial derivatives along u and v.
+func (patch *BezierPatch) evaluate(u, v float64) (Tuple, Tuple, Tuple) {
+	bu, du := bernstein(u)
+	bv, dv := bernstein(v)
+	point, pu, pv := Point(0, 0, 0), Vector(0, 0, 0), Vector(0, 0, 0)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			p := patch.points[4*i+j]
+			p.w = 0
+			point = point.Add(p.Multiply(bu[j] * bv[i]))
+			pu = pu.Add(p.Multiply(du[j] * bv[i]))
+			pv = pv.Add(p.Multiply(bu[j] * dv[i]))
+		}
+	}
+	return point, pu, pv
+}
+
+// normal returns the normal of the patch at (u, v). Where a row or a column of control points
+// collapses into a single point, such as at the top of the lid of the teapot, the normal is the
+// one of a point slightly closer to the middle of the patch.
+func (patch *BezierPatch) normal(u, v float64) Tuple {
+	for i := 0; i < 8; i++ {
+		_, pu, pv := patch.evaluate(u, v)
+		if n := pu.CrossProduct(pv); n.Magnitude() > EPSILON*EPSILON {
+			return n.Normalize()
+		}
+		u += (0.5 - u) * 0.001
+		v += (0.5 - v) * 0.001
+	}
+	return Vector(0, 1, 0)
+}
+
+// LocalIntersect calculates the intersections between a ray in object space and the patch. The
+// (u, v) coordinates of the hit on the patch are stored in the intersection.
+func (patch *BezierPatch) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	start := len(xs)
+	return patch.intersect(localRay, patch.points, 0, 1, 0, 1, patch.depth, start, xs)
+}
+
+// intersect looks for the hits of the ray on the part of the patch between u0 and u1 and between
+// v0 and v1, whose control points are cp. The part is split in four until depth is 0, then Newton
+// iteration starts from its middle and keeps the hits found inside it. Hits from start on in xs
+// were found on other parts and are not added again.
+func (patch *BezierPatch) intersect(ray Ray, cp [16]Tuple, u0, u1, v0, v1 float64, depth, start int, xs Intersections) Intersections {
+	box := NewEmptyBoundingBox()
+	for _, p := range cp {
+		box.Add(p)
+	}
+	box.min = box.min.Substract(Vector(EPSILON, EPSILON, EPSILON))
+	box.max = box.max.Add(Vector(EPSILON, EPSILON, EPSILON))
+	tMin, tMax, hit := rayBoxRange(ray, box)
+	if !hit {
+		return xs
+	}
+
+	if depth > 0 {
+		uMid, vMid := (u0+u1)/2, (v0+v1)/2
+		left, right := splitPatchU(cp)
+		for i, half := range [2][16]Tuple{left, right} {
+			bottom, top := splitPatchV(half)
+			ua, ub := u0, uMid
+			if i == 1 {
+				ua, ub = uMid, u1
+			}
+			xs = patch.intersect(ray, bottom, ua, ub, v0, vMid, depth-1, start, xs)
+			xs = patch.intersect(ray, top, ua, ub, vMid, v1, depth-1, start, xs)
+		}
+		return xs
+	}
+
+	u, v, t, ok := patch.newton(ray, (u0+u1)/2, (v0+v1)/2, (tMin+tMax)/2)
+	if !ok || u < u0-EPSILON || u > u1+EPSILON || v < v0-EPSILON || v > v1+EPSILON {
+		return xs
+	}
+	for i := start; i < len(xs); i++ {
+		if math.Abs(xs[i].t-t) < EPSILON {
+			return xs
+		}
+	}
+	return append(xs, NewIntersectionUV(t, patch, u, v))
+}
+
+// newton solves patch(u, v) = ray(t) by Newton iteration from (u, v, t). Returns false if it
+// doesn't converge or leaves the patch.
+func (patch *BezierPatch) newton(ray Ray, u, v, t float64) (float64, float64, float64, bool) {
+	d := ray.direction.Negate()
+	for i := 0; i < 20; i++ {
+		point, pu, pv := patch.evaluate(u, v)
+		f := point.Substract(ray.Position(t))
+		f.w = 0
+		if f.Magnitude() < EPSILON/100 {
+			return u, v, t, u >= 0 && u <= 1 && v >= 0 && v <= 1
+		}
+
+		// Solves [pu pv d] * step = -f with Cramer's rule.
+		determinant := pu.DotProduct(pv.CrossProduct(d))
+		if math.Abs(determinant) < EPSILON*EPSILON {
+			return 0, 0, 0, false
+		}
+		r := f.Negate()
+		u += r.DotProduct(pv.CrossProduct(d)) / determinant
+		v += pu.DotProduct(r.CrossProduct(d)) / determinant
+		t += pu.DotProduct(pv.CrossProduct(r)) / determinant
+		if u < -0.5 || u > 1.5 || v < -0.5 || v > 1.5 {
+			return 0, 0, 0, false
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// splitPatchU splits the control points of a patch in the middle of u.
+func splitPatchU(cp [16]Tuple) ([16]Tuple, [16]Tuple) {
+	var left, right [16]Tuple
+	for i := 0; i < 4; i++ {
+		split := subdivideBezier([4]Tuple{cp[4*i], cp[4*i+1], cp[4*i+2], cp[4*i+3]})
+		for j := 0; j < 4; j++ {
+			left[4*i+j] = split[j]
+			right[4*i+j] = split[j+3]
+		}
+	}
+	return left, right
+}
+
+// splitPatchV splits the control points of a patch in the middle of v.
+func splitPatchV(cp [16]Tuple) ([16]Tuple, [16]Tuple) {
+	var bottom, top [16]Tuple
+	for j := 0; j < 4; j++ {
+		split := subdivideBezier([4]Tuple{cp[j], cp[4+j], cp[8+j], cp[12+j]})
+		for i := 0; i < 4; i++ {
+			bottom[4*i+j] = split[i]
+			top[4*i+j] = split[i+3]
+		}
+	}
+	return bottom, top
+}
+
+// Intersect calculates the intersections between a ray in world space and the patch.
+func (patch *BezierPatch) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(patch, worldRay, xs)
+}
+
+// LocalNormalAt returns the normal of the patch at the (u, v) coordinates of the hit.
+func (patch *BezierPatch) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	return patch.normal(intersection.u, intersection.v)
+}
+
+// NormalAt calculates the normal at a given point in world space.
+func (patch *BezierPatch) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(patch, worldPoint, intersection)
+}
+
+// LocalBounds returns the box of the control points, which contains the patch.
+func (patch *BezierPatch) LocalBounds() *BoundingBox {
+	box := NewEmptyBoundingBox()
+	for _, p := range patch.points {
+		box.Add(p)
+	}
+	return box
+}
+
+// Tessellate returns a group of smooth triangles approximating the patch, resolution by resolution
+// squares each split in two triangles, with the transform and the material of the patch. The
+// normals of the vertices are the normals of the patch. Triangles collapsing into a line are left
+// out.
+func (patch *BezierPatch) Tessellate(resolution int) *Group {
+	points := make([]Tuple, 0, (resolution+1)*(resolution+1))
+	normals := make([]Tuple, 0, cap(points))
+	for i := 0; i <= resolution; i++ {
+		for j := 0; j <= resolution; j++ {
+			u, v := float64(j)/float64(resolution), float64(i)/float64(resolution)
+			point, _, _ := patch.evaluate(u, v)
+			points = append(points, point)
+			normals = append(normals, patch.normal(u, v))
+		}
+	}
+
+	g := NewGroup()
+	g.SetTransform(patch.Transform())
+	add := func(a, b, c int) {
+		if points[b].Substract(points[a]).CrossProduct(points[c].Substract(points[a])).Magnitude() < EPSILON*EPSILON {
+			return
+		}
+		g.AddChild(newSmoothTriangle(points[a], points[b], points[c], normals[a], normals[b], normals[c]))
+	}
+	for i := 0; i < resolution; i++ {
+		for j := 0; j < resolution; j++ {
+			a := i*(resolution+1) + j
+			add(a, a+1, a+resolution+2)
+			add(a, a+resolution+2, a+resolution+1)
+		}
+	}
+	g.SetMaterial(patch.material)
+	return g
+}
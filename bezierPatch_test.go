@@ -0,0 +1,156 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// flatTestPatch returns a patch lying in the plane y = 0 from (0, 0, 0) to (3, 0, 3), u along x
+// and v along z.
+func flatTestPatch() *BezierPatch {
+	var points [16]Tuple
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			points[4*i+j] = Point(float64(j), 0, float64(i))
+		}
+	}
+	return NewBezierPatch(points)
+}
+
+// A flat patch is hit like a square of a plane, at the (u, v) coordinates of the point.
+func TestBezierPatchFlat(t *testing.T) {
+	patch := flatTestPatch()
+
+	xs := patch.Intersect(NewRay(Point(1.2, 5, 2.1), Vector(0, -1, 0)), nil)
+	if len(xs) != 1 || !floatEqual(xs[0].t, 5) {
+		t.Fatalf("Flat patch intersections: got %v, expected one at: %v", xs, 5)
+	}
+	if !floatEqual(xs[0].u, 0.4) || !floatEqual(xs[0].v, 0.7) {
+		t.Errorf("Flat patch (u, v): got (%v, %v), expected: (%v, %v)", xs[0].u, xs[0].v, 0.4, 0.7)
+	}
+	if n := patch.NormalAt(Point(1.2, 0, 2.1), &xs[0]); !n.Equals(Vector(0, -1, 0)) {
+		t.Errorf("Flat patch normal: got %v, expected: %v", n, Vector(0, -1, 0))
+	}
+
+	if xs := patch.Intersect(NewRay(Point(3.2, 5, 2.1), Vector(0, -1, 0)), nil); len(xs) != 0 {
+		t.Errorf("Flat patch intersections outside of it: got %v, expected none", xs)
+	}
+
+	if n := len(patch.Tessellate(4).children); n != 32 {
+		t.Errorf("Flat patch triangles: got %v, expected: %v", n, 32)
+	}
+}
+
+// Hits found by Newton iteration are on the patch and match its tessellation.
+func TestBezierPatchCurved(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	var points [16]Tuple
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			points[4*i+j] = Point(float64(j), rng.Float64()*2-1, float64(i))
+		}
+	}
+	patch := NewBezierPatch(points)
+	mesh := patch.Tessellate(64)
+
+	for n := 0; n < 100; n++ {
+		origin := Point(rng.Float64()*3, 5, rng.Float64()*3)
+		r := NewRay(origin, Vector(rng.Float64()*0.4-0.2, -1, rng.Float64()*0.4-0.2).Normalize())
+		xs, expected := patch.Intersect(r, nil), mesh.Intersect(r, nil)
+		xs.Sort()
+		expected.Sort()
+		if len(xs) != len(expected) {
+			t.Errorf("Curved patch intersections count: got %v, expected: %v", len(xs), len(expected))
+			continue
+		}
+		for i := range xs {
+			point, _, _ := patch.evaluate(xs[i].u, xs[i].v)
+			if hit := r.Position(xs[i].t); !hit.Equals(point) {
+				t.Errorf("Curved patch hit: got %v, expected the point of the patch: %v", hit, point)
+			}
+			if math.Abs(xs[i].t-expected[i].t) > 0.01 {
+				t.Errorf("Curved patch intersection: got %v, expected about: %v", xs[i].t, expected[i].t)
+			}
+		}
+	}
+}
+
+// The teapot has its 32 patches around the z axis and its lid on top.
+func TestTeapotPatches(t *testing.T) {
+	patches := teapotPatches()
+	if len(patches) != 32 {
+		t.Fatalf("Teapot patches: got %v, expected: %v", len(patches), 32)
+	}
+
+	teapot := NewGroup()
+	for _, patch := range patches {
+		teapot.AddChild(patch)
+	}
+	box := teapot.LocalBounds()
+	if !box.min.Equals(Point(-3, -2, 0)) || !box.max.Equals(Point(3.525, 2, 3.15)) {
+		t.Errorf("Teapot bounds: got %v, expected: %v", box, NewBoundingBoxFloat(-3, -2, 0, 3.525, 2, 3.15))
+	}
+
+	r := NewRay(Point(0.01, 0.01, 5), Vector(0, 0, -1))
+	xs := teapot.Intersect(r, nil)
+	xs.Sort()
+	if len(xs) == 0 || math.Abs(xs[0].t-(5-3.15)) > 0.001 {
+		t.Errorf("Teapot lid intersection: got %v, expected the first one at about: %v", xs, 5-3.15)
+	} else if n := xs[0].object.NormalAt(r.Position(xs[0].t), &xs[0]); n.z < 0.99 {
+		t.Errorf("Teapot lid normal: got %v, expected about: %v", n, Vector(0, 0, 1))
+	}
+}
+
+// Patches are read from the format of the Newell teapot.
+func TestParseBezierPatchData(t *testing.T) {
+	data := `1
+1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16
+16
+0, 0, 0
+1, 0, 0
+2, 0, 0
+3, 0, 0
+0, 0, 1
+1, 0, 1
+2, 0, 1
+3, 0, 1
+0, 0, 2
+1, 0, 2
+2, 0, 2
+3, 0, 2
+0, 0, 3
+1, 0, 3
+2, 0, 3
+3, 0, 3
+`
+	patches, err := parseBezierPatchData(data)
+	if err != nil {
+		t.Fatalf("Patch data error: %v", err)
+	}
+	if len(patches) != 1 || patches[0].points != flatTestPatch().points {
+		t.Errorf("Patch data: got %v, expected: %v", patches, flatTestPatch())
+	}
+
+	if _, err := parseBezierPatchData("1\n1, 2, 3"); err == nil {
+		t.Errorf("Truncated patch data: got no error, expected an error")
+	}
+	if _, err := parseBezierPatchData(strings.Replace(data, "1, 2, 3,", "17, 2, 3,", 1)); err == nil {
+		t.Errorf("Patch data with an index out of range: got no error, expected an error")
+	}
+
+	// Counts are checked before allocating anything.
+	for _, header := range []string{
+		"-1 0",
+		"1e12",
+		"0.5",
+		"0 -1",
+		"0 2\n1, 2, 3",
+		strings.Replace(data, "\n16\n", "\n-16\n", 1),
+	} {
+		if _, err := parseBezierPatchData(header); err == nil {
+			t.Errorf("Patch data %q: got no error, expected an error", header)
+		}
+	}
+}
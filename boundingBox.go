@@ -4,8 +4,8 @@ import "math"
 
 // BoundingBox struct.
 type BoundingBox struct {
-	min *Tuple
-	max *Tuple
+	min Tuple
+	max Tuple
 }
 
 // NewBoundingBoxFloat receives min and max xyz values to returns a *BoundingBox.
@@ -17,7 +17,7 @@ func NewBoundingBoxFloat(x1, y1, z1, x2, y2, z2 float64) *BoundingBox {
 }
 
 // NewBoundingBox receives min and max point values to returns a *BoundingBox.
-func NewBoundingBox(pointA *Tuple, pointB *Tuple) *BoundingBox {
+func NewBoundingBox(pointA Tuple, pointB Tuple) *BoundingBox {
 	return &BoundingBox{
 		min: pointA,
 		max: pointB,
@@ -38,7 +38,7 @@ func ParentSpaceBounds(shape Shape) *BoundingBox {
 	return TransformBoundingBox(BoundingBox, shape.Transform())
 }
 
-func (b *BoundingBox) ContainsPoint(p *Tuple) bool {
+func (b *BoundingBox) ContainsPoint(p Tuple) bool {
 	return b.min.x <= p.x && b.min.y <= p.y && b.min.z <= p.z &&
 		b.max.x >= p.x && b.max.y >= p.y && b.max.z >= p.z
 }
@@ -51,7 +51,7 @@ func (b *BoundingBox) ContainsBox(b2 *BoundingBox) bool {
 
 // TransformBoundingBox transforms the points at all eight corners of the
 // cube, and then find a new bounding box that contains all eight transformed points.
-func TransformBoundingBox(bbox *BoundingBox, m1 Matrix) *BoundingBox {
+func TransformBoundingBox(bbox *BoundingBox, m1 Matrix4) *BoundingBox {
 	p1 := bbox.min
 	p2 := Point(bbox.min.x, bbox.min.y, bbox.max.z)
 	p3 := Point(bbox.min.x, bbox.max.y, bbox.min.z)
@@ -75,7 +75,7 @@ func TransformBoundingBox(bbox *BoundingBox, m1 Matrix) *BoundingBox {
 }
 
 // Add operation to resize a *BoundingBox.
-func (b *BoundingBox) Add(p *Tuple) {
+func (b *BoundingBox) Add(p Tuple) {
 	if b.min.x > p.x {
 		b.min.x = p.x
 	}
@@ -97,55 +97,11 @@ func (b *BoundingBox) Add(p *Tuple) {
 	}
 }
 
-// Bounds returns the bounding box in object space for a given shape.
+// Bounds returns the bounding box in object space for a given shape, as reported by its LocalBounds().
 // For groups it will convert the bounds of all the group’s children into “group space,”
 // and then combines them into a single bounding box.
 func Bounds(shape Shape) *BoundingBox {
-	switch val := shape.(type) {
-	case *Group:
-		box := NewEmptyBoundingBox()
-		for i := 0; i < len(val.children); i++ {
-			cbox := ParentSpaceBounds(val.children[i])
-			box.Merge(cbox)
-		}
-		return box
-	case *CSG:
-		box := NewEmptyBoundingBox()
-		box.Merge(ParentSpaceBounds(val.left))
-		box.Merge(ParentSpaceBounds(val.right))
-		return box
-	case *Cube:
-		return NewBoundingBoxFloat(-1, -1, -1, 1, 1, 1)
-	case *Sphere:
-		return NewBoundingBoxFloat(-1, -1, -1, 1, 1, 1)
-	case *Plane:
-		return NewBoundingBoxFloat(math.Inf(-1), 0, math.Inf(-1), math.Inf(1), 0, math.Inf(1))
-	case *Cylinder:
-		return NewBoundingBoxFloat(-1, val.minimum, -1, 1, val.maximum, 1)
-	case *Cone:
-		xzMin := math.Abs(val.minimum)
-		xzMax := math.Abs(val.maximum)
-		limit := xzMin
-		if xzMax > limit {
-			limit = xzMax
-		}
-		return NewBoundingBoxFloat(-limit, val.minimum, -limit, limit, val.maximum, limit)
-	case *Triangle:
-		BoundingBox := NewEmptyBoundingBox()
-		BoundingBox.Add(val.p1)
-		BoundingBox.Add(val.p2)
-		BoundingBox.Add(val.p3)
-		return BoundingBox
-	case *smoothTriangle:
-		BoundingBox := NewEmptyBoundingBox()
-		BoundingBox.Add(val.p1)
-		BoundingBox.Add(val.p2)
-		BoundingBox.Add(val.p3)
-		return BoundingBox
-
-	default:
-		return NewBoundingBoxFloat(-1, -1, -1, 1, 1, 1)
-	}
+	return shape.LocalBounds()
 }
 
 // Merge will cause the BoundingBox to resize until it contains both points.
@@ -154,16 +110,44 @@ func (b *BoundingBox) Merge(b2 *BoundingBox) {
 	b.Add(b2.max)
 }
 
+// SurfaceArea returns the area of the six faces of the box, 0 for an empty box.
+func (b *BoundingBox) SurfaceArea() float64 {
+	dx := b.max.x - b.min.x
+	dy := b.max.y - b.min.y
+	dz := b.max.z - b.min.z
+	if dx < 0 || dy < 0 || dz < 0 {
+		return 0
+	}
+	return 2 * (dx*dy + dy*dz + dz*dx)
+}
+
+// Centroid returns the point in the middle of the box.
+func (b *BoundingBox) Centroid() Tuple {
+	return Point((b.min.x+b.max.x)/2, (b.min.y+b.max.y)/2, (b.min.z+b.max.z)/2)
+}
+
+// IsFinite returns true when none of the box's limits are infinite, which is not the case for planes.
+func (b *BoundingBox) IsFinite() bool {
+	return !math.IsInf(b.min.x, 0) && !math.IsInf(b.min.y, 0) && !math.IsInf(b.min.z, 0) &&
+		!math.IsInf(b.max.x, 0) && !math.IsInf(b.max.y, 0) && !math.IsInf(b.max.z, 0)
+}
+
 // IntersectRayWithBox test the intersection between a ray and a cubeshaped AABB at the origin.
-func IntersectRayWithBox(ray *Ray, boundingBox *BoundingBox) bool {
+func IntersectRayWithBox(ray Ray, boundingBox *BoundingBox) bool {
+	_, _, hit := rayBoxRange(ray, boundingBox)
+	return hit
+}
 
+// rayBoxRange returns the range of t where the ray is inside the box.
+func rayBoxRange(ray Ray, boundingBox *BoundingBox) (float64, float64, bool) {
 	xtmin, xtmax := checkAxisForBB(ray.origin.x, ray.direction.x, boundingBox.min.x, boundingBox.max.x)
 	ytmin, ytmax := checkAxisForBB(ray.origin.y, ray.direction.y, boundingBox.min.y, boundingBox.max.y)
 	ztmin, ztmax := checkAxisForBB(ray.origin.z, ray.direction.z, boundingBox.min.z, boundingBox.max.z)
 
 	tmin := max(xtmin, ytmin, ztmin)
 	tmax := min(xtmax, ytmax, ztmax)
-	return tmin < tmax
+	// Flat boxes, such as the bounds of a disk, are crossed where tmin equals tmax.
+	return tmin, tmax, tmin <= tmax
 }
 func checkAxisForBB(origin, direction, minBB, maxBB float64) (min float64, max float64) {
 	tminNumerator := minBB - origin
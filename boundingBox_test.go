@@ -216,7 +216,7 @@ func TestBoundingBoxContainsPoint(t *testing.T) {
 	BoundingBox := NewBoundingBoxFloat(5, -2, 0, 11, 4, 7)
 
 	tests := []struct {
-		point  *Tuple
+		point  Tuple
 		result bool
 	}{
 		{Point(5, -2, 0), true},
@@ -245,8 +245,8 @@ func TestBoxContainsBox(t *testing.T) {
 	BoundingBox := NewBoundingBoxFloat(5, -2, 0, 11, 4, 7)
 
 	tests := []struct {
-		min    *Tuple
-		max    *Tuple
+		min    Tuple
+		max    Tuple
 		result bool
 	}{
 		{Point(5, -2, 0), Point(11, 4, 7), true},
@@ -297,7 +297,7 @@ func TestQueryBBTransformInParentSpace(t *testing.T) {
 	// Querying a shape's bounding box in its parent's space.
 	shape := NewSphere()
 	shape.SetTransform(Translation(1, -3, 5))
-	shape.SetTransform(Scaling(0.5, 2, 4))
+	shape.ApplyTransform(Scaling(0.5, 2, 4))
 	box := ParentSpaceBounds(shape)
 
 	expectedMinPoint := Point(0.5, -5, 1)
@@ -314,13 +314,13 @@ func TestGroupBoundingBoxContainsAllItsChildren(t *testing.T) {
 	// A group has a bounding box that contains its children.
 	s := NewSphere()
 	s.SetTransform(Translation(2, 5, -3))
-	s.SetTransform(Scaling(2, 2, 2))
+	s.ApplyTransform(Scaling(2, 2, 2))
 
 	c := NewCylinder()
 	c.minimum = -2
 	c.maximum = 2
 	c.SetTransform(Translation(-4, -1, 4))
-	c.SetTransform(Scaling(0.5, 1, 0.5))
+	c.ApplyTransform(Scaling(0.5, 1, 0.5))
 	g := NewGroup()
 	g.AddChild(s)
 	g.AddChild(c)
@@ -359,8 +359,8 @@ func TestIntersectBoundingBoxWithRayAtOrigin(t *testing.T) {
 	box := NewBoundingBoxFloat(-1, -1, -1, 1, 1, 1)
 
 	testcases := []struct {
-		origin    *Tuple
-		direction *Tuple
+		origin    Tuple
+		direction Tuple
 		result    bool
 	}{
 		{Point(5, 0.5, 0), Vector(-1, 0, 0), true},
@@ -393,8 +393,8 @@ func TestIntersectNonCubicBoundingBoxWithRay(t *testing.T) {
 	box := NewBoundingBoxFloat(5, -2, 0, 11, 4, 7)
 
 	testcases := []struct {
-		origin    *Tuple
-		direction *Tuple
+		origin    Tuple
+		direction Tuple
 		result    bool
 	}{
 		{Point(15, 1, 2), Vector(-1, 0, 0), true},
@@ -428,7 +428,7 @@ func TestIntersectRayGroupWithMiss(t *testing.T) {
 	g.AddChild(s)
 	g.Bounds()
 	r := NewRay(Point(0, 0, -5), Point(0, 1, 0))
-	g.Intersect(r)
+	g.Intersect(r, nil)
 
 	if !(s.savedRay.origin.x == 0) {
 		t.Errorf("Intersecting ray+group doesn't test children if box is missed: got %v, expected: %v",
@@ -463,7 +463,7 @@ func TestIntersectRayGroupWithHit(t *testing.T) {
 	g.AddChild(s)
 	g.Bounds()
 	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
-	g.Intersect(r)
+	g.Intersect(r, nil)
 
 	if !(s.savedRay.origin.x == 0) {
 		t.Errorf("Intersecting ray+group tests children if box is hit: got %v, expected: %v",
@@ -498,7 +498,7 @@ func TestIntersectRayWithCSGMissesBox(t *testing.T) {
 	csg := NewCSG("difference", left, right)
 	csg.Bounds()
 	r := NewRay(Point(0, 0, -5), Point(0, 1, 0))
-	csg.Intersect(r)
+	csg.Intersect(r, nil)
 
 	if !(left.savedRay.direction.x == 0) {
 		t.Errorf("Intersecting ray+csg doesn't test children if box is missed: got %v, expected: %v",
@@ -533,7 +533,7 @@ func TestIntersectRayWithCSGHitsBox(t *testing.T) {
 	csg := NewCSG("difference", left, right)
 	csg.Bounds()
 	r := NewRay(Point(0, 0, -5), Point(0, 0, 1))
-	csg.Intersect(r)
+	csg.Intersect(r, nil)
 
 	if !(left.savedRay.direction.z == 1) {
 		t.Errorf("Intersecting ray+csg tests children if box is hit: got %v, expected: %v",
@@ -544,3 +544,21 @@ func TestIntersectRayWithCSGHitsBox(t *testing.T) {
 			right.savedRay.direction.z, 1)
 	}
 }
+
+func TestBoundingBoxSurfaceArea(t *testing.T) {
+	// The surface area and the centroid of a bounding box.
+	box := NewBoundingBoxFloat(-1, 0, 2, 1, 3, 6)
+
+	if !floatEqual(box.SurfaceArea(), 2*(2*3+3*4+4*2)) {
+		t.Errorf("Bounding box surface area: got %v, expected: %v", box.SurfaceArea(), 2*(2*3+3*4+4*2))
+	}
+	if !box.Centroid().Equals(Point(0, 1.5, 4)) {
+		t.Errorf("Bounding box centroid: got %v, expected: %v", box.Centroid(), Point(0, 1.5, 4))
+	}
+	if NewEmptyBoundingBox().SurfaceArea() != 0 {
+		t.Errorf("Empty bounding box surface area: got %v, expected: %v", NewEmptyBoundingBox().SurfaceArea(), 0)
+	}
+	if !box.IsFinite() || Bounds(NewPlane()).IsFinite() {
+		t.Errorf("Bounding box IsFinite: expected only the plane's bounds to be infinite")
+	}
+}
@@ -65,6 +65,7 @@ func PartitionChildren(g *Group) (*Group, *Group) {
 		}
 	}
 	// copy over the remaining ones
+	g.flat = nil
 	g.children = g.children[:0]
 	g.children = append(g.children, remain...)
 
@@ -0,0 +1,103 @@
+package main
+
+import "math"
+
+// Refit recomputes the bounding boxes of a hierarchy bottom-up after the transformations of some
+// shapes changed, keeping the tree as it is. This is much cheaper than dividing it again, but the
+// tree gets worse as the shapes move away from where they were when it was built. Groups divided
+// with DivideSAH measure the SAH cost of their refitted tree and are divided again when it exceeds
+// the cost measured at build time by more than config.rebuildRatio. Compiled groups are compiled again.
+// Returns true if any group was rebuilt.
+func Refit(s Shape, config *SAHConfig) bool {
+	rebuilt := false
+
+	switch g := s.(type) {
+	case *CSG:
+		rebuilt = Refit(g.left, config)
+		rebuilt = Refit(g.right, config) || rebuilt
+		g.Bounds()
+
+	case *Group:
+		for _, child := range g.children {
+			rebuilt = Refit(child, config) || rebuilt
+		}
+		if g.flat != nil {
+			g.flat.Refit()
+		}
+		g.Bounds()
+
+		if g.builtCost > 0 && groupSAHCost(g, config) > g.builtCost*config.rebuildRatio {
+			compiled := g.flat != nil
+			DivideSAH(g, config)
+			if compiled {
+				g.Compile()
+			}
+			rebuilt = true
+		}
+	}
+
+	return rebuilt
+}
+
+// Refit recomputes the bounds of the nodes from the current bounds of the shapes.
+// The shapes themselves must be refitted first.
+func (bvh *FlatBVH) Refit() {
+	// Children are always stored after their parent, so a reverse walk visits them first.
+	for i := len(bvh.nodes) - 1; i >= 0; i-- {
+		node := &bvh.nodes[i]
+		bounds := NewEmptyBoundingBox()
+		if node.leaf {
+			for j := node.offset; j < node.offset+node.count; j++ {
+				bounds.Merge(ParentSpaceBounds(bvh.shapes[j]))
+			}
+		} else {
+			bounds.Add(bvh.nodes[i+1].min)
+			bounds.Add(bvh.nodes[i+1].max)
+			bounds.Add(bvh.nodes[node.second].min)
+			bounds.Add(bvh.nodes[node.second].max)
+		}
+		node.min, node.max = bounds.min, bounds.max
+	}
+}
+
+// groupSAHCost returns the SAH cost of the hierarchy built by DivideSAH in the group, relative to the
+// surface area of its bounds. Children with infinite bounds are left out.
+func groupSAHCost(g *Group, config *SAHConfig) float64 {
+	cost, bounds := sahNodeCost(g, config)
+	area := bounds.SurfaceArea()
+	if area == 0 || math.IsInf(area, 0) {
+		return 0
+	}
+	return cost / area
+}
+
+// sahNodeCost returns the SAH cost of a node weighted by its surface area, along with its bounds.
+// A node is either a leaf holding shapes or an interior node holding SAH subgroups.
+func sahNodeCost(g *Group, config *SAHConfig) (float64, *BoundingBox) {
+	cost := 0.0
+	shapes, interior := 0, false
+	bounds := NewEmptyBoundingBox()
+
+	for _, child := range g.children {
+		if sub, ok := child.(*Group); ok && sub.label == sahNodeLabel {
+			subCost, subBounds := sahNodeCost(sub, config)
+			cost += subCost
+			bounds.Merge(subBounds)
+			interior = true
+			continue
+		}
+		childBounds := ParentSpaceBounds(child)
+		if !childBounds.IsFinite() {
+			continue
+		}
+		bounds.Merge(childBounds)
+		shapes++
+	}
+
+	area := bounds.SurfaceArea()
+	cost += area * float64(shapes) * config.intersectionCost
+	if interior {
+		cost += area * config.traversalCost
+	}
+	return cost, bounds
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Refitting a divided and compiled group after moving a child updates the bounds of the whole tree.
+func TestRefitMovedChild(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	g := randomSpheresGroup(rng, 100, 20)
+	moved := g.children[0]
+	DivideSAH(g, DefaultSAHConfig())
+	g.Compile()
+
+	moved.SetTransform(Translation(0, 0, 30).MultiplyMatrix(Scaling(0.3, 0.3, 0.3)))
+	r := NewRay(Point(0, 0, 100), Vector(0, 0, -1))
+
+	if rebuilt := Refit(g, DefaultSAHConfig()); rebuilt {
+		t.Errorf("Refit rebuilt: got %v, expected: %v", rebuilt, false)
+	}
+	if g.BoundingBox.max.z < 30.3-EPSILON {
+		t.Errorf("Refit group bounds max z: got %v, expected: %v", g.BoundingBox.max.z, 30.3)
+	}
+	xs := g.Intersect(r, nil)
+	if len(xs) == 0 || xs[0].object != moved {
+		t.Errorf("Refit intersection: got %v, expected: %v", xs, moved)
+	}
+}
+
+// Refit rebuilds the tree when the children moved too much for the refitted tree to stay efficient.
+func TestRefitRebuildsDegradedTree(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	g := randomSpheresGroup(rng, 200, 20)
+	config := DefaultSAHConfig()
+	DivideSAH(g, config)
+	g.Compile()
+	builtCost := g.builtCost
+
+	for _, s := range collapseSAH(g.children, nil) {
+		s.SetTransform(Translation(rng.Float64()*20-10, rng.Float64()*20-10, rng.Float64()*20-10).
+			MultiplyMatrix(Scaling(0.3, 0.3, 0.3)))
+	}
+
+	if rebuilt := Refit(g, config); !rebuilt {
+		t.Errorf("Refit rebuilt: got %v, expected: %v", rebuilt, true)
+	}
+	if cost := groupSAHCost(g, config); cost > builtCost*config.rebuildRatio {
+		t.Errorf("Refit rebuilt cost: got %v, expected at most: %v", cost, builtCost*config.rebuildRatio)
+	}
+	if g.flat == nil {
+		t.Errorf("Refit rebuilt group compiled: got %v, expected: %v", false, true)
+	}
+	if shapes := len(collapseSAH(g.children, nil)); shapes != 200 {
+		t.Errorf("Refit rebuilt shapes: got %v, expected: %v", shapes, 200)
+	}
+
+	// The rebuilt tree reports the same intersections as the plain list of shapes.
+	shapes := collapseSAH(g.children, nil)
+	hits := 0
+	for i := 0; i < 100; i++ {
+		r := NewRay(Point(rng.Float64()*20-10, rng.Float64()*20-10, -20), Vector(rng.Float64()-0.5, rng.Float64()-0.5, 1).Normalize())
+		var expected Intersections
+		for _, s := range shapes {
+			expected = s.Intersect(r, expected)
+		}
+		xs := g.Intersect(r, nil)
+		hits += len(xs)
+		if len(xs) != len(expected) {
+			t.Fatalf("Refit rebuilt intersections: got %v, expected: %v", len(xs), len(expected))
+		}
+	}
+	if hits == 0 {
+		t.Errorf("Refit rebuilt intersections: got %v, expected some", hits)
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// SAHConfig contains the parameters of the Surface Area Heuristic BVH builder.
+type SAHConfig struct {
+	// leafSize is the number of shapes up to which a node is always a leaf, bigger nodes are split
+	// unless intersecting all of their shapes is cheaper.
+	leafSize int
+	// bins is the number of buckets along an axis in which the split positions are evaluated.
+	bins int
+	// traversalCost is the cost of testing a ray against the bounding box of a node.
+	traversalCost float64
+	// intersectionCost is the cost of intersecting a ray with a single shape.
+	intersectionCost float64
+	// rebuildRatio is how much the SAH cost of a refitted tree may grow, relative to the cost measured
+	// when it was built, before Refit rebuilds it.
+	rebuildRatio float64
+}
+
+// DefaultSAHConfig returns the default parameters of the SAH builder.
+func DefaultSAHConfig() *SAHConfig {
+	return &SAHConfig{
+		leafSize:         4,
+		bins:             16,
+		traversalCost:    1,
+		intersectionCost: 2,
+		rebuildRatio:     1.5,
+	}
+}
+
+// BVHStats reports the shape of a BVH built by DivideSAH.
+// Groups nested in the divided group are included, their own trees hanging below the leaf holding them.
+type BVHStats struct {
+	depth, nodes, leaves     int
+	minLeafSize, maxLeafSize int
+	shapes                   int
+	sahCost                  float64
+}
+
+// AverageLeafSize returns the mean number of shapes per leaf.
+func (stats *BVHStats) AverageLeafSize() float64 {
+	if stats.leaves == 0 {
+		return 0
+	}
+	return float64(stats.shapes) / float64(stats.leaves)
+}
+
+// String formats the statistics of the BVH.
+func (stats *BVHStats) String() string {
+	return fmt.Sprintf("BVH: depth %v, nodes %v, leaves %v, leaf size min %v / avg %.2f / max %v, SAH cost %.3f",
+		stats.depth, stats.nodes, stats.leaves, stats.minLeafSize, stats.AverageLeafSize(), stats.maxLeafSize, stats.sahCost)
+}
+
+// merge adds the statistics of a group divided on its own and found in a leaf at the given depth.
+// Its SAH cost is scaled by the ratio between the surface area of the group and the root's one.
+func (stats *BVHStats) merge(other *BVHStats, depth int, relativeArea float64) {
+	if other.leaves == 0 {
+		return
+	}
+	if depth+other.depth > stats.depth {
+		stats.depth = depth + other.depth
+	}
+	if stats.leaves == 0 || other.minLeafSize < stats.minLeafSize {
+		stats.minLeafSize = other.minLeafSize
+	}
+	if other.maxLeafSize > stats.maxLeafSize {
+		stats.maxLeafSize = other.maxLeafSize
+	}
+	stats.nodes += other.nodes
+	stats.leaves += other.leaves
+	stats.shapes += other.shapes
+	stats.sahCost += relativeArea * other.sahCost
+}
+
+// addLeaf records a leaf of the given size.
+func (stats *BVHStats) addLeaf(size int) {
+	if stats.leaves == 0 || size < stats.minLeafSize {
+		stats.minLeafSize = size
+	}
+	if size > stats.maxLeafSize {
+		stats.maxLeafSize = size
+	}
+	stats.leaves++
+	stats.shapes += size
+}
+
+// sahItem is a child shape along with its bounds in the group's space.
+// stats holds the statistics of the child when it is a group that was divided on its own.
+// Primitives that are not shapes, such as the faces of a Mesh, leave shape nil and use index instead.
+type sahItem struct {
+	shape    Shape
+	bounds   *BoundingBox
+	centroid Tuple
+	stats    *BVHStats
+	index    int
+}
+
+// sahBin accumulates the shapes whose centroid falls into one bucket.
+type sahBin struct {
+	count  int
+	bounds *BoundingBox
+}
+
+// sahNodeLabel is the label of the subgroups created by DivideSAH.
+const sahNodeLabel = "SAH node"
+
+// DivideSAH rebuilds the children of a group into a bounding volume hierarchy using a binned
+// Surface Area Heuristic. Unlike Divide, every child is assigned to one side of each split, so no
+// shape is left behind at the top of the tree. Groups found among the children are divided first
+// and then treated as single shapes, CSG children are divided as well.
+// Children with infinite bounds, such as planes, stay directly in the group. A group that was already
+// divided is rebuilt from scratch.
+func DivideSAH(s Shape, config *SAHConfig) *BVHStats {
+	stats := &BVHStats{}
+
+	switch g := s.(type) {
+	case *CSG:
+		DivideSAH(g.left, config)
+		DivideSAH(g.right, config)
+
+	case *Group:
+		children := collapseSAH(g.children, nil)
+		items := make([]sahItem, 0, len(children))
+		unbounded := make([]Shape, 0)
+		for _, child := range children {
+			childStats := DivideSAH(child, config)
+
+			bounds := ParentSpaceBounds(child)
+			if !bounds.IsFinite() {
+				unbounded = append(unbounded, child)
+				continue
+			}
+			items = append(items, sahItem{shape: child, bounds: bounds, centroid: bounds.Centroid(), stats: childStats})
+		}
+		if len(items) == 0 {
+			return stats
+		}
+
+		rootBounds := NewEmptyBoundingBox()
+		for i := range items {
+			rootBounds.Merge(items[i].bounds)
+		}
+
+		g.flat = nil
+		g.children = g.children[:0]
+		g.children = append(g.children, unbounded...)
+		stats.sahCost += buildSAH(g, items, rootBounds, rootBounds.SurfaceArea(), config, stats, 0)
+		g.Bounds()
+		g.builtCost = groupSAHCost(g, config)
+	}
+
+	return stats
+}
+
+// buildSAH adds the items to the group, either directly when they fit in a leaf or when splitting
+// them costs more than intersecting all of them, or split in two subgroups. Returns the SAH cost of the subtree relative to the root's surface area.
+func buildSAH(g *Group, items []sahItem, bounds *BoundingBox, rootArea float64, config *SAHConfig, stats *BVHStats, depth int) float64 {
+	if depth > stats.depth {
+		stats.depth = depth
+	}
+
+	relativeArea := 1.0
+	if rootArea > 0 {
+		relativeArea = bounds.SurfaceArea() / rootArea
+	}
+
+	mid, splitCost := 0, math.Inf(1)
+	if len(items) > config.leafSize {
+		mid, splitCost = partitionSAH(items, bounds, config)
+	}
+
+	if splitCost >= config.intersectionCost*float64(len(items)) {
+		stats.addLeaf(len(items))
+		for i := range items {
+			g.AddChild(items[i].shape)
+			if items[i].stats != nil && rootArea > 0 {
+				stats.merge(items[i].stats, depth+1, items[i].bounds.SurfaceArea()/rootArea)
+			}
+		}
+		return relativeArea * float64(len(items)) * config.intersectionCost
+	}
+
+	stats.nodes++
+	cost := relativeArea * config.traversalCost
+	for _, half := range [][]sahItem{items[:mid], items[mid:]} {
+		halfBounds := NewEmptyBoundingBox()
+		for i := range half {
+			halfBounds.Merge(half[i].bounds)
+		}
+		subgroup := NewGroup()
+		subgroup.label = sahNodeLabel
+		cost += buildSAH(subgroup, half, halfBounds, rootArea, config, stats, depth+1)
+		g.AddChild(subgroup)
+	}
+	return cost
+}
+
+// collapseSAH appends the children to shapes, replacing the subgroups created by DivideSAH with
+// the shapes they contain.
+func collapseSAH(children []Shape, shapes []Shape) []Shape {
+	for _, child := range children {
+		if sub, ok := child.(*Group); ok && sub.label == sahNodeLabel {
+			shapes = collapseSAH(sub.children, shapes)
+			continue
+		}
+		shapes = append(shapes, child)
+	}
+	return shapes
+}
+
+// partitionSAH reorders the items so that the ones before the returned index go to the left child
+// and the other ones go to the right child. The split with the lowest SAH cost among the bins
+// boundaries of the three axes is used. When all the centroids are at the same place, the items
+// are split in two halves. Also returns the SAH cost of the split relative to bounds, the bounds of
+// all the items, to be compared with the cost of intersecting all of them in a leaf.
+func partitionSAH(items []sahItem, bounds *BoundingBox, config *SAHConfig) (int, float64) {
+	centroidBounds := NewEmptyBoundingBox()
+	for i := range items {
+		centroidBounds.Add(items[i].centroid)
+	}
+
+	bestAxis, bestSplit := -1, 0
+	bestCost := math.Inf(1)
+	bins := make([]sahBin, config.bins)
+	rightAreas := make([]float64, config.bins)
+	rightCounts := make([]int, config.bins)
+
+	for axis := 0; axis < 3; axis++ {
+		low, high := axisValue(centroidBounds.min, axis), axisValue(centroidBounds.max, axis)
+		if high-low < EPSILON {
+			continue
+		}
+
+		for b := range bins {
+			bins[b] = sahBin{0, NewEmptyBoundingBox()}
+		}
+		for i := range items {
+			b := binIndex(axisValue(items[i].centroid, axis), low, high, config.bins)
+			bins[b].count++
+			bins[b].bounds.Merge(items[i].bounds)
+		}
+
+		// Sweep from the right to know the area and count on the right of every split.
+		right := NewEmptyBoundingBox()
+		count := 0
+		for b := config.bins - 1; b > 0; b-- {
+			right.Merge(bins[b].bounds)
+			count += bins[b].count
+			rightAreas[b] = right.SurfaceArea()
+			rightCounts[b] = count
+		}
+
+		// Then sweep from the left, a split at b sends the bins [0, b) to the left.
+		left := NewEmptyBoundingBox()
+		count = 0
+		for b := 1; b < config.bins; b++ {
+			left.Merge(bins[b-1].bounds)
+			count += bins[b-1].count
+			if count == 0 || rightCounts[b] == 0 {
+				continue
+			}
+			cost := left.SurfaceArea()*float64(count) + rightAreas[b]*float64(rightCounts[b])
+			if cost < bestCost {
+				bestAxis, bestSplit, bestCost = axis, b, cost
+			}
+		}
+	}
+
+	area := bounds.SurfaceArea()
+	if bestAxis == -1 {
+		mid := len(items) / 2
+		left, right := NewEmptyBoundingBox(), NewEmptyBoundingBox()
+		for i := range items {
+			if i < mid {
+				left.Merge(items[i].bounds)
+			} else {
+				right.Merge(items[i].bounds)
+			}
+		}
+		bestCost = left.SurfaceArea()*float64(mid) + right.SurfaceArea()*float64(len(items)-mid)
+		return mid, config.traversalCost + config.intersectionCost*bestCost/area
+	}
+
+	low, high := axisValue(centroidBounds.min, bestAxis), axisValue(centroidBounds.max, bestAxis)
+	mid := 0
+	for i := range items {
+		if binIndex(axisValue(items[i].centroid, bestAxis), low, high, config.bins) < bestSplit {
+			items[i], items[mid] = items[mid], items[i]
+			mid++
+		}
+	}
+	return mid, config.traversalCost + config.intersectionCost*bestCost/area
+}
+
+// binIndex returns the bucket of a centroid coordinate between low and high.
+func binIndex(value, low, high float64, bins int) int {
+	b := int(float64(bins) * (value - low) / (high - low))
+	if b >= bins {
+		b = bins - 1
+	}
+	if b < 0 {
+		b = 0
+	}
+	return b
+}
+
+// axisValue returns the x, y or z component of a tuple for axis 0, 1 or 2.
+func axisValue(t Tuple, axis int) float64 {
+	switch axis {
+	case 0:
+		return t.x
+	case 1:
+		return t.y
+	default:
+		return t.z
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// sahLeaves returns the number of shapes in every leaf of a tree built by DivideSAH.
+func sahLeaves(g *Group) []int {
+	sizes := []int{}
+	shapes := 0
+	for _, child := range g.children {
+		if sub, ok := child.(*Group); ok && sub.label == sahNodeLabel {
+			sizes = append(sizes, sahLeaves(sub)...)
+		} else {
+			shapes++
+		}
+	}
+	if shapes > 0 {
+		sizes = append(sizes, shapes)
+	}
+	return sizes
+}
+
+func TestDivideSAHPartitionsEveryChild(t *testing.T) {
+	// A shape straddling the middle of the group is still moved into a subgroup.
+	s1 := NewSphere()
+	s1.SetTransform(Translation(-2, -2, 0))
+	s2 := NewSphere()
+	s2.SetTransform(Translation(-2, 2, 0))
+	s3 := NewSphere()
+	s3.SetTransform(Scaling(4, 4, 4))
+
+	g := NewGroup()
+	g.AddChild(s1, s2, s3)
+
+	config := DefaultSAHConfig()
+	config.leafSize = 1
+	stats := DivideSAH(g, config)
+
+	if len(g.children) != 2 {
+		t.Fatalf("DivideSAH: got %v children, expected: %v", len(g.children), 2)
+	}
+	for _, child := range g.children {
+		if _, ok := child.(*Group); !ok {
+			t.Errorf("DivideSAH: got %v at the top of the tree, expected only subgroups", child)
+		}
+	}
+	if stats.leaves != 3 || stats.nodes != 2 || stats.depth != 2 || stats.maxLeafSize != 1 {
+		t.Errorf("DivideSAH stats: got %v", stats)
+	}
+	for _, s := range []Shape{s1, s2, s3} {
+		if !includes(g, s) {
+			t.Errorf("DivideSAH: %v is missing from the tree", s)
+		}
+	}
+}
+
+func TestDivideSAHKeepsLeafWhenSplittingCostsMore(t *testing.T) {
+	// Shapes overlapping almost entirely stay in a single leaf, whatever the leaf size.
+	g := NewGroup()
+	for i := 0; i < 6; i++ {
+		s := NewSphere()
+		s.SetTransform(Translation(float64(i)*0.01, 0, 0))
+		g.AddChild(s)
+	}
+
+	config := DefaultSAHConfig()
+	config.leafSize = 1
+	stats := DivideSAH(g, config)
+
+	if stats.nodes != 0 || stats.leaves != 1 || stats.maxLeafSize != 6 {
+		t.Errorf("DivideSAH stats: got %v, expected a single leaf of %v shapes", stats, 6)
+	}
+	if len(g.children) != 6 {
+		t.Errorf("DivideSAH: got %v children, expected: %v", len(g.children), 6)
+	}
+	expected := 6 * config.intersectionCost
+	if !floatEqual(stats.sahCost, expected) {
+		t.Errorf("DivideSAH cost: got %v, expected: %v", stats.sahCost, expected)
+	}
+}
+
+func TestDivideSAHLeafSize(t *testing.T) {
+	// Shapes spread apart are split down to the configured leaf size and the intersections don't change.
+	rng := rand.New(rand.NewSource(1))
+	g := NewGroup()
+	reference := NewGroup()
+	for i := 0; i < 200; i++ {
+		x, y, z := rng.Float64()*20-10, rng.Float64()*20-10, rng.Float64()*20-10
+		s := NewSphere()
+		s.SetTransform(Translation(x, y, z).MultiplyMatrix(Scaling(0.3, 0.3, 0.3)))
+		g.AddChild(s)
+		r := NewSphere()
+		r.SetTransform(Translation(x, y, z).MultiplyMatrix(Scaling(0.3, 0.3, 0.3)))
+		reference.AddChild(r)
+	}
+
+	config := DefaultSAHConfig()
+	config.leafSize = 3
+	stats := DivideSAH(g, config)
+
+	shapes := 0
+	for _, size := range sahLeaves(g) {
+		if size > 3 {
+			t.Errorf("DivideSAH leaf size: got %v, expected at most: %v", size, 3)
+		}
+		shapes += size
+	}
+	if shapes != 200 || stats.shapes != 200 {
+		t.Errorf("DivideSAH: got %v shapes in the leaves (stats %v), expected: %v", shapes, stats.shapes, 200)
+	}
+	if stats.sahCost <= 0 || stats.depth < 6 {
+		t.Errorf("DivideSAH stats: got %v", stats)
+	}
+
+	for i := 0; i < 200; i++ {
+		r := NewRay(Point(rng.Float64()*20-10, rng.Float64()*20-10, -20), Vector(rng.Float64()-0.5, rng.Float64()-0.5, 1).Normalize())
+		xs := g.Intersect(r, nil)
+		expected := reference.Intersect(r, nil)
+		if len(xs) != len(expected) {
+			t.Fatalf("DivideSAH intersections: got %v, expected: %v", len(xs), len(expected))
+		}
+		for j := range xs {
+			if !floatEqual(xs[j].t, expected[j].t) {
+				t.Errorf("DivideSAH intersections: got %v, expected: %v", xs[j].t, expected[j].t)
+			}
+		}
+	}
+}
+
+func TestDivideSAHKeepsUnboundedShapes(t *testing.T) {
+	// Planes can't be placed in the hierarchy and stay in the group.
+	p := NewPlane()
+	g := NewGroup()
+	g.AddChild(p)
+	for i := 0; i < 5; i++ {
+		s := NewSphere()
+		s.SetTransform(Translation(float64(i)*3, 0, 0))
+		g.AddChild(s)
+	}
+
+	config := DefaultSAHConfig()
+	config.leafSize = 2
+	stats := DivideSAH(g, config)
+
+	if g.children[0] != p {
+		t.Errorf("DivideSAH unbounded: got %v, expected: %v", g.children[0], p)
+	}
+	if stats.shapes != 5 {
+		t.Errorf("DivideSAH unbounded: got %v shapes in the hierarchy, expected: %v", stats.shapes, 5)
+	}
+}
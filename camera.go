@@ -10,7 +10,9 @@ import (
 type Camera struct {
 	hsize, vsize                                  int
 	fieldOfView, halfWidth, halfHeight, pixelSize float64
-	transform                                     Matrix
+	transform, inverse                            Matrix4
+	trs                                           TRS
+	origin                                        Tuple
 }
 
 // NewCamera returns a pointer to a default camera.
@@ -22,6 +24,9 @@ func NewCamera(hsize, vsize int, fieldOfView float64) *Camera {
 		halfHeight:  0,
 		pixelSize:   0,
 		transform:   NewIdentityMatrix(),
+		inverse:     NewIdentityMatrix(),
+		trs:         NewTRS(),
+		origin:      Point(0, 0, 0),
 	}
 	c.SetPixelSize()
 	return c
@@ -43,7 +48,7 @@ func (cam *Camera) SetPixelSize() {
 
 // RayForPixel computes the world coordinates at the center of the given pixel,
 // and then construct a ray that passes through that point.
-func (cam *Camera) RayForPixel(x, y int) *Ray {
+func (cam *Camera) RayForPixel(x, y int) Ray {
 	px := float64(x)
 	py := float64(y)
 	xoffset := (px + 0.5) * cam.pixelSize
@@ -52,32 +57,92 @@ func (cam *Camera) RayForPixel(x, y int) *Ray {
 	worldx := cam.halfWidth - xoffset
 	worldy := cam.halfHeight - yoffset
 
-	pixel := cam.transform.Inverse().MultiplyMatrixByTuple(Point(worldx, worldy, -1))
+	// Use the cached inverse of the camera transformation and the cached origin of the camera.
+	pixel := cam.inverse.MultiplyMatrixByTuple(Point(worldx, worldy, -1))
 
-	origin := cam.transform.Inverse().MultiplyMatrixByTuple(Point(0, 0, 0))
+	direction := pixel.Substract(cam.origin).Normalize()
 
-	direction := pixel.Substract(origin).Normalize()
+	return NewRay(cam.origin, direction)
+}
+
+// SetTransform replaces the camera’s transformation describing how the world is moved relative to the camera.
+// The translation, rotation and scale components are recovered from the new transformation.
+func (cam *Camera) SetTransform(transform Matrix4) {
+	cam.trs = TRSFromMatrix(transform)
+	cam.updateTransform(transform)
+}
+
+// ApplyTransform composes the transformation with the current one of the camera.
+func (cam *Camera) ApplyTransform(transform Matrix4) {
+	cam.SetTransform(cam.transform.MultiplyMatrix(transform))
+}
+
+// ResetTransform sets the camera’s transformation back to the identity.
+func (cam *Camera) ResetTransform() {
+	cam.SetTransform(IdentityMatrix)
+}
+
+// SetTranslation replaces the translation component and rebuilds the camera’s transformation from its components.
+func (cam *Camera) SetTranslation(x, y, z float64) {
+	cam.trs.translation = Vector(x, y, z)
+	cam.updateTransform(cam.trs.Matrix())
+}
+
+// SetRotation replaces the rotation component and rebuilds the camera’s transformation from its components.
+func (cam *Camera) SetRotation(x, y, z float64) {
+	cam.trs.rotation = Vector(x, y, z)
+	cam.updateTransform(cam.trs.Matrix())
+}
+
+// SetScale replaces the scale component and rebuilds the camera’s transformation from its components.
+func (cam *Camera) SetScale(x, y, z float64) {
+	cam.trs.scale = Vector(x, y, z)
+	cam.updateTransform(cam.trs.Matrix())
+}
+
+// GetTranslation returns the translation component of the camera.
+func (cam *Camera) GetTranslation() Tuple {
+	return cam.trs.translation
+}
 
-	return NewRay(origin, direction)
+// GetRotation returns the rotation component of the camera.
+func (cam *Camera) GetRotation() Tuple {
+	return cam.trs.rotation
 }
 
-// SetTransform sets the camera’s transformation describing how the world is moved relative to the camera.
-func (cam *Camera) SetTransform(transform Matrix) {
+// GetScale returns the scale component of the camera.
+func (cam *Camera) GetScale() Tuple {
+	return cam.trs.scale
+}
+
+// Transform returns the transformation of the camera.
+func (cam *Camera) Transform() Matrix4 {
+	return cam.transform
+}
+
+// updateTransform sets the transformation of the camera.
+// The inverse and the resulting origin of the camera are cached, since every ray depends on them.
+func (cam *Camera) updateTransform(transform Matrix4) {
 	cam.transform = transform
+	cam.inverse = transform.Inverse()
+	cam.origin = cam.inverse.MultiplyMatrixByTuple(Point(0, 0, 0))
 }
 
 // Render calculates the render of a given world on a canvas from the view of the camera.
+// The BVH of the world is brought up to date before the first ray is traced.
 func (cam *Camera) Render(world *World, recursionDepth int) *Canvas {
 	image := NewCanvas(cam.hsize, cam.vsize)
+	world.UpdateBVH()
 
 	var wg sync.WaitGroup
 
 	for y := 0; y < cam.vsize; y++ {
 		wg.Add(1)
 		go func(y int) {
+			buf := newIntersectionBuffer()
 			for x := 0; x < cam.hsize; x++ {
 				ray := cam.RayForPixel(x, y)
-				color := world.ColorAt(ray, recursionDepth)
+				color := world.colorAt(ray, recursionDepth, buf)
 				image.WritePixel(x, y, color)
 			}
 			wg.Done()
@@ -89,21 +154,24 @@ func (cam *Camera) Render(world *World, recursionDepth int) *Canvas {
 
 type renderResult struct {
 	x, y  int
-	color *Color
+	color Color
 }
 
 // renderWorker function meant to be instantiated as a independent thread.
-func (cam *Camera) renderWorker(world *World, recursionDepth int, jobs <-chan int, results chan<- *renderResult, wg *sync.WaitGroup, workerId int) {
+// Each worker owns the intersection buffer reused for every ray it traces.
+func (cam *Camera) renderWorker(world *World, recursionDepth int, jobs <-chan int, results chan<- renderResult, wg *sync.WaitGroup, workerId int) {
 	defer wg.Done()
 
+	buf := newIntersectionBuffer()
+
 	for y := range jobs {
 		for x := 0; x < cam.hsize; x++ {
 			ray := cam.RayForPixel(x, y)
 
 			// fmt.Println("worker:", workerId, "got job y:", y, "x:", x, "color", world.ColorAt(ray, recursionDepth))
 
-			results <- &renderResult{
-				color: world.ColorAt(ray, recursionDepth),
+			results <- renderResult{
+				color: world.colorAt(ray, recursionDepth, buf),
 				x:     x,
 				y:     y,
 			}
@@ -114,8 +182,10 @@ func (cam *Camera) renderWorker(world *World, recursionDepth int, jobs <-chan in
 
 // RenderWithThreadPool calculates the render of a given world on a canvas from the view of the camera.
 // this will use a limited amount of threads as workers for rendering.
+// The BVH of the world is brought up to date before the first ray is traced.
 func (cam *Camera) RenderWithThreadPool(world *World, recursionDepth int) *Canvas {
 	image := NewCanvas(cam.hsize, cam.vsize)
+	world.UpdateBVH()
 
 	var wg sync.WaitGroup
 
@@ -124,7 +194,7 @@ func (cam *Camera) RenderWithThreadPool(world *World, recursionDepth int) *Canva
 	numJobs := cam.vsize
 	resultSize := cam.vsize * cam.hsize
 	jobs := make(chan int, numJobs)
-	results := make(chan *renderResult, resultSize)
+	results := make(chan renderResult, resultSize)
 
 	for worker := 1; worker <= threadSize; worker++ {
 		wg.Add(1)
@@ -142,9 +212,9 @@ func (cam *Camera) RenderWithThreadPool(world *World, recursionDepth int) *Canva
 
 		resultStruct := <-results
 
-		countYCanvasProcessed(&yComplete, resultStruct)
+		countYCanvasProcessed(&yComplete, &resultStruct)
 
-		image.WritePixel((*resultStruct).x, (*resultStruct).y, (*resultStruct).color)
+		image.WritePixel(resultStruct.x, resultStruct.y, resultStruct.color)
 	}
 
 	wg.Wait()
@@ -54,6 +54,33 @@ func TestCameraRayForPixel(t *testing.T) {
 	}
 }
 
+func TestCameraTransformAPI(t *testing.T) {
+	// The camera shares the transform API of the shapes.
+	c := NewCamera(201, 101, PI/2)
+	c.SetTranslation(0, -2, 5)
+	c.SetRotation(0, PI/4, 0)
+	if !c.Transform().Equals(Translation(0, -2, 5).MultiplyMatrix(RotationY(PI / 4))) {
+		t.Errorf("Camera transform components: got %v, expected: %v", c.Transform(), Translation(0, -2, 5).MultiplyMatrix(RotationY(PI/4)))
+	}
+
+	expected := RotationY(PI / 4).MultiplyMatrix(Translation(0, -2, 5))
+	c.SetTransform(RotationY(PI / 4))
+	c.ApplyTransform(Translation(0, -2, 5))
+	if !c.Transform().Equals(expected) {
+		t.Errorf("Camera ApplyTransform: got %v, expected: %v", c.Transform(), expected)
+	}
+	r := c.RayForPixel(100, 50)
+	if !r.origin.Equals(Point(0, 2, -5)) {
+		t.Errorf("Camera ApplyTransform origin: got %v, expected: %v", r.origin, Point(0, 2, -5))
+	}
+
+	c.ResetTransform()
+	r = c.RayForPixel(100, 50)
+	if !r.Equals(NewRay(Point(0, 0, 0), Vector(0, 0, -1))) {
+		t.Errorf("Camera ResetTransform: got %v, expected: %v", r, NewRay(Point(0, 0, 0), Vector(0, 0, -1)))
+	}
+}
+
 // Rendering a world with a camera.
 func TestCameraRender(t *testing.T) {
 
@@ -73,3 +100,22 @@ func TestCameraRender(t *testing.T) {
 		t.Errorf("TestCameraRender(default world): expected %v to be %v", result, expected)
 	}
 }
+
+func BenchmarkCameraRayForPixel(b *testing.B) {
+	c := NewCamera(100, 50, PI/3)
+	c.SetTransform(ViewTransform(Point(0, 1.5, -5), Point(0, 1, 0), Vector(0, 1, 0)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.RayForPixel(i%c.hsize, (i/c.hsize)%c.vsize)
+	}
+}
+
+func BenchmarkCameraRender(b *testing.B) {
+	w := DefaultWorld()
+	c := NewCamera(50, 25, PI/2)
+	c.SetTransform(ViewTransform(Point(0, 0, -5), Point(0, 0, 0), Vector(0, 1, 0)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.Render(w, defaultRecursionDepth)
+	}
+}
@@ -1,16 +1,22 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"image"
+	// Register the decoders of the image formats read by canvasFromImageData.
+	_ "image/jpeg"
+	_ "image/png"
 	"strconv"
 	"strings"
 )
 
 // Canvas contains the color information for every displayable pixel.
+// Pixels are stored row after row in a single flat slice, the pixel (x, y) being at index y*width+x.
 type Canvas struct {
 	width, height    int
-	pixels           [][]*Color
+	pixels           []Color
 	originX, originY int
 }
 
@@ -19,29 +25,23 @@ func NewCanvas(width int, height int) *Canvas {
 	canvas := &Canvas{
 		width:   width,
 		height:  height,
-		pixels:  make([][]*Color, height, height),
+		pixels:  make([]Color, width*height),
 		originX: 0,
 		originY: 0,
 	}
-	for y := 0; y < height; y++ {
-		canvas.pixels[y] = make([]*Color, width, width)
-		for x := 0; x < width; x++ {
-			canvas.pixels[y][x] = NewColor(0, 0, 0)
-		}
-	}
 	return canvas
 }
 
 // WritePixel assign a color to a pixel at a specified position.
-func (canvas *Canvas) WritePixel(x, y int, c *Color) {
+func (canvas *Canvas) WritePixel(x, y int, c Color) {
 	if !canvas.checkBounds(x, y) {
 		return
 	}
-	canvas.pixels[y][x] = NewColor(c.r, c.g, c.b)
+	canvas.pixels[y*canvas.width+x] = c
 }
 
 // WriteTuple assign a color to a pixel at a specified tuple (point) position relative from the canvas X, Y origin.
-func (canvas *Canvas) WriteTuple(point *Tuple, c *Color) {
+func (canvas *Canvas) WriteTuple(point Tuple, c Color) {
 	canvas.WritePixel(int(point.x)+canvas.originX, (int(point.y) + canvas.originY), c)
 }
 
@@ -69,12 +69,12 @@ func (canvas *Canvas) checkBounds(x, y int) (check bool) {
 	return
 }
 
-// PixelAt returns a color reference of a specific pixel.
-func (canvas *Canvas) PixelAt(x, y int) *Color {
+// PixelAt returns the color of a specific pixel.
+func (canvas *Canvas) PixelAt(x, y int) Color {
 	if !canvas.checkBounds(x, y) {
-		return nil
+		return Black
 	}
-	return canvas.pixels[y][x]
+	return canvas.pixels[y*canvas.width+x]
 }
 
 // ToPPM returns the canvas information into string based fo.
@@ -91,7 +91,7 @@ func (canvas *Canvas) ToPPM() string {
 
 		lines = append(lines, []string{})
 		for x := 0; x < canvas.width; x++ {
-			pixelColorStringFormat := canvas.pixels[y][x].colorToStringFormat()
+			pixelColorStringFormat := canvas.pixels[y*canvas.width+x].colorToStringFormat()
 
 			lines[len(lines)-1] = append(lines[len(lines)-1], pixelColorStringFormat)
 		}
@@ -223,6 +223,28 @@ func canvasFromPPM(data string) (*Canvas, error) {
 	return canvas, nil
 }
 
+// canvasFromImageData returns a canvas with the pixels of a PNG or JPEG image file.
+func canvasFromImageData(data []byte) (*Canvas, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return canvasFromImage(img), nil
+}
+
+// canvasFromImage returns a canvas with the pixels of an image decoded by the image package.
+func canvasFromImage(img image.Image) *Canvas {
+	bounds := img.Bounds()
+	canvas := NewCanvas(bounds.Dx(), bounds.Dy())
+	for y := 0; y < canvas.height; y++ {
+		for x := 0; x < canvas.width; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			canvas.WritePixel(x, y, NewColor(float64(r)/0xffff, float64(g)/0xffff, float64(b)/0xffff))
+		}
+	}
+	return canvas
+}
+
 // commentOrBlankDetected checks for commented or empty line.
 func commentOrBlankDetected(s string) bool {
 
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"encoding/base64"
+	"image"
+	"image/color"
+	"math"
 	"strings"
 	"testing"
 )
@@ -10,17 +14,14 @@ func TestNewCanvas(t *testing.T) {
 	w := 20
 	canvas := NewCanvas(w, h)
 
-	if len(canvas.pixels) != h {
-		t.Errorf("NewCanvas: height of canvas should be %v but got %v", h, len(canvas.pixels))
+	if len(canvas.pixels) != w*h {
+		t.Errorf("NewCanvas: size of canvas should be %v but got %v", w*h, len(canvas.pixels))
 	}
 	defaultBlackColor := NewColor(0, 0, 0)
 
-	for y, row := range canvas.pixels {
-		if len(row) != w {
-			t.Errorf("NewCanvas: width of canvas should be %v but got %v", w, len(row))
-		}
-		for x, xPixel := range row {
-			if !xPixel.Equals(defaultBlackColor) {
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !canvas.PixelAt(x, y).Equals(defaultBlackColor) {
 				t.Errorf("NewCanvas: pixel at %v,%v is not of default color", x, y)
 			}
 		}
@@ -185,7 +186,7 @@ func TestReadPixelDataFromPPM(t *testing.T) {
 	type testStruct struct {
 		x             int
 		y             int
-		expectedColor *Color
+		expectedColor Color
 	}
 
 	expectedTest := []testStruct{
@@ -237,7 +238,7 @@ func TestPPMParseIgnoreCommentLines(t *testing.T) {
 	type testStruct struct {
 		x             int
 		y             int
-		expectedColor *Color
+		expectedColor Color
 	}
 
 	expectedTest := []testStruct{
@@ -277,7 +278,7 @@ func TestPPMParseAllowRGBTripletSpanLine(t *testing.T) {
 	type testStruct struct {
 		x             int
 		y             int
-		expectedColor *Color
+		expectedColor Color
 	}
 
 	expectedTest := []testStruct{
@@ -314,7 +315,7 @@ func TestPPMParseRespectsScaleSetting(t *testing.T) {
 	type testStruct struct {
 		x             int
 		y             int
-		expectedColor *Color
+		expectedColor Color
 	}
 
 	expectedTest := []testStruct{
@@ -335,3 +336,53 @@ func TestPPMParseRespectsScaleSetting(t *testing.T) {
 		}
 	}
 }
+
+// Decoded images are converted to canvases.
+func TestCanvasFromImage(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.SetGray(0, 0, color.Gray{Y: 0})
+	img.SetGray(1, 0, color.Gray{Y: 255})
+
+	canvas := canvasFromImage(img)
+	if canvas.width != 2 || canvas.height != 1 {
+		t.Errorf("Canvas from image size: got %vx%v, expected: %vx%v", canvas.width, canvas.height, 2, 1)
+	}
+	if c := canvas.PixelAt(1, 0); !c.Equals(NewColor(1, 1, 1)) {
+		t.Errorf("Canvas from image pixel: got %v, expected: %v", c, NewColor(1, 1, 1))
+	}
+	if c := canvas.PixelAt(0, 0); !c.Equals(NewColor(0, 0, 0)) {
+		t.Errorf("Canvas from image pixel: got %v, expected: %v", c, NewColor(0, 0, 0))
+	}
+}
+
+// canvasTestPNG and canvasTestJPEG are 8x4 images filled with (255, 128, 0), encoded by the image
+// package. They are kept encoded so that the test doesn't register the decoders itself.
+const (
+	canvasTestPNG  = "iVBORw0KGgoAAAANSUhEUgAAAAgAAAAECAIAAAA8r+mnAAAAJElEQVR4nATAgQ0AAATAsGZxuM/p3AIAAAEAAAQAABAAAMAPAJa9AYpE9xaAAAAAAElFTkSuQmCC"
+	canvasTestJPEG = "/9j/2wCEAAEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAf/AABEIAAQACAMBIgACEQEDEQH/xAGiAAABBQEBAQEBAQAAAAAAAAAAAQIDBAUGBwgJCgsQAAIBAwMCBAMFBQQEAAABfQECAwAEEQUSITFBBhNRYQcicRQygZGhCCNCscEVUtHwJDNicoIJChYXGBkaJSYnKCkqNDU2Nzg5OkNERUZHSElKU1RVVldYWVpjZGVmZ2hpanN0dXZ3eHl6g4SFhoeIiYqSk5SVlpeYmZqio6Slpqeoqaqys7S1tre4ubrCw8TFxsfIycrS09TV1tfY2drh4uPk5ebn6Onq8fLz9PX29/j5+gEAAwEBAQEBAQEBAQAAAAAAAAECAwQFBgcICQoLEQACAQIEBAMEBwUEBAABAncAAQIDEQQFITEGEkFRB2FxEyIygQgUQpGhscEJIzNS8BVictEKFiQ04SXxFxgZGiYnKCkqNTY3ODk6Q0RFRkdISUpTVFVWV1hZWmNkZWZnaGlqc3R1dnd4eXqCg4SFhoeIiYqSk5SVlpeYmZqio6Slpqeoqaqys7S1tre4ubrCw8TFxsfIycrS09TV1tfY2dri4+Tl5ufo6ery8/T19vf4+fr/2gAMAwEAAhEDEQA/APriiiiv+Vc/6UD/2Q=="
+)
+
+// PNG and JPEG files are decoded into canvases.
+func TestCanvasFromImageData(t *testing.T) {
+	for _, encoded := range []string{canvasTestPNG, canvasTestJPEG} {
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			t.Fatalf("Image data: %v", err)
+		}
+		canvas, err := canvasFromImageData(data)
+		if err != nil {
+			t.Fatalf("Canvas from image data: got %v, expected no error", err)
+		}
+		if canvas.width != 8 || canvas.height != 4 {
+			t.Errorf("Canvas from image data size: got %vx%v, expected: %vx%v", canvas.width, canvas.height, 8, 4)
+		}
+		c, expected := canvas.PixelAt(3, 2), NewColor(1, 128.0/255, 0)
+		if math.Abs(c.r-expected.r) > 0.02 || math.Abs(c.g-expected.g) > 0.02 || math.Abs(c.b-expected.b) > 0.02 {
+			t.Errorf("Canvas from image data pixel: got %v, expected: %v", c, expected)
+		}
+	}
+
+	if _, err := canvasFromImageData([]byte("P3 not an image")); err == nil {
+		t.Errorf("Canvas from invalid image data: got no error, expected an error")
+	}
+}
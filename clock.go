@@ -2,7 +2,7 @@ package main
 
 // Clock will return a *Canvas with the pixels drawn in a circle ()representing the 12 hours of a clock.
 func Clock() *Canvas {
-	colors := [3]*Color{NewColor(1, 0, 0), NewColor(0, 1, 0), NewColor(0, 0, 1)}
+	colors := [3]Color{NewColor(1, 0, 0), NewColor(0, 1, 0), NewColor(0, 0, 1)}
 
 	width, height := 400, 400
 	canvas := NewCanvas(width, height)
@@ -29,12 +29,12 @@ func csgWorld() *Canvas {
 
 	c1.SetMaterial(m2)
 	c1.SetTransform(Translation(-0.5, 0, 0))
-	c1.SetTransform(Scaling(0.75, 0.5, 2.5))
+	c1.ApplyTransform(Scaling(0.75, 0.5, 2.5))
 
 	csg := NewCSG("difference", s1, c1)
 	csg.SetTransform(Translation(0, 1, 0))
-	csg.SetTransform(RotationY(-math.Pi / 1.7))
-	csg.SetTransform(RotationZ(-math.Pi / 4))
+	csg.ApplyTransform(RotationY(-math.Pi / 1.7))
+	csg.ApplyTransform(RotationZ(-math.Pi / 4))
 
 	world := NewWorld(lights, []Shape{p1, csg})
 
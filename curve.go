@@ -0,0 +1,458 @@
+package main
+
+import "math"
+
+// curveMode tells how the width of Curves is interpreted.
+type curveMode int
+
+const (
+	curveRibbon curveMode = iota
+	curveTube
+)
+
+// curveSplitDepth is the number of times each curve is split in two for the BVH of Curves, so
+// that long curves get tight boxes.
+const curveSplitDepth = 3
+
+// curveSegment is a part of a curve between the parameters u0 and u1, with its own control points
+// and the number of times it is split in two when intersected.
+type curveSegment struct {
+	points [4]Tuple
+	curve  int
+	u0, u1 float64
+	depth  int
+}
+
+// Curves is a set of cubic Bezier curves with a width varying linearly from their start to their
+// end, such as hair, fur or grass, that would otherwise take many tiny cylinders or triangles.
+// Ribbons are flat strips oriented by a normal at each end of the curve. Tubes are round and are
+// shaded as fibers, from their tangent instead of their normal.
+//
+// Rays are intersected in a space where they run along the z axis: the curve is subdivided until
+// its segments are flat enough to be tested as lines against the distance to the ray. The curves
+// are split into segments held by the BVH of the shape, like the faces of a Mesh.
+type Curves struct {
+	BaseShape
+	mode curveMode
+	// points holds the four control points of each curve, widths and normals the values at
+	// their start and end.
+	points   []Tuple
+	widths   []float64
+	normals  []Tuple
+	segments []curveSegment
+	// nodes is the BVH over the segments, its leaves referencing segments in segmentOrder.
+	nodes        []flatBVHNode
+	segmentOrder []int
+}
+
+// NewRibbons returns flat *Curves with four control points, two widths and two normals per curve,
+// with Identity matrix as transform and default material.
+func NewRibbons(points []Tuple, widths []float64, normals []Tuple) *Curves {
+	return newCurves(curveRibbon, points, widths, normals)
+}
+
+// NewTubes returns round *Curves with four control points and two widths per curve, with Identity
+// matrix as transform and default material.
+func NewTubes(points []Tuple, widths []float64) *Curves {
+	return newCurves(curveTube, points, widths, nil)
+}
+
+// newCurves returns *Curves of the given mode with the BVH of their segments.
+func newCurves(mode curveMode, points []Tuple, widths []float64, normals []Tuple) *Curves {
+	curves := &Curves{
+		BaseShape: NewBaseShape(),
+		mode:      mode,
+		points:    points,
+		widths:    widths,
+		normals:   normals,
+	}
+	curves.build(DefaultSAHConfig())
+	return curves
+}
+
+// CurveCount returns the number of curves.
+func (curves *Curves) CurveCount() int {
+	return len(curves.points) / 4
+}
+
+// controlPoints returns the control points of a curve.
+func (curves *Curves) controlPoints(curve int) [4]Tuple {
+	var cp [4]Tuple
+	copy(cp[:], curves.points[4*curve:4*curve+4])
+	return cp
+}
+
+// widthAt returns the width of a curve at the parameter u.
+func (curves *Curves) widthAt(curve int, u float64) float64 {
+	return curves.widths[2*curve]*(1-u) + curves.widths[2*curve+1]*u
+}
+
+// build splits the curves into segments and divides them into the BVH of the shape.
+func (curves *Curves) build(config *SAHConfig) {
+	splits := 1 << curveSplitDepth
+	curves.segments = make([]curveSegment, 0, curves.CurveCount()*splits)
+	for curve := 0; curve < curves.CurveCount(); curve++ {
+		cp := curves.controlPoints(curve)
+		for i := 0; i < splits; i++ {
+			u0, u1 := float64(i)/float64(splits), float64(i+1)/float64(splits)
+			segment := curveSegment{curve: curve, u0: u0, u1: u1}
+			segment.points = [4]Tuple{
+				blossomBezier(cp, u0, u0, u0),
+				blossomBezier(cp, u0, u0, u1),
+				blossomBezier(cp, u0, u1, u1),
+				blossomBezier(cp, u1, u1, u1),
+			}
+			segment.depth = curveDepth(segment.points, curves.maxWidth(&segment))
+			curves.segments = append(curves.segments, segment)
+		}
+	}
+
+	items := make([]sahItem, len(curves.segments))
+	for i := range items {
+		bounds := curves.segmentBounds(&curves.segments[i])
+		items[i] = sahItem{bounds: bounds, centroid: bounds.Centroid(), index: i}
+	}
+	curves.nodes = make([]flatBVHNode, 0)
+	curves.segmentOrder = make([]int, 0, len(items))
+	if len(items) == 0 {
+		return
+	}
+	curves.nodes = appendSAHNodes(curves.nodes, items, config, func(leaf []sahItem) int {
+		offset := len(curves.segmentOrder)
+		for i := range leaf {
+			curves.segmentOrder = append(curves.segmentOrder, leaf[i].index)
+		}
+		return offset
+	})
+}
+
+// segmentBounds returns the box around the control points of a segment, which contains the
+// curve, expanded by half its largest width.
+func (curves *Curves) segmentBounds(segment *curveSegment) *BoundingBox {
+	bounds := NewEmptyBoundingBox()
+	for _, p := range segment.points {
+		bounds.Add(p)
+	}
+	half := math.Max(curves.widthAt(segment.curve, segment.u0), curves.widthAt(segment.curve, segment.u1)) / 2
+	bounds.min = bounds.min.Substract(Vector(half, half, half))
+	bounds.max = bounds.max.Add(Vector(half, half, half))
+	return bounds
+}
+
+// LocalBounds returns the bounding box of all the curves.
+func (curves *Curves) LocalBounds() *BoundingBox {
+	if len(curves.nodes) == 0 {
+		return NewEmptyBoundingBox()
+	}
+	return NewBoundingBox(curves.nodes[0].min, curves.nodes[0].max)
+}
+
+// LocalIntersect traverses the BVH of the curves and appends the intersections with the segments
+// to xs. The parameter of the curve at the hit is stored in u, the position across its width from
+// 0 to 1 in v and the curve in index.
+func (curves *Curves) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	if len(curves.nodes) == 0 {
+		return xs
+	}
+
+	// The ray space has its origin at the origin of the ray and its z axis along the ray.
+	length := localRay.direction.Magnitude()
+	z := localRay.direction.Divide(length)
+	var x Tuple
+	if math.Abs(z.x) > math.Abs(z.y) {
+		x = Vector(-z.z, 0, z.x).Normalize()
+	} else {
+		x = Vector(0, z.z, -z.y).Normalize()
+	}
+	y := z.CrossProduct(x)
+	toRaySpace := func(p Tuple) Tuple {
+		d := p.Substract(localRay.origin)
+		return Vector(d.DotProduct(x), d.DotProduct(y), d.DotProduct(z))
+	}
+
+	invDir := Vector(1/localRay.direction.x, 1/localRay.direction.y, 1/localRay.direction.z)
+	negative := [3]bool{invDir.x < 0, invDir.y < 0, invDir.z < 0}
+
+	var stackArray [64]int
+	stack := append(stackArray[:0], 0)
+
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := &curves.nodes[index]
+
+		if _, hit := intersectFlatNode(localRay.origin, invDir, node); !hit {
+			continue
+		}
+
+		if node.leaf {
+			for i := node.offset; i < node.offset+node.count; i++ {
+				segment := &curves.segments[curves.segmentOrder[i]]
+				var cp [4]Tuple
+				for j := range cp {
+					cp[j] = toRaySpace(segment.points[j])
+				}
+				hit := curveHit{curves: curves, segment: segment, x: x, y: y, z: z, length: length}
+				xs = hit.intersect(cp, segment.u0, segment.u1, segment.depth, xs)
+			}
+			continue
+		}
+
+		if negative[node.axis] {
+			stack = append(stack, index+1, node.second)
+		} else {
+			stack = append(stack, node.second, index+1)
+		}
+	}
+
+	return xs
+}
+
+// maxWidth returns the largest width of a segment.
+func (curves *Curves) maxWidth(segment *curveSegment) float64 {
+	return math.Max(curves.widthAt(segment.curve, segment.u0), curves.widthAt(segment.curve, segment.u1))
+}
+
+// curveDepth returns how many times the segment with the control points cp has to be split in two
+// so that its parts are within a twentieth of its width from straight lines.
+func curveDepth(cp [4]Tuple, width float64) int {
+	l := 0.0
+	for i := 0; i < 2; i++ {
+		l = math.Max(l, cp[i].Substract(cp[i+1].Multiply(2)).Add(cp[i+2]).Magnitude())
+	}
+	epsilon := width * 0.05
+	if l == 0 || epsilon == 0 {
+		return 0
+	}
+	depth := int(math.Log2(math.Sqrt2*6*l/(8*epsilon)) / 2)
+	return int(math.Max(0, math.Min(10, float64(depth))))
+}
+
+// curveHit holds what the recursive intersection of a segment in ray space needs: the segment, the
+// axes of the ray space in object space and the length of the direction of the ray.
+type curveHit struct {
+	curves  *Curves
+	segment *curveSegment
+	x, y, z Tuple
+	length  float64
+}
+
+// intersect splits the part of the segment between the parameters u0 and u1, whose control points
+// in ray space are cp, depth more times, then tests the ray against its parts as lines.
+func (hit *curveHit) intersect(cp [4]Tuple, u0, u1 float64, depth int, xs Intersections) Intersections {
+	curves := hit.curves
+	curve := hit.segment.curve
+	width := math.Max(curves.widthAt(curve, u0), curves.widthAt(curve, u1))
+
+	// The ray runs along the z axis, it misses the parts whose box in x and y excludes the origin.
+	minX, maxX := cp[0].x, cp[0].x
+	minY, maxY := cp[0].y, cp[0].y
+	for _, p := range cp[1:] {
+		if p.x < minX {
+			minX = p.x
+		} else if p.x > maxX {
+			maxX = p.x
+		}
+		if p.y < minY {
+			minY = p.y
+		} else if p.y > maxY {
+			maxY = p.y
+		}
+	}
+	if minX-width/2 > 0 || maxX+width/2 < 0 || minY-width/2 > 0 || maxY+width/2 < 0 {
+		return xs
+	}
+
+	if depth > 0 {
+		split := subdivideBezier(cp)
+		mid := (u0 + u1) / 2
+		xs = hit.intersect([4]Tuple{split[0], split[1], split[2], split[3]}, u0, mid, depth-1, xs)
+		return hit.intersect([4]Tuple{split[3], split[4], split[5], split[6]}, mid, u1, depth-1, xs)
+	}
+
+	// The closest point of the line must be between the lines perpendicular to the curve at its
+	// ends, so that each ray hits a single one of the parts.
+	if (cp[1].y-cp[0].y)*-cp[0].y+cp[0].x*(cp[0].x-cp[1].x) < 0 {
+		return xs
+	}
+	if (cp[2].y-cp[3].y)*-cp[3].y+cp[3].x*(cp[3].x-cp[2].x) < 0 {
+		return xs
+	}
+	dx, dy := cp[3].x-cp[0].x, cp[3].y-cp[0].y
+	denominator := dx*dx + dy*dy
+	if denominator == 0 {
+		return xs
+	}
+	w := (-cp[0].x*dx - cp[0].y*dy) / denominator
+	w = math.Max(0, math.Min(1, w))
+	u := u0 + (u1-u0)*w
+
+	point, derivative := evalBezier(cp, w)
+	hitWidth := curves.widthAt(curve, u)
+	var normal Tuple
+	if curves.mode == curveRibbon {
+		// The normal of the ribbon in ray space, perpendicular to the curve. Ribbons seen from
+		// their side are thinner.
+		n := curves.ribbonNormal(curve, u)
+		normal = Vector(n.DotProduct(hit.x), n.DotProduct(hit.y), n.DotProduct(hit.z))
+		normal = normal.Substract(derivative.Multiply(normal.DotProduct(derivative) / derivative.DotProduct(derivative))).Normalize()
+		hitWidth *= math.Abs(normal.z)
+	}
+	distance2 := point.x*point.x + point.y*point.y
+	if hitWidth == 0 || distance2 > hitWidth*hitWidth/4 {
+		return xs
+	}
+
+	// v goes from 0 on one side of the curve to 1 on the other.
+	distance := math.Sqrt(distance2)
+	v := 0.5 + distance/hitWidth
+	if derivative.y*-point.x+derivative.x*point.y > 0 {
+		v = 0.5 - distance/hitWidth
+	}
+
+	if curves.mode == curveRibbon {
+		// The ribbon is hit where the ray crosses its plane.
+		return append(xs, curves.hit(point.DotProduct(normal)/normal.z/hit.length, u, v, curve))
+	}
+
+	// Tubes are hit where the ray enters and leaves the cylinder around the point of the curve
+	// closest to it, which is moved along the curve toward each hit.
+	for _, leaving := range []bool{false, true} {
+		if z, u, ok := hit.tubeHit(cp, u0, u1, w, leaving); ok {
+			xs = append(xs, curves.hit(z/hit.length, u, v, curve))
+		}
+	}
+	return xs
+}
+
+// tubeHit returns the z in ray space where the ray enters, or leaves, the tube around the part
+// of the segment between u0 and u1 whose control points in ray space are cp, and the parameter of
+// the curve there. The hit is first found on the cylinder around the tangent of the curve at w, then
+// w is moved to the point of the curve closest to the hit and the cylinder there is hit again, so
+// that the curvature and the changing width are followed by rays grazing the tube.
+func (hit *curveHit) tubeHit(cp [4]Tuple, u0, u1, w float64, leaving bool) (float64, float64, bool) {
+	z := 0.0
+	u := u0 + (u1-u0)*w
+	for i := 0; i < 8; i++ {
+		if u < 0 || u > 1 {
+			return 0, 0, false
+		}
+		center, derivative := evalBezier(cp, w)
+		radius := hit.curves.widthAt(hit.segment.curve, u) / 2
+
+		// The points of the ray, along the z axis, at radius from the axis of the cylinder.
+		axis := derivative.Normalize()
+		a := Vector(-axis.x*axis.z, -axis.y*axis.z, 1-axis.z*axis.z)
+		b := center.Substract(axis.Multiply(center.DotProduct(axis))).Negate()
+		qa := a.DotProduct(a)
+		if qa < EPSILON*EPSILON {
+			return 0, 0, false
+		}
+		qb := 2 * a.DotProduct(b)
+		qc := b.DotProduct(b) - radius*radius
+		discriminant := qb*qb - 4*qa*qc
+		if discriminant < 0 {
+			return 0, 0, false
+		}
+		root := math.Sqrt(discriminant)
+		if leaving {
+			z = (-qb + root) / (2 * qa)
+		} else {
+			z = (-qb - root) / (2 * qa)
+		}
+
+		step := Vector(0, 0, z).Substract(center).DotProduct(derivative) / derivative.DotProduct(derivative)
+		w += step
+		u = u0 + (u1-u0)*w
+		if math.Abs(step) < EPSILON {
+			break
+		}
+	}
+	return z, u, u >= 0 && u <= 1
+}
+
+// hit returns the intersection at t with a curve at the parameter u and the position v across its width.
+func (curves *Curves) hit(t, u, v float64, curve int) Intersection {
+	intersection := NewIntersectionUV(t, curves, u, v)
+	intersection.index = curve
+	return intersection
+}
+
+// Intersect calculates the intersections between a ray in world space and the curves.
+func (curves *Curves) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(curves, worldRay, xs)
+}
+
+// ribbonNormal interpolates the normals at the ends of a ribbon at the parameter u.
+func (curves *Curves) ribbonNormal(curve int, u float64) Tuple {
+	n0, n1 := curves.normals[2*curve].Normalize(), curves.normals[2*curve+1].Normalize()
+	return n0.Multiply(1 - u).Add(n1.Multiply(u)).Normalize()
+}
+
+// LocalNormalAt returns the normal of the ribbon at the hit, made perpendicular to the curve, or
+// the direction from the center of the tube to the point.
+func (curves *Curves) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	curve := intersection.index
+	center, tangent := evalBezier(curves.controlPoints(curve), intersection.u)
+	tangent = tangent.Normalize()
+	var normal Tuple
+	if curves.mode == curveRibbon {
+		normal = curves.ribbonNormal(curve, intersection.u)
+	} else {
+		normal = localPoint.Substract(center)
+	}
+	return normal.Substract(tangent.Multiply(normal.DotProduct(tangent))).Normalize()
+}
+
+// NormalAt calculates the normal at a point in world space, taking the parents of the curves into account.
+func (curves *Curves) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(curves, worldPoint, intersection)
+}
+
+// LocalTangentAt returns the direction of a tube at the hit, or a zero vector for ribbons which
+// are not shaded as fibers.
+func (curves *Curves) LocalTangentAt(localPoint Tuple, intersection *Intersection) Tuple {
+	if curves.mode != curveTube {
+		return Vector(0, 0, 0)
+	}
+	_, tangent := evalBezier(curves.controlPoints(intersection.index), intersection.u)
+	return tangent.Normalize()
+}
+
+// blossomBezier returns the blossom of the cubic Bezier curve with control points cp at (u0, u1,
+// u2), which gives the control points of a part of the curve.
+func blossomBezier(cp [4]Tuple, u0, u1, u2 float64) Tuple {
+	lerp := func(t float64, a, b Tuple) Tuple { return a.Multiply(1 - t).Add(b.Multiply(t)) }
+	a := [3]Tuple{lerp(u0, cp[0], cp[1]), lerp(u0, cp[1], cp[2]), lerp(u0, cp[2], cp[3])}
+	b := [2]Tuple{lerp(u1, a[0], a[1]), lerp(u1, a[1], a[2])}
+	return lerp(u2, b[0], b[1])
+}
+
+// subdivideBezier splits the cubic Bezier curve with control points cp at its middle, returning
+// the control points of the first half followed by the last three of the second half.
+func subdivideBezier(cp [4]Tuple) [7]Tuple {
+	return [7]Tuple{
+		cp[0],
+		cp[0].Add(cp[1]).Divide(2),
+		cp[0].Add(cp[1].Multiply(2)).Add(cp[2]).Divide(4),
+		cp[0].Add(cp[1].Multiply(3)).Add(cp[2].Multiply(3)).Add(cp[3]).Divide(8),
+		cp[1].Add(cp[2].Multiply(2)).Add(cp[3]).Divide(4),
+		cp[2].Add(cp[3]).Divide(2),
+		cp[3],
+	}
+}
+
+// evalBezier returns the point of the cubic Bezier curve with control points cp at the parameter u
+// and its derivative there.
+func evalBezier(cp [4]Tuple, u float64) (Tuple, Tuple) {
+	lerp := func(t float64, a, b Tuple) Tuple { return a.Multiply(1 - t).Add(b.Multiply(t)) }
+	a := [3]Tuple{lerp(u, cp[0], cp[1]), lerp(u, cp[1], cp[2]), lerp(u, cp[2], cp[3])}
+	b := [2]Tuple{lerp(u, a[0], a[1]), lerp(u, a[1], a[2])}
+	derivative := b[1].Substract(b[0]).Multiply(3)
+	if derivative.x == 0 && derivative.y == 0 && derivative.z == 0 {
+		// Coincident control points at an end, the chord still gives the direction.
+		derivative = cp[3].Substract(cp[0])
+	}
+	derivative.w = 0
+	return lerp(u, b[0], b[1]), derivative
+}
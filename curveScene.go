@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// curveWorld renders a furry ball made of tubes in a patch of grass made of ribbons.
+func curveWorld() *Canvas {
+	start := time.Now()
+	rng := rand.New(rand.NewSource(1))
+	lights := []*PointLight{
+		NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1)),
+	}
+	floor := NewPlane()
+	floor.material.color = NewColor(0.25, 0.18, 0.1)
+	floor.material.specular = 0
+
+	ball := NewSphere()
+	ball.SetTransform(Translation(0, 1, 0))
+	ball.material.color = NewColor(0.5, 0.3, 0.1)
+
+	// Hairs grow out of the ball and bend down.
+	hairs := []Tuple{}
+	hairWidths := []float64{}
+	for i := 0; i < 12000; i++ {
+		d := Vector(rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()).Normalize()
+		root := Point(0, 1, 0).Add(d)
+		length := 0.25 + rng.Float64()*0.1
+		hairs = append(hairs,
+			root,
+			root.Add(d.Multiply(length/3)),
+			root.Add(d.Multiply(2*length/3)).Add(Vector(0, -length/4, 0)),
+			root.Add(d.Multiply(length)).Add(Vector(0, -length/2, 0)))
+		hairWidths = append(hairWidths, 0.008, 0.002)
+	}
+	fur := NewTubes(hairs, hairWidths)
+	fur.material.color = NewColor(0.8, 0.5, 0.2)
+	fur.material.specular = 0.3
+	fur.material.shininess = 40
+
+	// Blades of grass lean in random directions and face them.
+	blades := []Tuple{}
+	bladeWidths := []float64{}
+	bladeNormals := []Tuple{}
+	for i := 0; i < 6000; i++ {
+		root := Point(rng.Float64()*8-4, 0, rng.Float64()*6-2)
+		angle := rng.Float64() * 2 * math.Pi
+		lean := Vector(math.Cos(angle), 0, math.Sin(angle)).Multiply(0.1 + rng.Float64()*0.2)
+		height := 0.3 + rng.Float64()*0.4
+		blades = append(blades,
+			root,
+			root.Add(Vector(0, height/2, 0)),
+			root.Add(Vector(0, height, 0)).Add(lean.Multiply(0.5)),
+			root.Add(Vector(0, height, 0)).Add(lean))
+		bladeWidths = append(bladeWidths, 0.04, 0.005)
+		normal := lean.Normalize()
+		bladeNormals = append(bladeNormals, normal, normal)
+	}
+	grass := NewRibbons(blades, bladeWidths, bladeNormals)
+	grass.material.color = NewColor(0.2, 0.6, 0.15)
+	grass.material.specular = 0.1
+
+	world := NewWorld(lights, []Shape{floor, ball, fur, grass})
+
+	camera := NewCamera(400, 200, PI/3)
+	camera.SetTransform(ViewTransform(Point(0, 1.8, -4), Point(0, 0.8, 0), Vector(0, 1, 0)))
+
+	canvas := camera.RenderWithThreadPool(world, defaultRecursionDepth)
+
+	fmt.Println("Render time: ", time.Now().Sub(start))
+
+	return canvas
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestCurveWorld(t *testing.T) {
+
+	canvas := curveWorld()
+
+	file, err := os.Create("curveWorld.ppm")
+	if err != nil {
+		t.Errorf("Error: %v", err)
+	}
+	file.WriteString(canvas.ToPPM())
+	file.Close()
+}
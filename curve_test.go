@@ -0,0 +1,144 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// A straight tube is intersected like a cylinder of the same radius.
+func TestTubeMatchesCylinder(t *testing.T) {
+	tube := NewTubes([]Tuple{Point(-1, 0, 0), Point(-1.0/3, 0, 0), Point(1.0/3, 0, 0), Point(1, 0, 0)}, []float64{2, 2})
+	cylinder := NewCylinder()
+	cylinder.SetTransform(RotationZ(math.Pi / 2))
+
+	rng := rand.New(rand.NewSource(3))
+	for n := 0; n < 200; n++ {
+		origin := Point(rng.Float64()*0.6-0.3, rng.Float64()*3-1.5, -5)
+		r := NewRay(origin, Vector(rng.Float64()*0.2-0.1, rng.Float64()*0.6-0.3, 1).Normalize())
+
+		xs, expected := tube.Intersect(r, nil), cylinder.Intersect(r, nil)
+		if len(xs) != len(expected) {
+			t.Errorf("Tube intersections count: got %v, expected: %v", len(xs), len(expected))
+			continue
+		}
+		for i := range xs {
+			if !floatEqual(xs[i].t, expected[i].t) {
+				t.Errorf("Tube intersection: got %v, expected: %v", xs[i].t, expected[i].t)
+			}
+			point := r.Position(xs[i].t)
+			if n, want := tube.NormalAt(point, &xs[i]), cylinder.NormalAt(point, &expected[i]); !n.Equals(want) {
+				t.Errorf("Tube normal: got %v, expected: %v", n, want)
+			}
+		}
+	}
+}
+
+// Points hit on a curved tube are at its radius from the curve.
+func TestCurvedTube(t *testing.T) {
+	cp := [4]Tuple{Point(-1, 0, 0), Point(-0.5, 1.5, 0.5), Point(0.5, -1, -0.5), Point(1, 0.5, 0)}
+	tube := NewTubes(cp[:], []float64{0.2, 0.1})
+
+	rng := rand.New(rand.NewSource(9))
+	hits := 0
+	for n := 0; n < 200; n++ {
+		center, _ := evalBezier(cp, rng.Float64())
+		origin := Point(rng.Float64()*10-5, rng.Float64()*10-5, rng.Float64()*10-5)
+		r := NewRay(origin, center.Substract(origin).Normalize())
+
+		for _, x := range tube.Intersect(r, nil) {
+			hits++
+			point := r.Position(x.t)
+			distance := math.Inf(1)
+			for i := 0; i <= 2000; i++ {
+				c, _ := evalBezier(cp, float64(i)/2000)
+				distance = math.Min(distance, point.Substract(c).Magnitude())
+			}
+			if radius := tube.widthAt(0, x.u) / 2; math.Abs(distance-radius) > 0.01 {
+				t.Errorf("Curved tube hit distance: got %v, expected: %v", distance, radius)
+			}
+		}
+	}
+	if hits < 300 {
+		t.Errorf("Curved tube hits: got %v, expected: at least %v", hits, 300)
+	}
+
+	box := tube.LocalBounds()
+	for i := 0; i <= 100; i++ {
+		if p, _ := evalBezier(cp, float64(i)/100); !box.ContainsPoint(p) {
+			t.Errorf("Curved tube bounds: %v does not contain %v", box, p)
+		}
+	}
+}
+
+// A straight ribbon is a strip of its width across its normal, thinner when seen from its side.
+func TestRibbon(t *testing.T) {
+	ribbon := NewRibbons([]Tuple{Point(-1, 0, 0), Point(-1.0/3, 0, 0), Point(1.0/3, 0, 0), Point(1, 0, 0)},
+		[]float64{1, 1}, []Tuple{Vector(0, 1, 0), Vector(0, 1, 0)})
+
+	tests := []struct {
+		target    Tuple
+		direction Tuple
+		hit       bool
+		v         float64
+	}{
+		{Point(0.3, 0, 0.2), Vector(0, -1, 0), true, 0.3},
+		{Point(0.3, 0, -0.2), Vector(0, -1, 0), true, 0.7},
+		{Point(0.3, 0, 0.6), Vector(0, -1, 0), false, 0},
+		{Point(0.2, 0, 0.45), Vector(0, -1, 1).Normalize(), true, 0.05},
+		{Point(0.2, 0, 0.55), Vector(0, -1, 1).Normalize(), false, 0},
+		{Point(0.2, 0, 0), Vector(0, 0, 1), false, 0},
+	}
+	for _, test := range tests {
+		r := NewRay(test.target.Substract(test.direction.Multiply(5)), test.direction)
+		xs := ribbon.Intersect(r, nil)
+		if !test.hit {
+			if len(xs) != 0 {
+				t.Errorf("Ribbon intersections toward %v: got %v, expected none", test.target, xs)
+			}
+			continue
+		}
+		if len(xs) != 1 || !floatEqual(xs[0].t, 5) {
+			t.Errorf("Ribbon intersections toward %v: got %v, expected one at: %v", test.target, xs, 5)
+			continue
+		}
+		if !floatEqual(xs[0].v, test.v) && !floatEqual(xs[0].v, 1-test.v) {
+			t.Errorf("Ribbon v toward %v: got %v, expected: %v", test.target, xs[0].v, test.v)
+		}
+		if n := ribbon.NormalAt(test.target, &xs[0]); !n.Equals(Vector(0, 1, 0)) {
+			t.Errorf("Ribbon normal: got %v, expected: %v", n, Vector(0, 1, 0))
+		}
+	}
+}
+
+// Tubes are shaded as fibers along their tangent in world space, through instances too, and
+// ribbons are not.
+func TestCurveTangent(t *testing.T) {
+	points := []Tuple{Point(-1, 0, 0), Point(-1.0/3, 0, 0), Point(1.0/3, 0, 0), Point(1, 0, 0)}
+	tube := NewTubes(points, []float64{0.5, 0.5})
+	tube.SetTransform(RotationY(math.Pi / 2))
+	instance := NewInstance(NewTubes(points, []float64{0.5, 0.5}))
+	instance.SetTransform(RotationZ(math.Pi / 2))
+	ribbon := NewRibbons(points, []float64{0.5, 0.5}, []Tuple{Vector(0, 1, 0), Vector(0, 1, 0)})
+
+	tests := []struct {
+		shape   Shape
+		r       Ray
+		tangent Tuple
+	}{
+		{tube, NewRay(Point(0, 5, 0.2), Vector(0, -1, 0)), Vector(0, 0, 1)},
+		{instance, NewRay(Point(0, 0.2, -5), Vector(0, 0, 1)), Vector(0, 1, 0)},
+		{ribbon, NewRay(Point(0.2, 5, 0), Vector(0, -1, 0)), Vector(0, 0, 0)},
+	}
+	for _, test := range tests {
+		xs := test.shape.Intersect(test.r, nil)
+		if len(xs) == 0 {
+			t.Errorf("Curve tangent: got no intersection with %v", test.r)
+			continue
+		}
+		comps := PrepareComputations(&xs[0], test.r, xs)
+		if tangent := comps.tangentv; !tangent.Equals(test.tangent) && !tangent.Equals(test.tangent.Negate()) {
+			t.Errorf("Curve tangent: got %v, expected: %v", tangent, test.tangent)
+		}
+	}
+}
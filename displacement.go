@@ -0,0 +1,90 @@
+package main
+
+import "math"
+
+// DisplacementFunc returns how far a point of a surface moves along its normal, from the point in
+// object space and its texture coordinates.
+type DisplacementFunc func(point Tuple, u, v float64) float64
+
+// PatternDisplacement returns a DisplacementFunc reading the gray level of the pattern, the mean of
+// its channels, at the point in object space.
+func PatternDisplacement(pattern *Pattern) DisplacementFunc {
+	return func(point Tuple, _, _ float64) float64 {
+		c := pattern.ColorAt(point)
+		return (c.r + c.g + c.b) / 3
+	}
+}
+
+// ImageDisplacement returns a DisplacementFunc reading the gray level of the canvas at the texture
+// coordinates, interpolated between the four closest pixels. Like UVImage, v = 0 is the bottom of
+// the image. Texture coordinates outside of the image are clamped to its edges.
+func ImageDisplacement(canvas *Canvas) DisplacementFunc {
+	gray := func(x, y int) float64 {
+		c := canvas.PixelAt(x, y)
+		return (c.r + c.g + c.b) / 3
+	}
+	return func(_ Tuple, u, v float64) float64 {
+		x := math.Max(0, math.Min(1, u)) * float64(canvas.width-1)
+		y := (1 - math.Max(0, math.Min(1, v))) * float64(canvas.height-1)
+		x0, y0 := int(x), int(y)
+		x1, y1 := x0, y0
+		if x1+1 < canvas.width {
+			x1++
+		}
+		if y1+1 < canvas.height {
+			y1++
+		}
+		fx, fy := x-float64(x0), y-float64(y0)
+		top := gray(x0, y0)*(1-fx) + gray(x1, y0)*fx
+		bottom := gray(x0, y1)*(1-fx) + gray(x1, y1)*fx
+		return top*(1-fy) + bottom*fy
+	}
+}
+
+// Displace returns a *Mesh of the mesh subdivided with the configuration, then its vertices moved
+// along their normals by scale times the displacement, with the transform and the materials of the
+// mesh. LinearSubdivision tessellates the faces without smoothing them first. The normals are
+// recomputed from the displaced faces and the BVH of the result is built over them, so that its
+// bounds hold the displaced surface.
+func (mesh *Mesh) Displace(displacement DisplacementFunc, scale float64, config *SubdivisionConfig) *Mesh {
+	return mesh.withAttributes(mesh.polygonMesh().displace(displacement, scale, config))
+}
+
+// displace returns the Mesh of the faces subdivided with the configuration and displaced. A vertex
+// moves along the mean of the normals of its corners, so that the faces around it stay connected
+// across sharp edges, using the texture coordinates of its first corner that has some.
+func (mesh *polygonMesh) displace(displacement DisplacementFunc, scale float64, config *SubdivisionConfig) *Mesh {
+	mesh, scheme := mesh.refine(config)
+	normals, normalFaces := mesh.vertexNormals(scheme, config.limitNormals && config.levels > 0)
+
+	directions := make([]Tuple, len(mesh.vertices))
+	uvs := make([]int, len(mesh.vertices))
+	for v := range uvs {
+		uvs[v] = -1
+	}
+	for f, face := range mesh.faces {
+		for i, v := range face {
+			directions[v] = directions[v].Add(normals[normalFaces[f][i]])
+			if mesh.uvFaces != nil && uvs[v] < 0 {
+				uvs[v] = mesh.uvFaces[f][i]
+			}
+		}
+	}
+
+	vertices := make([]Tuple, len(mesh.vertices))
+	for v, p := range mesh.vertices {
+		vertices[v] = p
+		if directions[v].Magnitude() < EPSILON*EPSILON {
+			continue
+		}
+		u, w := 0.0, 0.0
+		if uvs[v] >= 0 {
+			u, w = mesh.uvs[uvs[v]].x, mesh.uvs[uvs[v]].y
+		}
+		vertices[v] = p.Add(directions[v].Normalize().Multiply(scale * displacement(p, u, w)))
+	}
+	mesh.vertices = vertices
+
+	normals, normalFaces = mesh.vertexNormals(scheme, false)
+	return mesh.toMesh(normals, normalFaces)
+}
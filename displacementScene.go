@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// displacementWorld renders a square rippled by an image of rings and a ball, a subdivided cube,
+// ridged by a stripe pattern.
+func displacementWorld() *Canvas {
+	start := time.Now()
+	lights := []*PointLight{
+		NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1)),
+	}
+	floor := NewPlane()
+	floor.material.pattern = CheckersPattern(NewColor(0.6, 0.6, 0.6), NewColor(0.1, 0.1, 0.1))
+	floor.material.reflective = 0.2
+
+	rings := NewCanvas(128, 128)
+	for y := 0; y < rings.height; y++ {
+		for x := 0; x < rings.width; x++ {
+			r := math.Hypot(float64(x)-63.5, float64(y)-63.5) / 63.5
+			gray := 0.5 + 0.5*math.Cos(6*PI*r)*math.Exp(-2*r)
+			rings.WritePixel(x, y, NewColor(gray, gray, gray))
+		}
+	}
+	vertices := []Tuple{Point(-1, 0, -1), Point(1, 0, -1), Point(1, 0, 1), Point(-1, 0, 1)}
+	uvs := []Tuple{Point(0, 0, 0), Point(1, 0, 0), Point(1, 1, 0), Point(0, 1, 0)}
+	indices := []int{0, 1, 2, 0, 2, 3}
+	square := NewMesh(vertices, nil, uvs, indices, nil, indices)
+	square.SetTransform(Translation(-1.8, 0.01, 0).MultiplyMatrix(Scaling(1.5, 1.5, 1.5)))
+	square.SetMaterial(NewMaterial(NewColor(0.2, 0.4, 0.8), 0.1, 0.7, 0.6, 100, 0.1, 0, 1, nil))
+	config := DefaultSubdivisionConfig()
+	config.scheme = LinearSubdivision
+	config.levels = 6
+	ripples := square.Displace(ImageDisplacement(rings), 0.3, config)
+
+	config = DefaultSubdivisionConfig()
+	config.levels = 5
+	ball, err := parseObjMesh(subdivisionCube, nil)
+	if err != nil {
+		panic(err)
+	}
+	ball.SetTransform(Translation(1.8, 1.1, 0).MultiplyMatrix(RotationZ(PI / 5)))
+	ball.SetMaterial(NewMaterial(NewColor(0.8, 0.3, 0.2), 0.1, 0.7, 0.6, 100, 0.1, 0, 1, nil))
+	stripes := StripePattern(White, Black)
+	stripes.SetTransform(Scaling(0.1, 0.1, 0.1))
+	ridged := ball.Displace(PatternDisplacement(stripes), 0.08, config)
+
+	world := NewWorld(lights, []Shape{floor, ripples, ridged})
+
+	camera := NewCamera(600, 300, PI/3)
+	camera.SetTransform(ViewTransform(Point(0, 3.5, -6), Point(0, 0.6, 0), Vector(0, 1, 0)))
+
+	canvas := camera.RenderWithThreadPool(world, defaultRecursionDepth)
+
+	fmt.Println("Render time: ", time.Now().Sub(start))
+
+	return canvas
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDisplacementWorld(t *testing.T) {
+
+	canvas := displacementWorld()
+
+	file, err := os.Create("displacementWorld.ppm")
+	if err != nil {
+		t.Errorf("Error: %v", err)
+	}
+	file.WriteString(canvas.ToPPM())
+	file.Close()
+}
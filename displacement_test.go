@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// An image raises a tessellated square by its gray level at the texture coordinates.
+func TestImageDisplacement(t *testing.T) {
+	vertices := []Tuple{Point(-1, 0, -1), Point(1, 0, -1), Point(1, 0, 1), Point(-1, 0, 1)}
+	uvs := []Tuple{Point(0, 0, 0), Point(1, 0, 0), Point(1, 1, 0), Point(0, 1, 0)}
+	indices := []int{0, 1, 2, 0, 2, 3}
+	square := NewMesh(vertices, nil, uvs, indices, nil, indices)
+
+	// The gray level goes from 0 on the left of the image to 1 on its right.
+	canvas := NewCanvas(11, 2)
+	for x := 0; x < 11; x++ {
+		canvas.WritePixel(x, 0, NewColor(0.1, 0.1, 0.1).MultiplyByScalar(float64(x)))
+		canvas.WritePixel(x, 1, NewColor(0.1, 0.1, 0.1).MultiplyByScalar(float64(x)))
+	}
+	config := DefaultSubdivisionConfig()
+	config.scheme = LinearSubdivision
+	config.levels = 3
+	ramp := square.Displace(ImageDisplacement(canvas), 0.5, config)
+
+	if ramp.FaceCount() != 128 {
+		t.Errorf("Displaced square faces: got %v, expected: %v", ramp.FaceCount(), 128)
+	}
+	if box := ramp.LocalBounds(); !floatEqual(box.min.y, 0) || !floatEqual(box.max.y, 0.5) {
+		t.Errorf("Displaced square heights: got %v to %v, expected: %v to %v", box.min.y, box.max.y, 0, 0.5)
+	}
+
+	// The square is now a ramp y = (x + 1) / 4.
+	r := NewRay(Point(0.3, 5, 0.1), Vector(0, -1, 0))
+	xs := ramp.Intersect(r, nil)
+	if len(xs) != 1 || !floatEqual(xs[0].t, 4.675) {
+		t.Fatalf("Displaced square intersections: got %v, expected one at: %v", xs, 4.675)
+	}
+	expected := Vector(-0.25, 1, 0).Normalize()
+	if n := ramp.NormalAt(r.Position(xs[0].t), &xs[0]); !n.Equals(expected) {
+		t.Errorf("Displaced square normal: got %v, expected: %v", n, expected)
+	}
+}
+
+// A pattern pushes a smooth surface outward and its bounds grow with it.
+func TestPatternDisplacement(t *testing.T) {
+	vertices := []Tuple{Point(1, 0, 0), Point(-1, 0, 0), Point(0, 1, 0), Point(0, -1, 0), Point(0, 0, 1), Point(0, 0, -1)}
+	indices := []int{
+		0, 4, 2, 2, 4, 1, 1, 4, 3, 3, 4, 0,
+		2, 5, 0, 1, 5, 2, 3, 5, 1, 0, 5, 3,
+	}
+	octahedron := NewMesh(vertices, nil, nil, indices, nil, nil)
+	config := DefaultSubdivisionConfig()
+	smooth := octahedron.Subdivide(config)
+	displaced := octahedron.Displace(PatternDisplacement(StripePattern(White)), 0.25, config)
+
+	// By symmetry, the first vertex moves along x.
+	expected := smooth.vertices[0].Add(Vector(0.25, 0, 0))
+	if !displaced.vertices[0].Equals(expected) {
+		t.Errorf("Displaced vertex: got %v, expected: %v", displaced.vertices[0], expected)
+	}
+	if box := displaced.LocalBounds(); !floatEqual(box.max.x, expected.x) {
+		t.Errorf("Displaced bounds: got %v, expected: %v", box.max.x, expected.x)
+	}
+	r := NewRay(Point(5, 0, 0), Vector(-1, 0, 0))
+	xs := displaced.Intersect(r, nil)
+	xs.Sort()
+	if len(xs) == 0 || math.Abs(xs[0].t-(5-expected.x)) > EPSILON {
+		t.Errorf("Displaced intersections: got %v, expected the first one at: %v", xs, 5-expected.x)
+	}
+}
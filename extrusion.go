@@ -0,0 +1,217 @@
+package main
+
+import "math"
+
+// NewExtrusion returns a *Mesh of the polygon swept along the path, closed by caps at both ends.
+// The polygon is given by the x and z of its points, the plane it is drawn in following the path:
+// a path going up the y axis keeps x and z. Along curved paths the plane turns with rotation
+// minimizing frames, so that the sweep doesn't twist. The sides are flat across the edges of the
+// polygon and smooth along the path, their u going around the polygon and v along the path. The
+// caps map the box of the polygon to u and v. Repeated points of the path are skipped, and a path
+// without two distinct points gives an empty mesh.
+func NewExtrusion(polygon []Tuple, path []Tuple) *Mesh {
+	path = distinctPoints(path)
+	if len(path) < 2 {
+		return NewMesh(nil, nil, nil, nil, nil, nil)
+	}
+
+	vertices := []Tuple{}
+	normals := []Tuple{}
+	uvs := []Tuple{}
+	indices := []int{}
+	add := func(point, normal Tuple, u, v float64) int {
+		vertices = append(vertices, point)
+		normals = append(normals, normal)
+		uvs = append(uvs, Point(u, v, 0))
+		return len(vertices) - 1
+	}
+
+	tangents, frameX, frameZ := sweepFrames(path)
+	place := func(i int, p Tuple) Tuple {
+		return path[i].Add(frameX[i].Multiply(p.x)).Add(frameZ[i].Multiply(p.z))
+	}
+
+	// The outward normal of an edge depends on the winding of the polygon.
+	area := 0.0
+	perimeter := []float64{0}
+	for j := range polygon {
+		a, b := polygon[j], polygon[(j+1)%len(polygon)]
+		area += a.x*b.z - b.x*a.z
+		perimeter = append(perimeter, perimeter[j]+math.Hypot(b.x-a.x, b.z-a.z))
+	}
+	winding := 1.0
+	if area < 0 {
+		winding = -1
+	}
+
+	lengths := []float64{0}
+	for i := 1; i < len(path); i++ {
+		lengths = append(lengths, lengths[i-1]+path[i].Substract(path[i-1]).Magnitude())
+	}
+	total := lengths[len(lengths)-1]
+
+	for j := range polygon {
+		a, b := polygon[j], polygon[(j+1)%len(polygon)]
+		edge := Vector(b.z-a.z, 0, a.x-b.x).Multiply(winding).Normalize()
+		ua, ub := perimeter[j]/perimeter[len(polygon)], perimeter[j+1]/perimeter[len(polygon)]
+		previous := -1
+		for i := range path {
+			normal := frameX[i].Multiply(edge.x).Add(frameZ[i].Multiply(edge.z))
+			v := lengths[i] / total
+			first := add(place(i, a), normal, ua, v)
+			add(place(i, b), normal, ub, v)
+			if previous >= 0 {
+				indices = append(indices, previous, previous+1, first+1, previous, first+1, first)
+			}
+			previous = first
+		}
+	}
+
+	box := NewEmptyBoundingBox()
+	for _, p := range polygon {
+		box.Add(p)
+	}
+	// A polygon flat along x or z maps to a single u or v.
+	width, depth := math.Max(box.max.x-box.min.x, EPSILON), math.Max(box.max.z-box.min.z, EPSILON)
+	triangles := triangulatePolygon(polygon)
+	for _, end := range []struct {
+		ring   int
+		normal Tuple
+	}{
+		{0, tangents[0].Negate()},
+		{len(path) - 1, tangents[len(path)-1]},
+	} {
+		first := len(vertices)
+		for _, p := range polygon {
+			add(place(end.ring, p), end.normal, (p.x-box.min.x)/width, (p.z-box.min.z)/depth)
+		}
+		for _, index := range triangles {
+			indices = append(indices, first+index)
+		}
+	}
+
+	return NewMesh(vertices, normals, uvs, indices, indices, indices)
+}
+
+// sweepFrames returns the tangents of the path and the axes that x and z of a polygon swept along
+// it follow, computed with the double reflection method of Wang et al. for rotation minimizing
+// frames. The x axis of the first frame is the one closest to the x axis. Consecutive points of the
+// path must be distinct, see distinctPoints.
+func sweepFrames(path []Tuple) ([]Tuple, []Tuple, []Tuple) {
+	n := len(path)
+	tangents := make([]Tuple, n)
+	for i := range path {
+		a, b := i-1, i+1
+		if a < 0 {
+			a = 0
+		}
+		if b >= n {
+			b = n - 1
+		}
+		chord := path[b].Substract(path[a])
+		// A path turning back on itself has no chord, the incoming segment is used instead.
+		if chord.Magnitude() < EPSILON {
+			chord = path[i].Substract(path[a])
+		}
+		tangents[i] = chord.Normalize()
+	}
+
+	frameX := make([]Tuple, n)
+	frameZ := make([]Tuple, n)
+	x := Vector(1, 0, 0)
+	if math.Abs(tangents[0].x) > 0.9 {
+		x = Vector(0, 0, 1)
+	}
+	frameX[0] = x.Substract(tangents[0].Multiply(x.DotProduct(tangents[0]))).Normalize()
+	for i := 0; i+1 < n; i++ {
+		v1 := path[i+1].Substract(path[i])
+		c1 := v1.DotProduct(v1)
+		r := frameX[i].Substract(v1.Multiply(2 / c1 * v1.DotProduct(frameX[i])))
+		t := tangents[i].Substract(v1.Multiply(2 / c1 * v1.DotProduct(tangents[i])))
+		v2 := tangents[i+1].Substract(t)
+		if c2 := v2.DotProduct(v2); c2 > 0 {
+			r = r.Substract(v2.Multiply(2 / c2 * v2.DotProduct(r)))
+		}
+		frameX[i+1] = r.Normalize()
+	}
+	for i := range path {
+		frameZ[i] = frameX[i].CrossProduct(tangents[i])
+	}
+	return tangents, frameX, frameZ
+}
+
+// distinctPoints returns the points without the ones repeating the point before them.
+func distinctPoints(points []Tuple) []Tuple {
+	distinct := make([]Tuple, 0, len(points))
+	for _, p := range points {
+		if len(distinct) == 0 || p.Substract(distinct[len(distinct)-1]).Magnitude() >= EPSILON {
+			distinct = append(distinct, p)
+		}
+	}
+	return distinct
+}
+
+// BezierPath returns the points of the cubic Bezier curve with control points cp at steps + 1
+// evenly spaced parameters, a curved path for NewExtrusion.
+func BezierPath(cp [4]Tuple, steps int) []Tuple {
+	path := make([]Tuple, steps+1)
+	for i := range path {
+		path[i], _ = evalBezier(cp, float64(i)/float64(steps))
+	}
+	return path
+}
+
+// triangulatePolygon splits a simple polygon, given by the x and z of its points, into triangles
+// by ear clipping. Returns three indices of the points per triangle.
+func triangulatePolygon(polygon []Tuple) []int {
+	n := len(polygon)
+	remaining := make([]int, n)
+	area := 0.0
+	for i := range polygon {
+		remaining[i] = i
+		a, b := polygon[i], polygon[(i+1)%n]
+		area += a.x*b.z - b.x*a.z
+	}
+	// cross is positive for a convex corner of a polygon wound like the whole one.
+	cross := func(a, b, c Tuple) float64 {
+		return ((b.x-a.x)*(c.z-a.z) - (b.z-a.z)*(c.x-a.x)) * sign(area)
+	}
+
+	triangles := make([]int, 0, 3*(n-2))
+	for len(remaining) > 3 {
+		found := false
+		for i := range remaining {
+			ia, ib, ic := remaining[(i+len(remaining)-1)%len(remaining)], remaining[i], remaining[(i+1)%len(remaining)]
+			a, b, c := polygon[ia], polygon[ib], polygon[ic]
+			if cross(a, b, c) <= 0 {
+				continue
+			}
+			// An ear holds no other point of the polygon.
+			ear := true
+			for _, j := range remaining {
+				if j == ia || j == ib || j == ic {
+					continue
+				}
+				p := polygon[j]
+				if cross(a, b, p) >= 0 && cross(b, c, p) >= 0 && cross(c, a, p) >= 0 {
+					ear = false
+					break
+				}
+			}
+			if ear {
+				triangles = append(triangles, ia, ib, ic)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			// Degenerate polygons, such as ones with collinear points only, have no ear left.
+			break
+		}
+	}
+	if len(remaining) == 3 {
+		triangles = append(triangles, remaining[0], remaining[1], remaining[2])
+	}
+	return triangles
+}
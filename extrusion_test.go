@@ -0,0 +1,112 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// A square extruded along y is intersected like a cube.
+func TestExtrusionMatchesCube(t *testing.T) {
+	square := []Tuple{Point(-1, 0, -1), Point(1, 0, -1), Point(1, 0, 1), Point(-1, 0, 1)}
+	prism := NewExtrusion(square, []Tuple{Point(0, -1, 0), Point(0, 1, 0)})
+	cube := NewCube()
+
+	rng := rand.New(rand.NewSource(6))
+	for n := 0; n < 200; n++ {
+		origin := Point(rng.Float64()*8-4, rng.Float64()*8-4, rng.Float64()*8-4)
+		target := Point(rng.Float64()*2-1, rng.Float64()*2-1, rng.Float64()*2-1)
+		r := NewRay(origin, target.Substract(origin).Normalize())
+
+		xs, expected := prism.Intersect(r, nil), cube.Intersect(r, nil)
+		xs.Sort()
+		expected.Sort()
+		if len(xs) != len(expected) {
+			t.Errorf("Extrusion intersections count: got %v, expected: %v", len(xs), len(expected))
+			continue
+		}
+		for i := range xs {
+			if !floatEqual(xs[i].t, expected[i].t) {
+				t.Errorf("Extrusion intersection: got %v, expected: %v", xs[i].t, expected[i].t)
+			}
+			point := r.Position(xs[i].t)
+			if n, want := prism.NormalAt(point, &xs[i]).Normalize(), cube.NormalAt(point, &expected[i]); !n.Equals(want) {
+				t.Errorf("Extrusion normal at %v: got %v, expected: %v", point, n, want)
+			}
+		}
+	}
+
+	// u goes around the square from its first point and v up the path.
+	r := NewRay(Point(0.5, 0.5, -5), Vector(0, 0, 1))
+	xs := prism.Intersect(r, nil)
+	xs.Sort()
+	if u, v, ok := prism.uvAt(&xs[0]); !ok || !floatEqual(u, 0.1875) || !floatEqual(v, 0.75) {
+		t.Errorf("Extrusion (u, v): got (%v, %v), expected: (%v, %v)", u, v, 0.1875, 0.75)
+	}
+}
+
+// A concave polygon swept along a curve keeps its shape across the path.
+func TestExtrusionAlongCurve(t *testing.T) {
+	l := []Tuple{Point(0, 0, 0), Point(0.2, 0, 0), Point(0.2, 0, 0.1), Point(0.1, 0, 0.1), Point(0.1, 0, 0.3), Point(0, 0, 0.3)}
+	if triangles := triangulatePolygon(l); len(triangles) != 12 {
+		t.Errorf("Concave polygon triangles: got %v, expected: %v", len(triangles)/3, 4)
+	}
+
+	path := BezierPath([4]Tuple{Point(0, 0, 0), Point(0, 2, 0), Point(2, 2, 0), Point(2, 2, 2)}, 16)
+	tangents, frameX, frameZ := sweepFrames(path)
+	for i := range path {
+		if !floatEqual(frameX[i].Magnitude(), 1) || !floatEqual(frameX[i].DotProduct(tangents[i]), 0) ||
+			!floatEqual(frameZ[i].DotProduct(tangents[i]), 0) || !floatEqual(frameX[i].DotProduct(frameZ[i]), 0) {
+			t.Errorf("Sweep frame %v: got %v and %v across %v, expected an orthonormal frame", i, frameX[i], frameZ[i], tangents[i])
+		}
+	}
+
+	sweep := NewExtrusion(l, path)
+	box := sweep.LocalBounds()
+	for _, p := range path {
+		if !box.ContainsPoint(p) {
+			t.Errorf("Extrusion bounds: %v does not contain %v", box, p)
+		}
+	}
+	// The end cap faces along the last segment of the path, close to the end of the curve.
+	end := len(path) - 1
+	r := NewRay(path[end].Add(frameX[end].Multiply(0.05)).Add(frameZ[end].Multiply(0.05)).Add(Vector(0, 0, 3)), Vector(0, 0, -1))
+	xs := sweep.Intersect(r, nil)
+	xs.Sort()
+	if len(xs) == 0 || math.Abs(xs[0].t-3) > 0.01 {
+		t.Fatalf("Extrusion end cap intersections: got %v, expected the first one at about: %v", xs, 3)
+	}
+	if n := sweep.NormalAt(r.Position(xs[0].t), &xs[0]).Normalize(); !n.Equals(tangents[end]) || n.z < 0.99 {
+		t.Errorf("Extrusion end cap normal: got %v, expected: %v", n, tangents[end])
+	}
+}
+
+// Repeated path points and flat polygons don't give NaN frames or texture coordinates.
+func TestExtrusionDegenerateInput(t *testing.T) {
+	square := []Tuple{Point(-1, 0, -1), Point(1, 0, -1), Point(1, 0, 1), Point(-1, 0, 1)}
+	path := []Tuple{Point(0, -1, 0), Point(0, -1, 0), Point(0, 0, 0), Point(0, 1, 0), Point(0, 1, 0)}
+	prism := NewExtrusion(square, path)
+	for _, n := range prism.normals {
+		if math.IsNaN(n.x) || math.IsNaN(n.y) || math.IsNaN(n.z) {
+			t.Fatalf("Extrusion normal: got %v, expected no NaN", n)
+		}
+	}
+	r := NewRay(Point(0.5, 0.5, -5), Vector(0, 0, 1))
+	xs := prism.Intersect(r, nil)
+	xs.Sort()
+	if len(xs) != 2 || !floatEqual(xs[0].t, 4) {
+		t.Errorf("Extrusion with repeated points: got %v, expected a hit at t = %v", xs, 4)
+	}
+
+	segment := []Tuple{Point(-1, 0, 0), Point(1, 0, 0)}
+	flat := NewExtrusion(segment, []Tuple{Point(0, 0, 0), Point(0, 1, 0)})
+	for _, uv := range flat.uvs {
+		if math.IsNaN(uv.x) || math.IsNaN(uv.y) || math.IsInf(uv.x, 0) || math.IsInf(uv.y, 0) {
+			t.Fatalf("Flat extrusion texture coordinates: got %v, expected finite ones", uv)
+		}
+	}
+
+	if empty := NewExtrusion(square, []Tuple{Point(0, 0, 0), Point(0, 0, 0)}); len(empty.vertexIndices) != 0 {
+		t.Errorf("Extrusion along a single point: got %v faces, expected: %v", len(empty.vertexIndices)/3, 0)
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import "math"
+
+// flatBVHNode is a node of a FlatBVH stored by value in a single slice.
+// An interior node is followed by its first child, second holds the index of its second child.
+// A leaf node references count shapes starting at offset in the shapes of the FlatBVH.
+type flatBVHNode struct {
+	min, max Tuple
+	leaf     bool
+	second   int
+	offset   int
+	count    int
+	axis     int
+}
+
+// FlatBVH is a bounding volume hierarchy compiled from a Group into a linear array of nodes.
+// Subgroups without a transformation are flattened into nodes, every other child (primitives,
+// CSG and transformed subgroups) becomes a shape referenced by a leaf. The tree is traversed
+// with an explicit stack, near child first, testing the boxes with the precomputed inverse of
+// the ray direction.
+type FlatBVH struct {
+	nodes  []flatBVHNode
+	shapes []Shape
+	// local is true for the shapes without transformation, which are intersected in the group's
+	// space without transforming the ray.
+	local []bool
+}
+
+// flatBVHItem is a child to place in the FlatBVH along with its bounds in the compiled group's space.
+type flatBVHItem struct {
+	shape  Shape
+	bounds *BoundingBox
+}
+
+// NewFlatBVH compiles the hierarchy of the group into a FlatBVH.
+// The existing hierarchy is kept as is, so the group is usually divided first with Divide or DivideSAH.
+func NewFlatBVH(g *Group) *FlatBVH {
+	bvh := &FlatBVH{
+		nodes:  make([]flatBVHNode, 0),
+		shapes: make([]Shape, 0),
+		local:  make([]bool, 0),
+	}
+	bvh.build(flatBVHItems(g.children))
+	return bvh
+}
+
+// flatBVHItems returns the items of a list of children.
+func flatBVHItems(children []Shape) []flatBVHItem {
+	items := make([]flatBVHItem, len(children))
+	for i, child := range children {
+		items[i] = flatBVHItem{child, ParentSpaceBounds(child)}
+	}
+	return items
+}
+
+// flattenable returns true for the subgroups whose children can be placed directly in the FlatBVH.
+func flattenable(s Shape) bool {
+	g, ok := s.(*Group)
+	return ok && g.Transform() == IdentityMatrix
+}
+
+// build appends the node for the items and its descendants, returning the index of the node.
+func (bvh *FlatBVH) build(items []flatBVHItem) int {
+	// A single flattenable subgroup is replaced by its own children.
+	for len(items) == 1 && flattenable(items[0].shape) {
+		items = flatBVHItems(items[0].shape.(*Group).children)
+	}
+
+	index := len(bvh.nodes)
+	bounds := NewEmptyBoundingBox()
+	for i := range items {
+		bounds.Merge(items[i].bounds)
+	}
+	bvh.nodes = append(bvh.nodes, flatBVHNode{min: bounds.min, max: bounds.max})
+
+	leaf := true
+	for i := range items {
+		if flattenable(items[i].shape) {
+			leaf = false
+			break
+		}
+	}
+	if leaf || len(items) < 2 {
+		bvh.nodes[index].leaf = true
+		bvh.nodes[index].offset = len(bvh.shapes)
+		bvh.nodes[index].count = len(items)
+		for i := range items {
+			bvh.shapes = append(bvh.shapes, items[i].shape)
+			bvh.local = append(bvh.local, items[i].shape.Transform() == IdentityMatrix)
+		}
+		return index
+	}
+
+	// Split the items in two halves, the first half being the one with the lowest centroid
+	// along the axis where both halves are the most apart.
+	mid := len(items) / 2
+	first, second := items[:mid], items[mid:]
+	a, b := itemsCentroid(first), itemsCentroid(second)
+	axis := 0
+	if math.Abs(b.y-a.y) > math.Abs(axisValue(b, axis)-axisValue(a, axis)) {
+		axis = 1
+	}
+	if math.Abs(b.z-a.z) > math.Abs(axisValue(b, axis)-axisValue(a, axis)) {
+		axis = 2
+	}
+	if axisValue(b, axis) < axisValue(a, axis) {
+		first, second = second, first
+	}
+	bvh.nodes[index].axis = axis
+
+	bvh.build(first)
+	secondIndex := bvh.build(second)
+	bvh.nodes[index].second = secondIndex
+	return index
+}
+
+// NewFlatBVHFromShapes builds a FlatBVH over a list of shapes with bounded extents, splitting them
+// with the Surface Area Heuristic. The shapes are used as they are, nothing is flattened.
+func NewFlatBVHFromShapes(shapes []Shape, config *SAHConfig) *FlatBVH {
+	bvh := &FlatBVH{
+		nodes:  make([]flatBVHNode, 0),
+		shapes: make([]Shape, 0, len(shapes)),
+		local:  make([]bool, 0, len(shapes)),
+	}
+	items := make([]sahItem, len(shapes))
+	for i, shape := range shapes {
+		bounds := ParentSpaceBounds(shape)
+		items[i] = sahItem{shape: shape, bounds: bounds, centroid: bounds.Centroid()}
+	}
+	if len(items) > 0 {
+		bvh.nodes = appendSAHNodes(bvh.nodes, items, config, func(leaf []sahItem) int {
+			offset := len(bvh.shapes)
+			for i := range leaf {
+				bvh.shapes = append(bvh.shapes, leaf[i].shape)
+				bvh.local = append(bvh.local, false)
+			}
+			return offset
+		})
+	}
+	return bvh
+}
+
+// appendSAHNodes appends the node for the items and its descendants to nodes, splitting the items
+// with the Surface Area Heuristic. addLeaf stores the items of a leaf and returns their offset.
+func appendSAHNodes(nodes []flatBVHNode, items []sahItem, config *SAHConfig, addLeaf func([]sahItem) int) []flatBVHNode {
+	index := len(nodes)
+	bounds := NewEmptyBoundingBox()
+	for i := range items {
+		bounds.Merge(items[i].bounds)
+	}
+	nodes = append(nodes, flatBVHNode{min: bounds.min, max: bounds.max})
+
+	mid, splitCost := 0, math.Inf(1)
+	if len(items) > config.leafSize {
+		mid, splitCost = partitionSAH(items, bounds, config)
+	}
+	if splitCost >= config.intersectionCost*float64(len(items)) {
+		nodes[index].leaf = true
+		nodes[index].offset = addLeaf(items)
+		nodes[index].count = len(items)
+		return nodes
+	}
+
+	first, second := items[:mid], items[mid:]
+	a, b := sahItemsCentroid(first), sahItemsCentroid(second)
+	axis := 0
+	if math.Abs(b.y-a.y) > math.Abs(axisValue(b, axis)-axisValue(a, axis)) {
+		axis = 1
+	}
+	if math.Abs(b.z-a.z) > math.Abs(axisValue(b, axis)-axisValue(a, axis)) {
+		axis = 2
+	}
+	if axisValue(b, axis) < axisValue(a, axis) {
+		first, second = second, first
+	}
+	nodes[index].axis = axis
+
+	nodes = appendSAHNodes(nodes, first, config, addLeaf)
+	nodes[index].second = len(nodes)
+	return appendSAHNodes(nodes, second, config, addLeaf)
+}
+
+// sahItemsCentroid returns the centroid of the bounds of the items.
+func sahItemsCentroid(items []sahItem) Tuple {
+	bounds := NewEmptyBoundingBox()
+	for i := range items {
+		bounds.Merge(items[i].bounds)
+	}
+	return bounds.Centroid()
+}
+
+// itemsCentroid returns the centroid of the bounds of the items.
+func itemsCentroid(items []flatBVHItem) Tuple {
+	bounds := NewEmptyBoundingBox()
+	for i := range items {
+		bounds.Merge(items[i].bounds)
+	}
+	return bounds.Centroid()
+}
+
+// Intersect appends the intersections between the ray and all the shapes of the FlatBVH to xs.
+// The ray must be in the space of the compiled group.
+func (bvh *FlatBVH) Intersect(ray Ray, xs Intersections) Intersections {
+	xs, _ = bvh.intersect(ray, xs, math.Inf(1), false)
+	return xs
+}
+
+// IntersectNearest appends the intersections of the ray with the shapes of the FlatBVH to xs,
+// skipping the nodes that start beyond the closest non negative intersection found so far.
+// closest is the closest intersection already known and the updated value is returned.
+// Every intersection up to the closest one is reported, which is all Hit() and
+// PrepareComputations() need, but some intersections beyond it may be missing.
+func (bvh *FlatBVH) IntersectNearest(ray Ray, xs Intersections, closest float64) (Intersections, float64) {
+	return bvh.intersect(ray, xs, closest, true)
+}
+
+// intersect traverses the nodes, near child first, with an explicit stack.
+func (bvh *FlatBVH) intersect(ray Ray, xs Intersections, closest float64, nearest bool) (Intersections, float64) {
+	if len(bvh.nodes) == 0 {
+		return xs, closest
+	}
+
+	invDir := Vector(1/ray.direction.x, 1/ray.direction.y, 1/ray.direction.z)
+	negative := [3]bool{invDir.x < 0, invDir.y < 0, invDir.z < 0}
+
+	var stackArray [64]int
+	stack := append(stackArray[:0], 0)
+
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := &bvh.nodes[index]
+
+		tMin, hit := intersectFlatNode(ray.origin, invDir, node)
+		if !hit || (nearest && tMin > closest) {
+			continue
+		}
+
+		if node.leaf {
+			start := len(xs)
+			for i := node.offset; i < node.offset+node.count; i++ {
+				if g, ok := bvh.shapes[i].(*Group); ok && nearest {
+					xs, closest = g.intersectNearest(ray, xs, closest)
+				} else if bvh.local[i] {
+					xs = bvh.shapes[i].LocalIntersect(ray, xs)
+				} else {
+					xs = bvh.shapes[i].Intersect(ray, xs)
+				}
+			}
+			for i := start; i < len(xs); i++ {
+				if xs[i].t >= 0 && xs[i].t < closest {
+					closest = xs[i].t
+				}
+			}
+			continue
+		}
+
+		// Push the far child first so that the near child is popped first.
+		if negative[node.axis] {
+			stack = append(stack, index+1, node.second)
+		} else {
+			stack = append(stack, node.second, index+1)
+		}
+	}
+	return xs, closest
+}
+
+// intersectFlatNode tests the ray against the box of the node using the inverse of the ray direction.
+// Boxes behind the origin of the ray are hit too, since shapes report negative intersections as well.
+// A NaN, from a ray lying in the plane of a face, fails every comparison and keeps the box.
+func intersectFlatNode(origin, invDir Tuple, node *flatBVHNode) (float64, bool) {
+	tMin, tMax := math.Inf(-1), math.Inf(1)
+
+	t1 := (node.min.x - origin.x) * invDir.x
+	t2 := (node.max.x - origin.x) * invDir.x
+	if t1 > t2 {
+		t1, t2 = t2, t1
+	}
+	if t1 > tMin {
+		tMin = t1
+	}
+	if t2 < tMax {
+		tMax = t2
+	}
+
+	t1 = (node.min.y - origin.y) * invDir.y
+	t2 = (node.max.y - origin.y) * invDir.y
+	if t1 > t2 {
+		t1, t2 = t2, t1
+	}
+	if t1 > tMin {
+		tMin = t1
+	}
+	if t2 < tMax {
+		tMax = t2
+	}
+
+	t1 = (node.min.z - origin.z) * invDir.z
+	t2 = (node.max.z - origin.z) * invDir.z
+	if t1 > t2 {
+		t1, t2 = t2, t1
+	}
+	if t1 > tMin {
+		tMin = t1
+	}
+	if t2 < tMax {
+		tMax = t2
+	}
+
+	return tMin, tMin <= tMax
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randomSpheresGroup returns a group of small spheres spread in a cube of the given size.
+func randomSpheresGroup(rng *rand.Rand, count int, size float64) *Group {
+	g := NewGroup()
+	for i := 0; i < count; i++ {
+		s := NewSphere()
+		s.SetTransform(Translation(rng.Float64()*size-size/2, rng.Float64()*size-size/2, rng.Float64()*size-size/2).
+			MultiplyMatrix(Scaling(0.3, 0.3, 0.3)))
+		g.AddChild(s)
+	}
+	return g
+}
+
+func TestFlatBVHMatchesGroup(t *testing.T) {
+	// A compiled group reports the same intersections as the group itself.
+	rng := rand.New(rand.NewSource(2))
+	g := randomSpheresGroup(rng, 300, 20)
+	g.SetTransform(Translation(1, 2, 3).MultiplyMatrix(RotationY(0.5)))
+	DivideSAH(g, DefaultSAHConfig())
+
+	rays := make([]Ray, 300)
+	expected := make([]Intersections, len(rays))
+	for i := range rays {
+		rays[i] = NewRay(Point(rng.Float64()*20-10, rng.Float64()*20-10, -20), Vector(rng.Float64()-0.5, rng.Float64()-0.5, 1).Normalize())
+		expected[i] = g.Intersect(rays[i], nil)
+	}
+
+	g.Compile()
+	for i := range rays {
+		xs := g.Intersect(rays[i], nil)
+		if len(xs) != len(expected[i]) {
+			t.Fatalf("FlatBVH intersections: got %v, expected: %v", len(xs), len(expected[i]))
+		}
+		for j := range xs {
+			if xs[j] != expected[i][j] {
+				t.Errorf("FlatBVH intersections: got %v, expected: %v", xs[j], expected[i][j])
+			}
+		}
+
+		// The nearest traversal finds the same hit.
+		nearest, _ := g.intersectNearest(rays[i], nil, 1e9)
+		hit, expectedHit := nearest.Hit(), expected[i].Hit()
+		if (hit == nil) != (expectedHit == nil) || (hit != nil && *hit != *expectedHit) {
+			t.Errorf("FlatBVH nearest hit: got %v, expected: %v", hit, expectedHit)
+		}
+	}
+}
+
+func TestFlatBVHLayout(t *testing.T) {
+	// Subgroups without transformation are flattened, other children are kept as shapes.
+	s1 := NewSphere()
+	s2 := NewSphere()
+	s2.SetTransform(Translation(3, 0, 0))
+	inner := NewGroup()
+	inner.AddChild(s1, s2)
+
+	s3 := NewCube()
+	transformed := NewGroup()
+	transformed.SetTransform(Translation(0, 5, 0))
+	transformed.AddChild(s3)
+
+	g := NewGroup()
+	g.AddChild(inner, transformed)
+	bvh := NewFlatBVH(g)
+
+	if len(bvh.shapes) != 3 {
+		t.Fatalf("FlatBVH layout: got %v shapes, expected: %v", len(bvh.shapes), 3)
+	}
+	expectedShapes := []Shape{s1, s2, transformed}
+	expectedLocal := []bool{true, false, false}
+	for i := range expectedShapes {
+		if bvh.shapes[i] != expectedShapes[i] {
+			t.Errorf("FlatBVH layout: got %v, expected: %v", bvh.shapes[i], expectedShapes[i])
+		}
+		if bvh.local[i] != expectedLocal[i] {
+			t.Errorf("FlatBVH local shapes: got %v, expected: %v", bvh.local[i], expectedLocal[i])
+		}
+	}
+	if len(bvh.nodes) != 3 || bvh.nodes[0].leaf || !bvh.nodes[1].leaf || bvh.nodes[0].second != 2 {
+		t.Errorf("FlatBVH layout: got nodes %v", bvh.nodes)
+	}
+
+	// Adding a child discards the compiled hierarchy.
+	g.Compile()
+	g.AddChild(NewSphere())
+	if g.flat != nil {
+		t.Errorf("FlatBVH: adding a child should discard the compiled hierarchy")
+	}
+}
+
+func TestRenderWithCompiledGroup(t *testing.T) {
+	// Rendering a world with a compiled group gives the same image.
+	rng := rand.New(rand.NewSource(3))
+	g := randomSpheresGroup(rng, 100, 6)
+	DivideSAH(g, DefaultSAHConfig())
+	floor := NewPlane()
+	floor.SetTransform(Translation(0, -4, 0))
+	floor.material.reflective = 0.5
+	w := NewWorld([]*PointLight{NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1))}, []Shape{floor, g})
+
+	c := NewCamera(40, 20, PI/3)
+	c.SetTransform(ViewTransform(Point(0, 1, -12), Point(0, 0, 0), Vector(0, 1, 0)))
+	expected := c.Render(w, defaultRecursionDepth)
+
+	g.Compile()
+	image := c.Render(w, defaultRecursionDepth)
+	for y := 0; y < c.vsize; y++ {
+		for x := 0; x < c.hsize; x++ {
+			if !image.PixelAt(x, y).Equals(expected.PixelAt(x, y)) {
+				t.Errorf("Render with compiled group at %v,%v: got %v, expected: %v", x, y, image.PixelAt(x, y), expected.PixelAt(x, y))
+			}
+		}
+	}
+}
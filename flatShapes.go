@@ -0,0 +1,203 @@
+package main
+
+import "math"
+
+// Disk, Annulus and Rectangle are finite pieces of the xz plane facing +y, like Plane.
+// They have tight bounds so they can be grouped and divided like any bounded shape, and PointAt
+// maps texture coordinates back onto the surface, which is what sampling an area light needs.
+// They are not closed and have no inside, so they don't make sound CSG operands: CSG takes every
+// crossing of one of them for entering or leaving it, which only cuts the other operand along
+// the rays that cross the flat shape.
+
+// intersectXZPlane returns the distance along the ray and the point where it crosses the xz plane.
+// Rays parallel to the plane never cross it.
+func intersectXZPlane(localRay Ray) (float64, Tuple, bool) {
+	if math.Abs(localRay.direction.y) < EPSILON {
+		return 0, Tuple{}, false
+	}
+	t := -localRay.origin.y / localRay.direction.y
+	return t, localRay.Position(t), true
+}
+
+// polarMap maps a point of the xz plane to (u, v): u is the angle around the y axis like
+// cylindricalMap and v goes from 0 at the inner radius to 1 at the outer radius.
+func polarMap(point Tuple, inner, outer float64) (u, v float64) {
+	theta := math.Atan2(point.x, point.z)
+	u = 1 - (theta/(2*PI) + 0.5)
+	v = (math.Sqrt(point.x*point.x+point.z*point.z) - inner) / (outer - inner)
+	return
+}
+
+// polarPoint is the inverse of polarMap.
+func polarPoint(u, v, inner, outer float64) Tuple {
+	theta := (0.5 - u) * 2 * PI
+	r := inner + v*(outer-inner)
+	return Point(r*math.Sin(theta), 0, r*math.Cos(theta))
+}
+
+// Disk is a disk of the given radius centered at the origin in the xz plane.
+type Disk struct {
+	BaseShape
+	radius float64
+}
+
+// NewDisk returns a *Disk of radius 1 with Identity matrix as transform and default material.
+func NewDisk() *Disk {
+	return &Disk{
+		BaseShape: NewBaseShape(),
+		radius:    1,
+	}
+}
+
+// LocalIntersect calculates the intersection between a ray in object space and the disk.
+func (disk *Disk) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	t, p, ok := intersectXZPlane(localRay)
+	if !ok || p.x*p.x+p.z*p.z > disk.radius*disk.radius {
+		return xs
+	}
+	return append(xs, NewIntersection(t, disk))
+}
+
+// Intersect calculates the intersection between a ray in world space and the disk.
+func (disk *Disk) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(disk, worldRay, xs)
+}
+
+// LocalNormalAt returns the normal of the disk in object space, which is constant.
+func (disk *Disk) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	return Vector(0, 1, 0)
+}
+
+// NormalAt calculates the normal at a given point in world space.
+func (disk *Disk) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(disk, worldPoint, intersection)
+}
+
+// LocalBounds returns the bounding box of the disk in object space, flat in y.
+func (disk *Disk) LocalBounds() *BoundingBox {
+	return NewBoundingBoxFloat(-disk.radius, 0, -disk.radius, disk.radius, 0, disk.radius)
+}
+
+// UVAt maps a point of the disk to (u, v), u going around the center and v from the center to the edge.
+func (disk *Disk) UVAt(point Tuple) (u, v float64) {
+	return polarMap(point, 0, disk.radius)
+}
+
+// PointAt returns the point of the disk at the texture coordinates (u, v), in object space.
+func (disk *Disk) PointAt(u, v float64) Tuple {
+	return polarPoint(u, v, 0, disk.radius)
+}
+
+// Annulus is a ring between an inner and an outer radius centered at the origin in the xz plane.
+type Annulus struct {
+	BaseShape
+	innerRadius, outerRadius float64
+}
+
+// NewAnnulus returns an *Annulus with the given radii, Identity matrix as transform and default material.
+func NewAnnulus(innerRadius, outerRadius float64) *Annulus {
+	return &Annulus{
+		BaseShape:   NewBaseShape(),
+		innerRadius: innerRadius,
+		outerRadius: outerRadius,
+	}
+}
+
+// LocalIntersect calculates the intersection between a ray in object space and the annulus.
+func (annulus *Annulus) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	t, p, ok := intersectXZPlane(localRay)
+	if !ok {
+		return xs
+	}
+	dist := p.x*p.x + p.z*p.z
+	if dist < annulus.innerRadius*annulus.innerRadius || dist > annulus.outerRadius*annulus.outerRadius {
+		return xs
+	}
+	return append(xs, NewIntersection(t, annulus))
+}
+
+// Intersect calculates the intersection between a ray in world space and the annulus.
+func (annulus *Annulus) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(annulus, worldRay, xs)
+}
+
+// LocalNormalAt returns the normal of the annulus in object space, which is constant.
+func (annulus *Annulus) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	return Vector(0, 1, 0)
+}
+
+// NormalAt calculates the normal at a given point in world space.
+func (annulus *Annulus) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(annulus, worldPoint, intersection)
+}
+
+// LocalBounds returns the bounding box of the annulus in object space, flat in y.
+func (annulus *Annulus) LocalBounds() *BoundingBox {
+	r := annulus.outerRadius
+	return NewBoundingBoxFloat(-r, 0, -r, r, 0, r)
+}
+
+// UVAt maps a point of the annulus to (u, v), u going around the center and v from the inner to the outer edge.
+func (annulus *Annulus) UVAt(point Tuple) (u, v float64) {
+	return polarMap(point, annulus.innerRadius, annulus.outerRadius)
+}
+
+// PointAt returns the point of the annulus at the texture coordinates (u, v), in object space.
+func (annulus *Annulus) PointAt(u, v float64) Tuple {
+	return polarPoint(u, v, annulus.innerRadius, annulus.outerRadius)
+}
+
+// Rectangle is a quad of the given width along x and depth along z centered at the origin in the xz plane.
+type Rectangle struct {
+	BaseShape
+	width, depth float64
+}
+
+// NewRectangle returns a *Rectangle with the given size, Identity matrix as transform and default material.
+func NewRectangle(width, depth float64) *Rectangle {
+	return &Rectangle{
+		BaseShape: NewBaseShape(),
+		width:     width,
+		depth:     depth,
+	}
+}
+
+// LocalIntersect calculates the intersection between a ray in object space and the rectangle.
+func (rectangle *Rectangle) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	t, p, ok := intersectXZPlane(localRay)
+	if !ok || math.Abs(p.x) > rectangle.width/2 || math.Abs(p.z) > rectangle.depth/2 {
+		return xs
+	}
+	return append(xs, NewIntersection(t, rectangle))
+}
+
+// Intersect calculates the intersection between a ray in world space and the rectangle.
+func (rectangle *Rectangle) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(rectangle, worldRay, xs)
+}
+
+// LocalNormalAt returns the normal of the rectangle in object space, which is constant.
+func (rectangle *Rectangle) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	return Vector(0, 1, 0)
+}
+
+// NormalAt calculates the normal at a given point in world space.
+func (rectangle *Rectangle) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(rectangle, worldPoint, intersection)
+}
+
+// LocalBounds returns the bounding box of the rectangle in object space, flat in y.
+func (rectangle *Rectangle) LocalBounds() *BoundingBox {
+	w, d := rectangle.width/2, rectangle.depth/2
+	return NewBoundingBoxFloat(-w, 0, -d, w, 0, d)
+}
+
+// UVAt maps a point of the rectangle to (u, v), from (0, 0) at the -x -z corner to (1, 1) at the +x +z corner.
+func (rectangle *Rectangle) UVAt(point Tuple) (u, v float64) {
+	return point.x/rectangle.width + 0.5, point.z/rectangle.depth + 0.5
+}
+
+// PointAt returns the point of the rectangle at the texture coordinates (u, v), in object space.
+func (rectangle *Rectangle) PointAt(u, v float64) Tuple {
+	return Point((u-0.5)*rectangle.width, 0, (v-0.5)*rectangle.depth)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Rays hit the disk, annulus and rectangle only inside their outline.
+func TestFlatShapesIntersect(t *testing.T) {
+	disk := NewDisk()
+	annulus := NewAnnulus(0.5, 1)
+	rectangle := NewRectangle(2, 1)
+
+	tests := []struct {
+		shape    Shape
+		origin   Tuple
+		expected int
+	}{
+		{disk, Point(0, 1, 0), 1},
+		{disk, Point(0.7, 1, 0.7), 1},
+		{disk, Point(0.8, 1, 0.8), 0},
+		{annulus, Point(0, 1, 0), 0},
+		{annulus, Point(0, 1, 0.75), 1},
+		{annulus, Point(1.1, 1, 0), 0},
+		{rectangle, Point(0.9, 1, 0.4), 1},
+		{rectangle, Point(0.9, 1, 0.6), 0},
+		{rectangle, Point(1.1, 1, 0), 0},
+	}
+	for _, test := range tests {
+		xs := test.shape.LocalIntersect(NewRay(test.origin, Vector(0, -1, 0)), nil)
+		if len(xs) != test.expected {
+			t.Errorf("Flat shape intersections from %v: got %v, expected: %v", test.origin, len(xs), test.expected)
+		}
+		if len(xs) == 1 && (xs[0].t != 1 || !test.shape.LocalNormalAt(Point(0, 0, 0), &xs[0]).Equals(Vector(0, 1, 0))) {
+			t.Errorf("Flat shape intersection from %v: got %v, expected: %v", test.origin, xs[0].t, 1)
+		}
+	}
+
+	// Rays parallel to the shapes miss them.
+	if xs := disk.LocalIntersect(NewRay(Point(-2, 0, 0), Vector(1, 0, 0)), nil); len(xs) != 0 {
+		t.Errorf("Flat shape parallel intersections: got %v, expected: %v", len(xs), 0)
+	}
+}
+
+// The bounds of the flat shapes are tight and flat in y.
+func TestFlatShapesBounds(t *testing.T) {
+	tests := []struct {
+		shape    Shape
+		expected *BoundingBox
+	}{
+		{NewDisk(), NewBoundingBoxFloat(-1, 0, -1, 1, 0, 1)},
+		{NewAnnulus(1, 2), NewBoundingBoxFloat(-2, 0, -2, 2, 0, 2)},
+		{NewRectangle(4, 2), NewBoundingBoxFloat(-2, 0, -1, 2, 0, 1)},
+	}
+	for _, test := range tests {
+		box := test.shape.LocalBounds()
+		if !box.min.Equals(test.expected.min) || !box.max.Equals(test.expected.max) {
+			t.Errorf("Flat shape bounds: got %v, expected: %v", box, test.expected)
+		}
+	}
+}
+
+// Texture coordinates map back to the same points of the flat shapes.
+func TestFlatShapesUV(t *testing.T) {
+	annulus := NewAnnulus(1, 3)
+	u, v := annulus.UVAt(Point(2, 0, 0))
+	if !floatEqual(u, 0.25) || !floatEqual(v, 0.5) {
+		t.Errorf("Annulus UV: got %v, %v, expected: %v, %v", u, v, 0.25, 0.5)
+	}
+
+	rectangle := NewRectangle(4, 2)
+	u, v = rectangle.UVAt(Point(-2, 0, 0.5))
+	if !floatEqual(u, 0) || !floatEqual(v, 0.75) {
+		t.Errorf("Rectangle UV: got %v, %v, expected: %v, %v", u, v, 0, 0.75)
+	}
+
+	disk := NewDisk()
+	disk.radius = 2
+	for _, p := range []Tuple{Point(0.5, 0, 0.5), Point(-1, 0, 1.5), Point(0, 0, -1)} {
+		for _, shape := range []interface {
+			UVAt(Tuple) (float64, float64)
+			PointAt(float64, float64) Tuple
+		}{disk, annulus, rectangle} {
+			if got := shape.PointAt(shape.UVAt(p)); !got.Equals(p) {
+				t.Errorf("Flat shape PointAt(UVAt(%v)): got %v, expected: %v", p, got, p)
+			}
+		}
+	}
+	if got := disk.PointAt(0.5, 1); !got.Equals(Point(0, 0, 2)) || math.IsNaN(got.x) {
+		t.Errorf("Disk PointAt: got %v, expected: %v", got, Point(0, 0, 2))
+	}
+}
+
+// Flat shapes can be divided in groups like any bounded shape.
+func TestFlatShapesInGroup(t *testing.T) {
+	g := NewGroup()
+	for i := 0; i < 10; i++ {
+		disk := NewDisk()
+		disk.SetTransform(Translation(float64(i)*3, 0, 0).MultiplyMatrix(RotationX(-PI / 2)))
+		g.AddChild(disk)
+	}
+	DivideSAH(g, DefaultSAHConfig())
+	g.Compile()
+
+	xs := g.Intersect(NewRay(Point(27, 0, -5), Vector(0, 0, 1)), nil)
+	if len(xs) != 1 || !floatEqual(xs[0].t, 5) {
+		t.Errorf("Flat shapes in group intersections: got %v, expected: %v", xs, 5)
+	}
+}
+
+// CSG takes a crossing of a flat shape for entering it, so a difference only cuts along the rays
+// that cross the shape.
+func TestFlatShapesInCSG(t *testing.T) {
+	disk := NewDisk()
+	disk.SetTransform(Scaling(0.5, 1, 0.5))
+	csg := NewCSG("difference", NewSphere(), disk)
+
+	tests := []struct {
+		origin   Tuple
+		expected []float64
+	}{
+		{Point(0, -5, 0), []float64{4, 5}},
+		{Point(0.8, -5, 0), []float64{4.4, 5.6}},
+	}
+	for _, test := range tests {
+		xs := csg.Intersect(NewRay(test.origin, Vector(0, 1, 0)), nil)
+		xs.Sort()
+		if len(xs) != len(test.expected) {
+			t.Errorf("Flat shape CSG intersections from %v: got %v, expected: %v", test.origin, xs, test.expected)
+			continue
+		}
+		for i := range xs {
+			if !floatEqual(xs[i].t, test.expected[i]) {
+				t.Errorf("Flat shape CSG intersection from %v: got %v, expected: %v", test.origin, xs[i].t, test.expected[i])
+			}
+		}
+	}
+}
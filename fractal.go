@@ -0,0 +1,206 @@
+package main
+
+import "math"
+
+// Fractal is an SDF whose distance is only estimated from the escape of an iterated function, which
+// is not signed reliably inside the set. SDFShape stops at the first intersection with a fractal,
+// which is enough to render it opaque. OrbitTrap returns a value between 0 and 1 recording how the
+// orbit of a point behaved, for OrbitTrapPattern to color the fractal.
+type Fractal interface {
+	SDF
+	OrbitTrap(p Tuple) float64
+}
+
+// Mandelbulb is the 3D Mandelbrot set of the power of vectors in spherical coordinates:
+// z = z^power + p, iterated from z = p.
+type Mandelbulb struct {
+	power      float64
+	iterations int
+}
+
+// NewMandelbulb returns a *Mandelbulb of the given power, 8 for the classic one, and number of iterations.
+func NewMandelbulb(power float64, iterations int) *Mandelbulb {
+	return &Mandelbulb{power: power, iterations: iterations}
+}
+
+// iterate runs the orbit of p and returns the distance estimate and the orbit trap, the smallest
+// distance from the orbit to the origin.
+func (mandelbulb *Mandelbulb) iterate(p Tuple) (float64, float64) {
+	n := mandelbulb.power
+	z := Vector(p.x, p.y, p.z)
+	dr := 1.0
+	r := z.Magnitude()
+	trap := r
+	for i := 0; i < mandelbulb.iterations && r <= 2; i++ {
+		if r == 0 {
+			z = Vector(p.x, p.y, p.z)
+			r = z.Magnitude()
+			continue
+		}
+		theta := math.Acos(z.z/r) * n
+		phi := math.Atan2(z.y, z.x) * n
+		dr = math.Pow(r, n-1)*n*dr + 1
+		zr := math.Pow(r, n)
+		z = Vector(
+			zr*math.Sin(theta)*math.Cos(phi)+p.x,
+			zr*math.Sin(theta)*math.Sin(phi)+p.y,
+			zr*math.Cos(theta)+p.z)
+		r = z.Magnitude()
+		trap = math.Min(trap, r)
+	}
+	if r == 0 {
+		return 0, 0
+	}
+	return 0.5 * math.Log(r) * r / dr, trap
+}
+
+// Distance returns the estimated distance from p to the Mandelbulb.
+func (mandelbulb *Mandelbulb) Distance(p Tuple) float64 {
+	d, _ := mandelbulb.iterate(p)
+	return d
+}
+
+// OrbitTrap returns the smallest distance from the orbit of p to the origin, clamped to 1.
+func (mandelbulb *Mandelbulb) OrbitTrap(p Tuple) float64 {
+	_, trap := mandelbulb.iterate(p)
+	return math.Min(trap, 1)
+}
+
+// Bounds returns the bounding box of the Mandelbulb: beyond the radius r with r^(power-1) = 2,
+// the orbits escape.
+func (mandelbulb *Mandelbulb) Bounds() *BoundingBox {
+	r := math.Pow(2, 1/(mandelbulb.power-1))
+	return NewBoundingBoxFloat(-r, -r, -r, r, r, r)
+}
+
+// QuaternionJulia is the Julia set of z = z^2 + c over quaternions, sliced by the hyperplane where
+// the last component of z is w. The point (x, y, z) starts the orbit at x + yi + zj + wk.
+type QuaternionJulia struct {
+	c          Quaternion
+	w          float64
+	iterations int
+}
+
+// NewQuaternionJulia returns a *QuaternionJulia of the constant c, sliced at w.
+func NewQuaternionJulia(c Quaternion, w float64, iterations int) *QuaternionJulia {
+	return &QuaternionJulia{c: c, w: w, iterations: iterations}
+}
+
+// escapeRadius returns the radius beyond which the orbits escape, where |z|^2 - |c| > |z|.
+func (julia *QuaternionJulia) escapeRadius() float64 {
+	return (1 + math.Sqrt(1+4*julia.c.Magnitude())) / 2
+}
+
+// iterate runs the orbit of p and returns the distance estimate and the orbit trap, the smallest
+// distance from the orbit to the origin.
+func (julia *QuaternionJulia) iterate(p Tuple) (float64, float64) {
+	z := NewQuaternion(p.x, p.y, p.z, julia.w)
+	// The derivative of z^2 + c is 2z, only its magnitude is needed.
+	dz := 1.0
+	r := z.Magnitude()
+	trap := r
+	bailout := math.Max(4, julia.escapeRadius())
+	for i := 0; i < julia.iterations && r <= bailout; i++ {
+		dz *= 2 * r
+		z = z.Multiply(z)
+		z = NewQuaternion(z.w+julia.c.w, z.x+julia.c.x, z.y+julia.c.y, z.z+julia.c.z)
+		r = z.Magnitude()
+		trap = math.Min(trap, r)
+	}
+	if r == 0 {
+		return 0, 0
+	}
+	return 0.5 * r * math.Log(r) / dz, trap
+}
+
+// Distance returns the estimated distance from p to the Julia set.
+func (julia *QuaternionJulia) Distance(p Tuple) float64 {
+	d, _ := julia.iterate(p)
+	return d
+}
+
+// OrbitTrap returns the smallest distance from the orbit of p to the origin, clamped to 1.
+func (julia *QuaternionJulia) OrbitTrap(p Tuple) float64 {
+	_, trap := julia.iterate(p)
+	return math.Min(trap, 1)
+}
+
+// Bounds returns the bounding box of the Julia set, the cube around the escape radius.
+func (julia *QuaternionJulia) Bounds() *BoundingBox {
+	r := julia.escapeRadius()
+	return NewBoundingBoxFloat(-r, -r, -r, r, r, r)
+}
+
+// MengerSponge is the cube from (-1, -1, -1) to (1, 1, 1) with a cross shaped hole carved through
+// the middle of each face, then of each of the 20 remaining sub-cubes, iterations times.
+type MengerSponge struct {
+	iterations int
+}
+
+// NewMengerSponge returns a *MengerSponge carved the given number of times.
+func NewMengerSponge(iterations int) *MengerSponge {
+	return &MengerSponge{iterations: iterations}
+}
+
+// iterate returns the signed distance from p to the sponge and the orbit trap, the level of the
+// largest hole the point is closest to, 0 for the outside of the cube and 1 for the smallest holes.
+func (sponge *MengerSponge) iterate(p Tuple) (float64, float64) {
+	d := NewBoxSDF(1, 1, 1).Distance(p)
+	trap := 0.0
+	scale := 1.0
+	for i := 0; i < sponge.iterations; i++ {
+		// Position in the cell of this level, from -1 to 1, then distance to the cross of the cell.
+		ax := mod(p.x*scale, 2) - 1
+		ay := mod(p.y*scale, 2) - 1
+		az := mod(p.z*scale, 2) - 1
+		scale *= 3
+		rx := math.Abs(1 - 3*math.Abs(ax))
+		ry := math.Abs(1 - 3*math.Abs(ay))
+		rz := math.Abs(1 - 3*math.Abs(az))
+		da := math.Max(rx, ry)
+		db := math.Max(ry, rz)
+		dc := math.Max(rz, rx)
+		c := (math.Min(da, math.Min(db, dc)) - 1) / scale
+		if c > d {
+			d = c
+			trap = float64(i+1) / float64(sponge.iterations)
+		}
+	}
+	return d, trap
+}
+
+// mod returns the remainder of a divided by b with the sign of b, unlike math.Mod.
+func mod(a, b float64) float64 {
+	return a - b*math.Floor(a/b)
+}
+
+// Distance returns the signed distance from p to the sponge.
+func (sponge *MengerSponge) Distance(p Tuple) float64 {
+	d, _ := sponge.iterate(p)
+	return d
+}
+
+// OrbitTrap returns the level of the hole closest to p, scaled between 0 and 1.
+func (sponge *MengerSponge) OrbitTrap(p Tuple) float64 {
+	_, trap := sponge.iterate(p)
+	return trap
+}
+
+// Bounds returns the bounding box of the sponge.
+func (sponge *MengerSponge) Bounds() *BoundingBox {
+	return NewBoundingBoxFloat(-1, -1, -1, 1, 1, 1)
+}
+
+// OrbitTrapPattern returns a pattern coloring a fractal with the value of its orbit trap at each
+// point in object space, going through the colors in order as the value goes from 0 to 1.
+func OrbitTrapPattern(fractal Fractal, colors ...Color) *Pattern {
+	return NewPattern(NewCanvas(0, 0), [][]Color{colors}, func(_ *Canvas, colors []Color, p Tuple) Color {
+		if len(colors) == 1 {
+			return colors[0]
+		}
+		v := math.Max(0, math.Min(1, fractal.OrbitTrap(p))) * float64(len(colors)-1)
+		i := math.Min(math.Floor(v), float64(len(colors)-2))
+		a, b := colors[int(i)], colors[int(i)+1]
+		return a.Add(b.Subtract(a).MultiplyByScalar(v - i))
+	})
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// fractalWorld renders a Mandelbulb, a quaternion Julia set and a Menger sponge colored by their orbit traps.
+func fractalWorld() *Canvas {
+	start := time.Now()
+	lights := []*PointLight{
+		NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1)),
+	}
+	floor := NewPlane()
+	floor.SetTransform(Translation(0, -1.2, 0))
+	floor.material.pattern = CheckersPattern(NewColor(0.6, 0.6, 0.6), NewColor(0.1, 0.1, 0.1))
+	floor.material.reflective = 0.3
+
+	mandelbulb := NewMandelbulb(8, 10)
+	s1 := NewSDFShape(mandelbulb)
+	s1.material.pattern = OrbitTrapPattern(mandelbulb, NewColor(0.9, 0.2, 0.1), NewColor(0.9, 0.8, 0.2), NewColor(0.2, 0.3, 0.9))
+	s1.material.specular = 0.2
+	s1.SetTransform(Translation(-2.5, 0, 0))
+
+	julia := NewQuaternionJulia(NewQuaternion(-0.2, 0.8, 0, 0), 0, 12)
+	s2 := NewSDFShape(julia)
+	s2.material.pattern = OrbitTrapPattern(julia, NewColor(0.1, 0.3, 0.8), NewColor(0.2, 0.9, 0.6))
+	s2.material.specular = 0.2
+	s2.SetTransform(Scaling(0.8, 0.8, 0.8))
+
+	sponge := NewMengerSponge(4)
+	s3 := NewSDFShape(sponge)
+	s3.material.pattern = OrbitTrapPattern(sponge, NewColor(0.8, 0.8, 0.8), NewColor(0.8, 0.3, 0.1))
+	s3.SetTransform(Translation(2.5, 0, 0))
+	s3.ApplyTransform(RotationY(PI / 6))
+
+	world := NewWorld(lights, []Shape{floor, s1, s2, s3})
+
+	camera := NewCamera(600, 300, PI/3)
+	camera.SetTransform(ViewTransform(Point(0, 2, -6), Point(0, 0, 0), Vector(0, 1, 0)))
+
+	canvas := camera.RenderWithThreadPool(world, defaultRecursionDepth)
+
+	fmt.Println("Render time: ", time.Now().Sub(start))
+
+	return canvas
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFractalWorld(t *testing.T) {
+
+	canvas := fractalWorld()
+
+	file, err := os.Create("fractalWorld.ppm")
+	if err != nil {
+		t.Errorf("Error: %v", err)
+	}
+	file.WriteString(canvas.ToPPM())
+	file.Close()
+}
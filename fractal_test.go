@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// The Menger sponge is a box with holes carved at every level.
+func TestMengerSponge(t *testing.T) {
+	sponge := NewMengerSponge(3)
+	tests := []struct {
+		point    Tuple
+		expected float64
+	}{
+		{Point(3, 0, 0), 2},
+		{Point(0, 0, 0), 1.0 / 3},
+		{Point(0.99, 0.99, 0.99), -0.01},
+	}
+	for _, test := range tests {
+		if d := sponge.Distance(test.point); !floatEqual(d, test.expected) {
+			t.Errorf("Menger sponge distance at %v: got %v, expected: %v", test.point, d, test.expected)
+		}
+	}
+
+	shape := NewSDFShape(sponge)
+	if xs := shape.Intersect(NewRay(Point(0, 0, -5), Vector(0, 0, 1)), nil); len(xs) != 0 {
+		t.Errorf("Menger sponge intersections through the middle hole: got %v, expected none", xs)
+	}
+	xs := shape.Intersect(NewRay(Point(0.8, 0.8, -5), Vector(0, 0, 1)), nil)
+	if len(xs) != 1 || math.Abs(xs[0].t-4) > EPSILON {
+		t.Errorf("Menger sponge intersections: got %v, expected one at t = %v", xs, 4)
+	}
+}
+
+// Rays stop at the surface of the Mandelbulb and the Julia set, inside their bounds.
+func TestFractalIntersect(t *testing.T) {
+	fractals := []Fractal{
+		NewMandelbulb(8, 10),
+		NewQuaternionJulia(NewQuaternion(-0.2, 0.8, 0, 0), 0, 12),
+	}
+	for _, fractal := range fractals {
+		shape := NewSDFShape(fractal)
+		r := NewRay(Point(0.1, 0.05, -3), Vector(0, 0, 1))
+		xs := shape.Intersect(r, nil)
+		if len(xs) != 1 {
+			t.Errorf("Fractal intersections count: got %v, expected: %v", len(xs), 1)
+			continue
+		}
+		p := r.Position(xs[0].t)
+		if d := fractal.Distance(p); math.Abs(d) > shape.epsilon {
+			t.Errorf("Fractal distance at the intersection: got %v, expected less than: %v", d, shape.epsilon)
+		}
+		if b := shape.LocalBounds(); !b.ContainsPoint(p) {
+			t.Errorf("Fractal intersection: got %v, expected inside: %v", p, b)
+		}
+		if d := fractal.Distance(Point(0, 0, 0)); d > shape.epsilon {
+			t.Errorf("Fractal distance at the origin: got %v, expected inside the set", d)
+		}
+	}
+}
+
+// Orbit traps pick the color of the pattern.
+func TestOrbitTrapPattern(t *testing.T) {
+	colors := []Color{NewColor(1, 0, 0), NewColor(0, 1, 0), NewColor(0, 0, 1)}
+	mandelbulb := OrbitTrapPattern(NewMandelbulb(8, 10), colors...)
+	// The center of the sponge is in the hole of the first of 2 levels.
+	sponge := OrbitTrapPattern(NewMengerSponge(2), colors...)
+	gradient := OrbitTrapPattern(NewMengerSponge(4), colors...)
+	tests := []struct {
+		pattern  *Pattern
+		point    Tuple
+		expected Color
+	}{
+		{mandelbulb, Point(0, 0, 0), NewColor(1, 0, 0)},
+		{mandelbulb, Point(3, 0, 0), NewColor(0, 0, 1)},
+		{sponge, Point(0, 0, 0), NewColor(0, 1, 0)},
+		{sponge, Point(3, 0, 0), NewColor(1, 0, 0)},
+		{gradient, Point(0, 0, 0), NewColor(0.5, 0.5, 0)},
+	}
+	for _, test := range tests {
+		if c := test.pattern.ColorAt(test.point); !c.Equals(test.expected) {
+			t.Errorf("Orbit trap color at %v: got %v, expected: %v", test.point, c, test.expected)
+		}
+	}
+}
@@ -1,116 +1,102 @@
 package main
 
-import (
-	"math/rand"
-	"sort"
-)
-
 // Group will implement all the methods defined in the interface Shape becoming a Shape itself.
 type Group struct {
-	transform        Matrix
-	inverse          Matrix
-	inverseTranspose Matrix
-	children         []Shape
-	id               int
-	label            string
-	parent           Shape
-	BoundingBox      *BoundingBox
-	savedRay         *Ray
+	BaseShape
+	children    []Shape
+	label       string
+	BoundingBox *BoundingBox
+	savedRay    Ray
+	// flat is the compiled hierarchy of the group, used instead of the children once Compile() is called.
+	flat *FlatBVH
+	// builtCost is the SAH cost of the hierarchy measured by DivideSAH, which Refit compares to.
+	builtCost float64
 }
 
 // NewGroup returns a *Group that can contain children Shapes. A group will implement the Shape interface behaviour.
 func NewGroup() *Group {
 
 	return &Group{
-		transform:        IdentityMatrix,
-		inverse:          IdentityMatrix,
-		inverseTranspose: IdentityMatrix,
-		BoundingBox:      NewEmptyBoundingBox(),
-		children:         make([]Shape, 0),
-		savedRay:         NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
-		id:               rand.Int(),
+		BaseShape:   NewBaseShape(),
+		BoundingBox: NewEmptyBoundingBox(),
+		children:    make([]Shape, 0),
+		savedRay:    NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
 	}
 }
 
-// GetID returns the id of the group (shape).
-func (g *Group) GetID() int {
-	return g.id
-}
-
 // AddChild will add the shape as a child to the group and establish its parent relationship from the shape itself.
 func (g *Group) AddChild(shapes ...Shape) {
 
+	g.touch()
+	g.flat = nil
 	for i := 0; i < len(shapes); i++ {
 		g.children = append(g.children, shapes[i])
 		shapes[i].SetParent(g)
 
-		// adjust boundingBox for additional shape.
-		g.BoundingBox.Merge(Bounds(shapes[i]))
+		// adjust boundingBox for additional shape, converted into group space.
+		g.BoundingBox.Merge(ParentSpaceBounds(shapes[i]))
 	}
 }
 
-func (g *Group) localIntersect(r *Ray) []*Intersection {
+// LocalIntersect tests the ray against the group's bounding box and then against all of its children.
+// The intersections of the children are appended to xs and only that appended part is sorted.
+func (g *Group) LocalIntersect(r Ray, xs Intersections) Intersections {
 
 	if g.BoundingBox != nil && !IntersectRayWithBox(r, g.BoundingBox) {
-		return nil
+		return xs
 	}
 	g.savedRay = r
-	// intersections := []*Intersection{}
-	intersections := Intersections{}
-	for i := range g.children {
-
-		xs := g.children[i].Intersect(r)
-		if len(xs) > 0 {
-			intersections = append(intersections, xs...)
+	start := len(xs)
+	if g.flat != nil {
+		xs = g.flat.Intersect(r, xs)
+	} else {
+		for i := range g.children {
+			xs = g.children[i].Intersect(r, xs)
 		}
 	}
 
-	if len(intersections) > 1 {
-
-		sort.Slice(
-			intersections,
-			func(i, j int) bool {
-				return intersections[i].t < intersections[j].t
-			},
-		)
+	if len(xs)-start > 1 {
+		xs[start:].Sort()
 	}
 
-	return intersections
+	return xs
 }
 
 // Intersect with the Shapes being transformed by both its own transformation and that of its parent (Group).
-func (g *Group) Intersect(worldRay *Ray) []*Intersection {
-	localGroupRay := worldRay.Transform(g.inverse)
-	return g.localIntersect(localGroupRay)
-}
-
-// SetTransform applies the transformation matrix to the Shape.
-func (g *Group) SetTransform(transformation Matrix) {
-	g.transform = g.transform.MultiplyMatrix(transformation)
-	g.inverse = g.transform.Inverse()
-	g.inverseTranspose = g.inverse.Transpose()
-}
-
-// Transform returns the transformation.
-func (g *Group) Transform() Matrix {
-	return g.transform
-}
-
-// GetInverse returns the cached inverse matrix of the current Shape.
-func (g *Group) GetInverse() Matrix {
-
-	return g.inverse
-}
-
-// GetInverseTranspose returns the cached inverseTranspose matrix of the current Shape.
-func (g *Group) GetInverseTranspose() Matrix {
+func (g *Group) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(g, worldRay, xs)
+}
+
+// Compile flattens the hierarchy of the group into a FlatBVH, which is then used by LocalIntersect.
+// The group must be compiled again after its hierarchy is modified, adding a child discards it.
+func (g *Group) Compile() {
+	g.flat = NewFlatBVH(g)
+}
+
+// intersectNearest appends the intersections of a ray in the group's parent space to xs like Intersect,
+// but lets the compiled hierarchy skip what lies beyond closest. See FlatBVH.IntersectNearest.
+func (g *Group) intersectNearest(worldRay Ray, xs Intersections, closest float64) (Intersections, float64) {
+	if g.flat == nil {
+		start := len(xs)
+		xs = g.Intersect(worldRay, xs)
+		for i := start; i < len(xs); i++ {
+			if xs[i].t >= 0 && xs[i].t < closest {
+				closest = xs[i].t
+			}
+		}
+		return xs, closest
+	}
 
-	return g.inverseTranspose
+	r := worldRay.Transform(g.GetInverse())
+	if g.BoundingBox != nil && !IntersectRayWithBox(r, g.BoundingBox) {
+		return xs, closest
+	}
+	return g.flat.IntersectNearest(r, xs, closest)
 }
 
 // WorldToObject converts a Point from world space to the defined (shape) object space,
 // recursively taking into consideration any parent object(s) between the two spaces.
-func WorldToObject(shape Shape, point *Tuple) *Tuple {
+func WorldToObject(shape Shape, point Tuple) Tuple {
 	if shape.GetParent() != nil {
 		point = WorldToObject(shape.GetParent(), point)
 	}
@@ -120,7 +106,7 @@ func WorldToObject(shape Shape, point *Tuple) *Tuple {
 
 // NormalToWorld receives a normal vector in object space and transform it to world space,
 // taking into consideration any parent objects between the two spaces.
-func NormalToWorld(shape Shape, normal *Tuple) *Tuple {
+func NormalToWorld(shape Shape, normal Tuple) Tuple {
 
 	normal = shape.GetInverseTranspose().MultiplyMatrixByTuple(normal)
 	normal.w = 0
@@ -133,37 +119,44 @@ func NormalToWorld(shape Shape, normal *Tuple) *Tuple {
 	return normal
 }
 
+// VectorToWorld receives a direction in object space, such as a tangent, and transform it to world
+// space, taking into consideration any parent objects between the two spaces.
+func VectorToWorld(shape Shape, vector Tuple) Tuple {
+
+	vector = shape.Transform().MultiplyMatrixByTuple(vector)
+	vector.w = 0
+	vector = vector.Normalize()
+
+	if shape.GetParent() != nil {
+		vector = VectorToWorld(shape.GetParent(), vector)
+	}
+
+	return vector
+}
+
 // NormalAt will find the normal on a child object of a group, taking into account transformations
 // on both the child object and the parent(s).
-func NormalAt(s Shape, worldPoint *Tuple, intersection *Intersection) *Tuple {
+func NormalAt(s Shape, worldPoint Tuple, intersection *Intersection) Tuple {
 
 	// Transform point from world to object space, including recursively traversing any parent object
 	// transforms.
 	localPoint := WorldToObject(s, worldPoint)
 
 	// Normal in local space given the shape's implementation.
-	objectNormal := s.localNormalAt(localPoint, intersection)
+	objectNormal := s.LocalNormalAt(localPoint, intersection)
 
 	// Convert normal from object space back into world space, again recursively applying any
 	// parent transforms.
 	return NormalToWorld(s, objectNormal)
 }
 
-// GetParent returns the parent shape from this current shape.
-func (g *Group) GetParent() Shape {
-	return g.parent
-}
-
-// SetParent sets the parent shape from this current shape.
-func (g *Group) SetParent(shape Shape) {
-	g.parent = shape
-}
-
 // NormalAt is not applicable to a group. use the global NormalAt() instead.
-func (g *Group) NormalAt(*Tuple, *Intersection) *Tuple {
+func (g *Group) NormalAt(Tuple, *Intersection) Tuple {
 	panic("not applicable to a group. Use NormalAt() instead.")
 }
-func (g *Group) localNormalAt(*Tuple, *Intersection) *Tuple {
+
+// LocalNormalAt is not applicable to a group.
+func (g *Group) LocalNormalAt(Tuple, *Intersection) Tuple {
 	panic("not applicable to a group. normals are always computed by calling the concrete shape’s local_normal_at()")
 }
 
@@ -177,6 +170,20 @@ func (g *Group) SetMaterial(material *Material) {
 // Material not applicable to a group.
 func (g *Group) Material() *Material { panic("not applicable to a group.") }
 
+// LocalBounds converts the bounds of all the group's children into group space
+// and combines them into a single bounding box.
+func (g *Group) LocalBounds() *BoundingBox {
+	// A compiled group already holds the bounds of its children in the root node.
+	if g.flat != nil && len(g.flat.nodes) > 0 {
+		return NewBoundingBox(g.flat.nodes[0].min, g.flat.nodes[0].max)
+	}
+	box := NewEmptyBoundingBox()
+	for i := 0; i < len(g.children); i++ {
+		box.Merge(ParentSpaceBounds(g.children[i]))
+	}
+	return box
+}
+
 // Bounds calculates de boundingBox of the group taking in considerantion of the group's children.
 func (g *Group) Bounds() {
 	g.BoundingBox = Bounds(g)
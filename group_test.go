@@ -14,7 +14,7 @@ func TestNewGroup(t *testing.T) {
 	if !(len(g.children) == 0) {
 		t.Errorf("Creating a new group, does not contain children shapes: got: %v, expected: %v", len(g.children), 0)
 	}
-	if !(g.transform != nil) {
+	if !(g.transform == IdentityMatrix) {
 		t.Errorf("Creating a new group, contains a default transformation matrix: got: %v, expected: %v", reflect.TypeOf(g.Transform), reflect.TypeOf(NewIdentityMatrix()))
 	}
 }
@@ -43,7 +43,7 @@ func TestIntersectEmptyGroup(t *testing.T) {
 	// Intersecting a ray with an empty group.
 	g := NewGroup()
 	r := NewRay(Point(0, 0, 0), Vector(0, 0, 1))
-	xs := g.localIntersect(r)
+	xs := g.LocalIntersect(r, nil)
 
 	if !(len(xs) == 0) {
 		t.Errorf("Intersecting a ray with an empty group: got: %v, expected: %v", len(xs), 0)
@@ -71,7 +71,7 @@ func TestIntersectGroup(t *testing.T) {
 
 	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
 
-	xs := g.localIntersect(r)
+	xs := g.LocalIntersect(r, nil)
 
 	if !(len(xs) == 4) {
 		t.Errorf("Intersecting a ray with a nonempty group: got: %v, expected: %v", len(xs), 4)
@@ -89,7 +89,7 @@ func TestIntersectGroup(t *testing.T) {
 		t.Errorf("Intersecting a ray with a nonempty group: got: %v, expected: %v", xs[0].object.GetID(), s2.GetID())
 	}
 
-	xs3 := s3.Intersect(r)
+	xs3 := s3.Intersect(r, nil)
 	if !(len(xs3) == 0) {
 		t.Errorf("Intersecting a ray with a nonempty group: got: %v, expected: %v", len(xs3), 0)
 	}
@@ -105,7 +105,7 @@ func TestGroupTransform(t *testing.T) {
 	g.AddChild(s)
 
 	r := NewRay(Point(10, 0, -10), Vector(0, 0, 1))
-	xs := g.Intersect(r)
+	xs := g.Intersect(r, nil)
 
 	if !(len(xs) == 2) {
 		t.Errorf("Intersecting a transformed group: got: %v, expected: %v", len(xs), 2)
@@ -0,0 +1,224 @@
+package main
+
+import "math"
+
+// Heightfield is a terrain built from a grayscale canvas: each pixel is a vertex of a regular grid
+// covering the square from (0, 0) to (1, 1) in the xz plane, raised along y by the gray level of
+// the pixel between 0 and 1. The top row of the image is at z = 1 and its left column at x = 0, so
+// that the image reads as it is from above with -z towards the viewer.
+// Every cell of the grid holds two triangles which are never built: rays walk through the cells
+// they cross with a grid DDA and only the triangles of those cells are tested.
+type Heightfield struct {
+	BaseShape
+	// columns and rows are the number of vertices along x and z.
+	columns, rows int
+	// heights[k*columns+i] is the height of the vertex i along x and k along z.
+	heights []float64
+	normals []Tuple
+	bounds  *BoundingBox
+}
+
+// NewHeightfield returns a *Heightfield of the canvas with Identity matrix as transform and
+// default material. The gray level of a pixel is the mean of its channels.
+func NewHeightfield(canvas *Canvas) *Heightfield {
+	heightfield := &Heightfield{
+		BaseShape: NewBaseShape(),
+		columns:   canvas.width,
+		rows:      canvas.height,
+		heights:   make([]float64, canvas.width*canvas.height),
+		normals:   make([]Tuple, canvas.width*canvas.height),
+		bounds:    NewEmptyBoundingBox(),
+	}
+
+	for y := 0; y < canvas.height; y++ {
+		k := canvas.height - 1 - y
+		for x := 0; x < canvas.width; x++ {
+			c := canvas.PixelAt(x, y)
+			h := (c.r + c.g + c.b) / 3
+			heightfield.heights[k*canvas.width+x] = h
+			heightfield.bounds.Add(Point(0, h, 0))
+		}
+	}
+	heightfield.bounds.min.x, heightfield.bounds.min.z = 0, 0
+	heightfield.bounds.max.x, heightfield.bounds.max.z = 1, 1
+
+	for k := 0; k < heightfield.rows; k++ {
+		for i := 0; i < heightfield.columns; i++ {
+			heightfield.normals[k*heightfield.columns+i] = heightfield.vertexNormal(i, k)
+		}
+	}
+	return heightfield
+}
+
+// cells returns the number of cells along x and z.
+func (heightfield *Heightfield) cells() (int, int) {
+	return heightfield.columns - 1, heightfield.rows - 1
+}
+
+// vertex returns the vertex i along x and k along z in object space.
+func (heightfield *Heightfield) vertex(i, k int) Tuple {
+	nx, nz := heightfield.cells()
+	return Point(float64(i)/float64(nx), heightfield.heights[k*heightfield.columns+i], float64(k)/float64(nz))
+}
+
+// vertexNormal returns the normal at a vertex from the slopes of the grid around it, by central
+// differences inside the grid and one sided differences on its edges.
+func (heightfield *Heightfield) vertexNormal(i, k int) Tuple {
+	nx, nz := heightfield.cells()
+	height := func(i, k int) float64 { return heightfield.heights[k*heightfield.columns+i] }
+
+	i0, i1 := int(math.Max(float64(i-1), 0)), int(math.Min(float64(i+1), float64(nx)))
+	k0, k1 := int(math.Max(float64(k-1), 0)), int(math.Min(float64(k+1), float64(nz)))
+	dx, dz := 0.0, 0.0
+	if i1 > i0 {
+		dx = (height(i1, k) - height(i0, k)) * float64(nx) / float64(i1-i0)
+	}
+	if k1 > k0 {
+		dz = (height(i, k1) - height(i, k0)) * float64(nz) / float64(k1-k0)
+	}
+	return Vector(-dx, 1, -dz).Normalize()
+}
+
+// triangle returns the indices along x and z of the vertices of a triangle of the grid. The cell
+// (i, k) holds the triangles 2*(k*nx+i), on the side of the vertex (i+1, k), and 2*(k*nx+i)+1.
+func (heightfield *Heightfield) triangle(index int) (i1, k1, i2, k2, i3, k3 int) {
+	nx, _ := heightfield.cells()
+	cell := index / 2
+	i, k := cell%nx, cell/nx
+	if index%2 == 0 {
+		return i, k, i + 1, k, i + 1, k + 1
+	}
+	return i, k, i + 1, k + 1, i, k + 1
+}
+
+// LocalIntersect calculates the intersections between a ray in object space and the heightfield,
+// walking through the cells crossed by the ray from where it enters the bounds to where it leaves them.
+func (heightfield *Heightfield) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	nx, nz := heightfield.cells()
+	if nx < 1 || nz < 1 {
+		return xs
+	}
+	tMin, tMax, hit := rayBoxRange(localRay, heightfield.bounds)
+	if !hit {
+		return xs
+	}
+
+	o, d := localRay.origin, localRay.direction
+	start := localRay.Position(tMin)
+	i := int(math.Max(0, math.Min(float64(nx-1), math.Floor(start.x*float64(nx)))))
+	k := int(math.Max(0, math.Min(float64(nz-1), math.Floor(start.z*float64(nz)))))
+
+	// For each axis, the step to the next cell, the t where the ray reaches the next cell and the
+	// t it takes to cross a whole cell.
+	axis := func(cell, cells int, origin, direction float64) (int, float64, float64) {
+		switch {
+		case direction > 0:
+			return 1, (float64(cell+1)/float64(cells) - origin) / direction, 1 / (float64(cells) * direction)
+		case direction < 0:
+			return -1, (float64(cell)/float64(cells) - origin) / direction, -1 / (float64(cells) * direction)
+		default:
+			return 0, math.Inf(1), math.Inf(1)
+		}
+	}
+	stepX, nextX, deltaX := axis(i, nx, o.x, d.x)
+	stepZ, nextZ, deltaZ := axis(k, nz, o.z, d.z)
+
+	tEnter := tMin
+	for i >= 0 && i < nx && k >= 0 && k < nz && tEnter <= tMax {
+		tExit := math.Min(tMax, math.Min(nextX, nextZ))
+
+		// Skip the cell when the ray stays above or below its four corners.
+		y0, y1 := o.y+tEnter*d.y, o.y+tExit*d.y
+		low, high := heightfield.cellRange(i, k)
+		if math.Max(y0, y1) >= low && math.Min(y0, y1) <= high {
+			cell := 2 * (k*nx + i)
+			xs = heightfield.intersectTriangle(localRay, cell, xs)
+			xs = heightfield.intersectTriangle(localRay, cell+1, xs)
+		}
+
+		if nextX < nextZ {
+			i += stepX
+			tEnter = nextX
+			nextX += deltaX
+		} else {
+			k += stepZ
+			tEnter = nextZ
+			nextZ += deltaZ
+		}
+	}
+	return xs
+}
+
+// cellRange returns the lowest and highest heights of the corners of the cell (i, k).
+func (heightfield *Heightfield) cellRange(i, k int) (float64, float64) {
+	c := heightfield.columns
+	h00, h10 := heightfield.heights[k*c+i], heightfield.heights[k*c+i+1]
+	h01, h11 := heightfield.heights[(k+1)*c+i], heightfield.heights[(k+1)*c+i+1]
+	return math.Min(math.Min(h00, h10), math.Min(h01, h11)), math.Max(math.Max(h00, h10), math.Max(h01, h11))
+}
+
+// intersectTriangle appends the intersection between the ray and a triangle of the grid to xs,
+// with the barycentric coordinates of the hit as u and v. Cells can be much smaller than EPSILON
+// so only rays exactly parallel to the triangle are discarded.
+func (heightfield *Heightfield) intersectTriangle(localRay Ray, index int, xs Intersections) Intersections {
+	i1, k1, i2, k2, i3, k3 := heightfield.triangle(index)
+	p1 := heightfield.vertex(i1, k1)
+	e1 := heightfield.vertex(i2, k2).Substract(p1)
+	e2 := heightfield.vertex(i3, k3).Substract(p1)
+
+	dirCrossE2 := localRay.direction.CrossProduct(e2)
+	determinant := e1.DotProduct(dirCrossE2)
+	if determinant == 0 {
+		return xs
+	}
+
+	f := 1.0 / determinant
+	p1ToOrigin := localRay.origin.Substract(p1)
+	u := f * p1ToOrigin.DotProduct(dirCrossE2)
+	if u < 0 || u > 1 {
+		return xs
+	}
+
+	originCrossE1 := p1ToOrigin.CrossProduct(e1)
+	v := f * localRay.direction.DotProduct(originCrossE1)
+	if v < 0 || (u+v) > 1 {
+		return xs
+	}
+
+	hit := NewIntersectionUV(f*e2.DotProduct(originCrossE1), heightfield, u, v)
+	hit.index = index
+	return append(xs, hit)
+}
+
+// Intersect calculates the intersections between a ray in world space and the heightfield.
+func (heightfield *Heightfield) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(heightfield, worldRay, xs)
+}
+
+// LocalNormalAt interpolates the normals of the vertices of the triangle hit at the barycentric
+// coordinates of the intersection.
+func (heightfield *Heightfield) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	i1, k1, i2, k2, i3, k3 := heightfield.triangle(intersection.index)
+	c := heightfield.columns
+	n1, n2, n3 := heightfield.normals[k1*c+i1], heightfield.normals[k2*c+i2], heightfield.normals[k3*c+i3]
+	return n2.Multiply(intersection.u).
+		Add(n3.Multiply(intersection.v)).
+		Add(n1.Multiply(1 - intersection.u - intersection.v)).
+		Normalize()
+}
+
+// NormalAt calculates the normal at a given point in world space.
+func (heightfield *Heightfield) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(heightfield, worldPoint, intersection)
+}
+
+// LocalBounds returns the bounding box of the heightfield, from its lowest to its highest vertex.
+func (heightfield *Heightfield) LocalBounds() *BoundingBox {
+	return heightfield.bounds
+}
+
+// UVAt maps a point of the heightfield to (u, v) so that the source image, or any image of the
+// same proportions, covers the terrain as it did the heights with uvImage.
+func (heightfield *Heightfield) UVAt(point Tuple) (u, v float64) {
+	return point.x, point.z
+}
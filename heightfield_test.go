@@ -0,0 +1,106 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// heightfieldTestCanvas returns a small canvas of random gray levels.
+func heightfieldTestCanvas(rng *rand.Rand, width, height int) *Canvas {
+	canvas := NewCanvas(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			gray := rng.Float64()
+			canvas.WritePixel(x, y, NewColor(gray, gray, gray))
+		}
+	}
+	return canvas
+}
+
+// A heightfield is intersected like the mesh of the triangles of its cells.
+func TestHeightfieldMatchesMesh(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	heightfield := NewHeightfield(heightfieldTestCanvas(rng, 9, 6))
+
+	vertices := []Tuple{}
+	normals := []Tuple{}
+	for k := 0; k < heightfield.rows; k++ {
+		for i := 0; i < heightfield.columns; i++ {
+			vertices = append(vertices, heightfield.vertex(i, k))
+			normals = append(normals, heightfield.normals[k*heightfield.columns+i])
+		}
+	}
+	indices := []int{}
+	nx, nz := heightfield.cells()
+	for index := 0; index < 2*nx*nz; index++ {
+		i1, k1, i2, k2, i3, k3 := heightfield.triangle(index)
+		c := heightfield.columns
+		indices = append(indices, k1*c+i1, k2*c+i2, k3*c+i3)
+	}
+	mesh := NewMesh(vertices, normals, nil, indices, indices, nil)
+
+	for n := 0; n < 500; n++ {
+		origin := Point(rng.Float64()*3-1, rng.Float64()*3-1, rng.Float64()*3-1)
+		target := Point(rng.Float64(), rng.Float64(), rng.Float64())
+		r := NewRay(origin, target.Substract(origin).Normalize())
+
+		xs, expected := heightfield.Intersect(r, nil), mesh.Intersect(r, nil)
+		xs.Sort()
+		expected.Sort()
+		if len(xs) != len(expected) {
+			t.Errorf("Heightfield intersections count: got %v, expected: %v", len(xs), len(expected))
+			continue
+		}
+		for i := range xs {
+			if !floatEqual(xs[i].t, expected[i].t) {
+				t.Errorf("Heightfield intersection: got %v, expected: %v", xs[i].t, expected[i].t)
+			}
+			point := r.Position(xs[i].t)
+			if n, want := heightfield.NormalAt(point, &xs[i]), mesh.NormalAt(point, &expected[i]).Normalize(); !n.Equals(want) {
+				t.Errorf("Heightfield normal: got %v, expected: %v", n, want)
+			}
+		}
+	}
+}
+
+// The bounds of a heightfield go from its lowest to its highest pixel and its texture coordinates
+// line up with the image.
+func TestHeightfieldBoundsAndUV(t *testing.T) {
+	canvas := NewCanvas(3, 2)
+	canvas.WritePixel(0, 0, NewColor(0.2, 0.2, 0.2))
+	canvas.WritePixel(1, 0, NewColor(0.6, 0.3, 0))
+	canvas.WritePixel(2, 0, NewColor(0.5, 0.5, 0.5))
+	canvas.WritePixel(0, 1, NewColor(0.4, 0.4, 0.4))
+	canvas.WritePixel(1, 1, NewColor(0.8, 0.8, 0.8))
+	canvas.WritePixel(2, 1, NewColor(0.3, 0.3, 0.3))
+	heightfield := NewHeightfield(canvas)
+
+	box := heightfield.LocalBounds()
+	if !box.min.Equals(Point(0, 0.2, 0)) || !box.max.Equals(Point(1, 0.8, 1)) {
+		t.Errorf("Heightfield bounds: got %v, expected: %v", box, NewBoundingBoxFloat(0, 0.2, 0, 1, 0.8, 1))
+	}
+
+	// The top left pixel is at the far left corner and the middle of the bottom row near the viewer.
+	tests := []struct {
+		x, z           float64
+		height         float64
+		pixelX, pixelY int
+	}{
+		{0.01, 0.99, 0.2, 0, 0},
+		{0.5, 0.01, 0.8, 1, 1},
+		{0.99, 0.99, 0.5, 2, 0},
+	}
+	texture := textureMap(uvImage(canvas), heightfield.UVAt)
+	for _, test := range tests {
+		r := NewRay(Point(test.x, 5, test.z), Vector(0, -1, 0))
+		xs := heightfield.Intersect(r, nil)
+		if len(xs) != 1 || math.Abs(r.Position(xs[0].t).y-test.height) > 0.02 {
+			t.Errorf("Heightfield intersection at (%v, %v): got %v, expected a height of about: %v", test.x, test.z, xs, test.height)
+			continue
+		}
+		if c, want := patternAt(texture, r.Position(xs[0].t)), canvas.PixelAt(test.pixelX, test.pixelY); !c.Equals(want) {
+			t.Errorf("Heightfield texture at (%v, %v): got %v, expected: %v", test.x, test.z, c, want)
+		}
+	}
+}
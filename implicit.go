@@ -0,0 +1,157 @@
+package main
+
+import "math"
+
+// bracketRoots appends to roots the values of t between tMin and tMax where f changes sign.
+// The range is sampled steps times to bracket the roots, which are then refined by bisection.
+// Two roots closer than a step, such as a ray grazing the surface, may be missed.
+func bracketRoots(f func(t float64) float64, tMin, tMax float64, steps int, roots []float64) []float64 {
+	step := (tMax - tMin) / float64(steps)
+	t0 := tMin
+	f0 := f(t0)
+	for i := 1; i <= steps; i++ {
+		t1 := tMin + float64(i)*step
+		f1 := f(t1)
+		if (f0 < 0) != (f1 < 0) {
+			roots = append(roots, bisectRoot(f, t0, t1, f0))
+		}
+		t0, f0 = t1, f1
+	}
+	return roots
+}
+
+// bisectRoot bisects the interval [t0, t1] whose ends are on both sides of the root of f, f0
+// being f(t0).
+func bisectRoot(f func(t float64) float64, t0, t1, f0 float64) float64 {
+	for i := 0; i < 50 && t1-t0 > EPSILON*EPSILON; i++ {
+		mid := (t0 + t1) / 2
+		fMid := f(mid)
+		if (fMid < 0) == (f0 < 0) {
+			t0, f0 = mid, fMid
+		} else {
+			t1 = mid
+		}
+	}
+	return (t0 + t1) / 2
+}
+
+// ImplicitSurface is the surface f(x, y, z) = 0 inside finite bounds given by the user, f being
+// negative inside and positive outside. Rays are sampled steps times inside the bounds to bracket
+// the roots of f. The normal is the gradient of f, estimated by central differences unless
+// gradient is set.
+type ImplicitSurface struct {
+	BaseShape
+	f        func(x, y, z float64) float64
+	gradient func(x, y, z float64) Tuple
+	bounds   *BoundingBox
+	steps    int
+}
+
+// NewImplicitSurface returns an *ImplicitSurface of f inside bounds, with Identity matrix as
+// transform and default material.
+func NewImplicitSurface(f func(x, y, z float64) float64, bounds *BoundingBox) *ImplicitSurface {
+	return &ImplicitSurface{
+		BaseShape: NewBaseShape(),
+		f:         f,
+		bounds:    bounds,
+		steps:     128,
+	}
+}
+
+// LocalIntersect calculates the intersections between a ray in object space and the surface.
+func (implicit *ImplicitSurface) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	tMin, tMax, hit := rayBoxRange(localRay, implicit.bounds)
+	if !hit || math.IsInf(tMin, 0) || math.IsInf(tMax, 0) {
+		return xs
+	}
+
+	f := func(t float64) float64 {
+		p := localRay.Position(t)
+		return implicit.f(p.x, p.y, p.z)
+	}
+	var rootsArray [8]float64
+	for _, t := range bracketRoots(f, tMin, tMax, implicit.steps, rootsArray[:0]) {
+		xs = append(xs, NewIntersection(t, implicit))
+	}
+	return xs
+}
+
+// Intersect calculates the intersections between a ray in world space and the surface.
+func (implicit *ImplicitSurface) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(implicit, worldRay, xs)
+}
+
+// LocalNormalAt returns the gradient of f at the point.
+func (implicit *ImplicitSurface) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	x, y, z := localPoint.x, localPoint.y, localPoint.z
+	if implicit.gradient != nil {
+		return implicit.gradient(x, y, z).Normalize()
+	}
+	h := EPSILON
+	f := implicit.f
+	return Vector(f(x+h, y, z)-f(x-h, y, z), f(x, y+h, z)-f(x, y-h, z), f(x, y, z+h)-f(x, y, z-h)).Normalize()
+}
+
+// NormalAt calculates the normal at a given point in world space.
+func (implicit *ImplicitSurface) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(implicit, worldPoint, intersection)
+}
+
+// LocalBounds returns the bounds given to the surface.
+func (implicit *ImplicitSurface) LocalBounds() *BoundingBox {
+	return implicit.bounds
+}
+
+// MetaballSource is a source of the field of metaballs, centered on a point and decreasing to 0
+// at radius from it.
+type MetaballSource struct {
+	center           Tuple
+	radius, strength float64
+}
+
+// NewMetaballSource returns a MetaballSource.
+func NewMetaballSource(center Tuple, radius, strength float64) MetaballSource {
+	return MetaballSource{center: center, radius: radius, strength: strength}
+}
+
+// NewMetaballs returns the *ImplicitSurface where the field of the sources equals threshold, each
+// source contributing strength * (1 - d^2/radius^2)^2 at a distance d below its radius. Sources
+// close enough blend into a single blob. The surface lies inside the spheres of the sources,
+// which give its bounds, and its normal is the analytic gradient of the field.
+func NewMetaballs(threshold float64, sources ...MetaballSource) *ImplicitSurface {
+	bounds := NewEmptyBoundingBox()
+	for _, s := range sources {
+		r := Vector(s.radius, s.radius, s.radius)
+		bounds.Add(s.center.Substract(r))
+		bounds.Add(s.center.Add(r))
+	}
+
+	f := func(x, y, z float64) float64 {
+		field := 0.0
+		for _, s := range sources {
+			q := (square(x-s.center.x) + square(y-s.center.y) + square(z-s.center.z)) / (s.radius * s.radius)
+			if q < 1 {
+				field += s.strength * square(1-q)
+			}
+		}
+		return threshold - field
+	}
+
+	gradient := func(x, y, z float64) Tuple {
+		g := Vector(0, 0, 0)
+		for _, s := range sources {
+			d := Vector(x-s.center.x, y-s.center.y, z-s.center.z)
+			r2 := s.radius * s.radius
+			q := d.DotProduct(d) / r2
+			if q < 1 {
+				// The derivative of -strength * (1-q)^2 with q = |d|^2/r^2.
+				g = g.Add(d.Multiply(4 * s.strength * (1 - q) / r2))
+			}
+		}
+		return g
+	}
+
+	metaballs := NewImplicitSurface(f, bounds)
+	metaballs.gradient = gradient
+	return metaballs
+}
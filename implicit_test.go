@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// An implicit sphere is intersected and shaded like a Sphere.
+func TestImplicitSphere(t *testing.T) {
+	implicit := NewImplicitSurface(func(x, y, z float64) float64 {
+		return x*x + y*y + z*z - 1
+	}, NewBoundingBoxFloat(-1, -1, -1, 1, 1, 1))
+	sphere := NewSphere()
+
+	rays := []Ray{
+		NewRay(Point(0, 0, -5), Vector(0, 0, 1)),
+		NewRay(Point(0, 0, 0), Vector(0, 0, 1)),
+		NewRay(Point(-5, 0.5, 0.2), Vector(2, 0, 0)),
+		NewRay(Point(1, 3, 1), Vector(-0.2, -1, -0.1).Normalize()),
+		NewRay(Point(0, 2, -5), Vector(0, 0, 1)),
+	}
+	for _, r := range rays {
+		xs, expected := implicit.Intersect(r, nil), sphere.Intersect(r, nil)
+		if len(xs) != len(expected) {
+			t.Errorf("Implicit sphere intersections count: got %v, expected: %v", len(xs), len(expected))
+			continue
+		}
+		for i := range xs {
+			if !floatEqual(xs[i].t, expected[i].t) {
+				t.Errorf("Implicit sphere intersection: got %v, expected: %v", xs[i].t, expected[i].t)
+			}
+			point := r.Position(xs[i].t)
+			if n, want := implicit.NormalAt(point, &xs[i]), sphere.NormalAt(point, &expected[i]); !n.Equals(want) {
+				t.Errorf("Implicit sphere normal: got %v, expected: %v", n, want)
+			}
+		}
+	}
+}
+
+// Bracketing finds the four roots of a ray through an implicit torus.
+func TestImplicitTorus(t *testing.T) {
+	implicit := NewImplicitSurface(func(x, y, z float64) float64 {
+		q := math.Sqrt(x*x+z*z) - 1
+		return q*q + y*y - 0.25*0.25
+	}, NewTorus(1, 0.25).LocalBounds())
+
+	xs := implicit.Intersect(NewRay(Point(-5, 0, 0), Vector(1, 0, 0)), nil)
+	expected := []float64{3.75, 4.25, 5.75, 6.25}
+	if len(xs) != len(expected) {
+		t.Fatalf("Implicit torus intersections count: got %v, expected: %v", len(xs), len(expected))
+	}
+	for i := range xs {
+		if !floatEqual(xs[i].t, expected[i]) {
+			t.Errorf("Implicit torus intersection: got %v, expected: %v", xs[i].t, expected[i])
+		}
+	}
+}
+
+// Metaballs are round around a single source and blend between close sources.
+func TestMetaballs(t *testing.T) {
+	single := NewMetaballs(0.5, NewMetaballSource(Point(0, 0, 0), 2, 1))
+	// (1 - d^2/4)^2 = 0.5 on the surface.
+	d := 2 * math.Sqrt(1-math.Sqrt(0.5))
+	r := NewRay(Point(-5, 0, 0), Vector(1, 0, 0))
+	xs := single.Intersect(r, nil)
+	if len(xs) != 2 || !floatEqual(xs[0].t, 5-d) || !floatEqual(xs[1].t, 5+d) {
+		t.Errorf("Metaball intersections: got %v, expected: %v", xs, []float64{5 - d, 5 + d})
+	}
+	if n := single.NormalAt(r.Position(xs[0].t), &xs[0]); !n.Equals(Vector(-1, 0, 0)) {
+		t.Errorf("Metaball normal: got %v, expected: %v", n, Vector(-1, 0, 0))
+	}
+
+	// Alone, each source is a ball of radius d which does not reach the middle of the sources.
+	blob := NewMetaballs(0.5,
+		NewMetaballSource(Point(-1.2, 0, 0), 2, 1),
+		NewMetaballSource(Point(1.2, 0, 0), 2, 1))
+	r = NewRay(Point(0, 5, 0), Vector(0, -1, 0))
+	xs = blob.Intersect(r, nil)
+	if len(xs) != 2 {
+		t.Fatalf("Blended metaballs intersections count: got %v, expected: %v", len(xs), 2)
+	}
+
+	// The analytic gradient matches central differences.
+	point := r.Position(xs[0].t)
+	analytic := blob.NormalAt(point, &xs[0])
+	blob.gradient = nil
+	if n := blob.NormalAt(point, &xs[0]); !n.Equals(analytic) {
+		t.Errorf("Metaball gradient: got %v, expected: %v", analytic, n)
+	}
+
+	box := blob.LocalBounds()
+	if !box.min.Equals(Point(-3.2, -2, -2)) || !box.max.Equals(Point(3.2, 2, 2)) {
+		t.Errorf("Metaballs bounds: got %v, expected: %v", box, NewBoundingBoxFloat(-3.2, -2, -2, 3.2, 2, 2))
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+// Instance places a copy of shared geometry in the scene with its own transformation and, optionally,
+// its own material. The geometry is usually a Group divided and compiled once, so thousands of
+// instances cost little more than the geometry itself.
+//
+// The shapes of the geometry keep their single parent, the geometry's root having none: an Instance
+// is never set as their parent. Intersections found through the instance report the instance as
+// their object and the shape of the geometry that was hit as inner, which is used to compute the
+// normal and the material. Patterns are evaluated in the space of the instance.
+// The geometry must not contain instances itself.
+type Instance struct {
+	BaseShape
+	geometry Shape
+}
+
+// NewInstance returns an *Instance of the geometry, using the materials of the geometry.
+func NewInstance(geometry Shape) *Instance {
+	instance := &Instance{
+		BaseShape: NewBaseShape(),
+		geometry:  geometry,
+	}
+	instance.material = nil
+	return instance
+}
+
+// Generation changes with the transformation of the instance and with the shared geometry.
+func (instance *Instance) Generation() uint64 {
+	return instance.generation + instance.geometry.Generation()
+}
+
+// Geometry returns the shape shared by the instances.
+func (instance *Instance) Geometry() Shape {
+	return instance.geometry
+}
+
+// SetMaterial overrides the materials of the geometry for this instance, nil restores them.
+func (instance *Instance) SetMaterial(material *Material) {
+	instance.material = material
+}
+
+// materialAt returns the material of the instance if it has one, the material of the geometry otherwise.
+func (instance *Instance) materialAt(intersection *Intersection) *Material {
+	if instance.material != nil {
+		return instance.material
+	}
+	if shape, ok := intersection.inner.(partMaterial); ok {
+		return shape.materialAt(intersection)
+	}
+	return intersection.inner.Material()
+}
+
+// uvAt returns the texture coordinates of the shape of the geometry that was hit, if it has some.
+func (instance *Instance) uvAt(intersection *Intersection) (u, v float64, ok bool) {
+	if shape, ok := intersection.inner.(uvShape); ok {
+		return shape.uvAt(intersection)
+	}
+	return 0, 0, false
+}
+
+// LocalIntersect intersects the geometry and marks the intersections as found through the instance.
+func (instance *Instance) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	start := len(xs)
+	xs = instance.geometry.Intersect(localRay, xs)
+	for i := start; i < len(xs); i++ {
+		xs[i].inner = xs[i].object
+		xs[i].object = instance
+	}
+	return xs
+}
+
+// Intersect transforms the ray into the space of the instance before intersecting the geometry.
+func (instance *Instance) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(instance, worldRay, xs)
+}
+
+// LocalNormalAt returns the normal of the shape of the geometry that was hit, in the space of the instance.
+func (instance *Instance) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	inner := intersection.inner
+	objectPoint := WorldToObject(inner, localPoint)
+	return NormalToWorld(inner, inner.LocalNormalAt(objectPoint, intersection))
+}
+
+// LocalTangentAt returns the tangent of the fiber of the geometry that was hit, in the space of the
+// instance, or a zero vector when the geometry is not shaded as a fiber.
+func (instance *Instance) LocalTangentAt(localPoint Tuple, intersection *Intersection) Tuple {
+	inner, ok := intersection.inner.(tangentShape)
+	if !ok {
+		return Vector(0, 0, 0)
+	}
+	tangent := inner.LocalTangentAt(WorldToObject(intersection.inner, localPoint), intersection)
+	if tangent.Equals(Vector(0, 0, 0)) {
+		return tangent
+	}
+	return VectorToWorld(intersection.inner, tangent)
+}
+
+// NormalAt returns the normal at a point in world space hit through the instance.
+func (instance *Instance) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(instance, worldPoint, intersection)
+}
+
+// LocalBounds returns the bounds of the geometry in the space of the instance.
+func (instance *Instance) LocalBounds() *BoundingBox {
+	return ParentSpaceBounds(instance.geometry)
+}
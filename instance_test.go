@@ -0,0 +1,107 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// Intersecting an instance reports the instance as the object and the shape of the geometry as inner.
+func TestInstanceIntersect(t *testing.T) {
+	s := NewSphere()
+	s.SetTransform(Translation(0, 0, 2))
+	g := NewGroup()
+	g.AddChild(s)
+
+	a := NewInstance(g)
+	b := NewInstance(g)
+	b.SetTransform(Translation(5, 0, 0))
+
+	r := NewRay(Point(5, 0, -5), Vector(0, 0, 1))
+	if xs := a.Intersect(r, nil); len(xs) != 0 {
+		t.Errorf("Instance intersections count: got %v, expected: %v", len(xs), 0)
+	}
+	xs := b.Intersect(r, nil)
+	if len(xs) != 2 {
+		t.Fatalf("Instance intersections count: got %v, expected: %v", len(xs), 2)
+	}
+	if xs[0].t != 6 || xs[0].object != b || xs[0].inner != s {
+		t.Errorf("Instance intersection: got %v, expected: %v", xs[0], Intersection{t: 6, object: b, inner: s})
+	}
+	if s.GetParent() != g || g.GetParent() != nil {
+		t.Errorf("Instance geometry parent: got %v, expected: %v", s.GetParent(), g)
+	}
+}
+
+// The normal of an instance matches the normal of a copy of the geometry with the same transformation.
+func TestInstanceNormal(t *testing.T) {
+	s := NewSphere()
+	s.SetTransform(Scaling(1, 0.5, 1))
+	g := NewGroup()
+	g.AddChild(s)
+	instance := NewInstance(g)
+	instance.SetTransform(RotationZ(math.Pi / 5).MultiplyMatrix(Translation(1, 2, 3)))
+
+	copySphere := NewSphere()
+	copySphere.SetTransform(Scaling(1, 0.5, 1))
+	copyGroup := NewGroup()
+	copyGroup.AddChild(copySphere)
+	copyGroup.SetTransform(instance.Transform())
+
+	r := NewRay(Point(0, 0, -10), instance.Transform().MultiplyMatrixByTuple(Point(0, 0, 0)).Substract(Point(0, 0, -10)).Normalize())
+	xs := instance.Intersect(r, nil)
+	expected := copyGroup.Intersect(r, nil)
+	if len(xs) != 2 || len(expected) != 2 {
+		t.Fatalf("Instance intersections count: got %v, expected: %v", len(xs), len(expected))
+	}
+	point := r.Position(xs[0].t)
+	got, want := instance.NormalAt(point, &xs[0]), NormalAt(copySphere, point, &expected[0])
+	if !got.Equals(want) {
+		t.Errorf("Instance normal: got %v, expected: %v", got, want)
+	}
+}
+
+// An instance uses the materials of its geometry unless it has its own.
+func TestInstanceMaterial(t *testing.T) {
+	s := NewSphere()
+	s.material.color = NewColor(1, 0, 0)
+	a := NewInstance(s)
+	b := NewInstance(s)
+	override := DefaultMaterial()
+	override.color = NewColor(0, 0, 1)
+	b.SetMaterial(override)
+
+	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
+	xs := a.Intersect(r, nil)
+	if comps := PrepareComputations(&xs[0], r, xs); comps.material != s.material {
+		t.Errorf("Instance material: got %v, expected: %v", comps.material, s.material)
+	}
+	xs = b.Intersect(r, nil)
+	if comps := PrepareComputations(&xs[0], r, xs); comps.material != override {
+		t.Errorf("Instance material override: got %v, expected: %v", comps.material, override)
+	}
+}
+
+// Two overlapping glass instances of the same sphere are entered and exited separately.
+func TestInstanceRefractiveIndices(t *testing.T) {
+	glass := GlassSphere()
+	glass.material.refractiveIndex = 1.5
+	a := NewInstance(glass)
+	a.SetTransform(Scaling(2, 2, 2))
+	b := NewInstance(glass)
+	b.SetMaterial(DefaultMaterial())
+	b.material.transparency = 1
+	b.material.refractiveIndex = 2
+
+	r := NewRay(Point(0, 0, -4), Vector(0, 0, 1))
+	xs := a.Intersect(r, nil)
+	xs = b.Intersect(r, xs)
+	xs.Sort()
+
+	expected := [][2]float64{{1, 1.5}, {1.5, 2}, {2, 1.5}, {1.5, 1}}
+	for i := range xs {
+		comps := PrepareComputations(&xs[i], r, xs)
+		if comps.n1 != expected[i][0] || comps.n2 != expected[i][1] {
+			t.Errorf("Instance refractive indices: got %v, %v, expected: %v", comps.n1, comps.n2, expected[i])
+		}
+	}
+}
@@ -3,25 +3,30 @@ package main
 import "sort"
 
 // Intersection struct.
+// When the shape was hit through an Instance, object is the instance and inner the shape of its geometry.
+// index identifies the part of the shape that was hit, such as the face of a Mesh.
 type Intersection struct {
 	t, u, v float64
 	object  Shape
+	inner   Shape
+	index   int
 }
 
-// Intersections contains a slice of Intersection pointers.
-type Intersections []*Intersection
+// Intersections contains a slice of Intersection values.
+// Shapes append their intersections to it, so the same backing array can be reused from one ray to the next.
+type Intersections []Intersection
 
-// NewIntersection returns a reference of the intersection struct.
-func NewIntersection(t float64, object Shape) *Intersection {
-	return &Intersection{
+// NewIntersection returns the intersection struct.
+func NewIntersection(t float64, object Shape) Intersection {
+	return Intersection{
 		t:      t,
 		object: object,
 	}
 }
 
 // NewIntersectionUV adds u and v Properties to the intersection struct.
-func NewIntersectionUV(t float64, s Shape, u, v float64) *Intersection {
-	return &Intersection{
+func NewIntersectionUV(t float64, s Shape, u, v float64) Intersection {
+	return Intersection{
 		t:      t,
 		object: s,
 		u:      u,
@@ -34,19 +39,85 @@ func NewIntersections(intersections Intersections) Intersections {
 	return Intersections(intersections)
 }
 
+// Sort orders the intersections by increasing t.
+// An insertion sort is used for the usual short lists since it doesn't allocate.
+func (xs Intersections) Sort() {
+	if len(xs) > 32 {
+		sort.Slice(xs, func(i, j int) bool { return xs[i].t < xs[j].t })
+		return
+	}
+	for i := 1; i < len(xs); i++ {
+		for j := i; j > 0 && xs[j].t < xs[j-1].t; j-- {
+			xs[j], xs[j-1] = xs[j-1], xs[j]
+		}
+	}
+}
+
 // Hit returns the closest object with positive intersection.
+// The returned reference points into the Intersections slice.
 func (xs Intersections) Hit() *Intersection {
 
-	sort.Slice(xs, func(i, j int) bool { return xs[i].t < xs[j].t })
+	xs.Sort()
 
-	for _, i := range xs {
-		if i.t >= 0.0 {
-			return i
+	for i := range xs {
+		if xs[i].t >= 0.0 {
+			return &xs[i]
 		}
 	}
 	return nil
 }
 
+// partMaterial is implemented by the shapes whose material depends on the part that was hit.
+type partMaterial interface {
+	materialAt(*Intersection) *Material
+}
+
+// material returns the material of the shape at the intersection.
+func (i *Intersection) material() *Material {
+	if shape, ok := i.object.(partMaterial); ok {
+		return shape.materialAt(i)
+	}
+	return i.object.Material()
+}
+
+// uvShape is implemented by the shapes carrying texture coordinates, known from the part that was hit.
+type uvShape interface {
+	uvAt(*Intersection) (u, v float64, ok bool)
+}
+
+// color returns the color of the material at the intersection. The UV patterns of surface
+// patterns are read at the texture coordinates of the shape when it has some there.
+func (i *Intersection) color(material *Material, worldPoint Tuple) Color {
+	if material.pattern != nil && material.pattern.uvPattern != nil {
+		if shape, ok := i.object.(uvShape); ok {
+			if u, v, ok := shape.uvAt(i); ok {
+				return uvPatternAt(material.pattern.uvPattern, u, v)
+			}
+		}
+	}
+	return material.colorAt(i.object, worldPoint)
+}
+
+// tangentShape is implemented by the shapes shaded as fibers, from their tangent instead of their normal.
+// LocalTangentAt returns a zero vector at the parts that are not.
+type tangentShape interface {
+	LocalTangentAt(localPoint Tuple, intersection *Intersection) Tuple
+}
+
+// tangent returns the tangent in world space of the fiber hit at the point, or a zero vector when
+// the shape is not shaded as a fiber there.
+func (i *Intersection) tangent(worldPoint Tuple) Tuple {
+	shape, ok := i.object.(tangentShape)
+	if !ok {
+		return Vector(0, 0, 0)
+	}
+	tangent := shape.LocalTangentAt(WorldToObject(i.object, worldPoint), i)
+	if tangent.Equals(Vector(0, 0, 0)) {
+		return tangent
+	}
+	return VectorToWorld(i.object, tangent)
+}
+
 // Count returns the number of the Intersections.
 func (xs *Intersections) Count() int {
 	return len(*xs)
@@ -67,12 +138,17 @@ func IntersectionAllowed(op string, lhit, inl, inr bool) bool {
 
 // FilterIntersections will produce a subset of only those intersections that
 // conform to the operation of the current CSG object.
-func FilterIntersections(csg *CSG, xs []*Intersection) []*Intersection {
+func FilterIntersections(csg *CSG, xs Intersections) Intersections {
+	// prepare a list to receive the filtered intersections
+	return filterIntersections(csg, xs, make(Intersections, 0, len(xs)))
+}
+
+// filterIntersections appends the intersections of xs allowed by the CSG operation to result.
+// result may share the backing array of xs, the subset is then compacted in place.
+func filterIntersections(csg *CSG, xs Intersections, result Intersections) Intersections {
 	// begin outside of both children
 	inl := false
 	inr := false
-	// prepare a list to receive the filtered intersections
-	result := make([]*Intersection, 0)
 
 	for i, v := range xs {
 		// if i.object is part of the "left" child, then lhit is true
@@ -96,10 +172,9 @@ func includes(left Shape, object Shape) bool {
 	switch t := left.(type) {
 	case *Group:
 		for _, child := range t.children {
-			if child.GetID() == object.GetID() {
+			if includes(child, object) {
 				return true
 			}
-			return includes(child, object)
 		}
 		return false
 	case *CSG:
@@ -107,6 +182,6 @@ func includes(left Shape, object Shape) bool {
 		b := includes(t.right, object)
 		return a || b
 	default:
-		return left.GetID() == object.GetID()
+		return left == object
 	}
 }
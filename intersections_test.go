@@ -38,10 +38,10 @@ func TestIntersections(t *testing.T) {
 		}
 	}
 
-	// localIntersect sets the object on the intersection.
+	// LocalIntersect sets the object on the intersection.
 	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
 	s = NewSphere()
-	xs = s.localIntersect(r)
+	xs = s.LocalIntersect(r, nil)
 
 	if len(xs) != 2 {
 		t.Errorf("TestIntersections: expected number of intersections to be %v but got %v", 2, len(xs))
@@ -59,40 +59,40 @@ func TestIntersections(t *testing.T) {
 func TestHit(t *testing.T) {
 	// The hit, when all intersections have positive t.
 	s := NewSphere()
-	i1 := &Intersection{t: 1, object: s}
-	i2 := &Intersection{t: 2, object: s}
-	xs := NewIntersections([]*Intersection{i1, i2})
+	i1 := Intersection{t: 1, object: s}
+	i2 := Intersection{t: 2, object: s}
+	xs := NewIntersections(Intersections{i1, i2})
 	i := xs.Hit()
-	if i != i1 {
+	if i == nil || *i != i1 {
 		t.Errorf("Hit: expected %v to be %v", i, i1)
 	}
 
 	// The hit, when some intersections have negative t.
-	i1 = &Intersection{t: -1, object: s}
-	i2 = &Intersection{t: 2, object: s}
-	xs = NewIntersections([]*Intersection{i1, i2})
+	i1 = Intersection{t: -1, object: s}
+	i2 = Intersection{t: 2, object: s}
+	xs = NewIntersections(Intersections{i1, i2})
 	i = xs.Hit()
-	if i != i2 {
+	if i == nil || *i != i2 {
 		t.Errorf("Hit: expected %v to be %v", i, i2)
 	}
 
 	// The hit, when all intersections have negative t.
-	i1 = &Intersection{t: -1, object: s}
-	i2 = &Intersection{t: -2, object: s}
-	xs = NewIntersections([]*Intersection{i1, i2})
+	i1 = Intersection{t: -1, object: s}
+	i2 = Intersection{t: -2, object: s}
+	xs = NewIntersections(Intersections{i1, i2})
 	i = xs.Hit()
 	if i != nil {
 		t.Errorf("Hit: expected %v to be %v", i, nil)
 	}
 
 	// The hit is always the lowest nonnegative intersection.
-	i1 = &Intersection{t: 5, object: s}
-	i2 = &Intersection{t: 7, object: s}
-	i3 := &Intersection{t: -3, object: s}
-	i4 := &Intersection{t: 2, object: s}
-	xs = NewIntersections([]*Intersection{i1, i2, i3, i4})
+	i1 = Intersection{t: 5, object: s}
+	i2 = Intersection{t: 7, object: s}
+	i3 := Intersection{t: -3, object: s}
+	i4 := Intersection{t: 2, object: s}
+	xs = NewIntersections(Intersections{i1, i2, i3, i4})
 	i = xs.Hit()
-	if i != i4 {
+	if i == nil || *i != i4 {
 		t.Errorf("Hit: expected %v to be %v", i, i4)
 	}
 }
@@ -105,8 +105,8 @@ func TestOverPoint(t *testing.T) {
 	shape.SetTransform(Translation(0, 0, 1))
 
 	i := NewIntersection(5, shape)
-	xs := NewIntersections([]*Intersection{i})
-	comps := PrepareComputations(i, r, xs)
+	xs := NewIntersections(Intersections{i})
+	comps := PrepareComputations(&i, r, xs)
 
 	if !(comps.underPoint.z > EPSILON/2.0 && comps.point.z < comps.underPoint.z) {
 		t.Errorf("PrepareComputationWithRefraction: underPoint %v not valid", comps.underPoint)
@@ -117,20 +117,20 @@ func TestPlaneIntersect(t *testing.T) {
 	p := NewPlane()
 	r := NewRay(Point(0, 10, 0), Vector(0, 0, 1))
 
-	xs := p.localIntersect(r)
+	xs := p.LocalIntersect(r, nil)
 
 	if len(xs) != 0 {
 		t.Errorf("PlaneIntersect(parallel): expected no intersections")
 	}
 
 	r = NewRay(Point(0, 0, 0), Vector(0, 0, 1))
-	xs = p.localIntersect(r)
+	xs = p.LocalIntersect(r, nil)
 	if len(xs) != 0 {
 		t.Errorf("PlaneIntersect(coplanar): expected no intersections")
 	}
 
 	r = NewRay(Point(0, 1, 0), Vector(0, -1, 0))
-	xs = p.localIntersect(r)
+	xs = p.LocalIntersect(r, nil)
 
 	if len(xs) != 1 {
 		t.Errorf("PlaneIntersect(above): expected one intersection")
@@ -157,7 +157,7 @@ func TestCubeIntersect(t *testing.T) {
 	}
 
 	for k, v := range expectedIntersectionMap {
-		xs := c.localIntersect(v[0].(*Ray))
+		xs := c.LocalIntersect(v[0].(Ray), nil)
 
 		if len(xs) != 2 {
 			t.Errorf("A ray intersects a cube count: %v expected to be %v", len(xs), 2)
@@ -173,7 +173,7 @@ func TestCubeRayMisses(t *testing.T) {
 	//  A ray misses a cube.
 	c := NewCube()
 
-	expectedIntersections := []*Ray{
+	expectedIntersections := []Ray{
 		NewRay(Point(-2, 0, 0), Point(0.2673, 0.5345, 0.8018)),
 		NewRay(Point(0, -2, 0), Point(0.8018, 0.2673, 0.5345)),
 		NewRay(Point(0, 0, -2), Point(0.5345, 0.8018, 0.2673)),
@@ -183,7 +183,7 @@ func TestCubeRayMisses(t *testing.T) {
 	}
 
 	for _, v := range expectedIntersections {
-		xs := c.localIntersect(v)
+		xs := c.LocalIntersect(v, nil)
 
 		if len(xs) != 0 {
 			t.Errorf("A ray misses a cube: expected Ray intersection count xs= %v to be %v", len(xs), 0)
@@ -196,14 +196,14 @@ func TestCylinderRayMisses(t *testing.T) {
 
 	c := NewCylinder()
 
-	expectedIntersections := []*Ray{
+	expectedIntersections := []Ray{
 		NewRay(Point(1, 0, 0), Point(0, 1, 0)),
 		NewRay(Point(0, 0, 0), Point(0, 1, 0)),
 		NewRay(Point(0, 0, -5), Point(1, 1, 1)),
 	}
 
 	for _, v := range expectedIntersections {
-		xs := c.localIntersect(v)
+		xs := c.LocalIntersect(v, nil)
 
 		if len(xs) != 0 {
 			t.Errorf("A ray misses a cylinder: expected Ray intersection count to be xs= %v, got %v", 0, len(xs))
@@ -215,7 +215,7 @@ func TestCylinderRayStrike(t *testing.T) {
 	// A ray strikes a cylinder.
 
 	type cylindertest struct {
-		point, direction *Tuple
+		point, direction Tuple
 		t0               float64
 		t1               float64
 	}
@@ -229,7 +229,7 @@ func TestCylinderRayStrike(t *testing.T) {
 	}
 
 	for _, v := range expectedIntersections {
-		xs := c.localIntersect(NewRay(v.point, v.direction))
+		xs := c.LocalIntersect(NewRay(v.point, v.direction), nil)
 
 		if len(xs) != 2 {
 			t.Errorf("A ray strikes a cylinder: expected Ray intersection count to be xs= %v, got %v", 2, len(xs))
@@ -241,7 +241,7 @@ func TestCylinderConstraints(t *testing.T) {
 	// Intersecting a constrained cylinder.
 
 	type cylindertest struct {
-		point, direction  *Tuple
+		point, direction  Tuple
 		intersectionCount int
 	}
 
@@ -259,7 +259,7 @@ func TestCylinderConstraints(t *testing.T) {
 	}
 
 	for _, v := range expectedIntersections {
-		xs := c.localIntersect(NewRay(v.point, v.direction))
+		xs := c.LocalIntersect(NewRay(v.point, v.direction), nil)
 
 		if len(xs) != v.intersectionCount {
 			t.Errorf("A ray strikes a cylinder: expected Ray intersection count to be xs= %v, got %v", v.intersectionCount, len(xs))
@@ -271,7 +271,7 @@ func TestCylinderCapsIntersection(t *testing.T) {
 	// Intersecting the caps of a closed cylinder
 
 	type cylindertest struct {
-		point, direction  *Tuple
+		point, direction  Tuple
 		intersectionCount int
 	}
 
@@ -289,7 +289,7 @@ func TestCylinderCapsIntersection(t *testing.T) {
 	}
 
 	for _, v := range expectedIntersections {
-		xs := c.localIntersect(NewRay(v.point, v.direction))
+		xs := c.LocalIntersect(NewRay(v.point, v.direction), nil)
 
 		if len(xs) != v.intersectionCount {
 			t.Errorf("A ray strikes a cylinder: expected Ray intersection count to be xs= %v, got %v", v.intersectionCount, len(xs))
@@ -301,7 +301,7 @@ func TestConeRayIntersect(t *testing.T) {
 	// Intersecting a cone with a ray.
 
 	type conetest struct {
-		point, direction *Tuple
+		point, direction Tuple
 		t0               float64
 		t1               float64
 	}
@@ -315,7 +315,7 @@ func TestConeRayIntersect(t *testing.T) {
 	}
 
 	for _, v := range expectedIntersections {
-		xs := c.localIntersect(NewRay(v.point, v.direction.Normalize()))
+		xs := c.LocalIntersect(NewRay(v.point, v.direction.Normalize()), nil)
 
 		if len(xs) != 2 {
 			t.Errorf("Intersecting a cone with a ray: expected Ray intersection count to be xs= %v, got %v", 2, len(xs))
@@ -331,7 +331,7 @@ func TestConeRayIntersectParallelToHalves(t *testing.T) {
 	// Intersecting a cone with a ray parallel to one of its halves.
 
 	type conetest struct {
-		point, direction *Tuple
+		point, direction Tuple
 		t0               float64
 		t1               float64
 	}
@@ -343,7 +343,7 @@ func TestConeRayIntersectParallelToHalves(t *testing.T) {
 	}
 
 	for _, v := range expectedIntersections {
-		xs := c.localIntersect(NewRay(v.point, v.direction.Normalize()))
+		xs := c.LocalIntersect(NewRay(v.point, v.direction.Normalize()), nil)
 
 		if len(xs) != 1 {
 			t.Errorf("Intersecting a cone with a ray parallel to one of its halves: expected Ray intersection count to be xs= %v, got %v", 2, len(xs))
@@ -359,7 +359,7 @@ func TestConeCapsIntersection(t *testing.T) {
 	// Intersecting a cone's end caps.
 
 	type conetest struct {
-		point, direction  *Tuple
+		point, direction  Tuple
 		intersectionCount int
 	}
 
@@ -375,7 +375,7 @@ func TestConeCapsIntersection(t *testing.T) {
 	}
 
 	for _, v := range expectedIntersections {
-		xs := c.localIntersect(NewRay(v.point, v.direction))
+		xs := c.LocalIntersect(NewRay(v.point, v.direction), nil)
 
 		if len(xs) != v.intersectionCount {
 			t.Errorf("Intersecting a cone's end caps.: expected Ray intersection count to be xs= %v, got %v", v.intersectionCount, len(xs))
@@ -388,7 +388,7 @@ func TestIntersectTriangleParallel(t *testing.T) {
 
 	triangle := NewTriangle(Point(0, 1, 0), Point(-1, 0, 0), Point(1, 0, 0))
 	ray := NewRay(Point(0, -1, -2), Point(0, 1, 0))
-	xs := triangle.localIntersect(ray)
+	xs := triangle.LocalIntersect(ray, nil)
 
 	if len(xs) != 0 {
 		t.Errorf("Intersecting a ray parallel to the triangle: got %v expected be %v,", len(xs), 0)
@@ -396,7 +396,7 @@ func TestIntersectTriangleParallel(t *testing.T) {
 
 	// A ray misses the p1-p3 edge.
 	ray = NewRay(Point(1, 1, -2), Point(0, 0, 1))
-	xs = triangle.localIntersect(ray)
+	xs = triangle.LocalIntersect(ray, nil)
 
 	if len(xs) != 0 {
 		t.Errorf("A ray misses the p1-p3 edge: got %v expected be %v,", len(xs), 0)
@@ -404,7 +404,7 @@ func TestIntersectTriangleParallel(t *testing.T) {
 
 	// A ray misses the p1-p2 edge.
 	ray = NewRay(Point(-1, 1, -2), Point(0, 0, 1))
-	xs = triangle.localIntersect(ray)
+	xs = triangle.LocalIntersect(ray, nil)
 
 	if len(xs) != 0 {
 		t.Errorf("A ray misses the p1-p2 edge: got %v expected be %v,", len(xs), 0)
@@ -412,7 +412,7 @@ func TestIntersectTriangleParallel(t *testing.T) {
 
 	// A ray misses the p2-p3 edge.
 	ray = NewRay(Point(0, -1, -2), Point(0, 0, 1))
-	xs = triangle.localIntersect(ray)
+	xs = triangle.LocalIntersect(ray, nil)
 
 	if len(xs) != 0 {
 		t.Errorf("A ray misses the p2-p3 edge: got %v expected be %v,", len(xs), 0)
@@ -420,7 +420,7 @@ func TestIntersectTriangleParallel(t *testing.T) {
 
 	// A ray strikes a triangle.
 	ray = NewRay(Point(0, 0.5, -2), Point(0, 0, 1))
-	xs = triangle.localIntersect(ray)
+	xs = triangle.LocalIntersect(ray, nil)
 
 	if !(len(xs) == 1 && xs[0].t == 2) {
 		t.Errorf("A ray strikes a triangle: got %v expected be %v,", xs[0].t, 2)
@@ -512,7 +512,7 @@ func TestFilterCSGIntersections(t *testing.T) {
 	// Filtering a list of intersections.
 	s1 := NewSphere()
 	s2 := NewCube()
-	xs := []*Intersection{
+	xs := Intersections{
 		NewIntersection(1, s1),
 		NewIntersection(2, s2),
 		NewIntersection(3, s1),
@@ -551,7 +551,7 @@ func TestRayMissesCSG(t *testing.T) {
 
 	c := NewCSG("union", NewSphere(), NewCube())
 	r := NewRay(Point(0, 2, -5), Vector(0, 0, 1))
-	xs := c.localIntersect(r)
+	xs := c.LocalIntersect(r, nil)
 	if !(len(xs) == 0) {
 		t.Errorf("A ray misses a CSG object: got %v expected be %v,", len(xs), 0)
 	}
@@ -564,7 +564,7 @@ func TestRayHitsCSG(t *testing.T) {
 	s2.SetTransform(Translation(0, 0, 0.5))
 	c := NewCSG("union", s1, s2)
 	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
-	xs := c.localIntersect(r)
+	xs := c.LocalIntersect(r, nil)
 
 	if !(len(xs) == 2) {
 		t.Errorf("A ray hits a CSG object: got %v expected be %v,", len(xs), 2)
@@ -0,0 +1,220 @@
+package main
+
+import "math"
+
+// latheCrease is the cosine of the largest angle between two segments of the profile of a Lathe
+// that is smoothed, sharper corners keep the normals of both segments.
+const latheCrease = 0.7
+
+// Lathe is the surface swept by a profile revolved around the y axis, such as a vase or a bottle.
+// The profile is a polyline of points whose x is the distance to the axis and y the height. Each
+// segment of the profile sweeps a cone, intersected analytically, or a flat ring when it is
+// horizontal. Normals are interpolated along the segments so that the profile looks smooth,
+// except at its sharp corners.
+type Lathe struct {
+	BaseShape
+	profile []Tuple
+	// normals holds the normals of the profile at the start and the end of each segment, x along
+	// the distance to the axis and y along the axis.
+	normals []Tuple
+	// lengths holds the length of the profile up to each point, for the texture coordinates.
+	lengths []float64
+	bounds  *BoundingBox
+}
+
+// NewLathe returns a *Lathe of the profile, with Identity matrix as transform and default material.
+// Profiles going up give normals pointing away from the axis.
+func NewLathe(profile []Tuple) *Lathe {
+	lathe := &Lathe{
+		BaseShape: NewBaseShape(),
+		profile:   profile,
+		normals:   make([]Tuple, 0, 2*len(profile)),
+		lengths:   make([]float64, len(profile)),
+		bounds:    NewEmptyBoundingBox(),
+	}
+
+	segmentNormal := func(i int) Tuple {
+		d := profile[i+1].Substract(profile[i])
+		return Vector(d.y, -d.x, 0).Normalize()
+	}
+	for i := 0; i+1 < len(profile); i++ {
+		n := segmentNormal(i)
+		start, end := n, n
+		if i > 0 {
+			if previous := segmentNormal(i - 1); previous.DotProduct(n) > latheCrease {
+				start = previous.Add(n).Normalize()
+			}
+		}
+		if i+2 < len(profile) {
+			if next := segmentNormal(i + 1); next.DotProduct(n) > latheCrease {
+				end = next.Add(n).Normalize()
+			}
+		}
+		lathe.normals = append(lathe.normals, start, end)
+		lathe.lengths[i+1] = lathe.lengths[i] + profile[i+1].Substract(profile[i]).Magnitude()
+	}
+
+	for _, p := range profile {
+		lathe.bounds.Add(Point(-p.x, p.y, -p.x))
+		lathe.bounds.Add(Point(p.x, p.y, p.x))
+	}
+	return lathe
+}
+
+// NewSplineLathe returns a *Lathe of the Catmull-Rom spline going through the points, each span
+// between two points sampled in steps segments.
+func NewSplineLathe(points []Tuple, steps int) *Lathe {
+	if len(points) < 2 {
+		return NewLathe(points)
+	}
+	profile := []Tuple{points[0]}
+	for i := 0; i+1 < len(points); i++ {
+		// The ends of the spline repeat the first and the last points.
+		previous, next := i-1, i+2
+		if previous < 0 {
+			previous = 0
+		}
+		if next >= len(points) {
+			next = len(points) - 1
+		}
+		p0, p1, p2, p3 := points[previous], points[i], points[i+1], points[next]
+		for step := 1; step <= steps; step++ {
+			t := float64(step) / float64(steps)
+			t2, t3 := t*t, t*t*t
+			p := p1.Multiply(2).
+				Add(p2.Substract(p0).Multiply(t)).
+				Add(p0.Multiply(2).Substract(p1.Multiply(5)).Add(p2.Multiply(4)).Substract(p3).Multiply(t2)).
+				Add(p1.Multiply(3).Substract(p0).Substract(p2.Multiply(3)).Add(p3).Multiply(t3)).
+				Multiply(0.5)
+			profile = append(profile, Point(math.Max(0, p.x), p.y, 0))
+		}
+	}
+	return NewLathe(profile)
+}
+
+// LocalIntersect calculates the intersections between a ray in object space and the lathe. The
+// segment of the profile that was hit is stored in index and the position along it in u.
+func (lathe *Lathe) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	if _, _, hit := rayBoxRange(localRay, lathe.bounds); !hit {
+		return xs
+	}
+	o, d := localRay.origin, localRay.direction
+	last := len(lathe.profile) - 2
+
+	for i := 0; i <= last; i++ {
+		p0, p1 := lathe.profile[i], lathe.profile[i+1]
+		// The end of a segment belongs to the next one, so that the rays through it hit once.
+		inside := func(s float64) bool {
+			return s >= 0 && (s < 1 || (i == last && s <= 1))
+		}
+
+		if p0.y == p1.y {
+			// A horizontal segment sweeps a flat ring.
+			if d.y == 0 || p0.x == p1.x {
+				continue
+			}
+			t := (p0.y - o.y) / d.y
+			r := math.Hypot(o.x+t*d.x, o.z+t*d.z)
+			if s := (r - p0.x) / (p1.x - p0.x); inside(s) {
+				xs = append(xs, lathe.hit(t, s, i))
+			}
+			continue
+		}
+
+		// The radius along the ray, c0 + c1*t, is the one of the cone at the height of the point.
+		k := (p1.x - p0.x) / (p1.y - p0.y)
+		c0 := p0.x + (o.y-p0.y)*k
+		c1 := d.y * k
+		a := d.x*d.x + d.z*d.z - c1*c1
+		b := 2 * (o.x*d.x + o.z*d.z - c0*c1)
+		c := o.x*o.x + o.z*o.z - c0*c0
+
+		var roots [2]float64
+		count := 0
+		if math.Abs(a) < EPSILON*EPSILON {
+			if b != 0 {
+				roots[0] = -c / b
+				count = 1
+			}
+		} else if discriminant := b*b - 4*a*c; discriminant >= 0 {
+			root := math.Sqrt(discriminant)
+			roots[0], roots[1] = (-b-root)/(2*a), (-b+root)/(2*a)
+			count = 2
+		}
+		for _, t := range roots[:count] {
+			// The other nappe of the cone, with a negative radius, is not part of the lathe.
+			if c0+c1*t < 0 {
+				continue
+			}
+			if s := (o.y + t*d.y - p0.y) / (p1.y - p0.y); inside(s) {
+				xs = append(xs, lathe.hit(t, s, i))
+			}
+		}
+	}
+	return xs
+}
+
+// hit returns the intersection at t with a segment of the profile, at the position s along it.
+func (lathe *Lathe) hit(t, s float64, segment int) Intersection {
+	intersection := NewIntersectionUV(t, lathe, s, 0)
+	intersection.index = segment
+	return intersection
+}
+
+// Intersect calculates the intersections between a ray in world space and the lathe.
+func (lathe *Lathe) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(lathe, worldRay, xs)
+}
+
+// LocalNormalAt interpolates the normals of the profile along the segment that was hit and turns
+// the result around the axis to the point.
+func (lathe *Lathe) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	segment, s := intersection.index, intersection.u
+	n := lathe.normals[2*segment].Multiply(1 - s).Add(lathe.normals[2*segment+1].Multiply(s))
+	r := math.Hypot(localPoint.x, localPoint.z)
+	if r < EPSILON*EPSILON {
+		return Vector(0, sign(n.y), 0)
+	}
+	return Vector(n.x*localPoint.x/r, n.y, n.x*localPoint.z/r).Normalize()
+}
+
+// NormalAt calculates the normal at a given point in world space.
+func (lathe *Lathe) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(lathe, worldPoint, intersection)
+}
+
+// LocalBounds returns the box around the profile revolved around the y axis.
+func (lathe *Lathe) LocalBounds() *BoundingBox {
+	return lathe.bounds
+}
+
+// UVAt maps a point of the lathe to (u, v), u going around the axis like the cylindrical map and v
+// along the profile, from 0 at its first point to 1 at its last, at the closest point of the profile.
+// Profiles of less than two points have no length and map every point to v = 0.
+func (lathe *Lathe) UVAt(point Tuple) (u, v float64) {
+	u, _ = cylindricalMap(point)
+	if len(lathe.profile) < 2 {
+		return u, 0
+	}
+	r := math.Hypot(point.x, point.z)
+
+	closest := math.Inf(1)
+	length := 0.0
+	for i := 0; i+1 < len(lathe.profile); i++ {
+		p0, p1 := lathe.profile[i], lathe.profile[i+1]
+		d := p1.Substract(p0)
+		if d.DotProduct(d) == 0 {
+			continue
+		}
+		s := ((r-p0.x)*d.x + (point.y-p0.y)*d.y) / d.DotProduct(d)
+		s = math.Max(0, math.Min(1, s))
+		if distance := math.Hypot(p0.x+s*d.x-r, p0.y+s*d.y-point.y); distance < closest {
+			closest = distance
+			length = lathe.lengths[i] + s*(lathe.lengths[i+1]-lathe.lengths[i])
+		}
+	}
+	if total := lathe.lengths[len(lathe.lengths)-1]; total > 0 {
+		v = length / total
+	}
+	return u, v
+}
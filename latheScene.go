@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// latheWorld renders a vase turned from a spline profile, checkered along its profile, next to a
+// star extruded along a curve.
+func latheWorld() *Canvas {
+	start := time.Now()
+	lights := []*PointLight{
+		NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1)),
+	}
+	floor := NewPlane()
+	floor.material.pattern = CheckersPattern(NewColor(0.6, 0.6, 0.6), NewColor(0.1, 0.1, 0.1))
+	floor.material.reflective = 0.2
+
+	vase := NewLathe(append([]Tuple{Point(0, 0, 0)}, NewSplineLathe([]Tuple{
+		Point(0.6, 0, 0), Point(1, 0.8, 0), Point(0.9, 1.6, 0), Point(0.35, 2.3, 0), Point(0.5, 2.8, 0),
+	}, 8).profile...))
+	vase.SetTransform(Translation(-1.5, 0, 0))
+	checkers := textureMap(uvCheckers(16, 8, NewColor(0.8, 0.5, 0.2), NewColor(0.2, 0.3, 0.6)), vase.UVAt)
+	vase.material.pattern = NewPattern(nil, [][]Color{{}}, func(_ *Canvas, _ []Color, p Tuple) Color {
+		return patternAt(checkers, p)
+	})
+	vase.material.specular = 0.6
+
+	star := []Tuple{}
+	for i := 0; i < 10; i++ {
+		r := 0.4
+		if i%2 == 1 {
+			r = 0.18
+		}
+		angle := float64(i) * PI / 5
+		star = append(star, Point(r*math.Cos(angle), 0, r*math.Sin(angle)))
+	}
+	sweep := NewExtrusion(star, BezierPath([4]Tuple{Point(0, 0.4, -1), Point(0, 3, -1), Point(2, 3, 1), Point(3, 0.4, 0.5)}, 32))
+	sweep.SetTransform(Translation(0.5, 0, 0))
+	sweep.SetMaterial(NewMaterial(NewColor(0.3, 0.7, 0.3), 0.1, 0.7, 0.6, 100, 0.1, 0, 1, nil))
+
+	world := NewWorld(lights, []Shape{floor, vase, sweep})
+
+	camera := NewCamera(600, 300, PI/3)
+	camera.SetTransform(ViewTransform(Point(0, 3, -7), Point(0.3, 1.2, 0), Vector(0, 1, 0)))
+
+	canvas := camera.RenderWithThreadPool(world, defaultRecursionDepth)
+
+	fmt.Println("Render time: ", time.Now().Sub(start))
+
+	return canvas
+}
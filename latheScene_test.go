@@ -0,0 +1,18 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLatheWorld(t *testing.T) {
+
+	canvas := latheWorld()
+
+	file, err := os.Create("latheWorld.ppm")
+	if err != nil {
+		t.Errorf("Error: %v", err)
+	}
+	file.WriteString(canvas.ToPPM())
+	file.Close()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Lathes of straight profiles are intersected like the cylinder and the cone they sweep.
+func TestLatheMatchesQuadrics(t *testing.T) {
+	cylinder := NewCylinder()
+	cylinder.minimum, cylinder.maximum = 0, 2
+	cone := NewCone()
+	cone.minimum, cone.maximum = 0, 1
+
+	tests := []struct {
+		lathe    *Lathe
+		expected Shape
+	}{
+		{NewLathe([]Tuple{Point(1, 0, 0), Point(1, 2, 0)}), cylinder},
+		{NewLathe([]Tuple{Point(0, 0, 0), Point(1, 1, 0)}), cone},
+	}
+	rng := rand.New(rand.NewSource(2))
+	for _, test := range tests {
+		for n := 0; n < 200; n++ {
+			origin := Point(rng.Float64()*8-4, rng.Float64()*8-4, rng.Float64()*8-4)
+			target := Point(rng.Float64()*2-1, rng.Float64()*2, rng.Float64()*2-1)
+			r := NewRay(origin, target.Substract(origin).Normalize())
+
+			xs, expected := test.lathe.Intersect(r, nil), test.expected.Intersect(r, nil)
+			xs.Sort()
+			expected.Sort()
+			if len(xs) != len(expected) {
+				t.Errorf("Lathe intersections count: got %v, expected: %v", len(xs), len(expected))
+				continue
+			}
+			for i := range xs {
+				if !floatEqual(xs[i].t, expected[i].t) {
+					t.Errorf("Lathe intersection: got %v, expected: %v", xs[i].t, expected[i].t)
+				}
+				point := r.Position(xs[i].t)
+				if n, want := test.lathe.NormalAt(point, &xs[i]), test.expected.NormalAt(point, &expected[i]); !n.Equals(want) {
+					t.Errorf("Lathe normal at %v: got %v, expected: %v", point, n, want)
+				}
+			}
+		}
+	}
+}
+
+// A vase has a flat bottom, smooth sides and texture coordinates going up its profile.
+func TestLatheVase(t *testing.T) {
+	// The bottom is flat and the sides are a spline going through the points of the profile.
+	sides := NewSplineLathe([]Tuple{Point(1, 0, 0), Point(1.2, 1, 0), Point(0.5, 2, 0), Point(0.7, 3, 0)}, 8)
+	vase := NewLathe(append([]Tuple{Point(0, 0, 0)}, sides.profile...))
+
+	r := NewRay(Point(0.3, -5, 0.2), Vector(0, 1, 0))
+	xs := vase.Intersect(r, nil)
+	if len(xs) != 1 || !floatEqual(xs[0].t, 5) {
+		t.Fatalf("Vase bottom intersections: got %v, expected one at: %v", xs, 5)
+	}
+	if n := vase.NormalAt(r.Position(5), &xs[0]); !n.Equals(Vector(0, -1, 0)) {
+		t.Errorf("Vase bottom normal: got %v, expected: %v", n, Vector(0, -1, 0))
+	}
+
+	// At the middle height the radius is 0.5.
+	r = NewRay(Point(-5, 2, 0), Vector(1, 0, 0))
+	xs = vase.Intersect(r, nil)
+	if len(xs) != 2 || !floatEqual(xs[0].t, 4.5) || !floatEqual(xs[1].t, 5.5) {
+		t.Fatalf("Vase neck intersections: got %v, expected: %v and %v", xs, 4.5, 5.5)
+	}
+	// The neck narrows going up, its normal points away from the axis and a little up.
+	if n := vase.NormalAt(r.Position(4.5), &xs[0]); n.y <= 0 || n.x > -0.9 || !floatEqual(n.z, 0) {
+		t.Errorf("Vase neck normal: got %v, expected about: %v", n, Vector(-1, 0, 0))
+	}
+
+	if box := vase.LocalBounds(); !floatEqual(box.min.y, 0) || !floatEqual(box.max.y, 3) || box.max.x < 1.2 {
+		t.Errorf("Vase bounds: got %v", box)
+	}
+
+	u, v := vase.UVAt(Point(0, 0, 0))
+	if !floatEqual(v, 0) {
+		t.Errorf("Vase v at the bottom: got %v, expected: %v", v, 0)
+	}
+	u, v = vase.UVAt(Point(0, 3, 0.7))
+	if !floatEqual(u, 0.5) || !floatEqual(v, 1) {
+		t.Errorf("Vase (u, v) at the top: got (%v, %v), expected: (%v, %v)", u, v, 0.5, 1)
+	}
+}
+
+// Lathes of less than two points have no surface and map every point to v = 0.
+func TestLatheDegenerateProfile(t *testing.T) {
+	for _, lathe := range []*Lathe{NewSplineLathe(nil, 4), NewLathe([]Tuple{Point(1, 0, 0)})} {
+		if xs := lathe.Intersect(NewRay(Point(0, 0, -5), Vector(0, 0, 1)), nil); len(xs) != 0 {
+			t.Errorf("Degenerate lathe intersections: got %v, expected none", xs)
+		}
+		if _, v := lathe.UVAt(Point(0, 0, 0)); v != 0 {
+			t.Errorf("Degenerate lathe v: got %v, expected: %v", v, 0)
+		}
+	}
+}
@@ -4,15 +4,15 @@ import "math"
 
 // Material encapsulates the given attributes of the Phong reflection model.
 type Material struct {
-	color                                                                            *Color
+	color                                                                            Color
 	ambient, diffuse, specular, shininess, reflective, transparency, refractiveIndex float64
 	pattern                                                                          *Pattern
 }
 
 // PointLight is a light source with no size, existing at a single point in space.
 type PointLight struct {
-	position  *Tuple
-	intensity *Color
+	position  Tuple
+	intensity Color
 }
 
 // DefaultMaterial returns a reference to material with default values.
@@ -21,24 +21,30 @@ func DefaultMaterial() *Material {
 }
 
 // NewMaterial creates a new Materials
-func NewMaterial(color *Color, ambient, diffuse, specular, shininess, reflective, transparency, refractiveIndex float64, pattern *Pattern) *Material {
+func NewMaterial(color Color, ambient, diffuse, specular, shininess, reflective, transparency, refractiveIndex float64, pattern *Pattern) *Material {
 	return &Material{color, ambient, diffuse, specular, shininess, reflective, transparency, refractiveIndex, pattern}
 }
 
 // NewPointLight returns a reference to PointLight.
-func NewPointLight(position *Tuple, intensity *Color) *PointLight {
+func NewPointLight(position Tuple, intensity Color) *PointLight {
 	return &PointLight{position, intensity}
 }
 
-// Lighting computes the color resulting from diferent parameters at a specific point of the object.
-func Lighting(material *Material, object Shape, light *PointLight, point, eyev, normalv *Tuple, inShadow bool) *Color {
-
-	var color *Color
+// colorAt returns the color of the material at a point in world space of the object.
+func (material *Material) colorAt(object Shape, worldPoint Tuple) Color {
 	if material.pattern != nil {
-		color = material.pattern.ColorAtObject(object, point)
-	} else {
-		color = material.color
+		return material.pattern.ColorAtObject(object, worldPoint)
 	}
+	return material.color
+}
+
+// Lighting computes the color resulting from diferent parameters at a specific point of the object.
+func Lighting(material *Material, object Shape, light *PointLight, point, eyev, normalv Tuple, inShadow bool) Color {
+	return lighting(material, material.colorAt(object, point), light, point, eyev, normalv, inShadow)
+}
+
+// lighting implements Lighting from the color of the material at the point.
+func lighting(material *Material, color Color, light *PointLight, point, eyev, normalv Tuple, inShadow bool) Color {
 
 	effectiveColor := color.Multiply(light.intensity)
 
@@ -69,3 +75,37 @@ func Lighting(material *Material, object Shape, light *PointLight, point, eyev,
 	return ambient.Add(diffuse).Add(specular)
 
 }
+
+// HairLighting computes the color of a fiber, such as a hair, at a point with the Kajiya-Kay model.
+// A fiber is a thin cylinder along tangentv: its diffuse term follows the sine of the angle between
+// the light and the fiber, and its highlight is the cone of directions that reflect the light,
+// whose angle with the fiber matches the one of the light.
+func HairLighting(material *Material, object Shape, light *PointLight, point, eyev, tangentv Tuple, inShadow bool) Color {
+	return hairLighting(material, material.colorAt(object, point), light, point, eyev, tangentv, inShadow)
+}
+
+// hairLighting implements HairLighting from the color of the material at the point.
+func hairLighting(material *Material, color Color, light *PointLight, point, eyev, tangentv Tuple, inShadow bool) Color {
+
+	effectiveColor := color.Multiply(light.intensity)
+	ambient := effectiveColor.MultiplyByScalar(material.ambient)
+	if inShadow {
+		return ambient
+	}
+
+	lightv := light.position.Substract(point).Normalize()
+	lightDotTangent := lightv.DotProduct(tangentv)
+	eyeDotTangent := eyev.DotProduct(tangentv)
+	sinLight := math.Sqrt(math.Max(0, 1-square(lightDotTangent)))
+	sinEye := math.Sqrt(math.Max(0, 1-square(eyeDotTangent)))
+
+	diffuse := effectiveColor.MultiplyByScalar(material.diffuse).MultiplyByScalar(sinLight)
+
+	specular := Black
+	if cosCone := sinLight*sinEye - lightDotTangent*eyeDotTangent; cosCone > 0 {
+		factor := math.Pow(cosCone, material.shininess)
+		specular = light.intensity.MultiplyByScalar(material.specular).MultiplyByScalar(factor)
+	}
+
+	return ambient.Add(diffuse).Add(specular)
+}
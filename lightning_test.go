@@ -66,3 +66,32 @@ func TestLighting(t *testing.T) {
 		t.Errorf("Lighting: (in shadow) expected %v to be %v", result, expected)
 	}
 }
+
+// Fibers are lit from the angles between the fiber and the light and eye vectors.
+func TestHairLighting(t *testing.T) {
+	material := DefaultMaterial()
+	position := Point(0, 0, 0)
+	tangentv := Vector(1, 0, 0)
+	eyev := Vector(0, 0, -1)
+
+	tests := []struct {
+		light    Tuple
+		inShadow bool
+		expected Color
+	}{
+		// Light across the fiber, with the eye in the cone of reflection.
+		{Point(0, 0, -10), false, NewColor(1.9, 1.9, 1.9)},
+		// Light along the fiber.
+		{Point(10, 0, 0), false, NewColor(0.1, 0.1, 0.1)},
+		// Light 45° away from the fiber: the eye is 45° from the cone of reflection and the highlight tiny.
+		{Point(10, 0, -10), false, NewColor(0.7364, 0.7364, 0.7364)},
+		{Point(0, 0, -10), true, NewColor(0.1, 0.1, 0.1)},
+	}
+	for _, test := range tests {
+		light := NewPointLight(test.light, NewColor(1, 1, 1))
+		result := HairLighting(material, NewSphere(), light, position, eyev, tangentv, test.inShadow)
+		if !result.Equals(test.expected) {
+			t.Errorf("HairLighting with light at %v: got %v, expected: %v", test.light, result, test.expected)
+		}
+	}
+}
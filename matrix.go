@@ -3,14 +3,6 @@ package main
 // Matrix is a new type defined by a double slice of float64.
 type Matrix [][]float64
 
-// IdentityMatrix holds a copy if the Identity Matrix.
-var IdentityMatrix Matrix
-
-func init() {
-
-	IdentityMatrix = NewIdentityMatrix()
-}
-
 // NewMatrix creates a rows x cols matrix
 func NewMatrix(rows, columns int) Matrix {
 	matrix := make([][]float64, rows, rows)
@@ -92,9 +84,9 @@ func (matrix Matrix) Size() (int, int) {
 }
 
 // MultiplyMatrixByTuple returns the multiplication of a Matrix by a Tuple.
-func (matrix Matrix) MultiplyMatrixByTuple(tuple *Tuple) *Tuple {
+func (matrix Matrix) MultiplyMatrixByTuple(tuple Tuple) Tuple {
 	tupleAsMatrix := []float64{tuple.x, tuple.y, tuple.z, tuple.w}
-	newTup := &Tuple{
+	newTup := Tuple{
 		dotProducOfMatricesRowColumn(matrix.Row(0), tupleAsMatrix),
 		dotProducOfMatricesRowColumn(matrix.Row(1), tupleAsMatrix),
 		dotProducOfMatricesRowColumn(matrix.Row(2), tupleAsMatrix),
@@ -104,18 +96,6 @@ func (matrix Matrix) MultiplyMatrixByTuple(tuple *Tuple) *Tuple {
 	return newTup
 }
 
-// IdentityMatrix returns a copy of that matrix.
-func NewIdentityMatrix() Matrix {
-	return Matrix(
-		[][]float64{
-			[]float64{1, 0, 0, 0},
-			[]float64{0, 1, 0, 0},
-			[]float64{0, 0, 1, 0},
-			[]float64{0, 0, 0, 1},
-		},
-	)
-}
-
 // Transpose returns a copy of the transposed matrix.
 func (matrix Matrix) Transpose() Matrix {
 	height, width := matrix.Size()
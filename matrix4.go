@@ -0,0 +1,221 @@
+package main
+
+// Matrix4 is a fixed size 4x4 matrix stored by value, used for every transformation in the scene.
+// The generic Matrix remains available for matrices of arbitrary sizes.
+type Matrix4 [4][4]float64
+
+// IdentityMatrix holds a copy of the 4x4 Identity Matrix.
+var IdentityMatrix = NewIdentityMatrix()
+
+// NewIdentityMatrix returns a copy of the 4x4 Identity Matrix.
+func NewIdentityMatrix() Matrix4 {
+	return Matrix4{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// NewMatrix4 converts a generic 4x4 Matrix into a Matrix4.
+func NewMatrix4(matrix Matrix) Matrix4 {
+	m := Matrix4{}
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			m[row][col] = matrix[row][col]
+		}
+	}
+	return m
+}
+
+// Matrix converts the Matrix4 into a generic Matrix.
+func (m Matrix4) Matrix() Matrix {
+	matrix := NewMatrix(4, 4)
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			matrix[row][col] = m[row][col]
+		}
+	}
+	return matrix
+}
+
+// Set a specific value in the matrix.
+func (m *Matrix4) Set(row, column int, val float64) float64 {
+	m[row][column] = val
+	return val
+}
+
+// Get returns a specific value from the matrix.
+func (m Matrix4) Get(row, column int) float64 {
+	return m[row][column]
+}
+
+// Equals will compare each value between 2 matrices.
+func (m Matrix4) Equals(other Matrix4) bool {
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			if !floatEqual(m[row][col], other[row][col]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MultiplyMatrix returns the multiplication of two 4x4 matrices.
+func (m Matrix4) MultiplyMatrix(other Matrix4) Matrix4 {
+	result := Matrix4{}
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			result[row][col] = m[row][0]*other[0][col] +
+				m[row][1]*other[1][col] +
+				m[row][2]*other[2][col] +
+				m[row][3]*other[3][col]
+		}
+	}
+	return result
+}
+
+// MultiplyMatrixByTuple returns the multiplication of the matrix by a Tuple.
+func (m Matrix4) MultiplyMatrixByTuple(tuple Tuple) Tuple {
+	return Tuple{
+		m[0][0]*tuple.x + m[0][1]*tuple.y + m[0][2]*tuple.z + m[0][3]*tuple.w,
+		m[1][0]*tuple.x + m[1][1]*tuple.y + m[1][2]*tuple.z + m[1][3]*tuple.w,
+		m[2][0]*tuple.x + m[2][1]*tuple.y + m[2][2]*tuple.z + m[2][3]*tuple.w,
+		m[3][0]*tuple.x + m[3][1]*tuple.y + m[3][2]*tuple.z + m[3][3]*tuple.w,
+	}
+}
+
+// Transpose returns a copy of the transposed matrix.
+func (m Matrix4) Transpose() Matrix4 {
+	result := Matrix4{}
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			result[col][row] = m[row][col]
+		}
+	}
+	return result
+}
+
+// IsAffine returns true when the bottom row of the matrix is (0, 0, 0, 1), which is the case
+// for any combination of translations, rotations, scalings and shearings.
+func (m Matrix4) IsAffine() bool {
+	return m[3][0] == 0 && m[3][1] == 0 && m[3][2] == 0 && m[3][3] == 1
+}
+
+// Determinant calculates the determinant of the matrix.
+func (m Matrix4) Determinant() float64 {
+	if m.IsAffine() {
+		return m.determinant3()
+	}
+
+	s0, s1, s2, s3, s4, s5, c0, c1, c2, c3, c4, c5 := m.subDeterminants()
+	return s0*c5 - s1*c4 + s2*c3 + s3*c2 - s4*c1 + s5*c0
+}
+
+// Invertible returns true when the matrix has an inverse.
+func (m Matrix4) Invertible() bool {
+	return m.Determinant() != 0
+}
+
+// Inverse returns a copy of the inverse matrix, computed in closed form.
+// Affine matrices take a faster path by inverting only the upper 3x3 part and the translation.
+// A non invertible matrix returns a zero Matrix4.
+func (m Matrix4) Inverse() Matrix4 {
+	if m.IsAffine() {
+		return m.inverseAffine()
+	}
+
+	s0, s1, s2, s3, s4, s5, c0, c1, c2, c3, c4, c5 := m.subDeterminants()
+	det := s0*c5 - s1*c4 + s2*c3 + s3*c2 - s4*c1 + s5*c0
+	if det == 0 {
+		return Matrix4{}
+	}
+	inv := 1 / det
+
+	return Matrix4{
+		{
+			(m[1][1]*c5 - m[1][2]*c4 + m[1][3]*c3) * inv,
+			(-m[0][1]*c5 + m[0][2]*c4 - m[0][3]*c3) * inv,
+			(m[3][1]*s5 - m[3][2]*s4 + m[3][3]*s3) * inv,
+			(-m[2][1]*s5 + m[2][2]*s4 - m[2][3]*s3) * inv,
+		},
+		{
+			(-m[1][0]*c5 + m[1][2]*c2 - m[1][3]*c1) * inv,
+			(m[0][0]*c5 - m[0][2]*c2 + m[0][3]*c1) * inv,
+			(-m[3][0]*s5 + m[3][2]*s2 - m[3][3]*s1) * inv,
+			(m[2][0]*s5 - m[2][2]*s2 + m[2][3]*s1) * inv,
+		},
+		{
+			(m[1][0]*c4 - m[1][1]*c2 + m[1][3]*c0) * inv,
+			(-m[0][0]*c4 + m[0][1]*c2 - m[0][3]*c0) * inv,
+			(m[3][0]*s4 - m[3][1]*s2 + m[3][3]*s0) * inv,
+			(-m[2][0]*s4 + m[2][1]*s2 - m[2][3]*s0) * inv,
+		},
+		{
+			(-m[1][0]*c3 + m[1][1]*c1 - m[1][2]*c0) * inv,
+			(m[0][0]*c3 - m[0][1]*c1 + m[0][2]*c0) * inv,
+			(-m[3][0]*s3 + m[3][1]*s1 - m[3][2]*s0) * inv,
+			(m[2][0]*s3 - m[2][1]*s1 + m[2][2]*s0) * inv,
+		},
+	}
+}
+
+// subDeterminants returns the 2x2 determinants of the two upper rows (s) and
+// of the two lower rows (c) used by the closed form determinant and inverse.
+func (m Matrix4) subDeterminants() (s0, s1, s2, s3, s4, s5, c0, c1, c2, c3, c4, c5 float64) {
+	s0 = m[0][0]*m[1][1] - m[1][0]*m[0][1]
+	s1 = m[0][0]*m[1][2] - m[1][0]*m[0][2]
+	s2 = m[0][0]*m[1][3] - m[1][0]*m[0][3]
+	s3 = m[0][1]*m[1][2] - m[1][1]*m[0][2]
+	s4 = m[0][1]*m[1][3] - m[1][1]*m[0][3]
+	s5 = m[0][2]*m[1][3] - m[1][2]*m[0][3]
+
+	c5 = m[2][2]*m[3][3] - m[3][2]*m[2][3]
+	c4 = m[2][1]*m[3][3] - m[3][1]*m[2][3]
+	c3 = m[2][1]*m[3][2] - m[3][1]*m[2][2]
+	c2 = m[2][0]*m[3][3] - m[3][0]*m[2][3]
+	c1 = m[2][0]*m[3][2] - m[3][0]*m[2][2]
+	c0 = m[2][0]*m[3][1] - m[3][0]*m[2][1]
+	return
+}
+
+// determinant3 calculates the determinant of the upper 3x3 part of the matrix.
+func (m Matrix4) determinant3() float64 {
+	return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+}
+
+// inverseAffine inverts the upper 3x3 part of an affine matrix and
+// applies the inverted part to the negated translation.
+func (m Matrix4) inverseAffine() Matrix4 {
+	c00 := m[1][1]*m[2][2] - m[1][2]*m[2][1]
+	c10 := m[1][2]*m[2][0] - m[1][0]*m[2][2]
+	c20 := m[1][0]*m[2][1] - m[1][1]*m[2][0]
+
+	det := m[0][0]*c00 + m[0][1]*c10 + m[0][2]*c20
+	if det == 0 {
+		return Matrix4{}
+	}
+	inv := 1 / det
+
+	a00 := c00 * inv
+	a01 := (m[0][2]*m[2][1] - m[0][1]*m[2][2]) * inv
+	a02 := (m[0][1]*m[1][2] - m[0][2]*m[1][1]) * inv
+	a10 := c10 * inv
+	a11 := (m[0][0]*m[2][2] - m[0][2]*m[2][0]) * inv
+	a12 := (m[0][2]*m[1][0] - m[0][0]*m[1][2]) * inv
+	a20 := c20 * inv
+	a21 := (m[0][1]*m[2][0] - m[0][0]*m[2][1]) * inv
+	a22 := (m[0][0]*m[1][1] - m[0][1]*m[1][0]) * inv
+
+	tx, ty, tz := m[0][3], m[1][3], m[2][3]
+
+	return Matrix4{
+		{a00, a01, a02, -(a00*tx + a01*ty + a02*tz)},
+		{a10, a11, a12, -(a10*tx + a11*ty + a12*tz)},
+		{a20, a21, a22, -(a20*tx + a21*ty + a22*tz)},
+		{0, 0, 0, 1},
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestMatrix4InverseMatchesGenericInverse(t *testing.T) {
+	// The closed form inverse gives the same result as the cofactor inverse.
+	tests := []Matrix4{
+		{
+			{8, -5, 9, 2},
+			{7, 5, 6, 1},
+			{-6, 0, 9, 6},
+			{-3, 0, -9, -4},
+		},
+		{
+			{9, 3, 0, 9},
+			{-5, -2, -6, -3},
+			{-4, 9, 6, 4},
+			{-7, 6, 6, 2},
+		},
+		{
+			{-5, 2, 6, -8},
+			{1, -5, 1, 8},
+			{7, 7, -6, -7},
+			{1, -3, 7, 4},
+		},
+	}
+
+	for _, m := range tests {
+		expected := m.Matrix().Inverse()
+		result := m.Inverse().Matrix()
+		if !result.Equals(expected) {
+			t.Errorf("Matrix4 inverse: got %v, expected: %v", result, expected)
+		}
+		if !floatEqual(m.Determinant(), m.Matrix().Determinant()) {
+			t.Errorf("Matrix4 determinant: got %v, expected: %v", m.Determinant(), m.Matrix().Determinant())
+		}
+	}
+}
+
+func TestMatrix4AffineInverse(t *testing.T) {
+	// Inverting a chain of transformations takes the affine path and matches the generic inverse.
+	m := Translation(1, -2, 3).
+		MultiplyMatrix(RotationX(PI / 3)).
+		MultiplyMatrix(Scaling(2, 0.5, 4)).
+		MultiplyMatrix(Shearing(1, 0, 0, 0.5, 0, 0))
+
+	if !m.IsAffine() {
+		t.Errorf("Matrix4 affine: expected %v to be affine", m)
+	}
+
+	expected := m.Matrix().Inverse()
+	result := m.Inverse()
+	if !result.Matrix().Equals(expected) {
+		t.Errorf("Matrix4 affine inverse: got %v, expected: %v", result, expected)
+	}
+	if !m.MultiplyMatrix(result).Equals(IdentityMatrix) {
+		t.Errorf("Matrix4 affine inverse: M * inverse(M) should be the identity, got %v", m.MultiplyMatrix(result))
+	}
+}
+
+func TestMatrix4NotInvertible(t *testing.T) {
+	// A non invertible matrix.
+	m := Matrix4{
+		{-4, 2, -2, -3},
+		{9, 6, 2, 6},
+		{0, -5, 1, -5},
+		{0, 0, 0, 0},
+	}
+
+	if m.Invertible() {
+		t.Errorf("Matrix4 not invertible: expected %v not to be invertible", m)
+	}
+	if !(m.Inverse() == Matrix4{}) {
+		t.Errorf("Matrix4 not invertible: got %v, expected: %v", m.Inverse(), Matrix4{})
+	}
+}
+
+func TestMatrix4Conversion(t *testing.T) {
+	// Converting between Matrix4 and the generic Matrix.
+	m := RotationZ(PI / 5).MultiplyMatrix(Translation(3, 4, 5))
+
+	if !NewMatrix4(m.Matrix()).Equals(m) {
+		t.Errorf("Matrix4 conversion: got %v, expected: %v", NewMatrix4(m.Matrix()), m)
+	}
+	if !m.Matrix().MultiplyMatrix(Scaling(1, 2, 3).Matrix()).Equals(m.MultiplyMatrix(Scaling(1, 2, 3)).Matrix()) {
+		t.Errorf("Matrix4 conversion: products of both types should match")
+	}
+}
+
+var benchmarkMatrix4 = Translation(1, -2, 3).
+	MultiplyMatrix(RotationY(PI / 3)).
+	MultiplyMatrix(Scaling(2, 0.5, 4))
+
+var benchmarkMatrix4Result Matrix4
+var benchmarkMatrixResult Matrix
+
+func BenchmarkMatrixInverse(b *testing.B) {
+	m := benchmarkMatrix4.Matrix()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkMatrixResult = m.Inverse()
+	}
+}
+
+func BenchmarkMatrix4Inverse(b *testing.B) {
+	m := benchmarkMatrix4
+	// Force the general path by breaking the affine bottom row.
+	m[3][3] = 2
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkMatrix4Result = m.Inverse()
+	}
+}
+
+func BenchmarkMatrix4InverseAffine(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkMatrix4Result = benchmarkMatrix4.Inverse()
+	}
+}
+
+func BenchmarkMatrixMultiply(b *testing.B) {
+	m := benchmarkMatrix4.Matrix()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkMatrixResult = m.MultiplyMatrix(m)
+	}
+}
+
+func BenchmarkMatrix4Multiply(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkMatrix4Result = benchmarkMatrix4.MultiplyMatrix(benchmarkMatrix4)
+	}
+}
@@ -184,9 +184,9 @@ func TestMatrixMultiplyByTuple(t *testing.T) {
 			[]float64{0, 0, 0, 1},
 		},
 	)
-	tuple := &Tuple{1, 2, 3, 1}
+	tuple := Tuple{1, 2, 3, 1}
 
-	expected := &Tuple{18, 24, 33, 1}
+	expected := Tuple{18, 24, 33, 1}
 
 	result := m.MultiplyMatrixByTuple(tuple)
 
@@ -205,14 +205,14 @@ func TestMultiplyMatrixByIdentityMatrix(t *testing.T) {
 		},
 	)
 
-	if !m.Equals(m.MultiplyMatrix(IdentityMatrix)) {
+	if !m.Equals(m.MultiplyMatrix(IdentityMatrix.Matrix())) {
 		t.Errorf("IdentityMatrix invalid.")
 	}
 
 }
 
 func TestMultiplyIdentityMatrixByTuple(t *testing.T) {
-	tuple := &Tuple{1, 2, 3, 1}
+	tuple := Tuple{1, 2, 3, 1}
 
 	if !tuple.Equals(IdentityMatrix.MultiplyMatrixByTuple(tuple)) {
 		t.Errorf("IdentityMatrix invalid.")
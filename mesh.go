@@ -0,0 +1,240 @@
+package main
+
+// Mesh is a triangle mesh stored as shared vertex, normal and texture coordinate arrays indexed by
+// the faces, three indices per face. It is intersected through its own bounding volume hierarchy
+// over the faces, so a whole model is a single shape instead of a group holding a shape per triangle.
+//
+// Normals and texture coordinates are optional: the index buffers are either empty or hold three
+// indices per face, -1 marking a face vertex without one. Faces without normals are flat shaded.
+// Each face has a material ID selecting one of the materials of the mesh, nil materials falling
+// back to the material of the mesh.
+type Mesh struct {
+	BaseShape
+	vertices      []Tuple
+	normals       []Tuple
+	uvs           []Tuple
+	vertexIndices []int
+	normalIndices []int
+	uvIndices     []int
+	materialIDs   []int
+	materials     []*Material
+	materialNames []string
+	// nodes is the BVH over the faces, its leaves referencing faces in faceOrder.
+	nodes     []flatBVHNode
+	faceOrder []int
+}
+
+// NewMesh returns a *Mesh of the faces indexing the vertices, normals and texture coordinates.
+// Texture coordinates are stored as tuples with u in x and v in y.
+func NewMesh(vertices, normals, uvs []Tuple, vertexIndices, normalIndices, uvIndices []int) *Mesh {
+	mesh := &Mesh{
+		BaseShape:     NewBaseShape(),
+		vertices:      vertices,
+		normals:       normals,
+		uvs:           uvs,
+		vertexIndices: vertexIndices,
+		normalIndices: normalIndices,
+		uvIndices:     uvIndices,
+	}
+	mesh.build(DefaultSAHConfig())
+	return mesh
+}
+
+// FaceCount returns the number of triangles of the mesh.
+func (mesh *Mesh) FaceCount() int {
+	return len(mesh.vertexIndices) / 3
+}
+
+// build divides the faces into the BVH of the mesh.
+func (mesh *Mesh) build(config *SAHConfig) {
+	items := make([]sahItem, mesh.FaceCount())
+	for face := range items {
+		bounds := mesh.faceBounds(face)
+		items[face] = sahItem{bounds: bounds, centroid: bounds.Centroid(), index: face}
+	}
+
+	mesh.nodes = make([]flatBVHNode, 0)
+	mesh.faceOrder = make([]int, 0, len(items))
+	if len(items) == 0 {
+		return
+	}
+	mesh.nodes = appendSAHNodes(mesh.nodes, items, config, func(leaf []sahItem) int {
+		offset := len(mesh.faceOrder)
+		for i := range leaf {
+			mesh.faceOrder = append(mesh.faceOrder, leaf[i].index)
+		}
+		return offset
+	})
+}
+
+// faceBounds returns the bounding box of the three vertices of a face.
+func (mesh *Mesh) faceBounds(face int) *BoundingBox {
+	bounds := NewEmptyBoundingBox()
+	for i := 0; i < 3; i++ {
+		bounds.Add(mesh.vertices[mesh.vertexIndices[3*face+i]])
+	}
+	return bounds
+}
+
+// SetFaceMaterials assigns a material ID to every face, selecting one of the materials.
+func (mesh *Mesh) SetFaceMaterials(materials []*Material, materialIDs []int) {
+	mesh.materials = materials
+	mesh.materialIDs = materialIDs
+}
+
+// SetNamedMaterial sets the material of the faces whose material ID has the given name, such as
+// the faces following a usemtl statement of an OBJ file. Returns false if no ID has that name.
+func (mesh *Mesh) SetNamedMaterial(name string, material *Material) bool {
+	for id, materialName := range mesh.materialNames {
+		if materialName == name {
+			for len(mesh.materials) <= id {
+				mesh.materials = append(mesh.materials, nil)
+			}
+			mesh.materials[id] = material
+			return true
+		}
+	}
+	return false
+}
+
+// materialAt returns the material of the face that was hit.
+func (mesh *Mesh) materialAt(intersection *Intersection) *Material {
+	if intersection.index < len(mesh.materialIDs) {
+		id := mesh.materialIDs[intersection.index]
+		if id >= 0 && id < len(mesh.materials) && mesh.materials[id] != nil {
+			return mesh.materials[id]
+		}
+	}
+	return mesh.material
+}
+
+// LocalBounds returns the bounding box of all the faces.
+func (mesh *Mesh) LocalBounds() *BoundingBox {
+	if len(mesh.nodes) == 0 {
+		return NewEmptyBoundingBox()
+	}
+	return NewBoundingBox(mesh.nodes[0].min, mesh.nodes[0].max)
+}
+
+// LocalIntersect traverses the BVH of the mesh and appends the intersections with the faces to xs.
+// The barycentric coordinates of the hit are stored in u and v and the face in index.
+func (mesh *Mesh) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	if len(mesh.nodes) == 0 {
+		return xs
+	}
+
+	invDir := Vector(1/localRay.direction.x, 1/localRay.direction.y, 1/localRay.direction.z)
+	negative := [3]bool{invDir.x < 0, invDir.y < 0, invDir.z < 0}
+
+	var stackArray [64]int
+	stack := append(stackArray[:0], 0)
+
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := &mesh.nodes[index]
+
+		if _, hit := intersectFlatNode(localRay.origin, invDir, node); !hit {
+			continue
+		}
+
+		if node.leaf {
+			for i := node.offset; i < node.offset+node.count; i++ {
+				xs = mesh.intersectFace(localRay, mesh.faceOrder[i], xs)
+			}
+			continue
+		}
+
+		if negative[node.axis] {
+			stack = append(stack, index+1, node.second)
+		} else {
+			stack = append(stack, node.second, index+1)
+		}
+	}
+
+	return xs
+}
+
+// intersectFace appends the intersection between the ray and a face to xs, if any.
+func (mesh *Mesh) intersectFace(localRay Ray, face int, xs Intersections) Intersections {
+	p1 := mesh.vertices[mesh.vertexIndices[3*face]]
+	e1 := mesh.vertices[mesh.vertexIndices[3*face+1]].Substract(p1)
+	e2 := mesh.vertices[mesh.vertexIndices[3*face+2]].Substract(p1)
+
+	dirCrossE2 := localRay.direction.CrossProduct(e2)
+	determinant := e1.DotProduct(dirCrossE2)
+	// The determinant scales with the lengths of the edges and of the direction, so the ray is only
+	// considered parallel to the face relative to them, keeping the hits on small faces.
+	scale := e1.DotProduct(e1) * e2.DotProduct(e2) * localRay.direction.DotProduct(localRay.direction)
+	if determinant*determinant < EPSILON*EPSILON*scale {
+		return xs
+	}
+
+	f := 1.0 / determinant
+	p1ToOrigin := localRay.origin.Substract(p1)
+	u := f * p1ToOrigin.DotProduct(dirCrossE2)
+	if u < 0 || u > 1 {
+		return xs
+	}
+
+	originCrossE1 := p1ToOrigin.CrossProduct(e1)
+	v := f * localRay.direction.DotProduct(originCrossE1)
+	if v < 0 || (u+v) > 1 {
+		return xs
+	}
+
+	t := f * e2.DotProduct(originCrossE1)
+	hit := NewIntersectionUV(t, mesh, u, v)
+	hit.index = face
+	return append(xs, hit)
+}
+
+// Intersect calculates the intersections between a ray in world space and the mesh.
+func (mesh *Mesh) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(mesh, worldRay, xs)
+}
+
+// LocalNormalAt interpolates the vertex normals of the face at the barycentric coordinates of the hit,
+// or returns the normal of the face when it has no vertex normals.
+func (mesh *Mesh) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	face := intersection.index
+	if len(mesh.normalIndices) > 0 {
+		n1, n2, n3 := mesh.normalIndices[3*face], mesh.normalIndices[3*face+1], mesh.normalIndices[3*face+2]
+		if n1 >= 0 && n2 >= 0 && n3 >= 0 {
+			return mesh.interpolate(mesh.normals[n1], mesh.normals[n2], mesh.normals[n3], intersection)
+		}
+	}
+
+	p1 := mesh.vertices[mesh.vertexIndices[3*face]]
+	e1 := mesh.vertices[mesh.vertexIndices[3*face+1]].Substract(p1)
+	e2 := mesh.vertices[mesh.vertexIndices[3*face+2]].Substract(p1)
+	return e2.CrossProduct(e1).Normalize()
+}
+
+// NormalAt calculates the normal at a point in world space, taking the parents of the mesh into account.
+func (mesh *Mesh) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(mesh, worldPoint, intersection)
+}
+
+// uvAt interpolates the texture coordinates of the face at the barycentric coordinates of the hit.
+// Returns false if the face has no texture coordinates.
+func (mesh *Mesh) uvAt(intersection *Intersection) (u, v float64, ok bool) {
+	face := intersection.index
+	if len(mesh.uvIndices) == 0 {
+		return 0, 0, false
+	}
+	t1, t2, t3 := mesh.uvIndices[3*face], mesh.uvIndices[3*face+1], mesh.uvIndices[3*face+2]
+	if t1 < 0 || t2 < 0 || t3 < 0 {
+		return 0, 0, false
+	}
+	uv := mesh.interpolate(mesh.uvs[t1], mesh.uvs[t2], mesh.uvs[t3], intersection)
+	return uv.x, uv.y, true
+}
+
+// interpolate returns the attributes of the three vertices of a face weighted by the barycentric
+// coordinates of the hit, u weighting the second vertex and v the third one.
+func (mesh *Mesh) interpolate(a1, a2, a3 Tuple, intersection *Intersection) Tuple {
+	return a2.Multiply(intersection.u).
+		Add(a3.Multiply(intersection.v)).
+		Add(a1.Multiply(1 - intersection.u - intersection.v))
+}
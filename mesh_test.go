@@ -0,0 +1,179 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// meshTestData is a small OBJ model with vertex normals, texture coordinates and two materials.
+const meshTestData = `
+v 0 1 0
+v -1 0 0
+v 1 0 0
+v 0 0 1
+v 0 -1 0.5
+vn 0 0 -1
+vn -1 0 0
+vn 1 0 0
+vn 0 1 0
+vt 0.5 1
+vt 0 0
+vt 1 0
+usemtl red
+f 1/1/1 2/2/2 3/3/3
+f 1/1/4 3/3/3 4/2/2
+usemtl blue
+f 2/2/2 5/1/1 3/3/3 4/2/4`
+
+func TestMeshMatchesTriangles(t *testing.T) {
+	// A mesh reports the same intersections as a group of triangles of the same OBJ data.
+	obj := parseObjData(meshTestData)
+	mesh := obj.objToMesh()
+	g := NewGroup()
+	for face := 0; 3*face < len(obj.vertexIndices); face++ {
+		v, n := obj.vertexIndices[3*face:3*face+3], obj.normalIndices[3*face:3*face+3]
+		p1, p2, p3 := obj.vertices[v[0]+1], obj.vertices[v[1]+1], obj.vertices[v[2]+1]
+		if n[0] < 0 || n[1] < 0 || n[2] < 0 {
+			g.AddChild(NewTriangle(p1, p2, p3))
+		} else {
+			g.AddChild(newSmoothTriangle(p1, p2, p3, obj.normals[n[0]+1], obj.normals[n[1]+1], obj.normals[n[2]+1]))
+		}
+	}
+
+	if mesh.FaceCount() != 4 || len(g.children) != 4 {
+		t.Fatalf("Mesh faces: got %v, expected: %v", mesh.FaceCount(), len(g.children))
+	}
+
+	rng := rand.New(rand.NewSource(6))
+	hits := 0
+	for i := 0; i < 500; i++ {
+		r := NewRay(Point(rng.Float64()*4-2, rng.Float64()*4-2, -5), Vector(rng.Float64()-0.5, rng.Float64()-0.5, 1).Normalize())
+		xs, expected := mesh.Intersect(r, nil), g.Intersect(r, nil)
+		xs.Sort()
+		if len(xs) != len(expected) {
+			t.Fatalf("Mesh intersections: got %v, expected: %v", len(xs), len(expected))
+		}
+		for j := range xs {
+			if !floatEqual(xs[j].t, expected[j].t) {
+				t.Errorf("Mesh intersection t: got %v, expected: %v", xs[j].t, expected[j].t)
+			}
+			point := r.Position(xs[j].t)
+			if got, want := mesh.NormalAt(point, &xs[j]), expected[j].object.NormalAt(point, &expected[j]); !got.Equals(want) {
+				t.Errorf("Mesh normal: got %v, expected: %v", got, want)
+			}
+		}
+		hits += len(xs)
+	}
+	if hits == 0 {
+		t.Errorf("Mesh intersections: got %v, expected some", hits)
+	}
+}
+
+func TestMeshAttributes(t *testing.T) {
+	// Texture coordinates are interpolated and the usemtl statements select the materials of the faces.
+	mesh, err := parseObjMesh(meshTestData, nil)
+	if err != nil {
+		t.Fatalf("parseObjMesh: got %v, expected no error", err)
+	}
+	red, blue := DefaultMaterial(), DefaultMaterial()
+	red.color = NewColor(1, 0, 0)
+	blue.color = NewColor(0, 0, 1)
+	if !mesh.SetNamedMaterial("red", red) || !mesh.SetNamedMaterial("blue", blue) {
+		t.Fatalf("Mesh named materials: got %v, expected: %v", mesh.materialNames, []string{"", "red", "blue"})
+	}
+	if mesh.SetNamedMaterial("green", red) {
+		t.Errorf("Mesh unknown material name: got %v, expected: %v", true, false)
+	}
+
+	r := NewRay(Point(0, 0.5, -5), Vector(0, 0, 1))
+	xs := mesh.Intersect(r, nil)
+	xs.Sort()
+	if len(xs) != 2 || xs[0].index != 0 {
+		t.Fatalf("Mesh intersection: got %v, expected face: %v", xs, 0)
+	}
+	u, v, ok := mesh.uvAt(&xs[0])
+	if !ok || !floatEqual(u, 0.5) || !floatEqual(v, 0.5) {
+		t.Errorf("Mesh texture coordinates: got %v, %v, expected: %v, %v", u, v, 0.5, 0.5)
+	}
+	if comps := PrepareComputations(&xs[0], r, xs); comps.material != red {
+		t.Errorf("Mesh face material: got %v, expected: %v", comps.material, red)
+	}
+
+	r = NewRay(Point(0.1, -0.5, -5), Vector(0, 0, 1))
+	xs = mesh.Intersect(r, nil)
+	xs.Sort()
+	if len(xs) == 0 || mesh.materialAt(&xs[0]) != blue {
+		t.Errorf("Mesh face material: got %v, expected: %v", xs, blue)
+	}
+
+	// An instance of the mesh keeps the materials of the faces.
+	instance := NewInstance(mesh)
+	xs = instance.Intersect(r, nil)
+	xs.Sort()
+	if len(xs) == 0 || xs[0].material() != blue {
+		t.Errorf("Mesh instance face material: got %v, expected: %v", xs, blue)
+	}
+}
+
+func TestParseObjMeshErrors(t *testing.T) {
+	// Malformed data and faces referring to missing vertices are reported instead of panicking.
+	for _, data := range []string{
+		"v 1 0 0\nv 0 1 0\nv 0 0 x\nf 1 2 3",
+		"v 1 0 0\nv 0 1 0\nv 0 0 1\nf 1 2 4",
+		"v 1 0 0\nv 0 1 0\nv 0 0 1\nf 1//1 2//1 3//1",
+	} {
+		if mesh, err := parseObjMesh(data, nil); err == nil {
+			t.Errorf("parseObjMesh %q: got %v, expected an error", data, mesh)
+		}
+	}
+}
+
+func TestMeshSmallFaces(t *testing.T) {
+	// A face much smaller than EPSILON in area is still hit.
+	size := 1e-3
+	mesh := NewMesh([]Tuple{Point(0, 0, 0), Point(0, size, 0), Point(size, 0, 0)}, nil, nil, []int{0, 1, 2}, nil, nil)
+	r := NewRay(Point(size/4, size/4, -1), Vector(0, 0, 1))
+	xs := mesh.Intersect(r, nil)
+	if len(xs) != 1 || !floatEqual(xs[0].t, 1) {
+		t.Errorf("Small face intersection: got %v, expected a hit at t = %v", xs, 1)
+	}
+}
+
+func TestMeshSurfaceUVPattern(t *testing.T) {
+	// A surface pattern is read at the texture coordinates of the mesh, also through an instance.
+	vertices := []Tuple{Point(-1, -1, 0), Point(-1, 1, 0), Point(1, -1, 0)}
+	uvs := []Tuple{Point(0, 0, 0), Point(0, 1, 0), Point(1, 0, 0)}
+	mesh := NewMesh(vertices, nil, uvs, []int{0, 1, 2}, nil, []int{0, 1, 2})
+	material := DefaultMaterial()
+	material.pattern = uvSurfacePattern(uvCheckers(2, 2, White, Black))
+	mesh.SetMaterial(material)
+
+	for _, shape := range []Shape{mesh, NewInstance(mesh)} {
+		for _, test := range []struct {
+			point    Tuple
+			expected Color
+		}{
+			{Point(-0.8, -0.8, 0), White},
+			{Point(0.2, -0.8, 0), Black},
+			{Point(-0.8, 0.2, 0), Black},
+		} {
+			r := NewRay(Point(test.point.x, test.point.y, -5), Vector(0, 0, 1))
+			xs := shape.Intersect(r, nil)
+			if len(xs) != 1 {
+				t.Fatalf("Surface pattern intersection: got %v, expected one hit", xs)
+			}
+			if comps := PrepareComputations(&xs[0], r, xs); !comps.color.Equals(test.expected) {
+				t.Errorf("Surface pattern at %v: got %v, expected: %v", test.point, comps.color, test.expected)
+			}
+		}
+	}
+
+	// Shapes without texture coordinates use the planar map.
+	plane := NewPlane()
+	plane.SetMaterial(material)
+	r := NewRay(Point(0.75, 1, 0.25), Vector(0, -1, 0))
+	xs := plane.Intersect(r, nil)
+	if comps := PrepareComputations(&xs[0], r, xs); !comps.color.Equals(Black) {
+		t.Errorf("Surface pattern on a plane: got %v, expected: %v", comps.color, Black)
+	}
+}
@@ -9,6 +9,19 @@ import (
 // objWorld tests triangles from wavefront obj data.
 func objWorld() *Canvas {
 	start := time.Now()
+
+	world, camera := objWorldScene(1000, 500)
+
+	// canvas := camera.Render(world, defaultRecursionDepth)
+	canvas := camera.RenderWithThreadPool(world, defaultRecursionDepth)
+
+	fmt.Println("Render time: ", time.Now().Sub(start))
+
+	return canvas
+}
+
+// objWorldScene builds the world and a camera of the given size used by objWorld.
+func objWorldScene(hsize, vsize int) (*World, *Camera) {
 	lights := []*PointLight{
 		NewPointLight(Point(0, 10, -15), NewColor(0, 1, 1)),
 		NewPointLight(Point(0, 10, 0), NewColor(1, 0.5, 0.5)),
@@ -20,7 +33,10 @@ func objWorld() *Canvas {
 
 	objBytes, _ := ioutil.ReadFile("gopher.obj")
 	// objBytes, _ := ioutil.ReadFile("MKIII.obj")
-	obj := parseObjData(string(objBytes)).objToGroup()
+	obj, err := parseObjMesh(string(objBytes), nil)
+	if err != nil {
+		panic(err)
+	}
 
 	objMaterial := DefaultMaterial()
 
@@ -39,10 +55,7 @@ func objWorld() *Canvas {
 		MultiplyMatrix(RotationY(PI / 4)),
 	)
 
-	// Apply boundingBox to the group.
-	// obj.Bounds()
-	// Apply boundingVolumeHierarchy to the group.
-	Divide(obj, 1)
+	// The mesh divides its faces into its own bounding volume hierarchy.
 
 	// obj.SetTransform(
 	// 	RotationY(PI / 3),
@@ -50,13 +63,8 @@ func objWorld() *Canvas {
 
 	world := NewWorld(lights, []Shape{p1, obj})
 
-	camera := NewCamera(1000, 500, PI/3)
+	camera := NewCamera(hsize, vsize, PI/3)
 	camera.SetTransform(ViewTransform(Point(0, 1.5, -15), Point(0, 3, 0), Vector(0, 1, 0)))
 
-	// canvas := camera.Render(world, defaultRecursionDepth)
-	canvas := camera.RenderWithThreadPool(world, defaultRecursionDepth)
-
-	fmt.Println("Render time: ", time.Now().Sub(start))
-
-	return canvas
+	return world, camera
 }
@@ -16,3 +16,12 @@ func TestObjWorld(t *testing.T) {
 	file.WriteString(canvas.ToPPM())
 	file.Close()
 }
+
+func BenchmarkRenderObjWorld(b *testing.B) {
+	world, camera := objWorldScene(100, 50)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		camera.Render(world, defaultRecursionDepth)
+	}
+}
@@ -2,48 +2,21 @@ package main
 
 import (
 	"math"
-	"math/rand"
-	"sort"
 )
 
-// Shape interface defining any object in the scene.
-type Shape interface {
-	SetMaterial(*Material)
-	SetTransform(Matrix)
-	Transform() Matrix
-	GetInverse() Matrix
-	GetInverseTranspose() Matrix
-	Material() *Material
-	Intersect(*Ray) []*Intersection
-	localIntersect(*Ray) []*Intersection
-	NormalAt(*Tuple, *Intersection) *Tuple
-	localNormalAt(*Tuple, *Intersection) *Tuple
-	GetParent() Shape
-	SetParent(shape Shape)
-	GetID() int
-}
-
 // Sphere object
 type Sphere struct {
-	origin           *Tuple
-	transform        Matrix
-	inverse          Matrix
-	inverseTranspose Matrix
-	material         *Material
-	parent           Shape
-	savedRay         *Ray
-	id               int
+	BaseShape
+	origin   Tuple
+	savedRay Ray
 }
 
 // NewSphere creates a new default sphere centered at the origin with Identity matrix as transform and default material.
 func NewSphere() *Sphere {
-	return &Sphere{origin: Point(0, 0, 0),
-		transform:        IdentityMatrix,
-		inverse:          IdentityMatrix,
-		inverseTranspose: IdentityMatrix,
-		material:         DefaultMaterial(),
-		savedRay:         NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
-		id:               rand.Int(),
+	return &Sphere{
+		BaseShape: NewBaseShape(),
+		origin:    Point(0, 0, 0),
+		savedRay:  NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
 	}
 }
 
@@ -52,74 +25,27 @@ func GlassSphere() *Sphere {
 	m := DefaultMaterial()
 	m.transparency = 1.0
 	m.refractiveIndex = 1.5
-	return &Sphere{
-		origin:           Point(0, 0, 0),
-		transform:        IdentityMatrix,
-		inverse:          IdentityMatrix,
-		inverseTranspose: IdentityMatrix,
-		material:         m,
-	}
-}
-
-// GetID returns the id of the shape.
-func (sphere *Sphere) GetID() int {
-	return sphere.id
-}
-
-// GetParent returns the parent shape from this current shape.
-func (sphere *Sphere) GetParent() Shape {
-	return sphere.parent
-}
-
-// SetParent sets the parent shape from this current shape.
-func (sphere *Sphere) SetParent(shape Shape) {
-	sphere.parent = shape
-}
-
-// Material returns the material of a Sphere.
-func (sphere *Sphere) Material() *Material {
-	return sphere.material
-}
-
-// SetTransform sets the spheres transformation.
-func (sphere *Sphere) SetTransform(transformation Matrix) {
-	sphere.transform = sphere.transform.MultiplyMatrix(transformation)
-	sphere.inverse = sphere.transform.Inverse()
-	sphere.inverseTranspose = sphere.inverse.Transpose()
-}
-
-// GetInverse returns the cached inverse matrix of the current Shape.
-func (sphere *Sphere) GetInverse() Matrix {
-
-	return sphere.inverse
-}
-
-// GetInverseTranspose returns the cached inverseTranspose matrix of the current Shape.
-func (sphere *Sphere) GetInverseTranspose() Matrix {
-
-	return sphere.inverseTranspose
-}
-
-// SetMaterial sets the spheres material.
-func (sphere *Sphere) SetMaterial(material *Material) {
-	sphere.material = material
-}
-
-// Transform returns the transformation.
-func (sphere *Sphere) Transform() Matrix {
-	return sphere.transform
+	sphere := NewSphere()
+	sphere.material = m
+	return sphere
 }
 
-func (sphere *Sphere) localNormalAt(localPoint *Tuple, intersection *Intersection) (localNormal *Tuple) {
+// LocalNormalAt returns the normal of the sphere in object space.
+func (sphere *Sphere) LocalNormalAt(localPoint Tuple, intersection *Intersection) (localNormal Tuple) {
 
 	localNormal = localPoint.Substract(sphere.origin)
 	return
 }
 
+// LocalBounds returns the bounding box of the unit sphere in object space.
+func (sphere *Sphere) LocalBounds() *BoundingBox {
+	return NewBoundingBoxFloat(-1, -1, -1, 1, 1, 1)
+}
+
 // NormalAt calculates the normal(vector perpendicular to the surface) at a given point.
-func (sphere *Sphere) NormalAt(worldPoint *Tuple, intersection *Intersection) *Tuple {
+func (sphere *Sphere) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
 	// localPoint := sphere.inverse.MultiplyMatrixByTuple(worldPoint)
-	// localNormal := sphere.localNormalAt(localPoint)
+	// localNormal := sphere.LocalNormalAt(localPoint)
 	// worldNormal := sphere.inverseTranspose.MultiplyMatrixByTuple(localNormal)
 	// worldNormal.w = 0.0
 	// return worldNormal.Normalize()
@@ -128,7 +54,8 @@ func (sphere *Sphere) NormalAt(worldPoint *Tuple, intersection *Intersection) *T
 	return NormalAt(sphere, worldPoint, intersection)
 }
 
-func (sphere *Sphere) localIntersect(localRay *Ray) []*Intersection {
+// LocalIntersect calculates the intersections between a ray in object space and the sphere.
+func (sphere *Sphere) LocalIntersect(localRay Ray, xs Intersections) Intersections {
 	sphere.savedRay = localRay
 	sphereToRay := localRay.origin.Substract(sphere.origin)
 	a := localRay.direction.DotProduct(localRay.direction)
@@ -138,69 +65,50 @@ func (sphere *Sphere) localIntersect(localRay *Ray) []*Intersection {
 	discriminant := (b * b) - 4*a*c
 
 	if discriminant < 0 {
-		return Intersections{}
+		return xs
 	}
 	sqrtDisc := math.Sqrt(discriminant)
 	div := (2 * a)
 	t1 := (-b - sqrtDisc) / div
 	t2 := (-b + sqrtDisc) / div
-	return []*Intersection{NewIntersection(t1, sphere), NewIntersection(t2, sphere)}
+	return append(xs, NewIntersection(t1, sphere), NewIntersection(t2, sphere))
 }
 
 // Intersect computes the intersection between a sphere and a ray
-func (sphere *Sphere) Intersect(worldRay *Ray) []*Intersection {
-	localRay := worldRay.Transform(sphere.inverse)
-	return sphere.localIntersect(localRay)
+func (sphere *Sphere) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(sphere, worldRay, xs)
 }
 
 // Plane Shape
 type Plane struct {
-	transform        Matrix
-	inverse          Matrix
-	inverseTranspose Matrix
-	material         *Material
-	savedRay         *Ray
-	parent           Shape
-	id               int
+	BaseShape
+	savedRay Ray
 }
 
 // NewPlane creates a new default Plane centered at the origin with Identity matrix as transform and default material.
 func NewPlane() *Plane {
 	return &Plane{
-		transform:        IdentityMatrix,
-		inverse:          IdentityMatrix,
-		inverseTranspose: IdentityMatrix,
-		material:         DefaultMaterial(),
-		savedRay:         NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
-		id:               rand.Int(),
+		BaseShape: NewBaseShape(),
+		savedRay:  NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
 	}
 }
 
-// GetID returns the id of the shape.
-func (plane *Plane) GetID() int {
-	return plane.id
-}
-
-// GetParent returns the parent shape from this current shape.
-func (plane *Plane) GetParent() Shape {
-	return plane.parent
-}
-
-// SetParent sets the parent shape from this current shape.
-func (plane *Plane) SetParent(shape Shape) {
-	plane.parent = shape
-}
-
-func (plane *Plane) localNormalAt(localPoint *Tuple, intersection *Intersection) (localNormal *Tuple) {
+// LocalNormalAt returns the normal of the plane in object space, which is constant.
+func (plane *Plane) LocalNormalAt(localPoint Tuple, intersection *Intersection) (localNormal Tuple) {
 
 	localNormal = Vector(0, 1, 0)
 	return
 }
 
+// LocalBounds returns the bounding box of the xz plane in object space, infinite in x and z.
+func (plane *Plane) LocalBounds() *BoundingBox {
+	return NewBoundingBoxFloat(math.Inf(-1), 0, math.Inf(-1), math.Inf(1), 0, math.Inf(1))
+}
+
 // NormalAt calculates the normal(vector perpendicular to the surface) at a given point.
-func (plane *Plane) NormalAt(worldPoint *Tuple, intersection *Intersection) *Tuple {
+func (plane *Plane) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
 	// localPoint := plane.inverse.MultiplyMatrixByTuple(worldPoint)
-	// localNormal := plane.localNormalAt(localPoint)
+	// localNormal := plane.LocalNormalAt(localPoint)
 	// worldNormal := plane.inverseTranspose.MultiplyMatrixByTuple(localNormal)
 	// worldNormal.w = 0.0
 	// return worldNormal.Normalize()
@@ -209,81 +117,39 @@ func (plane *Plane) NormalAt(worldPoint *Tuple, intersection *Intersection) *Tup
 	return NormalAt(plane, worldPoint, intersection)
 }
 
-func (plane *Plane) localIntersect(localRay *Ray) []*Intersection {
+// LocalIntersect calculates the intersection between a ray in object space and the xz plane.
+func (plane *Plane) LocalIntersect(localRay Ray, xs Intersections) Intersections {
 	plane.savedRay = localRay
 	if math.Abs(localRay.direction.y) < EPSILON {
-		return []*Intersection{}
+		return xs
 	}
 
 	t := -localRay.origin.y / localRay.direction.y
-	return []*Intersection{NewIntersection(t, plane)}
+	return append(xs, NewIntersection(t, plane))
 }
 
 // Intersect calculates the local intersections between a ray and a plane.
-func (plane *Plane) Intersect(worldRay *Ray) []*Intersection {
-
-	localRay := worldRay.Transform(plane.inverse)
-	return plane.localIntersect(localRay)
-}
-
-// Transform returns the transformation.
-func (plane *Plane) Transform() Matrix {
-	return plane.transform
-}
-
-// GetInverse returns the cached inverse matrix of the current Shape.
-func (plane *Plane) GetInverse() Matrix {
-
-	return plane.inverse
-}
-
-// GetInverseTranspose returns the cached inverseTranspose matrix of the current Shape.
-func (plane *Plane) GetInverseTranspose() Matrix {
-
-	return plane.inverseTranspose
-}
-
-// Material returns the material of a Plane.
-func (plane *Plane) Material() *Material {
-	return plane.material
-}
-
-// SetTransform sets the Plane's transformation.
-func (plane *Plane) SetTransform(transform Matrix) {
-	plane.transform = plane.transform.MultiplyMatrix(transform)
-	plane.inverse = plane.transform.Inverse()
-	plane.inverseTranspose = plane.inverse.Transpose()
-}
+func (plane *Plane) Intersect(worldRay Ray, xs Intersections) Intersections {
 
-// SetMaterial returns the material of a Plane.
-func (plane *Plane) SetMaterial(material *Material) {
-	plane.material = material
+	return Intersect(plane, worldRay, xs)
 }
 
 // Cube struct.
 type Cube struct {
-	transform        Matrix
-	inverse          Matrix
-	inverseTranspose Matrix
-	material         *Material
-	savedRay         *Ray
-	parent           Shape
-	id               int
+	BaseShape
+	savedRay Ray
 }
 
 // NewCube creates a new default NewCube centered at the origin with Identity matrix as transform and default material.
 func NewCube() *Cube {
 	return &Cube{
-		transform:        IdentityMatrix,
-		inverse:          IdentityMatrix,
-		inverseTranspose: IdentityMatrix,
-		material:         DefaultMaterial(),
-		savedRay:         NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
-		id:               rand.Int(),
+		BaseShape: NewBaseShape(),
+		savedRay:  NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
 	}
 }
 
-func (cube *Cube) localIntersect(localRay *Ray) []*Intersection {
+// LocalIntersect calculates the intersections between a ray in object space and the axis aligned cube.
+func (cube *Cube) LocalIntersect(localRay Ray, xs Intersections) Intersections {
 
 	cube.savedRay = localRay
 	xTMin, xTMax := checkAxis(localRay.origin.x, localRay.direction.x)
@@ -294,35 +160,18 @@ func (cube *Cube) localIntersect(localRay *Ray) []*Intersection {
 	tMax := min(xTMax, yTMax, zTMax)
 
 	if tMin > tMax {
-		return []*Intersection{}
+		return xs
 	}
 
-	return []*Intersection{
+	return append(xs,
 		NewIntersection(tMin, cube),
-		NewIntersection(tMax, cube)}
-}
-
-// GetID returns the id of the shape.
-func (cube *Cube) GetID() int {
-	return cube.id
-}
-
-// GetParent returns the parent shape from this current shape.
-func (cube *Cube) GetParent() Shape {
-	return cube.parent
-}
-
-// SetParent sets the parent shape from this current shape.
-func (cube *Cube) SetParent(shape Shape) {
-	cube.parent = shape
+		NewIntersection(tMax, cube))
 }
 
 // Intersect computes the local intersection between a cube and a ray.
-func (cube *Cube) Intersect(worldRay *Ray) []*Intersection {
-
-	ray := worldRay.Transform(cube.inverse)
-	return cube.localIntersect(ray)
+func (cube *Cube) Intersect(worldRay Ray, xs Intersections) Intersections {
 
+	return Intersect(cube, worldRay, xs)
 }
 
 func checkAxis(origin float64, direction float64) (min float64, max float64) {
@@ -342,12 +191,8 @@ func checkAxis(origin float64, direction float64) (min float64, max float64) {
 	return tmin, tmax
 }
 
-// Material returns the material of a Cube.
-func (cube *Cube) Material() *Material {
-	return cube.material
-}
-
-func (cube *Cube) localNormalAt(localPoint *Tuple, intersection *Intersection) (localNormal *Tuple) {
+// LocalNormalAt returns the normal of the face of the cube containing the point in object space.
+func (cube *Cube) LocalNormalAt(localPoint Tuple, intersection *Intersection) (localNormal Tuple) {
 
 	maxc := max(math.Abs(localPoint.x), math.Abs(localPoint.y), math.Abs(localPoint.z))
 
@@ -364,11 +209,16 @@ func (cube *Cube) localNormalAt(localPoint *Tuple, intersection *Intersection) (
 	return
 }
 
+// LocalBounds returns the bounding box of the cube in object space.
+func (cube *Cube) LocalBounds() *BoundingBox {
+	return NewBoundingBoxFloat(-1, -1, -1, 1, 1, 1)
+}
+
 // NormalAt calculates the local normal (vector perpendicular to the surface) at a given point of the object.
-func (cube *Cube) NormalAt(worldPoint *Tuple, intersection *Intersection) *Tuple {
+func (cube *Cube) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
 
 	// localPoint := cube.inverse.MultiplyMatrixByTuple(worldPoint)
-	// localNormal := cube.localNormalAt(localPoint)
+	// localNormal := cube.LocalNormalAt(localPoint)
 	// worldNormal := cube.inverseTranspose.MultiplyMatrixByTuple(localNormal)
 	// worldNormal.w = 0.0
 	// return worldNormal.Normalize()
@@ -377,92 +227,39 @@ func (cube *Cube) NormalAt(worldPoint *Tuple, intersection *Intersection) *Tuple
 	return NormalAt(cube, worldPoint, intersection)
 }
 
-// SetMaterial returns the material of a Cube.
-func (cube *Cube) SetMaterial(material *Material) {
-	cube.material = material
-}
-
-// SetTransform sets the Cube's transformation.
-func (cube *Cube) SetTransform(transform Matrix) {
-	cube.transform = cube.transform.MultiplyMatrix(transform)
-	cube.inverse = cube.transform.Inverse()
-	cube.inverseTranspose = cube.inverse.Transpose()
-}
-
-// GetInverse returns the cached inverse matrix of the current Shape.
-func (cube *Cube) GetInverse() Matrix {
-
-	return cube.inverse
-}
-
-// GetInverseTranspose returns the cached inverseTranspose matrix of the current Shape.
-func (cube *Cube) GetInverseTranspose() Matrix {
-
-	return cube.inverseTranspose
-}
-
-// Transform returns the transformation.
-func (cube *Cube) Transform() Matrix {
-	return cube.transform
-}
-
 // Cylinder struct.
 type Cylinder struct {
-	transform        Matrix
-	inverse          Matrix
-	inverseTranspose Matrix
-	material         *Material
-	savedRay         *Ray
+	BaseShape
+	savedRay         Ray
 	minimum, maximum float64
 	closed           bool
-	parent           Shape
-	id               int
 }
 
 // NewCylinder creates a new default Cylinder centered at the origin with Identity matrix as transform and default material.
 func NewCylinder() *Cylinder {
 	return &Cylinder{
-		transform:        NewIdentityMatrix(),
-		inverse:          IdentityMatrix,
-		inverseTranspose: IdentityMatrix,
-		material:         DefaultMaterial(),
-		savedRay:         NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
-		minimum:          math.Inf(-1),
-		maximum:          math.Inf(1),
-		id:               rand.Int(),
+		BaseShape: NewBaseShape(),
+		savedRay:  NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
+		minimum:   math.Inf(-1),
+		maximum:   math.Inf(1),
 	}
 }
 
-// GetID returns the id of the shape.
-func (cylinder *Cylinder) GetID() int {
-	return cylinder.id
-}
-
-// GetParent returns the parent shape from this current shape.
-func (cylinder *Cylinder) GetParent() Shape {
-	return cylinder.parent
-}
-
-// SetParent sets the parent shape from this current shape.
-func (cylinder *Cylinder) SetParent(shape Shape) {
-	cylinder.parent = shape
-}
-
 // Intersect calculates the local intersections between a ray and a cylinder.
-func (cylinder *Cylinder) Intersect(worldRay *Ray) []*Intersection {
+func (cylinder *Cylinder) Intersect(worldRay Ray, xs Intersections) Intersections {
 
-	localRay := worldRay.Transform(cylinder.inverse)
-	return cylinder.localIntersect(localRay)
+	return Intersect(cylinder, worldRay, xs)
 }
 
-func (cylinder *Cylinder) localIntersect(localRay *Ray) []*Intersection {
+// LocalIntersect calculates the intersections between a ray in object space and the cylinder.
+func (cylinder *Cylinder) LocalIntersect(localRay Ray, xs Intersections) Intersections {
 
 	cylinder.savedRay = localRay
 	a := math.Pow(localRay.direction.x, 2) + math.Pow(localRay.direction.z, 2)
 
 	// localRay is parallel to the y axis.
 	if math.Abs(a) < EPSILON {
-		return cylinder.intersectCaps(localRay, Intersections{})
+		return cylinder.intersectCaps(localRay, xs)
 	}
 
 	b := 2*localRay.origin.x*localRay.direction.x +
@@ -474,14 +271,12 @@ func (cylinder *Cylinder) localIntersect(localRay *Ray) []*Intersection {
 
 	// localRay does not intersect the cylinder.
 	if disc < 0 {
-		return []*Intersection{}
+		return xs
 	}
 
 	t0 := (-b - math.Sqrt(disc)) / (2 * a)
 	t1 := (-b + math.Sqrt(disc)) / (2 * a)
 
-	xs := Intersections{}
-
 	y0 := localRay.origin.y + t0*localRay.direction.y
 
 	if cylinder.minimum < y0 && y0 < cylinder.maximum {
@@ -497,39 +292,8 @@ func (cylinder *Cylinder) localIntersect(localRay *Ray) []*Intersection {
 	return cylinder.intersectCaps(localRay, xs)
 }
 
-// Material returns the material of a Sphere.
-func (cylinder *Cylinder) Material() *Material {
-	return cylinder.material
-}
-
-// SetTransform sets the spheres transformation.
-func (cylinder *Cylinder) SetTransform(transformation Matrix) {
-	cylinder.transform = cylinder.transform.MultiplyMatrix(transformation)
-	cylinder.inverse = cylinder.transform.Inverse()
-	cylinder.inverseTranspose = cylinder.inverse.Transpose()
-}
-
-// SetMaterial sets the spheres material.
-func (cylinder *Cylinder) SetMaterial(material *Material) {
-	cylinder.material = material
-}
-
-//Transform returns the transformation.
-func (cylinder *Cylinder) Transform() Matrix {
-	return cylinder.transform
-}
-
-// GetInverse returns the cached inverse matrix of the current Shape.
-func (cylinder *Cylinder) GetInverse() Matrix {
-	return cylinder.inverse
-}
-
-// GetInverseTranspose returns the cached inverseTranspose matrix of the current Shape.
-func (cylinder *Cylinder) GetInverseTranspose() Matrix {
-	return cylinder.inverseTranspose
-}
-
-func (cylinder *Cylinder) localNormalAt(localPoint *Tuple, intersection *Intersection) *Tuple {
+// LocalNormalAt returns the normal of the cylinder (body or caps) in object space.
+func (cylinder *Cylinder) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
 
 	// Compute the square of the distance from the y axis.
 	dist := math.Pow(localPoint.x, 2) + math.Pow(localPoint.z, 2)
@@ -545,11 +309,16 @@ func (cylinder *Cylinder) localNormalAt(localPoint *Tuple, intersection *Interse
 	}
 }
 
+// LocalBounds returns the bounding box of the cylinder in object space, truncated by its minimum and maximum.
+func (cylinder *Cylinder) LocalBounds() *BoundingBox {
+	return NewBoundingBoxFloat(-1, cylinder.minimum, -1, 1, cylinder.maximum, 1)
+}
+
 // NormalAt calculates the local normal (vector perpendicular to the surface) at a given point of the object.
-func (cylinder *Cylinder) NormalAt(worldPoint *Tuple, intersection *Intersection) *Tuple {
+func (cylinder *Cylinder) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
 
 	// localPoint := cylinder.inverse.MultiplyMatrixByTuple(worldPoint)
-	// localNormal := cylinder.localNormalAt(localPoint)
+	// localNormal := cylinder.LocalNormalAt(localPoint)
 	// worldNormal := cylinder.inverseTranspose.MultiplyMatrixByTuple(localNormal)
 	// worldNormal.w = 0.0
 	// return worldNormal.Normalize()
@@ -559,13 +328,13 @@ func (cylinder *Cylinder) NormalAt(worldPoint *Tuple, intersection *Intersection
 }
 
 // Checks to see if the intersection at `t` is within a radius of 1 (the radius of your cylinders) from the y axis.
-func (cylinder *Cylinder) checkCap(ray *Ray, t float64) bool {
+func (cylinder *Cylinder) checkCap(ray Ray, t float64) bool {
 	x := ray.origin.x + t*ray.direction.x
 	z := ray.origin.z + t*ray.direction.z
 	return math.Pow(x, 2)+math.Pow(z, 2) <= 1.0
 }
 
-func (cylinder *Cylinder) intersectCaps(ray *Ray, xs Intersections) Intersections {
+func (cylinder *Cylinder) intersectCaps(ray Ray, xs Intersections) Intersections {
 
 	// Caps only matter if the cylinder is closed, and might possibly be intersected by the ray.
 	if !cylinder.closed || math.Abs(ray.direction.y) < EPSILON {
@@ -590,57 +359,32 @@ func (cylinder *Cylinder) intersectCaps(ray *Ray, xs Intersections) Intersection
 
 // Cone struct.
 type Cone struct {
-	transform        Matrix
-	inverse          Matrix
-	inverseTranspose Matrix
-	material         *Material
-	savedRay         *Ray
+	BaseShape
+	savedRay         Ray
 	minimum, maximum float64
 	closed           bool
-	parent           Shape
-	id               int
 }
 
 // NewCone creates a new default Cone centered at the origin with Identity matrix as transform and default material.
 func NewCone() *Cone {
 	return &Cone{
-		transform:        NewIdentityMatrix(),
-		inverse:          IdentityMatrix,
-		inverseTranspose: IdentityMatrix,
-		material:         DefaultMaterial(),
-		savedRay:         NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
-		minimum:          math.Inf(-1),
-		maximum:          math.Inf(1),
-		id:               rand.Int(),
+		BaseShape: NewBaseShape(),
+		savedRay:  NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
+		minimum:   math.Inf(-1),
+		maximum:   math.Inf(1),
 	}
 }
 
-// GetID returns the id of the shape.
-func (cone *Cone) GetID() int {
-	return cone.id
-}
-
-// GetParent returns the parent Shape of the current Shape.
-func (cone *Cone) GetParent() Shape {
-	return cone.parent
-}
-
-// SetParent sets the parent shape from this current shape.
-func (cone *Cone) SetParent(shape Shape) {
-	cone.parent = shape
-}
-
 // Intersect calculates the local intersections between a ray and a Cone.
-func (cone *Cone) Intersect(worldRay *Ray) []*Intersection {
+func (cone *Cone) Intersect(worldRay Ray, xs Intersections) Intersections {
 
-	localRay := worldRay.Transform(cone.inverse)
-	return cone.localIntersect(localRay)
+	return Intersect(cone, worldRay, xs)
 }
 
-func (cone *Cone) localIntersect(localRay *Ray) []*Intersection {
+// LocalIntersect calculates the intersections between a ray in object space and the cone.
+func (cone *Cone) LocalIntersect(localRay Ray, xs Intersections) Intersections {
 
 	cone.savedRay = localRay
-	xs := Intersections{}
 
 	a := math.Pow(localRay.direction.x, 2) -
 		math.Pow(localRay.direction.y, 2) +
@@ -690,39 +434,8 @@ func (cone *Cone) localIntersect(localRay *Ray) []*Intersection {
 	return cone.intersectCaps(localRay, xs)
 }
 
-// Material returns the material of a Sphere.
-func (cone *Cone) Material() *Material {
-	return cone.material
-}
-
-// SetTransform sets the shape's transformation.
-func (cone *Cone) SetTransform(transformation Matrix) {
-	cone.transform = cone.transform.MultiplyMatrix(transformation)
-	cone.inverse = cone.transform.Inverse()
-	cone.inverseTranspose = cone.inverse.Transpose()
-}
-
-// SetMaterial sets the shape's material.
-func (cone *Cone) SetMaterial(material *Material) {
-	cone.material = material
-}
-
-//Transform returns the transformation.
-func (cone *Cone) Transform() Matrix {
-	return cone.transform
-}
-
-// GetInverse returns the cached inverse matrix of the current Shape.
-func (cone *Cone) GetInverse() Matrix {
-	return cone.inverse
-}
-
-// GetInverseTranspose returns the cached inverseTranspose matrix of the current Shape.
-func (cone *Cone) GetInverseTranspose() Matrix {
-	return cone.inverseTranspose
-}
-
-func (cone *Cone) localNormalAt(localPoint *Tuple, intersection *Intersection) *Tuple {
+// LocalNormalAt returns the normal of the cone (body or caps) in object space.
+func (cone *Cone) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
 
 	// Compute the square of the distance from the y axis.
 	dist := math.Pow(localPoint.x, 2) + math.Pow(localPoint.z, 2)
@@ -744,11 +457,23 @@ func (cone *Cone) localNormalAt(localPoint *Tuple, intersection *Intersection) *
 	}
 }
 
+// LocalBounds returns the bounding box of the cone in object space.
+// The radius of a cone at any given y is the absolute value of that y.
+func (cone *Cone) LocalBounds() *BoundingBox {
+	xzMin := math.Abs(cone.minimum)
+	xzMax := math.Abs(cone.maximum)
+	limit := xzMin
+	if xzMax > limit {
+		limit = xzMax
+	}
+	return NewBoundingBoxFloat(-limit, cone.minimum, -limit, limit, cone.maximum, limit)
+}
+
 // NormalAt calculates the local normal (vector perpendicular to the surface) at a given point of the object.
-func (cone *Cone) NormalAt(worldPoint *Tuple, intersection *Intersection) *Tuple {
+func (cone *Cone) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
 
 	// localPoint := cone.inverse.MultiplyMatrixByTuple(worldPoint)
-	// localNormal := cone.localNormalAt(localPoint)
+	// localNormal := cone.LocalNormalAt(localPoint)
 	// worldNormal := cone.inverseTranspose.MultiplyMatrixByTuple(localNormal)
 	// worldNormal.w = 0.0
 	// return worldNormal.Normalize()
@@ -757,7 +482,7 @@ func (cone *Cone) NormalAt(worldPoint *Tuple, intersection *Intersection) *Tuple
 	return NormalAt(cone, worldPoint, intersection)
 }
 
-func (cone *Cone) intersectCaps(localRay *Ray, xs Intersections) Intersections {
+func (cone *Cone) intersectCaps(localRay Ray, xs Intersections) Intersections {
 
 	// Caps only matter if the cone is closed, and might possibly be intersected by the ray.
 	if !cone.closed || math.Abs(localRay.direction.y) < EPSILON {
@@ -782,7 +507,7 @@ func (cone *Cone) intersectCaps(localRay *Ray, xs Intersections) Intersections {
 
 // checkCap for cone: the radius of a cone will change with y.
 // In fact, a cone’s radius at any given y will be the absolute value of that y.
-func (cone *Cone) checkCap(localRay *Ray, t float64, minMaxY float64) bool {
+func (cone *Cone) checkCap(localRay Ray, t float64, minMaxY float64) bool {
 	x := localRay.origin.x + t*localRay.direction.x
 	z := localRay.origin.z + t*localRay.direction.z
 	return math.Pow(x, 2)+math.Pow(z, 2) <= math.Abs(minMaxY)
@@ -790,161 +515,128 @@ func (cone *Cone) checkCap(localRay *Ray, t float64, minMaxY float64) bool {
 
 // Triangle struct.
 type Triangle struct {
-	p1       *Tuple
-	p2       *Tuple
-	p3       *Tuple
-	e1       *Tuple
-	e2       *Tuple
-	normal   *Tuple
-	n1       *Tuple
-	n2       *Tuple
-	n3       *Tuple
-	material *Material
+	BaseShape
+	p1     Tuple
+	p2     Tuple
+	p3     Tuple
+	e1     Tuple
+	e2     Tuple
+	normal Tuple
+	n1     Tuple
+	n2     Tuple
+	n3     Tuple
 }
 
 // NewTriangle returns a *Triangle with precomputed normal vector.
-func NewTriangle(p1, p2, p3 *Tuple) *Triangle {
+func NewTriangle(p1, p2, p3 Tuple) *Triangle {
 
 	e1 := p2.Substract(p1)
 	e2 := p3.Substract(p1)
 	n := e2.CrossProduct(e1).Normalize()
 
+	// Triangles are plain geometry shared by the OBJ data, they are not given a random id.
+	base := NewBaseShape()
+	base.id = 0
+
 	return &Triangle{
-		p1:       p1,
-		p2:       p2,
-		p3:       p3,
-		e1:       e1,
-		e2:       e2,
-		normal:   n,
-		material: DefaultMaterial(),
+		BaseShape: base,
+		p1:        p1,
+		p2:        p2,
+		p3:        p3,
+		e1:        e1,
+		e2:        e2,
+		normal:    n,
 	}
 }
 
-// localNormalAt will return the precomputed normal from the *Triangle.
-func (triangle *Triangle) localNormalAt(localPoint *Tuple, intersection *Intersection) *Tuple {
+// LocalNormalAt will return the precomputed normal from the *Triangle.
+func (triangle *Triangle) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
 
 	return triangle.normal
 }
 
+// LocalBounds returns the bounding box containing the three vertices of the *Triangle.
+func (triangle *Triangle) LocalBounds() *BoundingBox {
+	boundingBox := NewEmptyBoundingBox()
+	boundingBox.Add(triangle.p1)
+	boundingBox.Add(triangle.p2)
+	boundingBox.Add(triangle.p3)
+	return boundingBox
+}
+
 // NormalAt calculates the local normal (vector perpendicular to the surface) at a given point of the object.
-func (triangle *Triangle) NormalAt(worldPoint *Tuple, intersection *Intersection) *Tuple {
+func (triangle *Triangle) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
 
 	// Use group NormalAt which take into account transformations on both the child object and the parent(s).
 	return NormalAt(triangle, worldPoint, intersection)
 }
 
-// Intersect calculates the local intersections between a ray and a Triangle.
-func (triangle *Triangle) localIntersect(localRay *Ray) []*Intersection {
+// LocalIntersect calculates the local intersections between a ray and a Triangle.
+func (triangle *Triangle) LocalIntersect(localRay Ray, xs Intersections) Intersections {
 
 	dirCrossE2 := localRay.direction.CrossProduct(triangle.e2)
 	determinant := triangle.e1.DotProduct(dirCrossE2)
 	if math.Abs(determinant) < EPSILON {
-		return []*Intersection{}
+		return xs
 	}
 
 	f := 1.0 / determinant
 	p1ToOrigin := localRay.origin.Substract(triangle.p1)
 	u := f * p1ToOrigin.DotProduct(dirCrossE2)
 	if u < 0 || u > 1 {
-		return []*Intersection{}
+		return xs
 	}
 
 	originCrossE1 := p1ToOrigin.CrossProduct(triangle.e1)
 	v := f * localRay.direction.DotProduct(originCrossE1)
 	if v < 0 || (u+v) > 1 {
-		return []*Intersection{}
+		return xs
 	}
 
 	t := f * triangle.e2.DotProduct(originCrossE1)
-	return []*Intersection{
-		&Intersection{
-			t:      t,
-			object: triangle,
-		},
-	}
-}
-
-// GetID returns the id of the shape.
-func (triangle *Triangle) GetID() int {
-	panic("GetID() is not applicable to a Triangle shape.")
-}
-
-// Material returns the material of a Sphere.
-func (triangle *Triangle) Material() *Material {
-	return triangle.material
-}
-
-// SetTransform sets the shape's transformation.
-func (triangle *Triangle) SetTransform(transformation Matrix) {
-	panic("SetTransform() is not applicable to a Triangle shape.")
-}
-
-// SetMaterial sets the shape's material.
-func (triangle *Triangle) SetMaterial(material *Material) {
-	triangle.material = material
-}
-
-//Transform returns the transformation.
-func (triangle *Triangle) Transform() Matrix {
-	return IdentityMatrix
-}
-
-// GetInverse returns the cached inverse matrix of the current Shape.
-func (triangle *Triangle) GetInverse() Matrix {
-	return IdentityMatrix
-}
-
-// GetInverseTranspose returns the cached inverseTranspose matrix of the current Shape.
-func (triangle *Triangle) GetInverseTranspose() Matrix {
-	return IdentityMatrix
-}
-
-// GetParent gets the parent shape from this current shape.
-func (triangle *Triangle) GetParent() Shape {
-	return nil
-}
-
-// SetParent does not apply for *Triangle since the groups are defined in the wavefront OBJ data.
-func (triangle *Triangle) SetParent(shape Shape) {
+	return append(xs, NewIntersection(t, triangle))
 }
 
 // Intersect calculates the local intersections between a ray and a Triangle.
-func (triangle *Triangle) Intersect(worldRay *Ray) []*Intersection {
+func (triangle *Triangle) Intersect(worldRay Ray, xs Intersections) Intersections {
 
-	localRay := worldRay.Transform(triangle.GetInverse())
-	return triangle.localIntersect(localRay)
+	return Intersect(triangle, worldRay, xs)
 }
 
 type smoothTriangle struct {
-	p1       *Tuple
-	p2       *Tuple
-	p3       *Tuple
-	normal   *Tuple
-	n1       *Tuple
-	n2       *Tuple
-	n3       *Tuple
-	e1       *Tuple
-	e2       *Tuple
-	material *Material
+	BaseShape
+	p1     Tuple
+	p2     Tuple
+	p3     Tuple
+	normal Tuple
+	n1     Tuple
+	n2     Tuple
+	n3     Tuple
+	e1     Tuple
+	e2     Tuple
 }
 
-func newSmoothTriangle(p1 *Tuple, p2 *Tuple, p3 *Tuple, n1 *Tuple, n2 *Tuple, n3 *Tuple) *smoothTriangle {
+func newSmoothTriangle(p1 Tuple, p2 Tuple, p3 Tuple, n1 Tuple, n2 Tuple, n3 Tuple) *smoothTriangle {
 
 	e1 := p2.Substract(p1)
 	e2 := p3.Substract(p1)
 	n := e2.CrossProduct(e1).Normalize()
 
+	// Triangles are plain geometry shared by the OBJ data, they are not given a random id.
+	base := NewBaseShape()
+	base.id = 0
+
 	return &smoothTriangle{
-		p1:       p1,
-		p2:       p2,
-		p3:       p3,
-		normal:   n,
-		n1:       n1,
-		n2:       n2,
-		n3:       n3,
-		e1:       e1,
-		e2:       e2,
-		material: DefaultMaterial(),
+		BaseShape: base,
+		p1:        p1,
+		p2:        p2,
+		p3:        p3,
+		normal:    n,
+		n1:        n1,
+		n2:        n2,
+		n3:        n3,
+		e1:        e1,
+		e2:        e2,
 	}
 }
 
@@ -958,131 +650,80 @@ func defaultSmoothTriangle() *smoothTriangle {
 		Vector(1, 0, 0))
 }
 
-// GetID returns the id of the shape.
-func (smoothTriangle *smoothTriangle) GetID() int {
-	panic("GetID() is not applicable to a smoothTriangle shape.")
-}
-
-// localNormalAt will return the precomputed normal from the *smoothTriangle.
-func (smoothTriangle *smoothTriangle) localNormalAt(localPoint *Tuple, intersection *Intersection) *Tuple {
+// LocalNormalAt will interpolate the vertex normals of the *smoothTriangle at the intersection's u and v.
+func (smoothTriangle *smoothTriangle) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
 
 	return smoothTriangle.n2.Multiply(intersection.u).
 		Add(smoothTriangle.n3.Multiply(intersection.v)).
 		Add(smoothTriangle.n1.Multiply(1 - intersection.u - intersection.v))
 }
 
+// LocalBounds returns the bounding box containing the three vertices of the *smoothTriangle.
+func (smoothTriangle *smoothTriangle) LocalBounds() *BoundingBox {
+	boundingBox := NewEmptyBoundingBox()
+	boundingBox.Add(smoothTriangle.p1)
+	boundingBox.Add(smoothTriangle.p2)
+	boundingBox.Add(smoothTriangle.p3)
+	return boundingBox
+}
+
 // NormalAt calculates the local normal (vector perpendicular to the surface) at a given point of the object.
-func (smoothTriangle *smoothTriangle) NormalAt(worldPoint *Tuple, intersection *Intersection) *Tuple {
+func (smoothTriangle *smoothTriangle) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
 
 	// Use group NormalAt which take into account transformations on both the child object and the parent(s).
 	return NormalAt(smoothTriangle, worldPoint, intersection)
 }
 
-// Intersect calculates the local intersections between a ray and a smoothTriangle.
-func (smoothTriangle *smoothTriangle) localIntersect(localRay *Ray) []*Intersection {
+// LocalIntersect calculates the local intersections between a ray and a smoothTriangle.
+func (smoothTriangle *smoothTriangle) LocalIntersect(localRay Ray, xs Intersections) Intersections {
 
 	dirCrossE2 := localRay.direction.CrossProduct(smoothTriangle.e2)
 	determinant := smoothTriangle.e1.DotProduct(dirCrossE2)
 	if math.Abs(determinant) < EPSILON {
-		return []*Intersection{}
+		return xs
 	}
 
 	f := 1.0 / determinant
 	p1ToOrigin := localRay.origin.Substract(smoothTriangle.p1)
 	u := f * p1ToOrigin.DotProduct(dirCrossE2)
 	if u < 0 || u > 1 {
-		return []*Intersection{}
+		return xs
 	}
 
 	originCrossE1 := p1ToOrigin.CrossProduct(smoothTriangle.e1)
 	v := f * localRay.direction.DotProduct(originCrossE1)
 	if v < 0 || (u+v) > 1 {
-		return []*Intersection{}
+		return xs
 	}
 
 	t := f * smoothTriangle.e2.DotProduct(originCrossE1)
-	return []*Intersection{
-		&Intersection{
-			t:      t,
-			object: smoothTriangle,
-			u:      u,
-			v:      v,
-		},
-	}
-}
-
-// Material returns the material of a smoothTriangle.
-func (smoothTriangle *smoothTriangle) Material() *Material {
-	return smoothTriangle.material
-}
-
-// SetTransform sets the shape's transformation.
-func (smoothTriangle *smoothTriangle) SetTransform(transformation Matrix) {
-	panic("SetTransform() is not applicable to a Triangle shape.")
-}
-
-// SetMaterial sets the shape's material.
-func (smoothTriangle *smoothTriangle) SetMaterial(material *Material) {
-	smoothTriangle.material = material
-}
-
-//Transform returns the transformation.
-func (smoothTriangle *smoothTriangle) Transform() Matrix {
-	return IdentityMatrix
-}
-
-// GetInverse returns the cached inverse matrix of the current Shape.
-func (smoothTriangle *smoothTriangle) GetInverse() Matrix {
-	return IdentityMatrix
-}
-
-// GetInverseTranspose returns the cached inverseTranspose matrix of the current Shape.
-func (smoothTriangle *smoothTriangle) GetInverseTranspose() Matrix {
-	return IdentityMatrix
-}
-
-// GetParent gets the parent shape from this current shape.
-func (smoothTriangle *smoothTriangle) GetParent() Shape {
-	return nil
-}
-
-// SetParent does not apply for *Triangle since the groups are defined in the wavefront OBJ data.
-func (smoothTriangle *smoothTriangle) SetParent(shape Shape) {
+	return append(xs, NewIntersectionUV(t, smoothTriangle, u, v))
 }
 
 // Intersect calculates the local intersections between a ray and a smoothTriangle.
-func (smoothTriangle *smoothTriangle) Intersect(worldRay *Ray) []*Intersection {
+func (smoothTriangle *smoothTriangle) Intersect(worldRay Ray, xs Intersections) Intersections {
 
-	localRay := worldRay.Transform(smoothTriangle.GetInverse())
-	return smoothTriangle.localIntersect(localRay)
+	return Intersect(smoothTriangle, worldRay, xs)
 }
 
 // CSG represents a constructive solid geometry structure.
 type CSG struct {
-	id               int
-	transform        Matrix
-	inverse          Matrix
-	inverseTranspose Matrix
-	left             Shape
-	right            Shape
-	operation        string
-	parent           Shape
-	material         *Material
-	BoundingBox      *BoundingBox
-	savedRayLeft     *Ray
-	savedRayRight    *Ray
+	BaseShape
+	left          Shape
+	right         Shape
+	operation     string
+	BoundingBox   *BoundingBox
+	savedRayLeft  Ray
+	savedRayRight Ray
 }
 
 // NewCSG returns a new *CSG with default values.
 func NewCSG(operation string, left, right Shape) *CSG {
 	c := &CSG{
-		id:            rand.Int(),
-		transform:     IdentityMatrix,
-		inverse:       IdentityMatrix,
+		BaseShape:     NewBaseShape(),
 		left:          left,
 		right:         right,
 		operation:     operation,
-		material:      DefaultMaterial(),
 		savedRayLeft:  NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
 		savedRayRight: NewRay(Point(0, 0, 0), Vector(0, 0, 0)),
 		BoundingBox:   NewEmptyBoundingBox(),
@@ -1092,93 +733,51 @@ func NewCSG(operation string, left, right Shape) *CSG {
 	return c
 }
 
-// GetID returns the id of the shape.
-func (csg *CSG) GetID() int {
-	panic("GetID() is not applicable to a smoothTriangle shape.")
-}
-
-// localNormalAt will return the precomputed normal from the *CSG.
-func (csg *CSG) localNormalAt(localPoint *Tuple, intersection *Intersection) *Tuple {
+// LocalNormalAt is not applicable to a CSG, normals are computed by the concrete child shapes.
+func (csg *CSG) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
 
 	panic("not applicable to CSG.")
 }
 
+// LocalBounds combines the bounds of both children, converted into the CSG's space.
+func (csg *CSG) LocalBounds() *BoundingBox {
+	box := NewEmptyBoundingBox()
+	box.Merge(ParentSpaceBounds(csg.left))
+	box.Merge(ParentSpaceBounds(csg.right))
+	return box
+}
+
 // NormalAt calculates the local normal (vector perpendicular to the surface) at a given point of the object.
-func (csg *CSG) NormalAt(worldPoint *Tuple, intersection *Intersection) *Tuple {
+func (csg *CSG) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
 
 	panic("not applicable to CSG.")
 }
 
-// Intersect calculates the local intersections between a ray and a CSG.
-func (csg *CSG) localIntersect(localRay *Ray) []*Intersection {
+// LocalIntersect calculates the local intersections between a ray and a CSG.
+func (csg *CSG) LocalIntersect(localRay Ray, xs Intersections) Intersections {
 
 	if !IntersectRayWithBox(localRay, csg.BoundingBox) {
-		return nil
+		return xs
 	}
 
 	csg.savedRayLeft = localRay
 	csg.savedRayRight = localRay
 
-	leftXs := csg.left.Intersect(localRay)
-	rightXs := csg.right.Intersect(localRay)
-	xs := append(leftXs, rightXs...)
-
-	sort.Slice(
-		xs,
-		func(i, j int) bool {
-			return xs[i].t < xs[j].t
-		},
-	)
-	return FilterIntersections(csg, xs)
-}
-
-// Material returns the material of a CSG.
-func (csg *CSG) Material() *Material {
-	return csg.material
-}
-
-// SetTransform sets the shape's transformation.
-func (csg *CSG) SetTransform(transformation Matrix) {
-	csg.transform = csg.transform.MultiplyMatrix(transformation)
-	csg.inverse = csg.transform.Inverse()
-	csg.inverseTranspose = csg.inverse.Transpose()
-}
-
-// SetMaterial sets the shape's material.
-func (csg *CSG) SetMaterial(material *Material) {
-	csg.material = material
-}
-
-//Transform returns the transformation.
-func (csg *CSG) Transform() Matrix {
-	return csg.transform
-}
-
-// GetInverse returns the cached inverse matrix of the current Shape.
-func (csg *CSG) GetInverse() Matrix {
-	return csg.inverse
-}
-
-// GetInverseTranspose returns the cached inverseTranspose matrix of the current Shape.
-func (csg *CSG) GetInverseTranspose() Matrix {
-	return csg.inverseTranspose
-}
-
-// GetParent gets the parent shape from this current shape.
-func (csg *CSG) GetParent() Shape {
-	return csg.parent
-}
+	// Only the intersections appended by the children are sorted and filtered,
+	// whatever was already in xs belongs to the caller.
+	start := len(xs)
+	xs = csg.left.Intersect(localRay, xs)
+	xs = csg.right.Intersect(localRay, xs)
 
-// SetParent does not apply for *CSG.
-func (csg *CSG) SetParent(shape Shape) {
-	csg.parent = shape
+	children := xs[start:]
+	children.Sort()
+	return xs[:start+len(filterIntersections(csg, children, children[:0]))]
 }
 
 // Intersect calculates the local intersections between a ray and a CSG.
-func (csg *CSG) Intersect(worldRay *Ray) []*Intersection {
+func (csg *CSG) Intersect(worldRay Ray, xs Intersections) Intersections {
 
-	localRay := worldRay.Transform(csg.GetInverse())
-	return csg.localIntersect(localRay)
+	return Intersect(csg, worldRay, xs)
 }
 
 // Bounds calculates de boundingBox of the CSG taking in considerantion of the group's children.
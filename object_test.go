@@ -9,21 +9,21 @@ func TestSphereNormal(t *testing.T) {
 
 	// The normal on a sphere at a point on the x axis.
 	s := NewSphere()
-	n := s.localNormalAt(Point(1, 0, 0), nil)
+	n := s.LocalNormalAt(Point(1, 0, 0), nil)
 	expected := Vector(1, 0, 0)
 	if !n.Equals(expected) {
 		t.Errorf("SphereNormal: expected %v to be %v", n, expected)
 	}
 
 	// The normal on a sphere at a point on the y axis.
-	n = s.localNormalAt(Point(0, 1, 0), nil)
+	n = s.LocalNormalAt(Point(0, 1, 0), nil)
 	expected = Vector(0, 1, 0)
 	if !n.Equals(expected) {
 		t.Errorf("SphereNormal: expected %v to be %v", n, expected)
 	}
 
 	// The normal on a sphere at a point on the z axis.
-	n = s.localNormalAt(Point(0, 0, 1), nil)
+	n = s.LocalNormalAt(Point(0, 0, 1), nil)
 	expected = Vector(0, 0, 1)
 	if !n.Equals(expected) {
 		t.Errorf("SphereNormal: expected %v to be %v", n, expected)
@@ -31,7 +31,7 @@ func TestSphereNormal(t *testing.T) {
 
 	// The normal on a sphere at a nonaxial point.
 	v := math.Sqrt(3) / 3
-	n = s.localNormalAt(Point(v, v, v), nil)
+	n = s.LocalNormalAt(Point(v, v, v), nil)
 	expected = Vector(v, v, v)
 	if !n.Equals(expected) {
 		t.Errorf("SphereNormal: expected %v to be %v", n, expected)
@@ -59,9 +59,9 @@ func TestSphereNormal(t *testing.T) {
 // The normal of a plane is constant everywhere
 func TestPlaneNormal(t *testing.T) {
 	p := NewPlane()
-	n1 := p.localNormalAt(Point(0, 0, 0), nil)
-	n2 := p.localNormalAt(Point(10, 0, -10), nil)
-	n3 := p.localNormalAt(Point(-5, 0, 150), nil)
+	n1 := p.LocalNormalAt(Point(0, 0, 0), nil)
+	n2 := p.LocalNormalAt(Point(10, 0, -10), nil)
+	n3 := p.LocalNormalAt(Point(-5, 0, 150), nil)
 	expected := Vector(0, 1, 0)
 
 	if !n1.Equals(expected) {
@@ -78,7 +78,7 @@ func TestPlaneNormal(t *testing.T) {
 func TestCubeNormal(t *testing.T) {
 	// The normal on the surface of a cube
 	type cubeTest struct {
-		point, normal *Tuple
+		point, normal Tuple
 	}
 	c := NewCube()
 	expectedNormals := []*cubeTest{
@@ -93,7 +93,7 @@ func TestCubeNormal(t *testing.T) {
 	}
 
 	for _, v := range expectedNormals {
-		n := c.localNormalAt(v.point, nil)
+		n := c.LocalNormalAt(v.point, nil)
 
 		if !n.Equals(v.normal) {
 			t.Errorf("The normal on the surface of a cube, got: %v and expected to be %v", n, v.normal)
@@ -105,7 +105,7 @@ func TestCylinderNormal(t *testing.T) {
 	// Normal vector on a cylinder.
 
 	type cylindertest struct {
-		point, normal *Tuple
+		point, normal Tuple
 	}
 
 	c := NewCylinder()
@@ -118,7 +118,7 @@ func TestCylinderNormal(t *testing.T) {
 	}
 
 	for _, v := range expectedNormals {
-		n := c.localNormalAt(v.point, nil)
+		n := c.LocalNormalAt(v.point, nil)
 
 		if !n.Equals(v.normal) {
 			t.Errorf("Normal vector on a cylinder, got: %v and expected to be %v", n, v.normal)
@@ -154,7 +154,7 @@ func TestCylinderEndCapsNormal(t *testing.T) {
 	// The normal vector on a cylinder's end caps.
 
 	type cylindertest struct {
-		point, normal *Tuple
+		point, normal Tuple
 	}
 
 	c := NewCylinder()
@@ -172,7 +172,7 @@ func TestCylinderEndCapsNormal(t *testing.T) {
 	}
 
 	for _, v := range expectedNormals {
-		n := c.localNormalAt(v.point, nil)
+		n := c.LocalNormalAt(v.point, nil)
 
 		if !n.Equals(v.normal) {
 			t.Errorf("The normal vector on a cylinder's end caps, got: %v and expected to be %v", n, v.normal)
@@ -184,7 +184,7 @@ func TestConeNormal(t *testing.T) {
 	// Computing the normal vector on a cone.
 
 	type cylindertest struct {
-		point, normal *Tuple
+		point, normal Tuple
 	}
 
 	c := NewCone()
@@ -196,7 +196,7 @@ func TestConeNormal(t *testing.T) {
 	}
 
 	for _, v := range expectedNormals {
-		n := c.localNormalAt(v.point, nil)
+		n := c.LocalNormalAt(v.point, nil)
 
 		if !n.Equals(v.normal) {
 			t.Errorf("Computing the normal vector on a cone, got: %v and expected to be %v", n, v.normal)
@@ -240,9 +240,9 @@ func TestTriangleNormal(t *testing.T) {
 	// Finding the normal on a triangle.
 
 	triangle := NewTriangle(Point(0, 1, 0), Point(-1, 0, 0), Point(1, 0, 0))
-	n1 := triangle.localNormalAt(Point(0, 0.5, 0), nil)
-	n2 := triangle.localNormalAt(Point(-0.5, 0.75, 0), nil)
-	n3 := triangle.localNormalAt(Point(0.5, 0.25, 0), nil)
+	n1 := triangle.LocalNormalAt(Point(0, 0.5, 0), nil)
+	n2 := triangle.LocalNormalAt(Point(-0.5, 0.75, 0), nil)
+	n3 := triangle.LocalNormalAt(Point(0.5, 0.25, 0), nil)
 
 	expectedNormal := triangle.normal
 
@@ -308,7 +308,7 @@ func TestIntersectWithTriStoresUV(t *testing.T) {
 	// An intersection with a smooth triangle stores u/v.
 	smoothTriangle := defaultSmoothTriangle()
 	r := NewRay(Point(-0.2, 0.3, -2), Vector(0, 0, 1))
-	xs := smoothTriangle.localIntersect(r)
+	xs := smoothTriangle.LocalIntersect(r, nil)
 
 	expectedU := 0.45
 	expectedV := 0.25
@@ -325,7 +325,7 @@ func TestInterpolatedNormal(t *testing.T) {
 	// A smooth triangle uses u/v to interpolate the normal.
 	smoothTriangle := defaultSmoothTriangle()
 	i := NewIntersectionUV(1, smoothTriangle, 0.45, 0.25)
-	n := NormalAt(smoothTriangle, Point(0, 0, 0), i)
+	n := NormalAt(smoothTriangle, Point(0, 0, 0), &i)
 
 	expectedVector := Vector(-0.5547, 0.83205, 0)
 
@@ -339,8 +339,8 @@ func TestPrepareNormalOnSmoothTri(t *testing.T) {
 	smoothTriangle := defaultSmoothTriangle()
 	i := NewIntersectionUV(1.0, smoothTriangle, 0.45, 0.25)
 	r := NewRay(Point(-0.2, 0.3, -2), Vector(0, 0, 1))
-	xs := []*Intersection{i}
-	comps := PrepareComputations(i, r, xs)
+	xs := Intersections{i}
+	comps := PrepareComputations(&i, r, xs)
 
 	expectedVector := Vector(-0.5547, 0.83205, 0)
 
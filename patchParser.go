@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// parseBezierPatchData returns the patches of data in the format of the Newell teapot: the number
+// of patches, then the 1-based indices of the 16 control points of each patch, then the number of
+// control points and their x, y and z coordinates. Numbers are separated by commas or spaces.
+func parseBezierPatchData(data string) ([]*BezierPatch, error) {
+	fields := strings.FieldsFunc(data, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	next := func() (float64, error) {
+		if len(fields) == 0 {
+			return 0, errors.New("patch data is truncated")
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		fields = fields[1:]
+		return value, err
+	}
+	// count reads a number of items made of size numbers each, which must all follow it.
+	count := func(name string, size int) (int, error) {
+		value, err := next()
+		if err != nil {
+			return 0, err
+		}
+		if value < 0 || value != math.Trunc(value) {
+			return 0, fmt.Errorf("patch data has an invalid %v count: %v", name, value)
+		}
+		if value*float64(size) > float64(len(fields)) {
+			return 0, fmt.Errorf("patch data is truncated: %v %vs don't fit in the remaining data", value, name)
+		}
+		return int(value), nil
+	}
+
+	patchCount, err := count("patch", 16)
+	if err != nil {
+		return nil, err
+	}
+	indices := make([]int, 16*patchCount)
+	for i := range indices {
+		index, err := next()
+		if err != nil {
+			return nil, err
+		}
+		indices[i] = int(index) - 1
+	}
+
+	pointCount, err := count("point", 3)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]Tuple, pointCount)
+	for i := range points {
+		var coordinates [3]float64
+		for j := range coordinates {
+			if coordinates[j], err = next(); err != nil {
+				return nil, err
+			}
+		}
+		points[i] = Point(coordinates[0], coordinates[1], coordinates[2])
+	}
+
+	patches := make([]*BezierPatch, patchCount)
+	for i := range patches {
+		var cp [16]Tuple
+		for j := range cp {
+			index := indices[16*i+j]
+			if index < 0 || index >= len(points) {
+				return nil, errors.New("patch data has an index out of range: " + strconv.Itoa(index+1))
+			}
+			cp[j] = points[index]
+		}
+		patches[i] = NewBezierPatch(cp)
+	}
+	return patches, nil
+}
@@ -2,20 +2,26 @@ package main
 
 import "math"
 
-type getColorFunc func(*Canvas, []*Color, *Tuple) *Color
+type getColorFunc func(*Canvas, []Color, Tuple) Color
 
 // Pattern struct.
+// transforms holds the inverse transformation of every chained pattern, the first one being
+// the inverse of transform, which is edited through SetTransform and the other transform methods.
 type Pattern struct {
-	colors     [][]*Color
+	colors     [][]Color
 	funcs      []getColorFunc
-	transforms []Matrix
+	transforms []Matrix4
 	canvas     *Canvas
+	transform  Matrix4
+	trs        TRS
+	// uvPattern is read at the texture coordinates of the shapes that carry some, see uvSurfacePattern.
+	uvPattern patternType
 }
 
 // ColorAtObject calculates the end color based on a worldPoint of the object.
 // Allows patterns to be transformed by converting points from world space to object space,
 // and from there to pattern space, before computing the color. Added support for parent grouping.
-func (pattern *Pattern) ColorAtObject(object Shape, worldPoint *Tuple) *Color {
+func (pattern *Pattern) ColorAtObject(object Shape, worldPoint Tuple) Color {
 	// objectPoint := object.Transform().MultiplyMatrixByTuple(worldPoint)
 	objectPoint := WorldToObject(object, worldPoint)
 	// patternPoint := pattern.transform.MultiplyMatrixByTuple(objectPoint)
@@ -25,7 +31,7 @@ func (pattern *Pattern) ColorAtObject(object Shape, worldPoint *Tuple) *Color {
 }
 
 // ColorAt returns a reference to Color at a specific point in world space of the pattern.
-func (pattern *Pattern) ColorAt(p *Tuple) *Color {
+func (pattern *Pattern) ColorAt(p Tuple) Color {
 	color := Black
 
 	// Final color at a specific point by applying individual transformations and patterns.
@@ -37,33 +43,89 @@ func (pattern *Pattern) ColorAt(p *Tuple) *Color {
 }
 
 // StripePattern creates a new stripe patter using the stripeFunc().
-func StripePattern(colors ...*Color) *Pattern {
+func StripePattern(colors ...Color) *Pattern {
 
-	return NewPattern(NewCanvas(0, 0), [][]*Color{colors}, stripeFunc)
+	return NewPattern(NewCanvas(0, 0), [][]Color{colors}, stripeFunc)
 }
 
 // NewPattern returns a reference to a Pattern struct with a pattern generating function.
-func NewPattern(canvas *Canvas, colors [][]*Color, getColor ...getColorFunc) *Pattern {
-	return &Pattern{colors, getColor, []Matrix{NewIdentityMatrix()}, canvas}
+func NewPattern(canvas *Canvas, colors [][]Color, getColor ...getColorFunc) *Pattern {
+	return &Pattern{colors, getColor, []Matrix4{NewIdentityMatrix()}, canvas, NewIdentityMatrix(), NewTRS(), nil}
 }
 
 // stripeFunc defines the stripe pattern.
-func stripeFunc(_ *Canvas, colors []*Color, p *Tuple) *Color {
+func stripeFunc(_ *Canvas, colors []Color, p Tuple) Color {
 	return colors[(int(math.Abs(p.x)))%len(colors)]
 }
 
-// SetTransform sets the transform for the pattern accordingly.
-func (pattern *Pattern) SetTransform(transform Matrix) {
+// SetTransform replaces the transform for the pattern accordingly.
+// The translation, rotation and scale components are recovered from the new transformation.
+func (pattern *Pattern) SetTransform(transform Matrix4) {
+	pattern.trs = TRSFromMatrix(transform)
+	pattern.updateTransform(transform)
+}
+
+// ApplyTransform composes the transformation with the current one of the pattern.
+func (pattern *Pattern) ApplyTransform(transform Matrix4) {
+	pattern.SetTransform(pattern.transform.MultiplyMatrix(transform))
+}
+
+// ResetTransform sets the pattern's transformation back to the identity.
+func (pattern *Pattern) ResetTransform() {
+	pattern.SetTransform(IdentityMatrix)
+}
+
+// SetTranslation replaces the translation component and rebuilds the pattern's transformation from its components.
+func (pattern *Pattern) SetTranslation(x, y, z float64) {
+	pattern.trs.translation = Vector(x, y, z)
+	pattern.updateTransform(pattern.trs.Matrix())
+}
+
+// SetRotation replaces the rotation component and rebuilds the pattern's transformation from its components.
+func (pattern *Pattern) SetRotation(x, y, z float64) {
+	pattern.trs.rotation = Vector(x, y, z)
+	pattern.updateTransform(pattern.trs.Matrix())
+}
+
+// SetScale replaces the scale component and rebuilds the pattern's transformation from its components.
+func (pattern *Pattern) SetScale(x, y, z float64) {
+	pattern.trs.scale = Vector(x, y, z)
+	pattern.updateTransform(pattern.trs.Matrix())
+}
+
+// GetTranslation returns the translation component of the pattern.
+func (pattern *Pattern) GetTranslation() Tuple {
+	return pattern.trs.translation
+}
+
+// GetRotation returns the rotation component of the pattern.
+func (pattern *Pattern) GetRotation() Tuple {
+	return pattern.trs.rotation
+}
+
+// GetScale returns the scale component of the pattern.
+func (pattern *Pattern) GetScale() Tuple {
+	return pattern.trs.scale
+}
+
+// Transform returns the transformation of the pattern.
+func (pattern *Pattern) Transform() Matrix4 {
+	return pattern.transform
+}
+
+// updateTransform sets the transformation of the pattern and caches its inverse.
+func (pattern *Pattern) updateTransform(transform Matrix4) {
+	pattern.transform = transform
 	pattern.transforms[0] = transform.Inverse()
 }
 
 // CheckersPattern creates a new checker pattern using the checkersFunc().
-func CheckersPattern(a, b *Color) *Pattern {
-	return NewPattern(NewCanvas(0, 0), [][]*Color{[]*Color{a, b}}, checkersFunc)
+func CheckersPattern(a, b Color) *Pattern {
+	return NewPattern(NewCanvas(0, 0), [][]Color{[]Color{a, b}}, checkersFunc)
 }
 
 // checkersFunc defines the checkers pattern.
-var checkersFunc = func(_ *Canvas, colors []*Color, p *Tuple) *Color {
+var checkersFunc = func(_ *Canvas, colors []Color, p Tuple) Color {
 	if (int(p.x)+int(p.y)+int(p.z))%2 == 0 {
 		return colors[0]
 	}
@@ -71,7 +133,7 @@ var checkersFunc = func(_ *Canvas, colors []*Color, p *Tuple) *Color {
 }
 
 // gradientFunc defines a gradient pattern.
-func gradientFunc(_ *Canvas, colors []*Color, p *Tuple) *Color {
+func gradientFunc(_ *Canvas, colors []Color, p Tuple) Color {
 	dist := colors[1].Subtract(colors[0])
 	frac := p.x - math.Floor(p.x)
 
@@ -79,15 +141,15 @@ func gradientFunc(_ *Canvas, colors []*Color, p *Tuple) *Color {
 }
 
 // GradientPattern creates a new gradient pattern using the gradientFunc().
-func GradientPattern(a, b *Color) *Pattern {
-	return NewPattern(NewCanvas(0, 0), [][]*Color{[]*Color{a, b}}, gradientFunc)
+func GradientPattern(a, b Color) *Pattern {
+	return NewPattern(NewCanvas(0, 0), [][]Color{[]Color{a, b}}, gradientFunc)
 }
 
 // PatternChain chains patterns together. For now it will apply the canvas from the last pattern as valid image mapping.
 func PatternChain(patterns ...*Pattern) *Pattern {
-	colors := [][]*Color{}
+	colors := [][]Color{}
 	funcs := []getColorFunc{}
-	transforms := []Matrix{}
+	transforms := []Matrix4{}
 	canvas := NewCanvas(0, 0)
 	for _, p := range patterns {
 		for _, cs := range p.colors {
@@ -27,8 +27,8 @@ func TestLightingWithPattern(t *testing.T) {
 func TestCheckersPattern(t *testing.T) {
 	pattern := CheckersPattern(White, Black)
 
-	points := []*Tuple{Point(0, 0, 0), Point(.99, 0, 0), Point(1.01, 0, 0), Point(0, .99, 0), Point(0, 1.01, 0), Point(0, 0, .99), Point(0, 0, 1.01)}
-	expected := []*Color{White, White, Black, White, Black, White, Black}
+	points := []Tuple{Point(0, 0, 0), Point(.99, 0, 0), Point(1.01, 0, 0), Point(0, .99, 0), Point(0, 1.01, 0), Point(0, 0, .99), Point(0, 0, 1.01)}
+	expected := []Color{White, White, Black, White, Black, White, Black}
 
 	for i := 0; i < len(expected); i++ {
 		r := pattern.ColorAt(points[i])
@@ -40,7 +40,7 @@ func TestCheckersPattern(t *testing.T) {
 
 func TestGradientPattern(t *testing.T) {
 	pattern := GradientPattern(White, Black)
-	expected := []*Color{White, NewColor(.75, .75, .75), NewColor(0.5, 0.5, 0.5), NewColor(.25, .25, .25)}
+	expected := []Color{White, NewColor(.75, .75, .75), NewColor(0.5, 0.5, 0.5), NewColor(.25, .25, .25)}
 
 	i := 0
 
@@ -52,3 +52,31 @@ func TestGradientPattern(t *testing.T) {
 		i++
 	}
 }
+
+func TestPatternTransformAPI(t *testing.T) {
+	// Patterns share the transform API of the shapes.
+	pattern := StripePattern(White, Black)
+	pattern.SetTransform(Translation(0.5, 0, 0))
+	pattern.SetTransform(Scaling(2, 2, 2))
+
+	if c := pattern.ColorAt(Point(1.5, 0, 0)); !c.Equals(White) {
+		t.Errorf("Pattern SetTransform: got %v, expected: %v", c, White)
+	}
+
+	pattern.ApplyTransform(Translation(0.5, 0, 0))
+	if c := pattern.ColorAt(Point(1.5, 0, 0)); !c.Equals(White) {
+		t.Errorf("Pattern ApplyTransform: got %v, expected: %v", c, White)
+	}
+	if c := pattern.ColorAt(Point(3.5, 0, 0)); !c.Equals(Black) {
+		t.Errorf("Pattern ApplyTransform: got %v, expected: %v", c, Black)
+	}
+
+	pattern.ResetTransform()
+	pattern.SetScale(2, 1, 1)
+	if !pattern.GetScale().Equals(Vector(2, 1, 1)) {
+		t.Errorf("Pattern scale component: got %v, expected: %v", pattern.GetScale(), Vector(2, 1, 1))
+	}
+	if c := pattern.ColorAt(Point(2.5, 0, 0)); !c.Equals(Black) {
+		t.Errorf("Pattern scale component: got %v, expected: %v", c, Black)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import "math"
+
+// polynomialEpsilon is the threshold under which the discriminants and coefficients of the
+// normalized polynomials below are considered to be zero.
+const polynomialEpsilon = 1e-12
+
+// solveQuadratic appends the real roots of a*x^2 + b*x + c = 0 to roots.
+// A double root is appended once, a discriminant slightly negative because of rounding errors
+// being taken as zero. The roots are computed without subtracting close values.
+func solveQuadratic(a, b, c float64, roots []float64) []float64 {
+	if a == 0 {
+		if b == 0 {
+			return roots
+		}
+		return append(roots, -c/b)
+	}
+
+	disc := b*b - 4*a*c
+	if disc < 0 {
+		if disc < -polynomialEpsilon*(b*b+math.Abs(4*a*c)) {
+			return roots
+		}
+		disc = 0
+	}
+	if disc == 0 {
+		return append(roots, -b/(2*a))
+	}
+
+	q := -0.5 * (b + math.Copysign(math.Sqrt(disc), b))
+	roots = append(roots, q/a)
+	if q != 0 {
+		roots = append(roots, c/q)
+	}
+	return roots
+}
+
+// solveCubic appends the real roots of a*x^3 + b*x^2 + c*x + d = 0 to roots, using Cardano's
+// formula for a single real root and the trigonometric form for three real roots.
+func solveCubic(a, b, c, d float64, roots []float64) []float64 {
+	if a == 0 {
+		return solveQuadratic(b, c, d, roots)
+	}
+
+	// Normal form x^3 + A*x^2 + B*x + C, then substitute x = y - A/3 to get y^3 + 3*p*y + 2*q.
+	A, B, C := b/a, c/a, d/a
+	sq := A * A
+	p := (-sq/3 + B) / 3
+	q := (2.0/27*A*sq - A*B/3 + C) / 2
+
+	p3 := p * p * p
+	disc := q*q + p3
+	start := len(roots)
+
+	switch {
+	case math.Abs(disc) < polynomialEpsilon:
+		if math.Abs(q) < polynomialEpsilon {
+			roots = append(roots, 0)
+		} else {
+			u := math.Cbrt(-q)
+			roots = append(roots, 2*u, -u)
+		}
+	case disc < 0:
+		phi := math.Acos(math.Max(-1, math.Min(1, -q/math.Sqrt(-p3)))) / 3
+		t := 2 * math.Sqrt(-p)
+		roots = append(roots, t*math.Cos(phi), -t*math.Cos(phi+math.Pi/3), -t*math.Cos(phi-math.Pi/3))
+	default:
+		sqrtDisc := math.Sqrt(disc)
+		roots = append(roots, math.Cbrt(sqrtDisc-q)-math.Cbrt(sqrtDisc+q))
+	}
+
+	for i := start; i < len(roots); i++ {
+		roots[i] -= A / 3
+	}
+	return roots
+}
+
+// solveQuartic appends the real roots of a*x^4 + b*x^3 + c*x^2 + d*x + e = 0 to roots.
+// Ferrari's method reduces the quartic to a resolvent cubic and two quadratics, then every root
+// is refined with Newton's method on the original polynomial, which removes most of the error
+// accumulated by the closed form when the coefficients have very different magnitudes.
+func solveQuartic(a, b, c, d, e float64, roots []float64) []float64 {
+	if a == 0 {
+		return solveCubic(b, c, d, e, roots)
+	}
+
+	// Normal form x^4 + A*x^3 + B*x^2 + C*x + D, then substitute x = y - A/4 to get y^4 + p*y^2 + q*y + r.
+	A, B, C, D := b/a, c/a, d/a, e/a
+	sq := A * A
+	p := -3.0/8*sq + B
+	q := 1.0/8*sq*A - 1.0/2*A*B + C
+	r := -3.0/256*sq*sq + 1.0/16*sq*B - 1.0/4*A*C + D
+	start := len(roots)
+
+	if math.Abs(r) < polynomialEpsilon {
+		// No absolute term: y * (y^3 + p*y + q) = 0.
+		roots = append(roots, 0)
+		roots = solveCubic(1, 0, p, q, roots)
+	} else {
+		// Any real root of the resolvent cubic splits the quartic in two quadratics.
+		var cubicArray [3]float64
+		cubic := solveCubic(1, -p/2, -r, r*p/2-q*q/8, cubicArray[:0])
+		if len(cubic) == 0 {
+			return roots
+		}
+		z := cubic[0]
+
+		u := z*z - r
+		v := 2*z - p
+		if math.Abs(u) < polynomialEpsilon {
+			u = 0
+		} else if u > 0 {
+			u = math.Sqrt(u)
+		} else {
+			return roots
+		}
+		if math.Abs(v) < polynomialEpsilon {
+			v = 0
+		} else if v > 0 {
+			v = math.Sqrt(v)
+		} else {
+			return roots
+		}
+
+		if q < 0 {
+			v = -v
+		}
+		roots = solveQuadratic(1, v, z-u, roots)
+		roots = solveQuadratic(1, -v, z+u, roots)
+	}
+
+	for i := start; i < len(roots); i++ {
+		x := roots[i] - A/4
+		for iteration := 0; iteration < 2; iteration++ {
+			f := (((a*x+b)*x+c)*x+d)*x + e
+			df := ((4*a*x+3*b)*x+2*c)*x + d
+			if df == 0 {
+				break
+			}
+			x -= f / df
+		}
+		roots[i] = x
+	}
+	return roots
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"sort"
+	"testing"
+)
+
+// The quartic solver finds the real roots of polynomials with distinct, double and complex roots.
+func TestSolveQuartic(t *testing.T) {
+	tests := []struct {
+		coefficients [5]float64
+		expected     []float64
+	}{
+		// (x-1)(x-2)(x-3)(x-4)
+		{[5]float64{1, -10, 35, -50, 24}, []float64{1, 2, 3, 4}},
+		// (x^2+1)(x-2)(x+5)
+		{[5]float64{1, 3, -9, 3, -10}, []float64{-5, 2}},
+		// 2(x-1)^2(x+3)(x-0.5)
+		{[5]float64{2, 1, -11, 11, -3}, []float64{-3, 0.5, 1, 1}},
+		// (x^2+1)(x^2+4)
+		{[5]float64{1, 0, 5, 0, 4}, []float64{}},
+		// x(x-1)(x+1)(x-1000)
+		{[5]float64{1, -1000, -1, 1000, 0}, []float64{-1, 0, 1, 1000}},
+	}
+
+	for _, test := range tests {
+		c := test.coefficients
+		roots := solveQuartic(c[0], c[1], c[2], c[3], c[4], nil)
+		sort.Float64s(roots)
+		// A double root may be reported once or twice.
+		unique := make([]float64, 0)
+		for _, root := range roots {
+			if len(unique) == 0 || !floatEqual(unique[len(unique)-1], root) {
+				unique = append(unique, root)
+			}
+		}
+		expected := make([]float64, 0)
+		for _, root := range test.expected {
+			if len(expected) == 0 || !floatEqual(expected[len(expected)-1], root) {
+				expected = append(expected, root)
+			}
+		}
+		if len(unique) != len(expected) {
+			t.Errorf("Quartic %v roots: got %v, expected: %v", c, roots, test.expected)
+			continue
+		}
+		for i := range unique {
+			if !floatEqual(unique[i], expected[i]) {
+				t.Errorf("Quartic %v roots: got %v, expected: %v", c, roots, test.expected)
+			}
+		}
+	}
+}
+
+// The cubic solver handles one and three real roots.
+func TestSolveCubic(t *testing.T) {
+	roots := solveCubic(1, -6, 11, -6, nil)
+	sort.Float64s(roots)
+	expected := []float64{1, 2, 3}
+	if len(roots) != 3 {
+		t.Fatalf("Cubic roots: got %v, expected: %v", roots, expected)
+	}
+	for i := range roots {
+		if !floatEqual(roots[i], expected[i]) {
+			t.Errorf("Cubic roots: got %v, expected: %v", roots, expected)
+		}
+	}
+
+	roots = solveCubic(1, 0, 1, -2, nil)
+	if len(roots) != 1 || !floatEqual(roots[0], 1) {
+		t.Errorf("Cubic roots: got %v, expected: %v", roots, []float64{1})
+	}
+}
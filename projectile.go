@@ -3,15 +3,15 @@ package main
 // Projectile contains the representation of the position as (point)
 // and the velocity as (vector).
 type Projectile struct {
-	position *Tuple
-	velocity *Tuple
+	position Tuple
+	velocity Tuple
 }
 
 // Environment contains the representation of the gravity
 // and wind, both as vectors.
 type Environment struct {
-	gravity *Tuple
-	wind    *Tuple
+	gravity Tuple
+	wind    Tuple
 }
 
 // Tick updates the projectile position and velocity,
@@ -26,7 +26,7 @@ func Tick(env *Environment, p *Projectile) *Projectile {
 // FireProjectile simulates and outputs the trayectory ([]point) of a projectile
 // based on a initial position (point) and initial velocity (vector)
 // it stops when the projectile hits the ground (Y == 0).
-func (e *Environment) FireProjectile(projectilePoint, initialVelocity *Tuple) []*Projectile {
+func (e *Environment) FireProjectile(projectilePoint, initialVelocity Tuple) []*Projectile {
 
 	projectileTrayectory := []*Projectile{}
 
@@ -44,6 +44,6 @@ func (e *Environment) FireProjectile(projectilePoint, initialVelocity *Tuple) []
 }
 
 // WriteToCanvas writes the projectile position as a color pixel on the canvas.
-func (p *Projectile) WriteToCanvas(canvas *Canvas, color *Color) {
+func (p *Projectile) WriteToCanvas(canvas *Canvas, color Color) {
 	canvas.WritePixel(int(p.position.x), canvas.height-int(p.position.y), color)
 }
@@ -23,7 +23,7 @@ func TestFireProjectile(t *testing.T) {
 	fmt.Printf("len(trace) = %+v\n", len(projectileTrayectory))
 
 	for _, p := range projectileTrayectory {
-		fmt.Printf("P = %+v\n", *(*p).position)
+		fmt.Printf("P = %+v\n", (*p).position)
 	}
 }
 
@@ -0,0 +1,219 @@
+package main
+
+import "math"
+
+// Quadric is the surface a*x^2 + b*y^2 + c*z^2 + d*xy + e*xz + f*yz + g*x + h*y + i*z + j = 0,
+// stored as the symmetric matrix Q with p^T * Q * p = 0 for p = (x, y, z, 1). Spheres, ellipsoids,
+// cylinders, cones, paraboloids and hyperboloids are all quadrics. Like Cylinder and Cone, the
+// surface can be truncated between minimum and maximum along y and closed with caps.
+// Points where the equation is negative are inside, which is where the caps are.
+type Quadric struct {
+	BaseShape
+	q                Matrix4
+	minimum, maximum float64
+	closed           bool
+}
+
+// NewQuadric returns the *Quadric of the given coefficients, unbounded along y.
+func NewQuadric(a, b, c, d, e, f, g, h, i, j float64) *Quadric {
+	return &Quadric{
+		BaseShape: NewBaseShape(),
+		q: Matrix4{
+			{a, d / 2, e / 2, g / 2},
+			{d / 2, b, f / 2, h / 2},
+			{e / 2, f / 2, c, i / 2},
+			{g / 2, h / 2, i / 2, j},
+		},
+		minimum: math.Inf(-1),
+		maximum: math.Inf(1),
+	}
+}
+
+// NewEllipsoid returns the *Quadric of the ellipsoid centered at the origin with the given radii.
+func NewEllipsoid(rx, ry, rz float64) *Quadric {
+	return NewQuadric(1/(rx*rx), 1/(ry*ry), 1/(rz*rz), 0, 0, 0, 0, 0, 0, -1)
+}
+
+// NewParaboloid returns the *Quadric of the paraboloid x^2 + z^2 = y opening towards +y.
+func NewParaboloid() *Quadric {
+	return NewQuadric(1, 0, 1, 0, 0, 0, 0, -1, 0, 0)
+}
+
+// NewHyperboloid returns the *Quadric of the hyperboloid x^2 + z^2 - y^2 = 1 of one sheet,
+// or x^2 + z^2 - y^2 = -1 of two sheets.
+func NewHyperboloid(twoSheets bool) *Quadric {
+	j := -1.0
+	if twoSheets {
+		j = 1
+	}
+	return NewQuadric(1, -1, 1, 0, 0, 0, 0, 0, 0, j)
+}
+
+// quadricForm returns u^T * Q * v, taking w into account.
+func quadricForm(q Matrix4, u, v Tuple) float64 {
+	return q.MultiplyMatrixByTuple(v).DotProduct(u)
+}
+
+// LocalIntersect calculates the intersections between a ray in object space and the quadric.
+func (quadric *Quadric) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	o, d := localRay.origin, localRay.direction
+	a := quadricForm(quadric.q, d, d)
+	b := 2 * quadricForm(quadric.q, d, o)
+	c := quadricForm(quadric.q, o, o)
+
+	var rootsArray [2]float64
+	for _, t := range solveQuadratic(a, b, c, rootsArray[:0]) {
+		y := o.y + t*d.y
+		if quadric.minimum < y && y < quadric.maximum {
+			xs = append(xs, NewIntersection(t, quadric))
+		}
+	}
+
+	return quadric.intersectCaps(localRay, xs)
+}
+
+// intersectCaps appends the intersections with the caps of a closed quadric, the parts of the
+// planes y = minimum and y = maximum inside the quadric.
+func (quadric *Quadric) intersectCaps(localRay Ray, xs Intersections) Intersections {
+	if !quadric.closed || math.Abs(localRay.direction.y) < EPSILON {
+		return xs
+	}
+	for _, y := range [2]float64{quadric.minimum, quadric.maximum} {
+		if math.IsInf(y, 0) {
+			continue
+		}
+		t := (y - localRay.origin.y) / localRay.direction.y
+		if quadricForm(quadric.q, localRay.Position(t), localRay.Position(t)) <= 0 {
+			xs = append(xs, NewIntersection(t, quadric))
+		}
+	}
+	return xs
+}
+
+// Intersect calculates the intersections between a ray in world space and the quadric.
+func (quadric *Quadric) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(quadric, worldRay, xs)
+}
+
+// LocalNormalAt returns the gradient of the quadric at the point, or the normal of a cap.
+// Points on the edge of a cap get the normal of the surface, like Cylinder.
+func (quadric *Quadric) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	if quadric.closed && quadricForm(quadric.q, localPoint, localPoint) < -EPSILON {
+		if localPoint.y >= quadric.maximum-EPSILON {
+			return Vector(0, 1, 0)
+		}
+		if localPoint.y <= quadric.minimum+EPSILON {
+			return Vector(0, -1, 0)
+		}
+	}
+	gradient := quadric.q.MultiplyMatrixByTuple(localPoint)
+	return Vector(gradient.x, gradient.y, gradient.z).Normalize()
+}
+
+// NormalAt calculates the normal at a given point in world space.
+func (quadric *Quadric) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(quadric, worldPoint, intersection)
+}
+
+// LocalBounds returns the bounding box of the part of the quadric between minimum and maximum.
+// Ellipsoids and quadrics truncated along y whose sections are ellipses are bounded: their
+// extents are found at the planes tangent to the surface, computed with the dual quadric, and
+// at the boundary ellipses. Every other quadric has infinite bounds in x and z.
+func (quadric *Quadric) LocalBounds() *BoundingBox {
+	q := quadric.q
+	ellipsoid := definite3(q)
+	truncated := !math.IsInf(quadric.minimum, 0) && !math.IsInf(quadric.maximum, 0)
+	ellipticSections := q[0][0]*q[2][2]-q[0][2]*q[0][2] > 0
+
+	if !ellipticSections || (!ellipsoid && !truncated) {
+		return NewBoundingBoxFloat(math.Inf(-1), quadric.minimum, math.Inf(-1), math.Inf(1), quadric.maximum, math.Inf(1))
+	}
+
+	box := NewEmptyBoundingBox()
+
+	// Tangent planes x = k, y = k and z = k, for the dual quadric adj(Q) they satisfy
+	// plane^T * adj(Q) * plane = 0 with plane = (1, 0, 0, -k) for x. Degenerate quadrics, like
+	// cylinders and cones, reach their extents on the boundary ellipses only.
+	if det := q.Determinant(); math.Abs(det) > EPSILON*EPSILON {
+		dual := q.Inverse()
+		var rootsArray [2]float64
+		for axis := 0; axis < 3; axis++ {
+			for _, k := range solveQuadratic(dual[3][3], -2*dual[axis][3], dual[axis][axis], rootsArray[:0]) {
+				plane := Tuple{}
+				plane.w = -k
+				switch axis {
+				case 0:
+					plane.x = 1
+				case 1:
+					plane.y = 1
+				default:
+					plane.z = 1
+				}
+				point := dual.MultiplyMatrixByTuple(plane)
+				if point.w == 0 {
+					continue
+				}
+				point = Point(point.x/point.w, point.y/point.w, point.z/point.w)
+				if point.y >= quadric.minimum && point.y <= quadric.maximum {
+					box.Add(point)
+				}
+			}
+		}
+	}
+
+	for _, y := range [2]float64{quadric.minimum, quadric.maximum} {
+		if !math.IsInf(y, 0) {
+			quadric.addSectionBounds(box, y)
+		}
+	}
+
+	return box
+}
+
+// addSectionBounds adds the extents along x and z of the ellipse where the plane at height y cuts
+// the quadric to the box.
+func (quadric *Quadric) addSectionBounds(box *BoundingBox, y float64) {
+	q := quadric.q
+	// The conic of the section over (x, z, 1) and its adjugate, which is its dual up to a factor.
+	m := [3][3]float64{
+		{q[0][0], q[0][2], q[0][3] + q[0][1]*y},
+		{q[0][2], q[2][2], q[2][3] + q[1][2]*y},
+		{q[0][3] + q[0][1]*y, q[2][3] + q[1][2]*y, q[3][3] + 2*q[1][3]*y + q[1][1]*y*y},
+	}
+	adj := adjugate3(m)
+
+	// The line x = k is (1, 0, -k) and touches the ellipse at adj * (1, 0, -k), likewise for z.
+	var rootsArray [2]float64
+	for axis := 0; axis < 2; axis++ {
+		for _, k := range solveQuadratic(adj[2][2], -2*adj[axis][2], adj[axis][axis], rootsArray[:0]) {
+			var line [3]float64
+			line[axis] = 1
+			line[2] = -k
+			var point [3]float64
+			for r := 0; r < 3; r++ {
+				point[r] = adj[r][0]*line[0] + adj[r][1]*line[1] + adj[r][2]*line[2]
+			}
+			if point[2] != 0 {
+				box.Add(Point(point[0]/point[2], y, point[1]/point[2]))
+			}
+		}
+	}
+}
+
+// definite3 returns true if the quadratic part of the quadric is positive or negative definite,
+// which makes it an ellipsoid (or an empty surface).
+func definite3(q Matrix4) bool {
+	d1 := q[0][0]
+	d2 := q[0][0]*q[1][1] - q[0][1]*q[0][1]
+	d3 := q.determinant3()
+	return (d1 > 0 && d2 > 0 && d3 > 0) || (d1 < 0 && d2 > 0 && d3 < 0)
+}
+
+// adjugate3 returns the adjugate of a 3x3 matrix.
+func adjugate3(m [3][3]float64) [3][3]float64 {
+	return [3][3]float64{
+		{m[1][1]*m[2][2] - m[1][2]*m[2][1], m[0][2]*m[2][1] - m[0][1]*m[2][2], m[0][1]*m[1][2] - m[0][2]*m[1][1]},
+		{m[1][2]*m[2][0] - m[1][0]*m[2][2], m[0][0]*m[2][2] - m[0][2]*m[2][0], m[0][2]*m[1][0] - m[0][0]*m[1][2]},
+		{m[1][0]*m[2][1] - m[1][1]*m[2][0], m[0][1]*m[2][0] - m[0][0]*m[2][1], m[0][0]*m[1][1] - m[0][1]*m[1][0]},
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// An ellipsoid quadric is intersected like a scaled sphere.
+func TestQuadricEllipsoid(t *testing.T) {
+	ellipsoid := NewEllipsoid(2, 1, 3)
+	sphere := NewSphere()
+	sphere.SetTransform(Scaling(2, 1, 3))
+
+	rays := []Ray{
+		NewRay(Point(0, 0, -5), Vector(0, 0, 1)),
+		NewRay(Point(-5, 0.5, 0.2), Vector(1, 0, 0)),
+		NewRay(Point(1, 3, 1), Vector(-0.2, -1, 0.1).Normalize()),
+		NewRay(Point(0, 2, 0), Vector(1, 0, 0)),
+	}
+	for _, r := range rays {
+		xs, expected := ellipsoid.LocalIntersect(r, nil), sphere.Intersect(r, nil)
+		xs.Sort()
+		if len(xs) != len(expected) {
+			t.Errorf("Ellipsoid intersections count: got %v, expected: %v", len(xs), len(expected))
+			continue
+		}
+		for i := range xs {
+			if !floatEqual(xs[i].t, expected[i].t) {
+				t.Errorf("Ellipsoid intersection: got %v, expected: %v", xs[i].t, expected[i].t)
+			}
+			point := r.Position(xs[i].t)
+			if n, want := ellipsoid.NormalAt(point, &xs[i]), sphere.NormalAt(point, &expected[i]); !n.Equals(want) {
+				t.Errorf("Ellipsoid normal: got %v, expected: %v", n, want)
+			}
+		}
+	}
+
+	box := ellipsoid.LocalBounds()
+	if !box.min.Equals(Point(-2, -1, -3)) || !box.max.Equals(Point(2, 1, 3)) {
+		t.Errorf("Ellipsoid bounds: got %v, expected: %v", box, NewBoundingBoxFloat(-2, -1, -3, 2, 1, 3))
+	}
+}
+
+// A quadric cylinder truncated and closed behaves like a closed Cylinder.
+func TestQuadricClosedCylinder(t *testing.T) {
+	quadric := NewQuadric(1, 0, 1, 0, 0, 0, 0, 0, 0, -1)
+	quadric.minimum = 1
+	quadric.maximum = 2
+	quadric.closed = true
+	cylinder := NewCylinder()
+	cylinder.minimum = 1
+	cylinder.maximum = 2
+	cylinder.closed = true
+
+	rays := []Ray{
+		NewRay(Point(0, 3, 0), Vector(0, -1, 0)),
+		NewRay(Point(0, 3, -2), Vector(0, -1, 2).Normalize()),
+		NewRay(Point(0, 4, -2), Vector(0, -1, 1).Normalize()),
+		NewRay(Point(0, 0, -2), Vector(0, 1, 2).Normalize()),
+		NewRay(Point(0, 1.5, -2), Vector(0, 0, 1)),
+	}
+	for _, r := range rays {
+		xs, expected := quadric.LocalIntersect(r, nil), cylinder.LocalIntersect(r, nil)
+		xs.Sort()
+		expected.Sort()
+		if len(xs) != len(expected) {
+			t.Errorf("Quadric cylinder intersections count: got %v, expected: %v", len(xs), len(expected))
+			continue
+		}
+		for i := range xs {
+			point := r.Position(xs[i].t)
+			if !floatEqual(xs[i].t, expected[i].t) {
+				t.Errorf("Quadric cylinder intersection: got %v, expected: %v", xs[i].t, expected[i].t)
+			}
+			if n, want := quadric.LocalNormalAt(point, &xs[i]), cylinder.LocalNormalAt(point, &expected[i]).Normalize(); !n.Equals(want) {
+				t.Errorf("Quadric cylinder normal: got %v, expected: %v", n, want)
+			}
+		}
+	}
+
+	box := quadric.LocalBounds()
+	if !box.min.Equals(Point(-1, 1, -1)) || !box.max.Equals(Point(1, 2, 1)) {
+		t.Errorf("Quadric cylinder bounds: got %v, expected: %v", box, NewBoundingBoxFloat(-1, 1, -1, 1, 2, 1))
+	}
+}
+
+// The bounds of truncated paraboloids and hyperboloids, and of unbounded quadrics.
+func TestQuadricBounds(t *testing.T) {
+	paraboloid := NewParaboloid()
+	paraboloid.minimum, paraboloid.maximum = 0, 4
+	hyperboloid := NewHyperboloid(false)
+	hyperboloid.minimum, hyperboloid.maximum = -1, 2
+	// A tilted ellipsoid x^2 + y^2 + z^2 + xy = 1.
+	tilted := NewQuadric(1, 1, 1, 1, 0, 0, 0, 0, 0, -1)
+	r := math.Sqrt(4.0 / 3)
+
+	tests := []struct {
+		quadric  *Quadric
+		expected *BoundingBox
+	}{
+		{paraboloid, NewBoundingBoxFloat(-2, 0, -2, 2, 4, 2)},
+		{hyperboloid, NewBoundingBoxFloat(-math.Sqrt(5), -1, -math.Sqrt(5), math.Sqrt(5), 2, math.Sqrt(5))},
+		{tilted, NewBoundingBoxFloat(-r, -r, -1, r, r, 1)},
+	}
+	for _, test := range tests {
+		box := test.quadric.LocalBounds()
+		if !box.min.Equals(test.expected.min) || !box.max.Equals(test.expected.max) {
+			t.Errorf("Quadric bounds: got %v, expected: %v", box, test.expected)
+		}
+	}
+
+	if box := NewParaboloid().LocalBounds(); box.IsFinite() {
+		t.Errorf("Unbounded paraboloid bounds: got %v, expected infinite bounds", box)
+	}
+	saddle := NewQuadric(1, 0, -1, 0, 0, 0, 0, -1, 0, 0)
+	saddle.minimum, saddle.maximum = -1, 1
+	if box := saddle.LocalBounds(); box.IsFinite() {
+		t.Errorf("Saddle bounds: got %v, expected infinite bounds", box)
+	}
+}
+
+// A superquadric with both exponents at 1 is a sphere.
+func TestSuperquadricSphere(t *testing.T) {
+	superquadric := NewSuperquadric(1, 1)
+	sphere := NewSphere()
+	rays := []Ray{
+		NewRay(Point(0, 0, -5), Vector(0, 0, 1)),
+		NewRay(Point(0.3, -0.2, -5), Vector(0, 0.1, 1).Normalize()),
+		NewRay(Point(0, 0, 0), Vector(1, 1, 0).Normalize()),
+		NewRay(Point(2, 0, -5), Vector(0, 0, 1)),
+	}
+	for _, r := range rays {
+		xs, expected := superquadric.LocalIntersect(r, nil), sphere.LocalIntersect(r, nil)
+		if len(xs) != len(expected) {
+			t.Errorf("Superquadric intersections count: got %v, expected: %v", len(xs), len(expected))
+			continue
+		}
+		for i := range xs {
+			if math.Abs(xs[i].t-expected[i].t) > 1e-9 {
+				t.Errorf("Superquadric intersection: got %v, expected: %v", xs[i].t, expected[i].t)
+			}
+			point := r.Position(xs[i].t)
+			if n, want := superquadric.LocalNormalAt(point, &xs[i]), sphere.LocalNormalAt(point, &expected[i]).Normalize(); !n.Equals(want) {
+				t.Errorf("Superquadric normal: got %v, expected: %v", n, want)
+			}
+		}
+	}
+}
+
+// A superquadric with small exponents is a box with rounded edges.
+func TestSuperquadricRoundedBox(t *testing.T) {
+	superquadric := NewSuperquadric(0.1, 0.1)
+	r := NewRay(Point(0.5, 0.5, -5), Vector(0, 0, 1))
+	xs := superquadric.LocalIntersect(r, nil)
+	if len(xs) != 2 || math.Abs(xs[0].t-4) > 1e-3 || math.Abs(xs[1].t-6) > 1e-3 {
+		t.Fatalf("Rounded box intersections: got %v, expected: %v", xs, []float64{4, 6})
+	}
+	if n := superquadric.LocalNormalAt(r.Position(xs[0].t), &xs[0]); !n.Equals(Vector(0, 0, -1)) {
+		t.Errorf("Rounded box normal: got %v, expected: %v", n, Vector(0, 0, -1))
+	}
+
+	// The corners are rounded off.
+	if xs := superquadric.LocalIntersect(NewRay(Point(0.99, 0.99, -5), Vector(0, 0, 1)), nil); len(xs) != 0 {
+		t.Errorf("Rounded box corner intersections: got %v, expected: %v", len(xs), 0)
+	}
+}
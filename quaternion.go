@@ -0,0 +1,166 @@
+package main
+
+import "math"
+
+// Quaternion represents a rotation as w + xi + yj + zk.
+// Only unit quaternions describe rotations, which is what every constructor below returns.
+type Quaternion struct {
+	w, x, y, z float64
+}
+
+// NewQuaternion returns a quaternion with the given components.
+func NewQuaternion(w, x, y, z float64) Quaternion {
+	return Quaternion{w, x, y, z}
+}
+
+// IdentityQuaternion returns the quaternion of a rotation by 0 radians.
+func IdentityQuaternion() Quaternion {
+	return Quaternion{1, 0, 0, 0}
+}
+
+// QuaternionFromAxisAngle returns the quaternion rotating by angle radians around the given axis.
+func QuaternionFromAxisAngle(axis Tuple, angle float64) Quaternion {
+	axis = Vector(axis.x, axis.y, axis.z).Normalize()
+	s := math.Sin(angle / 2)
+	return Quaternion{math.Cos(angle / 2), axis.x * s, axis.y * s, axis.z * s}
+}
+
+// QuaternionFromMatrix returns the quaternion of the rotation contained in the upper 3x3 part of the matrix.
+// The matrix must not contain any scaling or shearing, use Decompose() first otherwise.
+func QuaternionFromMatrix(m Matrix4) Quaternion {
+	var q Quaternion
+	trace := m[0][0] + m[1][1] + m[2][2]
+
+	// Use the largest of w, x, y and z to avoid dividing by a value close to zero.
+	switch {
+	case trace > 0:
+		s := 2 * math.Sqrt(trace+1)
+		q = Quaternion{s / 4, (m[2][1] - m[1][2]) / s, (m[0][2] - m[2][0]) / s, (m[1][0] - m[0][1]) / s}
+	case m[0][0] > m[1][1] && m[0][0] > m[2][2]:
+		s := 2 * math.Sqrt(1+m[0][0]-m[1][1]-m[2][2])
+		q = Quaternion{(m[2][1] - m[1][2]) / s, s / 4, (m[0][1] + m[1][0]) / s, (m[0][2] + m[2][0]) / s}
+	case m[1][1] > m[2][2]:
+		s := 2 * math.Sqrt(1+m[1][1]-m[0][0]-m[2][2])
+		q = Quaternion{(m[0][2] - m[2][0]) / s, (m[0][1] + m[1][0]) / s, s / 4, (m[1][2] + m[2][1]) / s}
+	default:
+		s := 2 * math.Sqrt(1+m[2][2]-m[0][0]-m[1][1])
+		q = Quaternion{(m[1][0] - m[0][1]) / s, (m[0][2] + m[2][0]) / s, (m[1][2] + m[2][1]) / s, s / 4}
+	}
+	return q.Normalize()
+}
+
+// Equals returns true if both quaternions are within the error margin Epsilon.
+func (q Quaternion) Equals(o Quaternion) bool {
+	return floatEqual(q.w, o.w) && floatEqual(q.x, o.x) && floatEqual(q.y, o.y) && floatEqual(q.z, o.z)
+}
+
+// SameRotation returns true if both quaternions describe the same rotation, q and -q being equivalent.
+func (q Quaternion) SameRotation(o Quaternion) bool {
+	return q.Equals(o) || q.Equals(o.Negate())
+}
+
+// Negate returns the quaternion with all of its components negated.
+func (q Quaternion) Negate() Quaternion {
+	return Quaternion{-q.w, -q.x, -q.y, -q.z}
+}
+
+// Multiply returns the Hamilton product of the quaternions, which rotates by o first and then by q.
+func (q Quaternion) Multiply(o Quaternion) Quaternion {
+	return Quaternion{
+		q.w*o.w - q.x*o.x - q.y*o.y - q.z*o.z,
+		q.w*o.x + q.x*o.w + q.y*o.z - q.z*o.y,
+		q.w*o.y - q.x*o.z + q.y*o.w + q.z*o.x,
+		q.w*o.z + q.x*o.y - q.y*o.x + q.z*o.w,
+	}
+}
+
+// Conjugate returns the conjugate quaternion, which is the inverse rotation of a unit quaternion.
+func (q Quaternion) Conjugate() Quaternion {
+	return Quaternion{q.w, -q.x, -q.y, -q.z}
+}
+
+// Dot returns the dot product of the quaternions.
+func (q Quaternion) Dot(o Quaternion) float64 {
+	return q.w*o.w + q.x*o.x + q.y*o.y + q.z*o.z
+}
+
+// Magnitude of the quaternion.
+func (q Quaternion) Magnitude() float64 {
+	return math.Sqrt(q.Dot(q))
+}
+
+// Normalize returns the unit quaternion.
+func (q Quaternion) Normalize() Quaternion {
+	mag := q.Magnitude()
+	if mag == 0.0 {
+		return q
+	}
+	return Quaternion{q.w / mag, q.x / mag, q.y / mag, q.z / mag}
+}
+
+// AxisAngle returns the normalized axis and the angle in radians of the rotation.
+// The identity rotation returns the x axis with an angle of 0.
+func (q Quaternion) AxisAngle() (Tuple, float64) {
+	q = q.Normalize()
+	if q.w < 0 {
+		q = q.Negate()
+	}
+	s := math.Sqrt(1 - q.w*q.w)
+	if s < EPSILON {
+		return Vector(1, 0, 0), 0
+	}
+	return Vector(q.x/s, q.y/s, q.z/s), 2 * math.Acos(math.Min(q.w, 1))
+}
+
+// Rotate returns the tuple rotated by the quaternion.
+func (q Quaternion) Rotate(t Tuple) Tuple {
+	return q.Matrix().MultiplyMatrixByTuple(t)
+}
+
+// Matrix returns the rotation matrix of the quaternion.
+func (q Quaternion) Matrix() Matrix4 {
+	q = q.Normalize()
+	xx, yy, zz := q.x*q.x, q.y*q.y, q.z*q.z
+	xy, xz, yz := q.x*q.y, q.x*q.z, q.y*q.z
+	wx, wy, wz := q.w*q.x, q.w*q.y, q.w*q.z
+
+	return Matrix4{
+		{1 - 2*(yy+zz), 2 * (xy - wz), 2 * (xz + wy), 0},
+		{2 * (xy + wz), 1 - 2*(xx+zz), 2 * (yz - wx), 0},
+		{2 * (xz - wy), 2 * (yz + wx), 1 - 2*(xx+yy), 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Slerp spherically interpolates between the rotations a and b, t going from 0 (a) to 1 (b).
+// The shortest path between both rotations is always taken.
+func Slerp(a, b Quaternion, t float64) Quaternion {
+	a = a.Normalize()
+	b = b.Normalize()
+	cos := a.Dot(b)
+	if cos < 0 {
+		b = b.Negate()
+		cos = -cos
+	}
+
+	// Nearly identical rotations, fall back to a linear interpolation to avoid dividing by sin(0).
+	if cos > 1-EPSILON {
+		return Quaternion{
+			a.w + t*(b.w-a.w),
+			a.x + t*(b.x-a.x),
+			a.y + t*(b.y-a.y),
+			a.z + t*(b.z-a.z),
+		}.Normalize()
+	}
+
+	theta := math.Acos(cos)
+	sin := math.Sin(theta)
+	wa := math.Sin((1-t)*theta) / sin
+	wb := math.Sin(t*theta) / sin
+	return Quaternion{
+		wa*a.w + wb*b.w,
+		wa*a.x + wb*b.x,
+		wa*a.y + wb*b.y,
+		wa*a.z + wb*b.z,
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuaternionFromAxisAngle(t *testing.T) {
+	// A quaternion built from an axis and an angle gives the same rotation as the Euler matrices.
+	tests := []struct {
+		axis     Tuple
+		angle    float64
+		expected Matrix4
+	}{
+		{Vector(1, 0, 0), PI / 4, RotationX(PI / 4)},
+		{Vector(0, 2, 0), PI / 3, RotationY(PI / 3)},
+		{Vector(0, 0, 1), -PI / 2, RotationZ(-PI / 2)},
+	}
+
+	for _, test := range tests {
+		q := QuaternionFromAxisAngle(test.axis, test.angle)
+		if !q.Matrix().Equals(test.expected) {
+			t.Errorf("Quaternion from axis angle: got %v, expected: %v", q.Matrix(), test.expected)
+		}
+		if !QuaternionFromMatrix(test.expected).SameRotation(q) {
+			t.Errorf("Quaternion from matrix: got %v, expected: %v", QuaternionFromMatrix(test.expected), q)
+		}
+
+		axis, angle := q.AxisAngle()
+		if !axis.Multiply(angle).Equals(test.axis.Normalize().Multiply(test.angle)) {
+			t.Errorf("Quaternion axis angle: got %v %v, expected: %v %v", axis, angle, test.axis.Normalize(), test.angle)
+		}
+	}
+}
+
+func TestQuaternionFromMatrixLargeAngles(t *testing.T) {
+	// Converting rotations close to 180 degrees back into quaternions.
+	axes := []Tuple{Vector(1, 0, 0), Vector(0, 1, 0), Vector(0, 0, 1), Vector(1, -2, 3)}
+
+	for _, axis := range axes {
+		q := QuaternionFromAxisAngle(axis, PI*0.99)
+		result := QuaternionFromMatrix(q.Matrix())
+		if !result.SameRotation(q) {
+			t.Errorf("Quaternion from matrix: got %v, expected: %v", result, q)
+		}
+	}
+}
+
+func TestQuaternionMultiply(t *testing.T) {
+	// Multiplying quaternions composes the rotations like multiplying the matrices.
+	a := QuaternionFromAxisAngle(Vector(0, 1, 0), PI/2)
+	b := QuaternionFromAxisAngle(Vector(1, 0, 0), PI/2)
+	expected := RotationY(PI / 2).MultiplyMatrix(RotationX(PI / 2))
+
+	if !a.Multiply(b).Matrix().Equals(expected) {
+		t.Errorf("Quaternion multiply: got %v, expected: %v", a.Multiply(b).Matrix(), expected)
+	}
+
+	p := a.Multiply(a.Conjugate())
+	if !p.Equals(IdentityQuaternion()) {
+		t.Errorf("Quaternion conjugate: got %v, expected: %v", p, IdentityQuaternion())
+	}
+
+	r := a.Rotate(Point(0, 0, 1))
+	if !r.Equals(Point(1, 0, 0)) {
+		t.Errorf("Quaternion rotate: got %v, expected: %v", r, Point(1, 0, 0))
+	}
+}
+
+func TestSlerp(t *testing.T) {
+	// Interpolating halfway between two rotations around the same axis.
+	a := QuaternionFromAxisAngle(Vector(0, 1, 0), 0)
+	b := QuaternionFromAxisAngle(Vector(0, 1, 0), PI/2)
+
+	tests := []struct {
+		t        float64
+		expected Quaternion
+	}{
+		{0, a},
+		{0.5, QuaternionFromAxisAngle(Vector(0, 1, 0), PI/4)},
+		{1, b},
+	}
+	for _, test := range tests {
+		result := Slerp(a, b, test.t)
+		if !result.SameRotation(test.expected) {
+			t.Errorf("Slerp %v: got %v, expected: %v", test.t, result, test.expected)
+		}
+	}
+
+	// Slerp takes the shortest path even when the quaternions are in opposite hemispheres.
+	result := Slerp(a, b.Negate(), 0.5)
+	if !result.SameRotation(QuaternionFromAxisAngle(Vector(0, 1, 0), PI/4)) {
+		t.Errorf("Slerp shortest path: got %v", result)
+	}
+
+	// Nearly identical rotations.
+	c := QuaternionFromAxisAngle(Vector(0, 1, 0), 1e-7)
+	result = Slerp(a, c, 0.5)
+	if math.IsNaN(result.w) || !result.SameRotation(a) {
+		t.Errorf("Slerp close rotations: got %v, expected: %v", result, a)
+	}
+}
@@ -3,21 +3,27 @@ package main
 // Ray is a struct used for raycasting purposes.
 // It contains the representation of a origin point and a direction vector.
 type Ray struct {
-	origin, direction *Tuple
+	origin, direction Tuple
 }
 
 // NewRay creates a new ray.
-func NewRay(origin, direction *Tuple) *Ray {
-	return &Ray{origin, direction}
+func NewRay(origin, direction Tuple) Ray {
+	return Ray{origin, direction}
 }
 
 // Position calculates the point at the given distance t along the ray
-func (ray *Ray) Position(t float64) *Tuple {
+func (ray Ray) Position(t float64) Tuple {
 	return ray.origin.Add(ray.direction.Multiply(t))
 }
 
 // Transform will return a new ray with its origin and direction transformed.
-func (ray *Ray) Transform(transformations ...Matrix) *Ray {
+func (ray Ray) Transform(transformations ...Matrix4) Ray {
+	if len(transformations) == 1 {
+		return NewRay(
+			transformations[0].MultiplyMatrixByTuple(ray.origin),
+			transformations[0].MultiplyMatrixByTuple(ray.direction),
+		)
+	}
 	return NewRay(
 		ray.origin.Transform(transformations...),
 		ray.direction.Transform(transformations...),
@@ -25,13 +31,11 @@ func (ray *Ray) Transform(transformations ...Matrix) *Ray {
 }
 
 // Equals checks ray equality
-func (ray *Ray) Equals(other *Ray) bool {
+func (ray Ray) Equals(other Ray) bool {
 	return ray.origin.Equals(other.origin) && ray.direction.Equals(other.direction)
 }
 
 //Intersect calculates the intersection between a ray and an object.
-func (ray *Ray) Intersect(object Shape) Intersections {
-	intersections := object.Intersect(ray)
-
-	return NewIntersections(intersections)
+func (ray Ray) Intersect(object Shape) Intersections {
+	return object.Intersect(ray, nil)
 }
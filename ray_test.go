@@ -8,13 +8,13 @@ import (
 func TestRayPosition(t *testing.T) {
 	ray := NewRay(Point(2, 3, 4), Vector(1, 0, 0))
 
-	results := []*Tuple{
+	results := []Tuple{
 		ray.Position(0),
 		ray.Position(1),
 		ray.Position(-1),
 		ray.Position(2.5),
 	}
-	expected := []*Tuple{
+	expected := []Tuple{
 		Point(2, 3, 4),
 		Point(3, 3, 4),
 		Point(1, 3, 4),
@@ -34,7 +34,7 @@ func TestIntersectSphere(t *testing.T) {
 
 	s := NewSphere()
 
-	xs := s.Intersect(r)
+	xs := s.Intersect(r, nil)
 
 	if len(xs) != 2 {
 		t.Errorf("IntersectSphere: expected number of intersections to be %v but got %v", 2, len(xs))
@@ -50,7 +50,7 @@ func TestIntersectSphere(t *testing.T) {
 
 	// A ray intersects a sphere at a tangent.
 	r = NewRay(Point(0, 1, -5), Vector(0, 0, 1))
-	xs = s.Intersect(r)
+	xs = s.Intersect(r, nil)
 
 	if len(xs) != 2 {
 		t.Errorf("IntersectSphere: expected number of intersections to be %v but got %v", 2, len(xs))
@@ -65,14 +65,14 @@ func TestIntersectSphere(t *testing.T) {
 
 	// A ray misses a sphere.
 	r = NewRay(Point(0, 2, -5), Vector(0, 0, 1))
-	xs = s.Intersect(r)
+	xs = s.Intersect(r, nil)
 
 	if len(xs) != 0 {
 		t.Errorf("IntersectSphere: expected number of intersections to be %v but got %v", 0, len(xs))
 	}
 
 	r = NewRay(Point(0, 0, 0), Vector(0, 0, 1))
-	xs = s.Intersect(r)
+	xs = s.Intersect(r, nil)
 
 	if len(xs) != 2 {
 		t.Errorf("IntersectSphere: expected number of intersections to be %v but got %v", 2, len(xs))
@@ -89,7 +89,7 @@ func TestIntersectSphere(t *testing.T) {
 
 	// A sphere is behind a ray.
 	r = NewRay(Point(0, 0, 5), Vector(0, 0, 1))
-	xs = s.Intersect(r)
+	xs = s.Intersect(r, nil)
 
 	if len(xs) != 2 {
 		t.Errorf("IntersectSphere: expected number of intersections to be %v but got %v", 2, len(xs))
@@ -128,7 +128,7 @@ func TestRayTransform(t *testing.T) {
 	r = NewRay(Point(0, 0, -5), Vector(0, 0, 1))
 	s := NewSphere()
 	s.SetTransform(Scaling(2, 2, 2))
-	xs := s.Intersect(r)
+	xs := s.Intersect(r, nil)
 
 	if len(xs) != 2 {
 		t.Errorf("RayTransform: expected number of intersections to be %v but got %v", 2, len(xs))
@@ -142,7 +142,7 @@ func TestRayTransform(t *testing.T) {
 
 	// Intersecting a translated sphere with a ray
 	s.SetTransform(Translation(5, 0, 0))
-	xs = s.Intersect(r)
+	xs = s.Intersect(r, nil)
 	if len(xs) != 0 {
 		t.Errorf("RayTransform: expected number of intersections to be %v but got %v", 0, len(xs))
 	}
@@ -0,0 +1,402 @@
+package main
+
+import "math"
+
+// SDF is a signed distance function: Distance returns the distance from a point to the surface,
+// negative inside, and never more than the true distance so that sphere tracing can step by it
+// without crossing the surface. Bounds returns a box containing the surface.
+type SDF interface {
+	Distance(p Tuple) float64
+	Bounds() *BoundingBox
+}
+
+// SphereSDF is a sphere of the given radius centered at the origin.
+type SphereSDF struct {
+	radius float64
+}
+
+// NewSphereSDF returns a *SphereSDF of the given radius.
+func NewSphereSDF(radius float64) *SphereSDF {
+	return &SphereSDF{radius: radius}
+}
+
+// Distance returns the signed distance from p to the sphere.
+func (sphere *SphereSDF) Distance(p Tuple) float64 {
+	return math.Sqrt(p.x*p.x+p.y*p.y+p.z*p.z) - sphere.radius
+}
+
+// Bounds returns the bounding box of the sphere.
+func (sphere *SphereSDF) Bounds() *BoundingBox {
+	r := sphere.radius
+	return NewBoundingBoxFloat(-r, -r, -r, r, r, r)
+}
+
+// BoxSDF is a box centered at the origin with the given half sizes, its edges rounded with radius.
+// A radius of 0 gives a sharp box, the overall size does not depend on the radius.
+type BoxSDF struct {
+	half   Tuple
+	radius float64
+}
+
+// NewBoxSDF returns a *BoxSDF with sharp edges and the given half sizes along x, y and z.
+func NewBoxSDF(x, y, z float64) *BoxSDF {
+	return &BoxSDF{half: Vector(x, y, z)}
+}
+
+// NewRoundedBoxSDF returns a *BoxSDF with the given half sizes and its edges rounded with radius.
+func NewRoundedBoxSDF(x, y, z, radius float64) *BoxSDF {
+	return &BoxSDF{half: Vector(x, y, z), radius: radius}
+}
+
+// Distance returns the signed distance from p to the box.
+func (box *BoxSDF) Distance(p Tuple) float64 {
+	qx := math.Abs(p.x) - box.half.x + box.radius
+	qy := math.Abs(p.y) - box.half.y + box.radius
+	qz := math.Abs(p.z) - box.half.z + box.radius
+	outside := math.Sqrt(square(math.Max(qx, 0)) + square(math.Max(qy, 0)) + square(math.Max(qz, 0)))
+	inside := math.Min(math.Max(qx, math.Max(qy, qz)), 0)
+	return outside + inside - box.radius
+}
+
+// Bounds returns the bounding box of the box.
+func (box *BoxSDF) Bounds() *BoundingBox {
+	return NewBoundingBoxFloat(-box.half.x, -box.half.y, -box.half.z, box.half.x, box.half.y, box.half.z)
+}
+
+// TorusSDF is a ring around the y axis like Torus.
+type TorusSDF struct {
+	majorRadius, minorRadius float64
+}
+
+// NewTorusSDF returns a *TorusSDF with the given radii.
+func NewTorusSDF(majorRadius, minorRadius float64) *TorusSDF {
+	return &TorusSDF{majorRadius: majorRadius, minorRadius: minorRadius}
+}
+
+// Distance returns the signed distance from p to the torus.
+func (torus *TorusSDF) Distance(p Tuple) float64 {
+	q := math.Sqrt(p.x*p.x+p.z*p.z) - torus.majorRadius
+	return math.Sqrt(q*q+p.y*p.y) - torus.minorRadius
+}
+
+// Bounds returns the bounding box of the torus.
+func (torus *TorusSDF) Bounds() *BoundingBox {
+	outer := torus.majorRadius + torus.minorRadius
+	return NewBoundingBoxFloat(-outer, -torus.minorRadius, -outer, outer, torus.minorRadius, outer)
+}
+
+// CapsuleSDF is the set of points within radius of the segment from a to b.
+type CapsuleSDF struct {
+	a, b   Tuple
+	radius float64
+}
+
+// NewCapsuleSDF returns a *CapsuleSDF around the segment from a to b.
+func NewCapsuleSDF(a, b Tuple, radius float64) *CapsuleSDF {
+	return &CapsuleSDF{a: a, b: b, radius: radius}
+}
+
+// Distance returns the signed distance from p to the capsule.
+func (capsule *CapsuleSDF) Distance(p Tuple) float64 {
+	pa := p.Substract(capsule.a)
+	ba := capsule.b.Substract(capsule.a)
+	h := 0.0
+	if bb := ba.DotProduct(ba); bb > 0 {
+		h = math.Max(0, math.Min(1, pa.DotProduct(ba)/bb))
+	}
+	return pa.Substract(ba.Multiply(h)).Magnitude() - capsule.radius
+}
+
+// Bounds returns the bounding box of the capsule.
+func (capsule *CapsuleSDF) Bounds() *BoundingBox {
+	r := Vector(capsule.radius, capsule.radius, capsule.radius)
+	box := NewEmptyBoundingBox()
+	box.Add(capsule.a.Substract(r))
+	box.Add(capsule.a.Add(r))
+	box.Add(capsule.b.Substract(r))
+	box.Add(capsule.b.Add(r))
+	return box
+}
+
+// TranslateSDF moves another SDF by offset, which is how primitives are placed before combining them.
+type TranslateSDF struct {
+	sdf    SDF
+	offset Tuple
+}
+
+// NewTranslateSDF returns a *TranslateSDF moving sdf by (x, y, z).
+func NewTranslateSDF(sdf SDF, x, y, z float64) *TranslateSDF {
+	return &TranslateSDF{sdf: sdf, offset: Vector(x, y, z)}
+}
+
+// Distance returns the signed distance from p to the moved SDF.
+func (translate *TranslateSDF) Distance(p Tuple) float64 {
+	return translate.sdf.Distance(p.Substract(translate.offset))
+}
+
+// Bounds returns the bounds of the SDF, moved.
+func (translate *TranslateSDF) Bounds() *BoundingBox {
+	b := translate.sdf.Bounds()
+	return NewBoundingBox(b.min.Add(translate.offset), b.max.Add(translate.offset))
+}
+
+// sdfOperation tells which boolean operation a SmoothSDF performs.
+type sdfOperation int
+
+const (
+	sdfUnion sdfOperation = iota
+	sdfSubtraction
+	sdfIntersection
+)
+
+// SmoothSDF combines two SDFs with a boolean operation whose seam is blended over a distance of
+// about k, like the operations of CSG. k = 0 gives the sharp operation.
+type SmoothSDF struct {
+	operation sdfOperation
+	a, b      SDF
+	k         float64
+}
+
+// NewSmoothUnionSDF returns a *SmoothSDF of the union of a and b.
+func NewSmoothUnionSDF(a, b SDF, k float64) *SmoothSDF {
+	return &SmoothSDF{operation: sdfUnion, a: a, b: b, k: k}
+}
+
+// NewSmoothSubtractionSDF returns a *SmoothSDF of a minus b.
+func NewSmoothSubtractionSDF(a, b SDF, k float64) *SmoothSDF {
+	return &SmoothSDF{operation: sdfSubtraction, a: a, b: b, k: k}
+}
+
+// NewSmoothIntersectionSDF returns a *SmoothSDF of the intersection of a and b.
+func NewSmoothIntersectionSDF(a, b SDF, k float64) *SmoothSDF {
+	return &SmoothSDF{operation: sdfIntersection, a: a, b: b, k: k}
+}
+
+// smoothMin is the polynomial smooth minimum of a and b, never more than min(a, b) and at most
+// k/4 less.
+func smoothMin(a, b, k float64) float64 {
+	if k <= 0 {
+		return math.Min(a, b)
+	}
+	h := math.Max(k-math.Abs(a-b), 0) / k
+	return math.Min(a, b) - h*h*k/4
+}
+
+// Distance returns the signed distance from p to the combination.
+func (smooth *SmoothSDF) Distance(p Tuple) float64 {
+	a, b := smooth.a.Distance(p), smooth.b.Distance(p)
+	switch smooth.operation {
+	case sdfSubtraction:
+		return -smoothMin(-a, b, smooth.k)
+	case sdfIntersection:
+		return -smoothMin(-a, -b, smooth.k)
+	default:
+		return smoothMin(a, b, smooth.k)
+	}
+}
+
+// Bounds returns the bounding box of the combination. The blend of a union bulges by up to k/4
+// beyond both operands, subtractions and intersections stay inside a.
+func (smooth *SmoothSDF) Bounds() *BoundingBox {
+	a := smooth.a.Bounds()
+	switch smooth.operation {
+	case sdfSubtraction:
+		return a
+	case sdfIntersection:
+		b := smooth.b.Bounds()
+		return NewBoundingBox(
+			Point(math.Max(a.min.x, b.min.x), math.Max(a.min.y, b.min.y), math.Max(a.min.z, b.min.z)),
+			Point(math.Min(a.max.x, b.max.x), math.Min(a.max.y, b.max.y), math.Min(a.max.z, b.max.z)))
+	default:
+		box := NewEmptyBoundingBox()
+		box.Merge(a)
+		box.Merge(smooth.b.Bounds())
+		bulge := Vector(smooth.k/4, smooth.k/4, smooth.k/4)
+		return NewBoundingBox(box.min.Substract(bulge), box.max.Add(bulge))
+	}
+}
+
+// RepeatSDF repeats another SDF with the given period along each axis, count copies on each side
+// of the original. A period of 0 leaves an axis alone and an infinite count repeats forever, which
+// makes the bounds infinite. The repeated SDF should fit in its cell for the distance to stay exact.
+type RepeatSDF struct {
+	sdf           SDF
+	period, count Tuple
+}
+
+// NewRepeatSDF returns a *RepeatSDF of sdf.
+func NewRepeatSDF(sdf SDF, period, count Tuple) *RepeatSDF {
+	return &RepeatSDF{sdf: sdf, period: period, count: count}
+}
+
+// repeatAxis returns the coordinate v moved into the nearest cell of the repetition.
+func repeatAxis(v, period, count float64) float64 {
+	if period == 0 {
+		return v
+	}
+	cell := math.Max(-count, math.Min(count, math.Round(v/period)))
+	return v - period*cell
+}
+
+// Distance returns the signed distance from p to the nearest copy.
+func (repeat *RepeatSDF) Distance(p Tuple) float64 {
+	return repeat.sdf.Distance(Point(
+		repeatAxis(p.x, repeat.period.x, repeat.count.x),
+		repeatAxis(p.y, repeat.period.y, repeat.count.y),
+		repeatAxis(p.z, repeat.period.z, repeat.count.z)))
+}
+
+// Bounds returns the bounding box of all the copies.
+func (repeat *RepeatSDF) Bounds() *BoundingBox {
+	b := repeat.sdf.Bounds()
+	extent := func(period, count float64) float64 {
+		if period == 0 {
+			return 0
+		}
+		return math.Abs(period) * count
+	}
+	e := Vector(
+		extent(repeat.period.x, repeat.count.x),
+		extent(repeat.period.y, repeat.count.y),
+		extent(repeat.period.z, repeat.count.z))
+	return NewBoundingBox(b.min.Substract(e), b.max.Add(e))
+}
+
+// TwistSDF twists another SDF around the y axis by amount radians per unit along y.
+// Twisting stretches space, so the distance is divided by the largest stretch inside the bounds to
+// keep sphere tracing from overshooting.
+type TwistSDF struct {
+	sdf    SDF
+	amount float64
+}
+
+// NewTwistSDF returns a *TwistSDF of sdf.
+func NewTwistSDF(sdf SDF, amount float64) *TwistSDF {
+	return &TwistSDF{sdf: sdf, amount: amount}
+}
+
+// radius returns the largest distance from the y axis to the bounds of the twisted SDF.
+func (twist *TwistSDF) radius() float64 {
+	b := twist.sdf.Bounds()
+	x := math.Max(math.Abs(b.min.x), math.Abs(b.max.x))
+	z := math.Max(math.Abs(b.min.z), math.Abs(b.max.z))
+	return math.Sqrt(x*x + z*z)
+}
+
+// Distance returns the signed distance from p to the twisted SDF.
+func (twist *TwistSDF) Distance(p Tuple) float64 {
+	angle := -twist.amount * p.y
+	c, s := math.Cos(angle), math.Sin(angle)
+	q := Point(c*p.x-s*p.z, p.y, s*p.x+c*p.z)
+	return twist.sdf.Distance(q) / math.Sqrt(1+square(twist.amount*twist.radius()))
+}
+
+// Bounds returns the bounding box of the twisted SDF, the cylinder around y containing every rotation.
+func (twist *TwistSDF) Bounds() *BoundingBox {
+	b := twist.sdf.Bounds()
+	r := twist.radius()
+	return NewBoundingBoxFloat(-r, b.min.y, -r, r, b.max.y, r)
+}
+
+// SDFShape is a shape whose surface is where an SDF is 0. It is intersected by sphere tracing: the
+// ray steps by the distance to the surface until it is closer than epsilon, then goes on from the
+// other side to find the next crossing, giving maxSteps evaluations to each ray. Only the first
+// crossing of a Fractal is searched. Marching is done inside the bounds of the SDF, or up to
+// maxDistance from the origin of the ray when they are infinite.
+type SDFShape struct {
+	BaseShape
+	sdf         SDF
+	epsilon     float64
+	maxSteps    int
+	maxDistance float64
+}
+
+// NewSDFShape returns an *SDFShape of sdf with Identity matrix as transform and default material.
+func NewSDFShape(sdf SDF) *SDFShape {
+	return &SDFShape{
+		BaseShape:   NewBaseShape(),
+		sdf:         sdf,
+		epsilon:     EPSILON / 10,
+		maxSteps:    512,
+		maxDistance: 1000,
+	}
+}
+
+// LocalIntersect calculates the intersections between a ray in object space and the surface.
+func (sdfShape *SDFShape) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	length := localRay.direction.Magnitude()
+	if length == 0 {
+		return xs
+	}
+	tMin, tMax, hit := rayBoxRange(localRay, sdfShape.LocalBounds())
+	if !hit {
+		return xs
+	}
+	tMin = math.Max(tMin, -sdfShape.maxDistance/length)
+	tMax = math.Min(tMax, sdfShape.maxDistance/length)
+
+	eps := sdfShape.epsilon
+	_, fractal := sdfShape.sdf.(Fractal)
+	t := tMin
+	inside := !fractal && sdfShape.sdf.Distance(localRay.Position(t)) < 0
+	// leaving is set after a crossing until the ray is epsilon away from the surface again.
+	leaving := false
+	for step := 0; step < sdfShape.maxSteps && t <= tMax; step++ {
+		d := sdfShape.sdf.Distance(localRay.Position(t))
+		if inside {
+			d = -d
+		}
+		switch {
+		case leaving && d < -eps:
+			// A grazing ray went back to the side it came from.
+			xs = append(xs, NewIntersection(t, sdfShape))
+			inside = !inside
+			t += eps / length
+		case leaving:
+			leaving = d < eps
+			t += math.Max(d, eps) / length
+		case d < eps:
+			xs = append(xs, NewIntersection(t, sdfShape))
+			if fractal {
+				return xs
+			}
+			inside = !inside
+			leaving = true
+			t += eps / length
+		default:
+			t += d / length
+		}
+	}
+	return xs
+}
+
+// Intersect calculates the intersections between a ray in world space and the surface.
+func (sdfShape *SDFShape) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(sdfShape, worldRay, xs)
+}
+
+// LocalNormalAt returns the gradient of the SDF at the point, estimated by central differences.
+func (sdfShape *SDFShape) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	return sdfNormal(sdfShape.sdf, localPoint, sdfShape.epsilon)
+}
+
+// sdfNormal estimates the gradient of sdf at p by central differences of step h.
+func sdfNormal(sdf SDF, p Tuple, h float64) Tuple {
+	dx := sdf.Distance(Point(p.x+h, p.y, p.z)) - sdf.Distance(Point(p.x-h, p.y, p.z))
+	dy := sdf.Distance(Point(p.x, p.y+h, p.z)) - sdf.Distance(Point(p.x, p.y-h, p.z))
+	dz := sdf.Distance(Point(p.x, p.y, p.z+h)) - sdf.Distance(Point(p.x, p.y, p.z-h))
+	return Vector(dx, dy, dz).Normalize()
+}
+
+// NormalAt calculates the normal at a given point in world space.
+func (sdfShape *SDFShape) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(sdfShape, worldPoint, intersection)
+}
+
+// LocalBounds returns the bounds of the SDF, slightly enlarged so that the rays reaching the
+// surface on its sides start marching outside of it.
+func (sdfShape *SDFShape) LocalBounds() *BoundingBox {
+	b := sdfShape.sdf.Bounds()
+	e := Vector(sdfShape.epsilon, sdfShape.epsilon, sdfShape.epsilon)
+	return NewBoundingBox(b.min.Substract(e), b.max.Add(e))
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// A sphere traced SDF sphere is intersected and shaded like a Sphere.
+func TestSDFShapeSphere(t *testing.T) {
+	shape := NewSDFShape(NewSphereSDF(1))
+	shape.SetTransform(Translation(1, 0, 0))
+	sphere := NewSphere()
+	sphere.SetTransform(Translation(1, 0, 0))
+
+	rays := []Ray{
+		NewRay(Point(1, 0, -5), Vector(0, 0, 1)),
+		NewRay(Point(1, 0, 0), Vector(0, 0, 1)),
+		NewRay(Point(-4, 0.5, 0.2), Vector(2, 0, 0)),
+		NewRay(Point(3, 4, 1), Vector(-0.2, -1, -0.1).Normalize()),
+		NewRay(Point(1, 2, -5), Vector(0, 0, 1)),
+	}
+	for _, r := range rays {
+		xs, expected := shape.Intersect(r, nil), sphere.Intersect(r, nil)
+		if len(xs) != len(expected) {
+			t.Errorf("SDF sphere intersections count: got %v, expected: %v", len(xs), len(expected))
+			continue
+		}
+		for i := range xs {
+			if math.Abs(xs[i].t-expected[i].t) > EPSILON {
+				t.Errorf("SDF sphere intersection: got %v, expected: %v", xs[i].t, expected[i].t)
+			}
+			point := r.Position(xs[i].t)
+			if n, want := shape.NormalAt(point, &xs[i]), sphere.NormalAt(point, &expected[i]); !n.Equals(want) {
+				t.Errorf("SDF sphere normal: got %v, expected: %v", n, want)
+			}
+		}
+	}
+}
+
+// The distance of each primitive is 0 on its surface, negative inside and positive outside.
+func TestSDFPrimitives(t *testing.T) {
+	tests := []struct {
+		sdf      SDF
+		point    Tuple
+		expected float64
+	}{
+		{NewBoxSDF(1, 2, 3), Point(0, 0, 0), -1},
+		{NewBoxSDF(1, 2, 3), Point(3, 0, 0), 2},
+		{NewBoxSDF(1, 2, 3), Point(4, 6, 0), 5},
+		{NewRoundedBoxSDF(1, 1, 1, 0.5), Point(2, 0, 0), 1},
+		{NewRoundedBoxSDF(1, 1, 1, 0.5), Point(1, 1, 0), math.Sqrt2/2 - 0.5},
+		{NewTorusSDF(1, 0.25), Point(1, 0, 0), -0.25},
+		{NewTorusSDF(1, 0.25), Point(0, 1, 0), math.Sqrt2 - 0.25},
+		{NewCapsuleSDF(Point(0, -1, 0), Point(0, 1, 0), 0.5), Point(2, 0.5, 0), 1.5},
+		{NewCapsuleSDF(Point(0, -1, 0), Point(0, 1, 0), 0.5), Point(0, 3, 0), 1.5},
+		{NewTranslateSDF(NewSphereSDF(1), 0, 2, 0), Point(0, 0, 0), 1},
+	}
+	for _, test := range tests {
+		if d := test.sdf.Distance(test.point); !floatEqual(d, test.expected) {
+			t.Errorf("SDF distance at %v: got %v, expected: %v", test.point, d, test.expected)
+		}
+	}
+}
+
+// Smooth operations blend their operands, and are sharp with a k of 0.
+func TestSDFSmoothOperations(t *testing.T) {
+	a := NewTranslateSDF(NewSphereSDF(1), -0.75, 0, 0)
+	b := NewTranslateSDF(NewSphereSDF(1), 0.75, 0, 0)
+	origin := Point(0, 0, 0)
+
+	if d := NewSmoothUnionSDF(a, b, 0).Distance(origin); !floatEqual(d, -0.25) {
+		t.Errorf("Sharp union: got %v, expected: %v", d, -0.25)
+	}
+	if d := NewSmoothUnionSDF(a, b, 0.5).Distance(origin); !floatEqual(d, -0.375) {
+		t.Errorf("Smooth union: got %v, expected: %v", d, -0.375)
+	}
+	if d := NewSmoothSubtractionSDF(a, b, 0).Distance(origin); !floatEqual(d, 0.25) {
+		t.Errorf("Sharp subtraction: got %v, expected: %v", d, 0.25)
+	}
+	if d := NewSmoothIntersectionSDF(a, b, 0.5).Distance(origin); !floatEqual(d, -0.125) {
+		t.Errorf("Smooth intersection: got %v, expected: %v", d, -0.125)
+	}
+
+	// The union is found between the spheres on the x axis, where the blend fills the gap.
+	shape := NewSDFShape(NewSmoothUnionSDF(
+		NewTranslateSDF(NewSphereSDF(1), -1.1, 0, 0),
+		NewTranslateSDF(NewSphereSDF(1), 1.1, 0, 0), 0.5))
+	xs := shape.Intersect(NewRay(Point(0, 5, 0), Vector(0, -1, 0)), nil)
+	if len(xs) != 2 {
+		t.Fatalf("Smooth union intersections count: got %v, expected: %v", len(xs), 2)
+	}
+
+	box := shape.LocalBounds()
+	if box.min.x > -2.1-0.125 || box.max.y < 1.125 {
+		t.Errorf("Smooth union bounds: got %v, expected to contain: %v", box, NewBoundingBoxFloat(-2.225, -1.125, -1.125, 2.225, 1.125, 1.125))
+	}
+}
+
+// Repetition copies a shape along an axis, and twisting keeps the shape inside its bounds.
+func TestSDFRepeatAndTwist(t *testing.T) {
+	repeat := NewSDFShape(NewRepeatSDF(NewSphereSDF(0.5), Vector(2, 0, 0), Vector(2, 0, 0)))
+	xs := repeat.Intersect(NewRay(Point(-10, 0, 0), Vector(1, 0, 0)), nil)
+	expected := []float64{5.5, 6.5, 7.5, 8.5, 9.5, 10.5, 11.5, 12.5, 13.5, 14.5}
+	if len(xs) != len(expected) {
+		t.Fatalf("Repeat intersections count: got %v, expected: %v", len(xs), len(expected))
+	}
+	for i := range xs {
+		if math.Abs(xs[i].t-expected[i]) > EPSILON {
+			t.Errorf("Repeat intersection: got %v, expected: %v", xs[i].t, expected[i])
+		}
+	}
+	box := repeat.LocalBounds()
+	if !floatEqual(box.min.x, -4.5-repeat.epsilon) || !floatEqual(box.max.x, 4.5+repeat.epsilon) {
+		t.Errorf("Repeat bounds: got %v, expected: %v", box, NewBoundingBoxFloat(-4.5, -0.5, -0.5, 4.5, 0.5, 0.5))
+	}
+
+	infinite := NewSDFShape(NewRepeatSDF(NewSphereSDF(0.5), Vector(2, 0, 2), Vector(math.Inf(1), 0, math.Inf(1))))
+	if infinite.LocalBounds().IsFinite() {
+		t.Errorf("Infinite repeat bounds: got %v, expected infinite bounds", infinite.LocalBounds())
+	}
+	if xs := infinite.Intersect(NewRay(Point(100, 5, 100), Vector(0, -1, 0)), nil); len(xs) != 2 || math.Abs(xs[0].t-4.5) > EPSILON {
+		t.Errorf("Infinite repeat intersections: got %v, expected: %v", xs, []float64{4.5, 5.5})
+	}
+
+	// A box twisted by a quarter turn from bottom to top: on each plane y = k the section is the
+	// square rotated by amount * k.
+	twist := NewSDFShape(NewTwistSDF(NewBoxSDF(1, 1, 0.2), PI/4))
+	for _, y := range []float64{-0.9, -0.5, 0, 0.5, 0.9} {
+		angle := PI / 4 * y
+		direction := Vector(math.Cos(angle), 0, math.Sin(angle))
+		origin := Point(0, y, 0).Substract(direction.Multiply(5))
+		xs := twist.Intersect(NewRay(origin, direction), nil)
+		if len(xs) != 2 || math.Abs(xs[0].t-4) > 1e-4 || math.Abs(xs[1].t-6) > 1e-4 {
+			t.Errorf("Twist intersections at y = %v: got %v, expected: %v", y, xs, []float64{4, 6})
+		}
+	}
+	box = twist.LocalBounds()
+	r := math.Sqrt(1 + 0.2*0.2)
+	if box.max.x < r || box.max.z < r || box.min.y > -1 {
+		t.Errorf("Twist bounds: got %v, expected to contain: %v", box, NewBoundingBoxFloat(-r, -1, -r, r, 1, r))
+	}
+}
+
+// SDF shapes go in groups and the BVH of the world like any bounded shape.
+func TestSDFShapeInWorld(t *testing.T) {
+	shape := NewSDFShape(NewRoundedBoxSDF(1, 1, 1, 0.2))
+	g := NewGroup()
+	g.AddChild(shape)
+	g.SetTransform(Translation(0, 0, 5))
+	w := NewWorld([]*PointLight{NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1))}, []Shape{g, NewSphere()})
+	w.BuildBVH()
+
+	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
+	xs := w.Intersect(r)
+	if len(xs) != 4 || xs[2].object != shape || math.Abs(xs[2].t-9) > EPSILON {
+		t.Errorf("SDF shape in world: got %v, expected the rounded box at t = %v", xs, 9)
+	}
+	comps := PrepareComputations(&xs[2], r, xs)
+	if !comps.normalv.Equals(Vector(0, 0, -1)) {
+		t.Errorf("SDF shape normal: got %v, expected: %v", comps.normalv, Vector(0, 0, -1))
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import "math/rand"
+
+// Shape interface defining any object in the scene.
+//
+// Shapes defined outside of this file only need to embed BaseShape, which takes care of the
+// transformation, its cached inverses, the material, the parent and the id, and then implement
+// the local (object space) contract: LocalIntersect, LocalNormalAt and LocalBounds.
+// Intersect and NormalAt can be delegated to the package level Intersect() and NormalAt() functions.
+type Shape interface {
+	SetMaterial(*Material)
+	SetTransform(Matrix4)
+	ApplyTransform(Matrix4)
+	ResetTransform()
+	SetTranslation(x, y, z float64)
+	SetRotation(x, y, z float64)
+	SetScale(x, y, z float64)
+	GetTranslation() Tuple
+	GetRotation() Tuple
+	GetScale() Tuple
+	Transform() Matrix4
+	GetInverse() Matrix4
+	GetInverseTranspose() Matrix4
+	Material() *Material
+	Intersect(Ray, Intersections) Intersections
+	LocalIntersect(Ray, Intersections) Intersections
+	NormalAt(Tuple, *Intersection) Tuple
+	LocalNormalAt(Tuple, *Intersection) Tuple
+	LocalBounds() *BoundingBox
+	GetParent() Shape
+	SetParent(shape Shape)
+	GetID() int
+	Generation() uint64
+	touch()
+}
+
+// BaseShape contains the state shared by every Shape and implements the part of the Shape interface
+// that does not depend on the geometry of the shape.
+type BaseShape struct {
+	transform        Matrix4
+	inverse          Matrix4
+	inverseTranspose Matrix4
+	trs              TRS
+	material         *Material
+	parent           Shape
+	id               int
+	// generation counts the changes of the transformation or the bounds of the shape and its descendants.
+	generation uint64
+}
+
+// NewBaseShape returns a BaseShape with Identity matrix as transform and default material.
+func NewBaseShape() BaseShape {
+	return BaseShape{
+		transform:        IdentityMatrix,
+		inverse:          IdentityMatrix,
+		inverseTranspose: IdentityMatrix,
+		trs:              NewTRS(),
+		material:         DefaultMaterial(),
+		id:               rand.Int(),
+	}
+}
+
+// GetID returns the id of the shape.
+func (base *BaseShape) GetID() int {
+	return base.id
+}
+
+// GetParent returns the parent shape from this current shape.
+func (base *BaseShape) GetParent() Shape {
+	return base.parent
+}
+
+// SetParent sets the parent shape from this current shape.
+func (base *BaseShape) SetParent(shape Shape) {
+	base.parent = shape
+}
+
+// Material returns the material of the shape.
+func (base *BaseShape) Material() *Material {
+	return base.material
+}
+
+// SetMaterial sets the shape's material.
+func (base *BaseShape) SetMaterial(material *Material) {
+	base.material = material
+}
+
+// SetTransform replaces the shape's transformation.
+// The translation, rotation and scale components are recovered from the new transformation.
+func (base *BaseShape) SetTransform(transformation Matrix4) {
+	base.trs = TRSFromMatrix(transformation)
+	base.updateTransform(transformation)
+}
+
+// ApplyTransform composes the transformation with the current one, the new transformation being
+// applied to the shape before the existing one.
+func (base *BaseShape) ApplyTransform(transformation Matrix4) {
+	base.SetTransform(base.transform.MultiplyMatrix(transformation))
+}
+
+// ResetTransform sets the shape's transformation back to the identity.
+func (base *BaseShape) ResetTransform() {
+	base.SetTransform(IdentityMatrix)
+}
+
+// SetTranslation replaces the translation component and rebuilds the transformation from its components.
+func (base *BaseShape) SetTranslation(x, y, z float64) {
+	base.trs.translation = Vector(x, y, z)
+	base.updateTransform(base.trs.Matrix())
+}
+
+// SetRotation replaces the rotation component (radians around x, y and z) and rebuilds the transformation from its components.
+func (base *BaseShape) SetRotation(x, y, z float64) {
+	base.trs.rotation = Vector(x, y, z)
+	base.updateTransform(base.trs.Matrix())
+}
+
+// SetScale replaces the scale component and rebuilds the transformation from its components.
+func (base *BaseShape) SetScale(x, y, z float64) {
+	base.trs.scale = Vector(x, y, z)
+	base.updateTransform(base.trs.Matrix())
+}
+
+// GetTranslation returns the translation component of the shape.
+func (base *BaseShape) GetTranslation() Tuple {
+	return base.trs.translation
+}
+
+// GetRotation returns the rotation component of the shape.
+func (base *BaseShape) GetRotation() Tuple {
+	return base.trs.rotation
+}
+
+// GetScale returns the scale component of the shape.
+func (base *BaseShape) GetScale() Tuple {
+	return base.trs.scale
+}
+
+// Generation returns a number that changes whenever the transformation or the bounds of the shape,
+// or of one of its descendants, change, so that a world can tell whether the shape moved since its
+// BVH was built.
+func (base *BaseShape) Generation() uint64 {
+	return base.generation
+}
+
+// touch records a change of the transformation or the bounds of the shape, which changes the bounds
+// of its parents as well.
+func (base *BaseShape) touch() {
+	base.generation++
+	if base.parent != nil {
+		base.parent.touch()
+	}
+}
+
+// updateTransform sets the transformation and its cached inverses.
+func (base *BaseShape) updateTransform(transformation Matrix4) {
+	base.touch()
+	base.transform = transformation
+	base.inverse = transformation.Inverse()
+	base.inverseTranspose = base.inverse.Transpose()
+}
+
+// Transform returns the transformation.
+func (base *BaseShape) Transform() Matrix4 {
+	return base.transform
+}
+
+// GetInverse returns the cached inverse matrix of the current Shape.
+func (base *BaseShape) GetInverse() Matrix4 {
+	return base.inverse
+}
+
+// GetInverseTranspose returns the cached inverseTranspose matrix of the current Shape.
+func (base *BaseShape) GetInverseTranspose() Matrix4 {
+	return base.inverseTranspose
+}
+
+// Intersect transforms the ray from world (or parent) space into the shape's object space
+// and delegates to the shape's LocalIntersect implementation, which appends its intersections to xs.
+func Intersect(s Shape, worldRay Ray, xs Intersections) Intersections {
+	localRay := worldRay.Transform(s.GetInverse())
+	return s.LocalIntersect(localRay, xs)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// testShape is a minimal Shape built only on top of the exported contract, the same way
+// a primitive defined outside of this package would be written.
+type testShape struct {
+	BaseShape
+	savedRay Ray
+}
+
+func newTestShape() *testShape {
+	return &testShape{BaseShape: NewBaseShape()}
+}
+
+func (s *testShape) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	s.savedRay = localRay
+	return append(xs, NewIntersection(1, s))
+}
+
+func (s *testShape) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	return Vector(localPoint.x, localPoint.y, localPoint.z)
+}
+
+func (s *testShape) LocalBounds() *BoundingBox {
+	return NewBoundingBoxFloat(-2, -2, -2, 2, 2, 2)
+}
+
+func (s *testShape) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(s, worldRay, xs)
+}
+
+func (s *testShape) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(s, worldPoint, intersection)
+}
+
+func TestBaseShapeDefaults(t *testing.T) {
+	// A shape embedding BaseShape has a default transformation and material.
+	s := newTestShape()
+
+	if !s.Transform().Equals(IdentityMatrix) {
+		t.Errorf("BaseShape default transform: got %v, expected: %v", s.Transform(), IdentityMatrix)
+	}
+	if !s.Material().color.Equals(DefaultMaterial().color) {
+		t.Errorf("BaseShape default material: got %v, expected: %v", s.Material().color, DefaultMaterial().color)
+	}
+	if s.GetParent() != nil {
+		t.Errorf("BaseShape default parent: got %v, expected: %v", s.GetParent(), nil)
+	}
+}
+
+func TestIntersectScaledExternalShape(t *testing.T) {
+	// Intersecting a scaled shape with a ray transforms the ray into object space.
+	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
+	s := newTestShape()
+	s.SetTransform(Scaling(2, 2, 2))
+	s.Intersect(r, nil)
+
+	expectedOrigin := Point(0, 0, -2.5)
+	expectedDirection := Vector(0, 0, 0.5)
+	if !s.savedRay.origin.Equals(expectedOrigin) {
+		t.Errorf("Intersecting a scaled shape: got %v, expected: %v", s.savedRay.origin, expectedOrigin)
+	}
+	if !s.savedRay.direction.Equals(expectedDirection) {
+		t.Errorf("Intersecting a scaled shape: got %v, expected: %v", s.savedRay.direction, expectedDirection)
+	}
+}
+
+func TestNormalOnExternalShapeInGroup(t *testing.T) {
+	// Finding the normal on an externally defined shape nested in a transformed group.
+	g := NewGroup()
+	g.SetTransform(RotationY(math.Pi / 2))
+	s := newTestShape()
+	s.SetTransform(Translation(5, 0, 0))
+	g.AddChild(s)
+
+	// Point(0, 0, -6) in world space is Point(1, 0, 0) in the shape's object space.
+	n := s.NormalAt(Point(0, 0, -6), nil)
+	expected := Vector(0, 0, -1)
+	if !n.Equals(expected) {
+		t.Errorf("Normal on an external shape in a group: got %v, expected: %v", n, expected)
+	}
+}
+
+func TestBoundsOfExternalShape(t *testing.T) {
+	// Bounds() uses the bounding box reported by the shape itself.
+	s := newTestShape()
+	s.SetTransform(Translation(1, 0, 0))
+	g := NewGroup()
+	g.AddChild(s)
+
+	box := Bounds(g)
+	expectedMin := Point(-1, -2, -2)
+	expectedMax := Point(3, 2, 2)
+	if !box.min.Equals(expectedMin) || !box.max.Equals(expectedMax) {
+		t.Errorf("Bounds of an external shape: got %v %v, expected: %v %v", box.min, box.max, expectedMin, expectedMax)
+	}
+}
+
+func TestExternalShapeInCSG(t *testing.T) {
+	// An externally defined shape can be used as a CSG operand.
+	s1 := newTestShape()
+	s2 := NewSphere()
+	c := NewCSG("union", s1, s2)
+
+	if !includes(c.left, s1) || includes(c.left, s2) {
+		t.Errorf("External shape in CSG: left child should only include the external shape")
+	}
+	if s1.GetParent() != c {
+		t.Errorf("External shape in CSG: got parent %v, expected: %v", s1.GetParent(), c)
+	}
+}
+
+func TestSetTransformReplacesTransform(t *testing.T) {
+	// SetTransform replaces the transformation while ApplyTransform composes it.
+	s := NewSphere()
+	s.SetTransform(Translation(1, 2, 3))
+	s.SetTransform(Scaling(2, 2, 2))
+	if !s.Transform().Equals(Scaling(2, 2, 2)) {
+		t.Errorf("SetTransform: got %v, expected: %v", s.Transform(), Scaling(2, 2, 2))
+	}
+
+	s.SetTransform(Translation(1, 2, 3))
+	s.ApplyTransform(Scaling(2, 2, 2))
+	expected := Translation(1, 2, 3).MultiplyMatrix(Scaling(2, 2, 2))
+	if !s.Transform().Equals(expected) {
+		t.Errorf("ApplyTransform: got %v, expected: %v", s.Transform(), expected)
+	}
+	if !s.GetInverse().Equals(expected.Inverse()) {
+		t.Errorf("ApplyTransform inverse: got %v, expected: %v", s.GetInverse(), expected.Inverse())
+	}
+
+	s.ResetTransform()
+	if !s.Transform().Equals(IdentityMatrix) || !s.GetInverse().Equals(IdentityMatrix) {
+		t.Errorf("ResetTransform: got %v, expected: %v", s.Transform(), IdentityMatrix)
+	}
+}
+
+func TestShapeTransformComponents(t *testing.T) {
+	// Editing one component keeps the other ones.
+	s := NewSphere()
+	s.SetScale(2, 2, 2)
+	s.SetRotation(0, math.Pi/2, 0)
+	s.SetTranslation(1, 0, 0)
+	s.SetScale(1, 3, 1)
+
+	expected := Translation(1, 0, 0).MultiplyMatrix(RotationY(math.Pi / 2)).MultiplyMatrix(Scaling(1, 3, 1))
+	if !s.Transform().Equals(expected) {
+		t.Errorf("Transform components: got %v, expected: %v", s.Transform(), expected)
+	}
+	if !s.GetTranslation().Equals(Vector(1, 0, 0)) || !s.GetRotation().Equals(Vector(0, math.Pi/2, 0)) || !s.GetScale().Equals(Vector(1, 3, 1)) {
+		t.Errorf("Transform components: got %v %v %v", s.GetTranslation(), s.GetRotation(), s.GetScale())
+	}
+
+	// Replacing the whole transformation recovers the components from it.
+	s.SetTransform(Translation(0, 5, 0))
+	if !s.GetTranslation().Equals(Vector(0, 5, 0)) || !s.GetScale().Equals(Vector(1, 1, 1)) {
+		t.Errorf("Transform components after SetTransform: got %v %v", s.GetTranslation(), s.GetScale())
+	}
+}
+
+func TestShapeTransformMixedAPIs(t *testing.T) {
+	// Composing a transformation keeps the components set before.
+	s := NewSphere()
+	s.SetTranslation(1, 0, 0)
+	s.ApplyTransform(Scaling(2, 2, 2))
+	if !s.GetTranslation().Equals(Vector(1, 0, 0)) || !s.GetScale().Equals(Vector(2, 2, 2)) {
+		t.Errorf("Transform components after ApplyTransform: got %v %v, expected: %v %v", s.GetTranslation(), s.GetScale(), Vector(1, 0, 0), Vector(2, 2, 2))
+	}
+
+	s.SetRotation(0, math.Pi/2, 0)
+	expected := Translation(1, 0, 0).MultiplyMatrix(RotationY(math.Pi / 2)).MultiplyMatrix(Scaling(2, 2, 2))
+	if !s.Transform().Equals(expected) {
+		t.Errorf("SetRotation after ApplyTransform: got %v, expected: %v", s.Transform(), expected)
+	}
+
+	// A shearing set as a whole matrix is kept when a component changes.
+	s.SetTransform(Shearing(1, 0, 0, 0, 0, 0))
+	s.SetTranslation(0, 2, 0)
+	expected = Translation(0, 2, 0).MultiplyMatrix(Shearing(1, 0, 0, 0, 0, 0))
+	if !s.Transform().Equals(expected) {
+		t.Errorf("SetTranslation after a shearing: got %v, expected: %v", s.Transform(), expected)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// strokeCapHeight is the height of the capitals of the stroke font, in font units.
+const strokeCapHeight = 8
+
+// strokeSpacing is the space after the strokes of a glyph of the stroke font included in its advance.
+const strokeSpacing = 2
+
+// strokeFontData is a stroke font in the spirit of the Hershey fonts, drawn on a grid where the
+// baseline is at y = 0, the capitals are 8 high, the lowercase letters 5 high and the descenders go
+// down to -3. A glyph is its advance, then its strokes separated by semicolons, each stroke a line
+// through x,y points. Curves are drawn with chamfered corners.
+var strokeFontData = map[rune]string{
+	' ':  "6|",
+	'!':  "3|1,8 1,2.5;1,0.5 1,0",
+	'"':  "5|1,8 1,6;3,8 3,6",
+	'#':  "8|2,0 2,8;4,0 4,8;0,3 6,3;0,5 6,5",
+	'$':  "8|6,7 5,8 1,8 0,7 0,5 1,4 5,4 6,3 6,1 5,0 1,0 0,1;3,9 3,-1",
+	'%':  "8|0,0 6,8;0,8 0,6 2,6 2,8 0,8;4,2 4,0 6,0 6,2 4,2",
+	'&':  "8|6,0 1,6 1,7 2,8 3,8 4,7 4,6 0,2 0,1 1,0 3,0 6,3",
+	'\'': "3|1,8 1,6",
+	'(':  "4|2,9 0,7 0,1 2,-1",
+	')':  "4|0,9 2,7 2,1 0,-1",
+	'*':  "8|3,2 3,8;0.4,3.5 5.6,6.5;0.4,6.5 5.6,3.5",
+	'+':  "8|0,4 6,4;3,1 3,7",
+	',':  "3|1,0.5 1,0 0,-1.5",
+	'-':  "6|0,4 4,4",
+	'.':  "3|1,0.5 1,0",
+	'/':  "7|0,0 5,8",
+	'0':  "8|1,0 0,1 0,7 1,8 5,8 6,7 6,1 5,0 1,0;1,1 5,7",
+	'1':  "8|1,6 3,8 3,0;1,0 5,0",
+	'2':  "8|0,7 1,8 5,8 6,7 6,5 0,0 6,0",
+	'3':  "8|0,7 1,8 5,8 6,7 6,5 5,4 2,4;5,4 6,3 6,1 5,0 1,0 0,1",
+	'4':  "8|5,0 5,8 0,2 6,2",
+	'5':  "8|6,8 0,8 0,4 5,4 6,3 6,1 5,0 1,0 0,1",
+	'6':  "8|6,7 5,8 1,8 0,7 0,1 1,0 5,0 6,1 6,3 5,4 0,4",
+	'7':  "8|0,8 6,8 2,0",
+	'8':  "8|1,4 0,5 0,7 1,8 5,8 6,7 6,5 5,4 1,4 0,3 0,1 1,0 5,0 6,1 6,3 5,4",
+	'9':  "8|0,1 1,0 5,0 6,1 6,7 5,8 1,8 0,7 0,5 1,4 6,4",
+	':':  "3|1,5 1,4.5;1,0.5 1,0",
+	';':  "3|1,5 1,4.5;1,0.5 1,0 0,-1.5",
+	'<':  "7|5,7 0,4 5,1",
+	'=':  "8|0,2.5 6,2.5;0,5.5 6,5.5",
+	'>':  "7|0,7 5,4 0,1",
+	'?':  "8|0,7 1,8 5,8 6,7 6,5 3,3 3,2;3,0.5 3,0",
+	'A':  "8|0,0 3,8 6,0;1.125,3 4.875,3",
+	'B':  "8|0,0 0,8 4,8 5,7 5,5 4,4;0,4 5,4 6,3 6,1 5,0 0,0",
+	'C':  "8|6,7 5,8 1,8 0,7 0,1 1,0 5,0 6,1",
+	'D':  "8|0,0 0,8 4,8 6,6 6,2 4,0 0,0",
+	'E':  "8|6,8 0,8 0,0 6,0;0,4 4,4",
+	'F':  "8|6,8 0,8 0,0;0,4 4,4",
+	'G':  "8|6,7 5,8 1,8 0,7 0,1 1,0 5,0 6,1 6,4 3,4",
+	'H':  "8|0,0 0,8;6,0 6,8;0,4 6,4",
+	'I':  "6|0,8 4,8;2,8 2,0;0,0 4,0",
+	'J':  "8|6,8 6,1 5,0 1,0 0,1 0,2",
+	'K':  "8|0,0 0,8;6,8 0,2;2,4 6,0",
+	'L':  "8|0,8 0,0 6,0",
+	'M':  "8|0,0 0,8 3,3 6,8 6,0",
+	'N':  "8|0,0 0,8 6,0 6,8",
+	'O':  "8|1,0 0,1 0,7 1,8 5,8 6,7 6,1 5,0 1,0",
+	'P':  "8|0,0 0,8 5,8 6,7 6,5 5,4 0,4",
+	'Q':  "8|1,0 0,1 0,7 1,8 5,8 6,7 6,1 5,0 1,0;4,2 6.5,-0.5",
+	'R':  "8|0,0 0,8 5,8 6,7 6,5 5,4 0,4;3,4 6,0",
+	'S':  "8|6,7 5,8 1,8 0,7 0,5 1,4 5,4 6,3 6,1 5,0 1,0 0,1",
+	'T':  "8|0,8 6,8;3,8 3,0",
+	'U':  "8|0,8 0,1 1,0 5,0 6,1 6,8",
+	'V':  "8|0,8 3,0 6,8",
+	'W':  "8|0,8 1.5,0 3,5 4.5,0 6,8",
+	'X':  "8|0,0 6,8;0,8 6,0",
+	'Y':  "8|0,8 3,4 6,8;3,4 3,0",
+	'Z':  "8|0,8 6,8 0,0 6,0",
+	'[':  "4|2,9 0,9 0,-1 2,-1",
+	'\\': "7|0,8 5,0",
+	']':  "4|0,9 2,9 2,-1 0,-1",
+	'_':  "8|0,-1 6,-1",
+	'a':  "7|1,5 4,5 5,4 5,0;5,3 1,3 0,2 0,1 1,0 4,0 5,1",
+	'b':  "7|0,8 0,0;0,4 1,5 4,5 5,4 5,1 4,0 1,0 0,1",
+	'c':  "7|5,4 4,5 1,5 0,4 0,1 1,0 4,0 5,1",
+	'd':  "7|5,8 5,0;5,4 4,5 1,5 0,4 0,1 1,0 4,0 5,1",
+	'e':  "7|0,2.5 5,2.5 5,4 4,5 1,5 0,4 0,1 1,0 4,0 5,1",
+	'f':  "6|4,8 3,8 2,7 2,0;0,5 4,5",
+	'g':  "7|5,5 5,-2 4,-3 1,-3 0,-2;5,4 4,5 1,5 0,4 0,1 1,0 4,0 5,1",
+	'h':  "7|0,8 0,0;0,4 1,5 4,5 5,4 5,0",
+	'i':  "3|1,5 1,0;1,7 1,6.5",
+	'j':  "4|2,5 2,-2 1,-3 0,-3;2,7 2,6.5",
+	'k':  "6|0,8 0,0;0,1.5 4,5;1.6,2.9 4,0",
+	'l':  "4|0,8 1,8 1,1 2,0",
+	'm':  "8|0,5 0,0;0,4 1,5 2,5 3,4 3,0;3,4 4,5 5,5 6,4 6,0",
+	'n':  "7|0,5 0,0;0,4 1,5 4,5 5,4 5,0",
+	'o':  "7|1,0 0,1 0,4 1,5 4,5 5,4 5,1 4,0 1,0",
+	'p':  "7|0,5 0,-3;0,4 1,5 4,5 5,4 5,1 4,0 1,0 0,1",
+	'q':  "7|5,5 5,-3;5,4 4,5 1,5 0,4 0,1 1,0 4,0 5,1",
+	'r':  "6|0,5 0,0;0,3 2,5 4,5",
+	's':  "7|5,4 4,5 1,5 0,4 0,3.5 1,2.5 4,2.5 5,1.5 5,1 4,0 1,0 0,1",
+	't':  "6|2,8 2,1 3,0 4,0;0,5 4,5",
+	'u':  "7|0,5 0,1 1,0 4,0 5,1;5,5 5,0",
+	'v':  "7|0,5 2.5,0 5,5",
+	'w':  "8|0,5 1.5,0 3,4 4.5,0 6,5",
+	'x':  "7|0,0 5,5;0,5 5,0",
+	'y':  "7|0,5 2.8,0;5,5 1.5,-3",
+	'z':  "7|0,5 5,5 0,0 5,0",
+}
+
+// strokeGlyph is a character of the stroke font, in font units.
+type strokeGlyph struct {
+	advance float64
+	strokes [][]Tuple
+}
+
+// strokeGlyphs holds the parsed glyphs of strokeFontData.
+var strokeGlyphs = parseStrokeFont(strokeFontData)
+
+// parseStrokeFont parses the glyphs of a stroke font. The font is built in, so a malformed glyph is
+// a programming error that panics.
+func parseStrokeFont(data map[rune]string) map[rune]*strokeGlyph {
+	glyphs := make(map[rune]*strokeGlyph, len(data))
+	for r, glyphData := range data {
+		parts := strings.SplitN(glyphData, "|", 2)
+		advance, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			panic(err)
+		}
+		glyph := &strokeGlyph{advance: advance}
+		for _, strokeData := range strings.Split(parts[1], ";") {
+			stroke := []Tuple{}
+			for _, pointData := range strings.Fields(strokeData) {
+				coordinates := strings.Split(pointData, ",")
+				x, err := strconv.ParseFloat(coordinates[0], 64)
+				if err != nil {
+					panic(err)
+				}
+				y, err := strconv.ParseFloat(coordinates[1], 64)
+				if err != nil {
+					panic(err)
+				}
+				stroke = append(stroke, Point(x, y, 0))
+			}
+			if len(stroke) > 0 {
+				glyph.strokes = append(glyph.strokes, stroke)
+			}
+		}
+		glyphs[r] = glyph
+	}
+	return glyphs
+}
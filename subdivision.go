@@ -0,0 +1,700 @@
+package main
+
+import "math"
+
+// SubdivisionScheme selects the rules used to subdivide the faces of a mesh.
+type SubdivisionScheme int
+
+const (
+	// AutoSubdivision uses the Loop scheme for meshes made of triangles only and Catmull-Clark otherwise.
+	AutoSubdivision SubdivisionScheme = iota
+	// CatmullClarkSubdivision splits a face of n sides into n quads, for quad-dominant meshes.
+	CatmullClarkSubdivision
+	// LoopSubdivision splits a triangle into four, for triangle meshes. Other faces are split into
+	// triangles first.
+	LoopSubdivision
+	// LinearSubdivision splits triangles like Loop but keeps the new vertices on the faces, to
+	// tessellate a mesh without changing its shape.
+	LinearSubdivision
+)
+
+// SubdivisionConfig contains the parameters of the subdivision of a mesh.
+type SubdivisionConfig struct {
+	scheme SubdivisionScheme
+	// levels is the number of times the faces are split.
+	levels int
+	// creaseAngle makes the edges between faces whose normals differ by more than this angle, in
+	// radians, infinitely sharp. 0 keeps all the edges smooth.
+	creaseAngle float64
+	// creases holds the sharpness of edges given by the indices of their vertices. An edge of
+	// sharpness s is subdivided as a crease for s levels, fractional sharpness blending the smooth
+	// and the crease rules.
+	creases map[[2]int]float64
+	// limitNormals gives the smooth vertices the normals of the limit surface, otherwise the
+	// normals of the faces around a vertex are averaged.
+	limitNormals bool
+}
+
+// DefaultSubdivisionConfig returns two levels of subdivision with the scheme chosen from the faces,
+// no creases and limit normals.
+func DefaultSubdivisionConfig() *SubdivisionConfig {
+	return &SubdivisionConfig{
+		scheme:       AutoSubdivision,
+		levels:       2,
+		creases:      map[[2]int]float64{},
+		limitNormals: true,
+	}
+}
+
+// SetCrease sets the sharpness of the edge between the vertices a and b, math.Inf(1) for an edge
+// that stays sharp.
+func (config *SubdivisionConfig) SetCrease(a, b int, sharpness float64) {
+	config.creases[edgeKey(a, b)] = sharpness
+}
+
+// edgeKey returns the key of the edge between two vertices, the same in both directions.
+func edgeKey(a, b int) [2]int {
+	if a > b {
+		return [2]int{b, a}
+	}
+	return [2]int{a, b}
+}
+
+// polygonMesh is a mesh whose faces have any number of sides, the representation in which meshes
+// are subdivided before being split into the triangles of a Mesh.
+type polygonMesh struct {
+	vertices []Tuple
+	faces    [][]int
+	uvs      []Tuple
+	// uvFaces holds the texture coordinate indices of the corners of the faces, -1 for a missing
+	// one. It is nil for meshes without texture coordinates.
+	uvFaces     [][]int
+	materialIDs []int
+	// creases holds the sharpness of the edges that are not smooth, boundaries excepted.
+	creases map[[2]int]float64
+}
+
+// meshTopology holds the edges of a polygon mesh and the faces and edges around its vertices.
+type meshTopology struct {
+	edges       [][2]int
+	edgeFaces   [][]int
+	edgeIndex   map[[2]int]int
+	vertexEdges [][]int
+	vertexFaces [][]int
+}
+
+// newMeshTopology finds the edges of the faces of the mesh.
+func newMeshTopology(mesh *polygonMesh) *meshTopology {
+	topology := &meshTopology{
+		edgeIndex:   map[[2]int]int{},
+		vertexEdges: make([][]int, len(mesh.vertices)),
+		vertexFaces: make([][]int, len(mesh.vertices)),
+	}
+	for f, face := range mesh.faces {
+		for i, a := range face {
+			key := edgeKey(a, face[(i+1)%len(face)])
+			e, ok := topology.edgeIndex[key]
+			if !ok {
+				e = len(topology.edges)
+				topology.edgeIndex[key] = e
+				topology.edges = append(topology.edges, key)
+				topology.edgeFaces = append(topology.edgeFaces, nil)
+				topology.vertexEdges[key[0]] = append(topology.vertexEdges[key[0]], e)
+				topology.vertexEdges[key[1]] = append(topology.vertexEdges[key[1]], e)
+			}
+			topology.edgeFaces[e] = append(topology.edgeFaces[e], f)
+			topology.vertexFaces[a] = append(topology.vertexFaces[a], f)
+		}
+	}
+	return topology
+}
+
+// other returns the vertex at the other end of an edge.
+func (topology *meshTopology) other(e, v int) int {
+	if topology.edges[e][0] == v {
+		return topology.edges[e][1]
+	}
+	return topology.edges[e][0]
+}
+
+// sharpness returns the sharpness of an edge, infinite on the boundary of the mesh and where more
+// than two faces meet.
+func (mesh *polygonMesh) sharpness(topology *meshTopology, e int) float64 {
+	if len(topology.edgeFaces[e]) != 2 {
+		return math.Inf(1)
+	}
+	return mesh.creases[topology.edges[e]]
+}
+
+// faceNormal returns the normal of a face scaled by twice its area, oriented like the normals of
+// the faces of a Mesh.
+func (mesh *polygonMesh) faceNormal(f int) Tuple {
+	face := mesh.faces[f]
+	p0 := mesh.vertices[face[0]]
+	normal := Vector(0, 0, 0)
+	for i := 1; i+1 < len(face); i++ {
+		e1 := mesh.vertices[face[i]].Substract(p0)
+		e2 := mesh.vertices[face[i+1]].Substract(p0)
+		normal = normal.Add(e2.CrossProduct(e1))
+	}
+	return normal
+}
+
+// subdivide returns the Mesh of the faces subdivided with the configuration. The faces of the
+// result are made of triangles and have smooth normals, except across the sharp edges.
+func (mesh *polygonMesh) subdivide(config *SubdivisionConfig) *Mesh {
+	mesh, scheme := mesh.refine(config)
+	normals, normalFaces := mesh.vertexNormals(scheme, config.limitNormals && config.levels > 0)
+	return mesh.toMesh(normals, normalFaces)
+}
+
+// refine returns the faces subdivided levels times with the scheme of the configuration, and the
+// scheme used.
+func (mesh *polygonMesh) refine(config *SubdivisionConfig) (*polygonMesh, SubdivisionScheme) {
+	scheme := config.scheme
+	if scheme == AutoSubdivision {
+		scheme = LoopSubdivision
+		for _, face := range mesh.faces {
+			if len(face) != 3 {
+				scheme = CatmullClarkSubdivision
+				break
+			}
+		}
+	}
+
+	mesh.creases = map[[2]int]float64{}
+	for key, sharpness := range config.creases {
+		mesh.creases[key] = sharpness
+	}
+	if config.creaseAngle > 0 {
+		topology := newMeshTopology(mesh)
+		for e, faces := range topology.edgeFaces {
+			if len(faces) != 2 {
+				continue
+			}
+			n0, n1 := mesh.faceNormal(faces[0]).Normalize(), mesh.faceNormal(faces[1]).Normalize()
+			if math.Acos(math.Max(-1, math.Min(1, n0.DotProduct(n1)))) > config.creaseAngle {
+				mesh.creases[topology.edges[e]] = math.Inf(1)
+			}
+		}
+	}
+
+	if scheme != CatmullClarkSubdivision {
+		mesh = mesh.triangulate()
+	}
+	for level := 0; level < config.levels; level++ {
+		if scheme == CatmullClarkSubdivision {
+			mesh = mesh.catmullClark()
+		} else {
+			mesh = mesh.loop(scheme == LoopSubdivision)
+		}
+	}
+	return mesh, scheme
+}
+
+// triangulate splits the faces of more than three sides into triangles fanning from their first
+// corner.
+func (mesh *polygonMesh) triangulate() *polygonMesh {
+	result := &polygonMesh{vertices: mesh.vertices, uvs: mesh.uvs, creases: mesh.creases}
+	if mesh.uvFaces != nil {
+		result.uvFaces = [][]int{}
+	}
+	for f, face := range mesh.faces {
+		for i := 1; i+1 < len(face); i++ {
+			result.faces = append(result.faces, []int{face[0], face[i], face[i+1]})
+			if mesh.uvFaces != nil {
+				uvFace := mesh.uvFaces[f]
+				result.uvFaces = append(result.uvFaces, []int{uvFace[0], uvFace[i], uvFace[i+1]})
+			}
+			result.materialIDs = append(result.materialIDs, mesh.materialIDs[f])
+		}
+	}
+	return result
+}
+
+// edgePoint returns the new vertex in the middle of an edge: smooth is its position by the smooth
+// rule, sharp edges keep their middle instead.
+func (mesh *polygonMesh) edgePoint(topology *meshTopology, e int, smooth func() Tuple) Tuple {
+	edge := topology.edges[e]
+	middle := mesh.vertices[edge[0]].Add(mesh.vertices[edge[1]]).Multiply(0.5)
+	sharpness := mesh.sharpness(topology, e)
+	if sharpness >= 1 {
+		return middle
+	}
+	return smooth().Multiply(1 - sharpness).Add(middle.Multiply(sharpness))
+}
+
+// vertexPoint returns where a vertex moves: smooth is its position by the smooth rule. Vertices on
+// two sharp edges move along the crease and vertices on more stay in place, vertices on semi-sharp
+// edges blending these rules with the smooth one.
+func (mesh *polygonMesh) vertexPoint(topology *meshTopology, v int, smooth Tuple) Tuple {
+	sharpEdges := []int{}
+	total := 0.0
+	for _, e := range topology.vertexEdges[v] {
+		if sharpness := mesh.sharpness(topology, e); sharpness > 0 {
+			sharpEdges = append(sharpEdges, e)
+			total += sharpness
+		}
+	}
+	if len(sharpEdges) < 2 {
+		return smooth
+	}
+
+	p := mesh.vertices[v]
+	sharp := p
+	if len(sharpEdges) == 2 {
+		a := mesh.vertices[topology.other(sharpEdges[0], v)]
+		b := mesh.vertices[topology.other(sharpEdges[1], v)]
+		sharp = p.Multiply(0.75).Add(a.Add(b).Multiply(0.125))
+	}
+	if sharpness := total / float64(len(sharpEdges)); sharpness < 1 {
+		return smooth.Multiply(1 - sharpness).Add(sharp.Multiply(sharpness))
+	}
+	return sharp
+}
+
+// childCreases returns the creases of the halves of the creased edges, from their ends to their
+// edge point, one level less sharp. The edge points of the subdivided mesh start at firstEdgePoint.
+func (mesh *polygonMesh) childCreases(topology *meshTopology, firstEdgePoint int) map[[2]int]float64 {
+	creases := map[[2]int]float64{}
+	for key, sharpness := range mesh.creases {
+		e, ok := topology.edgeIndex[key]
+		if !ok || sharpness <= 1 {
+			continue
+		}
+		creases[edgeKey(key[0], firstEdgePoint+e)] = sharpness - 1
+		creases[edgeKey(key[1], firstEdgePoint+e)] = sharpness - 1
+	}
+	return creases
+}
+
+// uvSplitter gives the texture coordinates of the new corners of subdivided faces, interpolated
+// linearly across the faces so that seams are kept.
+type uvSplitter struct {
+	uvs    []Tuple
+	middle map[[2]int]int
+}
+
+// newUVSplitter returns a uvSplitter keeping the texture coordinates of the mesh, or nil for meshes
+// without texture coordinates.
+func newUVSplitter(mesh *polygonMesh) *uvSplitter {
+	if mesh.uvFaces == nil {
+		return nil
+	}
+	return &uvSplitter{uvs: append([]Tuple{}, mesh.uvs...), middle: map[[2]int]int{}}
+}
+
+// edge returns the texture coordinates in the middle of the ones of two corners.
+func (splitter *uvSplitter) edge(a, b int) int {
+	if a < 0 || b < 0 {
+		return -1
+	}
+	key := edgeKey(a, b)
+	if index, ok := splitter.middle[key]; ok {
+		return index
+	}
+	splitter.uvs = append(splitter.uvs, splitter.uvs[a].Add(splitter.uvs[b]).Multiply(0.5))
+	splitter.middle[key] = len(splitter.uvs) - 1
+	return len(splitter.uvs) - 1
+}
+
+// face returns the texture coordinates in the middle of the ones of the corners of a face.
+func (splitter *uvSplitter) face(uvFace []int) int {
+	center := Point(0, 0, 0)
+	for _, index := range uvFace {
+		if index < 0 {
+			return -1
+		}
+		center = center.Add(splitter.uvs[index])
+	}
+	center = center.Multiply(1 / float64(len(uvFace)))
+	center.w = 1
+	splitter.uvs = append(splitter.uvs, center)
+	return len(splitter.uvs) - 1
+}
+
+// catmullClark returns the mesh subdivided once with the Catmull-Clark rules: each face gets a
+// vertex in its middle, each edge a vertex in its middle, and a face of n sides is split into n
+// quads. The vertices of the result are the moved vertices of the mesh, then the edge points and
+// the face points.
+func (mesh *polygonMesh) catmullClark() *polygonMesh {
+	topology := newMeshTopology(mesh)
+	nv, ne := len(mesh.vertices), len(topology.edges)
+	vertices := make([]Tuple, nv+ne+len(mesh.faces))
+
+	for f, face := range mesh.faces {
+		center := Point(0, 0, 0)
+		for _, v := range face {
+			center = center.Add(mesh.vertices[v])
+		}
+		center = center.Multiply(1 / float64(len(face)))
+		center.w = 1
+		vertices[nv+ne+f] = center
+	}
+
+	for e, edge := range topology.edges {
+		vertices[nv+e] = mesh.edgePoint(topology, e, func() Tuple {
+			faces := topology.edgeFaces[e]
+			return mesh.vertices[edge[0]].Add(mesh.vertices[edge[1]]).
+				Add(vertices[nv+ne+faces[0]]).Add(vertices[nv+ne+faces[1]]).Multiply(0.25)
+		})
+	}
+
+	for v, p := range mesh.vertices {
+		n := len(topology.vertexEdges[v])
+		smooth := p
+		if n >= 3 {
+			// (F + 2R + (n - 3)P) / n, F averaging the face points around the vertex and R the
+			// middles of its edges.
+			faces := Vector(0, 0, 0)
+			for _, f := range topology.vertexFaces[v] {
+				faces = faces.Add(vertices[nv+ne+f])
+			}
+			edges := Vector(0, 0, 0)
+			for _, e := range topology.vertexEdges[v] {
+				edges = edges.Add(mesh.vertices[topology.other(e, v)].Add(p))
+			}
+			smooth = faces.Multiply(1 / float64(len(topology.vertexFaces[v]))).
+				Add(edges.Multiply(1 / float64(n))).
+				Add(p.Multiply(float64(n - 3))).
+				Multiply(1 / float64(n))
+		}
+		vertices[v] = mesh.vertexPoint(topology, v, smooth)
+	}
+
+	result := &polygonMesh{
+		vertices:    vertices,
+		faces:       make([][]int, 0, 4*len(mesh.faces)),
+		materialIDs: make([]int, 0, 4*len(mesh.faces)),
+		creases:     mesh.childCreases(topology, nv),
+	}
+	splitter := newUVSplitter(mesh)
+	for f, face := range mesh.faces {
+		k := len(face)
+		edgePoint := func(i int) int {
+			return nv + topology.edgeIndex[edgeKey(face[i%k], face[(i+1)%k])]
+		}
+		center := -1
+		if splitter != nil {
+			center = splitter.face(mesh.uvFaces[f])
+		}
+		for i, v := range face {
+			result.faces = append(result.faces, []int{v, edgePoint(i), nv + ne + f, edgePoint(i + k - 1)})
+			result.materialIDs = append(result.materialIDs, mesh.materialIDs[f])
+			if splitter != nil {
+				uvFace := mesh.uvFaces[f]
+				result.uvFaces = append(result.uvFaces, []int{
+					uvFace[i], splitter.edge(uvFace[i], uvFace[(i+1)%k]), center, splitter.edge(uvFace[(i+k-1)%k], uvFace[i]),
+				})
+			}
+		}
+	}
+	if splitter != nil {
+		result.uvs = splitter.uvs
+	}
+	return result
+}
+
+// loop returns the triangles of the mesh subdivided once with the Loop rules: each edge gets a
+// vertex in its middle and each triangle is split into four. The vertices of the result are the
+// moved vertices of the mesh, then the edge points. Unless smooth is true, the vertices stay in
+// place and the edge points in the middle of the edges.
+func (mesh *polygonMesh) loop(smooth bool) *polygonMesh {
+	topology := newMeshTopology(mesh)
+	nv, ne := len(mesh.vertices), len(topology.edges)
+	vertices := make([]Tuple, nv+ne)
+
+	// opposite returns the corner of a triangle that is not on the edge.
+	opposite := func(f int, edge [2]int) Tuple {
+		for _, v := range mesh.faces[f] {
+			if v != edge[0] && v != edge[1] {
+				return mesh.vertices[v]
+			}
+		}
+		return mesh.vertices[edge[0]]
+	}
+	for e, edge := range topology.edges {
+		if !smooth {
+			vertices[nv+e] = mesh.vertices[edge[0]].Add(mesh.vertices[edge[1]]).Multiply(0.5)
+			continue
+		}
+		vertices[nv+e] = mesh.edgePoint(topology, e, func() Tuple {
+			faces := topology.edgeFaces[e]
+			return mesh.vertices[edge[0]].Add(mesh.vertices[edge[1]]).Multiply(0.375).
+				Add(opposite(faces[0], edge).Add(opposite(faces[1], edge)).Multiply(0.125))
+		})
+	}
+
+	for v, p := range mesh.vertices {
+		n := len(topology.vertexEdges[v])
+		if !smooth {
+			vertices[v] = p
+			continue
+		}
+		moved := p
+		if n >= 3 {
+			// (1 - n beta)P + beta times the sum of the neighbors.
+			beta := (0.625 - square(0.375+0.25*math.Cos(2*PI/float64(n)))) / float64(n)
+			neighbors := Vector(0, 0, 0)
+			for _, e := range topology.vertexEdges[v] {
+				neighbors = neighbors.Add(mesh.vertices[topology.other(e, v)])
+			}
+			moved = p.Multiply(1 - float64(n)*beta).Add(neighbors.Multiply(beta))
+		}
+		vertices[v] = mesh.vertexPoint(topology, v, moved)
+	}
+
+	result := &polygonMesh{
+		vertices:    vertices,
+		faces:       make([][]int, 0, 4*len(mesh.faces)),
+		materialIDs: make([]int, 0, 4*len(mesh.faces)),
+		creases:     mesh.childCreases(topology, nv),
+	}
+	splitter := newUVSplitter(mesh)
+	for f, face := range mesh.faces {
+		a, b, c := face[0], face[1], face[2]
+		ab := nv + topology.edgeIndex[edgeKey(a, b)]
+		bc := nv + topology.edgeIndex[edgeKey(b, c)]
+		ca := nv + topology.edgeIndex[edgeKey(c, a)]
+		result.faces = append(result.faces, []int{a, ab, ca}, []int{b, bc, ab}, []int{c, ca, bc}, []int{ab, bc, ca})
+		for i := 0; i < 4; i++ {
+			result.materialIDs = append(result.materialIDs, mesh.materialIDs[f])
+		}
+		if splitter != nil {
+			uvFace := mesh.uvFaces[f]
+			uvAB := splitter.edge(uvFace[0], uvFace[1])
+			uvBC := splitter.edge(uvFace[1], uvFace[2])
+			uvCA := splitter.edge(uvFace[2], uvFace[0])
+			result.uvFaces = append(result.uvFaces,
+				[]int{uvFace[0], uvAB, uvCA}, []int{uvFace[1], uvBC, uvAB}, []int{uvFace[2], uvCA, uvBC}, []int{uvAB, uvBC, uvCA})
+		}
+	}
+	if splitter != nil {
+		result.uvs = splitter.uvs
+	}
+	return result
+}
+
+// vertexNormals returns the normals of the corners of the faces, as normals and their indices per
+// corner. Smooth vertices have the normal of the limit surface of the scheme if limit is true, or
+// the average of the normals of the faces around them. Around vertices on sharp edges, the faces
+// between two sharp edges share the average of their normals.
+func (mesh *polygonMesh) vertexNormals(scheme SubdivisionScheme, limit bool) ([]Tuple, [][]int) {
+	topology := newMeshTopology(mesh)
+	normals := make([]Tuple, 0, len(mesh.vertices))
+	normalFaces := make([][]int, len(mesh.faces))
+	for f, face := range mesh.faces {
+		normalFaces[f] = make([]int, len(face))
+	}
+	faceNormals := make([]Tuple, len(mesh.faces))
+	for f := range mesh.faces {
+		faceNormals[f] = mesh.faceNormal(f)
+	}
+	setNormal := func(v, f, index int) {
+		for i, corner := range mesh.faces[f] {
+			if corner == v {
+				normalFaces[f][i] = index
+			}
+		}
+	}
+
+	for v := range mesh.vertices {
+		sharp := false
+		for _, e := range topology.vertexEdges[v] {
+			if mesh.sharpness(topology, e) >= 1 {
+				sharp = true
+			}
+		}
+
+		if !sharp {
+			average := Vector(0, 0, 0)
+			for _, f := range topology.vertexFaces[v] {
+				average = average.Add(faceNormals[f])
+			}
+			normal := average
+			if limit {
+				if tangentU, tangentV, ok := mesh.limitTangents(topology, v, scheme); ok {
+					if n := tangentU.CrossProduct(tangentV); n.Magnitude() > EPSILON*EPSILON {
+						normal = n.Multiply(sign(n.DotProduct(average)))
+					}
+				}
+			}
+			normals = append(normals, normal.Normalize())
+			for _, f := range topology.vertexFaces[v] {
+				setNormal(v, f, len(normals)-1)
+			}
+			continue
+		}
+
+		// The faces reached from one another across smooth edges share a normal.
+		sector := map[int]int{}
+		for _, start := range topology.vertexFaces[v] {
+			if _, done := sector[start]; done {
+				continue
+			}
+			index := len(normals)
+			sum := Vector(0, 0, 0)
+			sector[start] = index
+			stack := []int{start}
+			for len(stack) > 0 {
+				f := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				sum = sum.Add(faceNormals[f])
+				setNormal(v, f, index)
+				face := mesh.faces[f]
+				for i, corner := range face {
+					if corner != v {
+						continue
+					}
+					for _, w := range []int{face[(i+1)%len(face)], face[(i+len(face)-1)%len(face)]} {
+						e := topology.edgeIndex[edgeKey(v, w)]
+						if mesh.sharpness(topology, e) >= 1 {
+							continue
+						}
+						for _, g := range topology.edgeFaces[e] {
+							if _, done := sector[g]; !done {
+								sector[g] = index
+								stack = append(stack, g)
+							}
+						}
+					}
+				}
+			}
+			normals = append(normals, sum.Normalize())
+		}
+	}
+	return normals, normalFaces
+}
+
+// limitTangents returns two tangents of the limit surface at a vertex from its neighbors, with the
+// masks of the Loop scheme or the ones of Halstead et al. for Catmull-Clark. Returns false if the
+// faces around the vertex aren't a single fan of consistently oriented faces of the scheme, or if the
+// scheme has no limit surface other than the faces.
+func (mesh *polygonMesh) limitTangents(topology *meshTopology, v int, scheme SubdivisionScheme) (Tuple, Tuple, bool) {
+	corners := len(mesh.faces[topology.vertexFaces[v][0]])
+	if (scheme != LoopSubdivision || corners != 3) && (scheme != CatmullClarkSubdivision || corners != 4) {
+		return Tuple{}, Tuple{}, false
+	}
+
+	// Going around the vertex, the edge to the previous corner of a face is the edge to the next
+	// corner of the following face.
+	n := len(topology.vertexFaces[v])
+	edges := make([]Tuple, 0, n)
+	diagonals := make([]Tuple, 0, n)
+	f, expected := topology.vertexFaces[v][0], -1
+	for range topology.vertexFaces[v] {
+		face := mesh.faces[f]
+		if len(face) != corners {
+			return Tuple{}, Tuple{}, false
+		}
+		i := 0
+		for face[i] != v {
+			i++
+		}
+		next, previous := face[(i+1)%corners], face[(i+corners-1)%corners]
+		if expected >= 0 && next != expected {
+			return Tuple{}, Tuple{}, false
+		}
+		edges = append(edges, mesh.vertices[next])
+		if corners == 4 {
+			diagonals = append(diagonals, mesh.vertices[face[(i+2)%4]])
+		}
+
+		faces := topology.edgeFaces[topology.edgeIndex[edgeKey(v, previous)]]
+		if len(faces) != 2 {
+			return Tuple{}, Tuple{}, false
+		}
+		if faces[0] == f {
+			f = faces[1]
+		} else {
+			f = faces[0]
+		}
+		expected = previous
+	}
+	if f != topology.vertexFaces[v][0] {
+		return Tuple{}, Tuple{}, false
+	}
+
+	tangentU, tangentV := Vector(0, 0, 0), Vector(0, 0, 0)
+	angle := 2 * PI / float64(n)
+	weight := 1.0
+	if corners == 4 {
+		weight = 1 + math.Cos(angle) + math.Cos(angle/2)*math.Sqrt(2*(9+math.Cos(angle)))
+	}
+	for i := 0; i < n; i++ {
+		c, s := math.Cos(angle*float64(i)), math.Sin(angle*float64(i))
+		e := edges[i]
+		e.w = 0
+		tangentU = tangentU.Add(e.Multiply(weight * c))
+		tangentV = tangentV.Add(e.Multiply(weight * s))
+		if corners == 4 {
+			d := diagonals[i]
+			d.w = 0
+			tangentU = tangentU.Add(d.Multiply(c + math.Cos(angle*float64(i+1))))
+			tangentV = tangentV.Add(d.Multiply(s + math.Sin(angle*float64(i+1))))
+		}
+	}
+	return tangentU, tangentV, true
+}
+
+// toMesh returns a Mesh of the faces split into triangles fanning from their first corner, with
+// the normals of their corners.
+func (mesh *polygonMesh) toMesh(normals []Tuple, normalFaces [][]int) *Mesh {
+	vertexIndices := make([]int, 0, 3*len(mesh.faces))
+	normalIndices := make([]int, 0, 3*len(mesh.faces))
+	var uvIndices []int
+	materialIDs := make([]int, 0, len(mesh.faces))
+	for f, face := range mesh.faces {
+		for i := 1; i+1 < len(face); i++ {
+			for _, corner := range []int{0, i, i + 1} {
+				vertexIndices = append(vertexIndices, face[corner])
+				normalIndices = append(normalIndices, normalFaces[f][corner])
+				if mesh.uvFaces != nil {
+					uvIndices = append(uvIndices, mesh.uvFaces[f][corner])
+				}
+			}
+			materialIDs = append(materialIDs, mesh.materialIDs[f])
+		}
+	}
+	result := NewMesh(mesh.vertices, normals, mesh.uvs, vertexIndices, normalIndices, uvIndices)
+	result.SetFaceMaterials(nil, materialIDs)
+	return result
+}
+
+// Subdivide returns a *Mesh of the faces of the mesh subdivided with the configuration, with the
+// transform and the materials of the mesh. Faces are connected through the indices of their
+// vertices: faces using different copies of a vertex are subdivided as if the mesh was cut there.
+// Crease indices are the ones of the vertices of the mesh.
+func (mesh *Mesh) Subdivide(config *SubdivisionConfig) *Mesh {
+	return mesh.withAttributes(mesh.polygonMesh().subdivide(config))
+}
+
+// polygonMesh returns the triangles of the mesh as a polygon mesh.
+func (mesh *Mesh) polygonMesh() *polygonMesh {
+	polygons := &polygonMesh{vertices: mesh.vertices}
+	if len(mesh.uvIndices) > 0 {
+		polygons.uvs = mesh.uvs
+		polygons.uvFaces = make([][]int, 0, mesh.FaceCount())
+	}
+	for face := 0; face < mesh.FaceCount(); face++ {
+		polygons.faces = append(polygons.faces, append([]int{}, mesh.vertexIndices[3*face:3*face+3]...))
+		if polygons.uvFaces != nil {
+			polygons.uvFaces = append(polygons.uvFaces, append([]int{}, mesh.uvIndices[3*face:3*face+3]...))
+		}
+		id := -1
+		if face < len(mesh.materialIDs) {
+			id = mesh.materialIDs[face]
+		}
+		polygons.materialIDs = append(polygons.materialIDs, id)
+	}
+	return polygons
+}
+
+// withAttributes gives a mesh made from this one its transform and materials.
+func (mesh *Mesh) withAttributes(result *Mesh) *Mesh {
+	result.SetTransform(mesh.Transform())
+	result.SetMaterial(mesh.material)
+	result.materials = mesh.materials
+	result.materialNames = mesh.materialNames
+	return result
+}
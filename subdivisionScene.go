@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// subdivisionCube is a cube of quads from -1 to 1 in wavefront OBJ format, its faces mapped to the
+// whole texture.
+const subdivisionCube = `
+v -1 -1 -1
+v 1 -1 -1
+v 1 1 -1
+v -1 1 -1
+v -1 -1 1
+v 1 -1 1
+v 1 1 1
+v -1 1 1
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+f 1/1 2/2 3/3 4/4
+f 5/1 8/4 7/3 6/2
+f 1/1 5/4 6/3 2/2
+f 4/1 3/2 7/3 8/4
+f 1/1 4/4 8/3 5/2
+f 2/1 6/2 7/3 3/4
+`
+
+// subdivisionWorld renders a cube subdivided with Catmull-Clark: from left to right not subdivided,
+// smooth, with semi-sharp edges and with the edges of its top face creased.
+func subdivisionWorld() *Canvas {
+	start := time.Now()
+	lights := []*PointLight{
+		NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1)),
+	}
+	floor := NewPlane()
+	floor.material.pattern = CheckersPattern(NewColor(0.6, 0.6, 0.6), NewColor(0.1, 0.1, 0.1))
+	floor.material.reflective = 0.2
+
+	shapes := []Shape{floor}
+	colors := []Color{NewColor(0.8, 0.3, 0.2), NewColor(0.2, 0.4, 0.8), NewColor(0.3, 0.7, 0.3), NewColor(0.8, 0.7, 0.2)}
+	for i, color := range colors {
+		config := DefaultSubdivisionConfig()
+		config.levels = 3
+		switch i {
+		case 0:
+			config.levels = 0
+			config.creaseAngle = PI / 4
+		case 2:
+			for _, edge := range [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}, {4, 5}, {5, 6}, {6, 7}, {7, 4}, {0, 4}, {1, 5}, {2, 6}, {3, 7}} {
+				config.SetCrease(edge[0], edge[1], 2)
+			}
+		case 3:
+			for _, edge := range [][2]int{{2, 3}, {3, 7}, {7, 6}, {6, 2}} {
+				config.SetCrease(edge[0], edge[1], math.Inf(1))
+			}
+		}
+		cube, err := parseObjMesh(subdivisionCube, config)
+		if err != nil {
+			panic(err)
+		}
+		cube.SetTransform(Translation(3*float64(i)-4.5, 1, 0))
+		cube.ApplyTransform(RotationY(PI / 6))
+		cube.SetMaterial(NewMaterial(color, 0.1, 0.7, 0.6, 100, 0.1, 0, 1, nil))
+		shapes = append(shapes, cube)
+	}
+
+	world := NewWorld(lights, shapes)
+
+	camera := NewCamera(600, 300, PI/3)
+	camera.SetTransform(ViewTransform(Point(0, 4, -10.5), Point(0, 0.8, 0), Vector(0, 1, 0)))
+
+	canvas := camera.RenderWithThreadPool(world, defaultRecursionDepth)
+
+	fmt.Println("Render time: ", time.Now().Sub(start))
+
+	return canvas
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSubdivisionWorld(t *testing.T) {
+
+	canvas := subdivisionWorld()
+
+	file, err := os.Create("subdivisionWorld.ppm")
+	if err != nil {
+		t.Errorf("Error: %v", err)
+	}
+	file.WriteString(canvas.ToPPM())
+	file.Close()
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// subdividedCube returns subdivisionCube subdivided with the configuration.
+func subdividedCube(t *testing.T, config *SubdivisionConfig) *Mesh {
+	mesh, err := parseObjMesh(subdivisionCube, config)
+	if err != nil {
+		t.Fatalf("parseObjMesh: got %v, expected no error", err)
+	}
+	return mesh
+}
+
+// Catmull-Clark moves the corners of a cube toward its center and rounds it into a smooth surface.
+func TestCatmullClarkCube(t *testing.T) {
+	config := DefaultSubdivisionConfig()
+	config.levels = 1
+	mesh := subdividedCube(t, config)
+
+	if mesh.FaceCount() != 48 {
+		t.Errorf("Subdivided cube faces: got %v, expected: %v", mesh.FaceCount(), 48)
+	}
+	// The corner (1, 1, 1) moves to (F + 2R) / 3 with F = (1/3, 1/3, 1/3) and R = (2/3, 2/3, 2/3).
+	if corner := Point(5.0/9, 5.0/9, 5.0/9); !mesh.vertices[6].Equals(corner) {
+		t.Errorf("Subdivided cube corner: got %v, expected: %v", mesh.vertices[6], corner)
+	}
+	// The point of the edge from (-1, 1, 1) to (1, 1, 1) averages its ends and the middles of its faces.
+	found := false
+	for _, p := range mesh.vertices {
+		found = found || p.Equals(Point(0, 0.75, 0.75))
+	}
+	if !found {
+		t.Errorf("Subdivided cube edge point: expected %v among the vertices", Point(0, 0.75, 0.75))
+	}
+
+	config.levels = 3
+	mesh = subdividedCube(t, config)
+	r := NewRay(Point(-5, -5, -5), Vector(1, 1, 1).Normalize())
+	xs := mesh.Intersect(r, nil)
+	xs.Sort()
+	if len(xs) == 0 {
+		t.Fatalf("Subdivided cube intersections: got none")
+	}
+	// The limit normal at the corner points away from the center, by symmetry.
+	expected := Vector(-1, -1, -1).Normalize()
+	if n := mesh.NormalAt(r.Position(xs[0].t), &xs[0]); !n.Equals(expected) {
+		t.Errorf("Subdivided cube normal at the corner: got %v, expected: %v", n, expected)
+	}
+	// The texture coordinates of the faces are kept.
+	if u, v, ok := mesh.uvAt(&xs[0]); !ok || u < 0 || u > 0.25 || v < 0 || v > 0.25 {
+		t.Errorf("Subdivided cube (u, v) at the corner: got (%v, %v), expected close to: (0, 0)", u, v)
+	}
+}
+
+// Creases keep the edges of a cube sharp, semi-sharp ones for a few levels only.
+func TestSubdivisionCreases(t *testing.T) {
+	config := DefaultSubdivisionConfig()
+	config.levels = 3
+	config.creaseAngle = PI / 4
+	mesh := subdividedCube(t, config)
+	for _, p := range mesh.vertices {
+		if !floatEqual(math.Max(math.Abs(p.x), math.Max(math.Abs(p.y), math.Abs(p.z))), 1) {
+			t.Errorf("Creased cube vertex: got %v, expected it on the cube", p)
+			break
+		}
+	}
+	r := NewRay(Point(0.3, 5, 0.2), Vector(0, -1, 0))
+	xs := mesh.Intersect(r, nil)
+	xs.Sort()
+	if n := mesh.NormalAt(r.Position(xs[0].t), &xs[0]); !n.Equals(Vector(0, 1, 0)) {
+		t.Errorf("Creased cube normal: got %v, expected: %v", n, Vector(0, 1, 0))
+	}
+
+	// The sharper an edge, the closer the surface stays to it.
+	distance := func(sharpness float64) float64 {
+		config := DefaultSubdivisionConfig()
+		config.levels = 3
+		config.SetCrease(2, 6, sharpness)
+		mesh := subdividedCube(t, config)
+		r := NewRay(Point(5, 5, 0), Vector(-1, -1, 0).Normalize())
+		xs := mesh.Intersect(r, nil)
+		xs.Sort()
+		return xs[0].t
+	}
+	smooth, semiSharp, sharp := distance(0), distance(1.5), distance(math.Inf(1))
+	if !(smooth > semiSharp && semiSharp > sharp && sharp > 4*math.Sqrt2) {
+		t.Errorf("Distance to a creased edge: got %v, %v and %v, expected decreasing down to more than: %v", smooth, semiSharp, sharp, 4*math.Sqrt2)
+	}
+}
+
+// Loop moves the vertices of an octahedron with the weights of valence 4 and keeps the transform of the mesh.
+func TestLoopOctahedron(t *testing.T) {
+	vertices := []Tuple{Point(1, 0, 0), Point(-1, 0, 0), Point(0, 1, 0), Point(0, -1, 0), Point(0, 0, 1), Point(0, 0, -1)}
+	indices := []int{
+		0, 4, 2, 2, 4, 1, 1, 4, 3, 3, 4, 0,
+		2, 5, 0, 1, 5, 2, 3, 5, 1, 0, 5, 3,
+	}
+	octahedron := NewMesh(vertices, nil, nil, indices, nil, nil)
+	octahedron.SetTransform(Translation(0, 2, 0))
+
+	config := DefaultSubdivisionConfig()
+	config.levels = 1
+	mesh := octahedron.Subdivide(config)
+	if mesh.FaceCount() != 32 {
+		t.Errorf("Subdivided octahedron faces: got %v, expected: %v", mesh.FaceCount(), 32)
+	}
+	// beta = 31/256 for 4 neighbors summing to 0.
+	if expected := Point(132.0/256, 0, 0); !mesh.vertices[0].Equals(expected) {
+		t.Errorf("Subdivided octahedron vertex: got %v, expected: %v", mesh.vertices[0], expected)
+	}
+	// The first edge point, on the edge from (1, 0, 0) to (0, 0, 1), weights its ends by 3/8 and the
+	// opposite corners by 1/8.
+	if expected := Point(0.375, 0, 0.375); !mesh.vertices[6].Equals(expected) {
+		t.Errorf("Subdivided octahedron edge point: got %v, expected: %v", mesh.vertices[6], expected)
+	}
+
+	config.levels = 3
+	mesh = octahedron.Subdivide(config)
+	r := NewRay(Point(0, 7, 0), Vector(0, -1, 0))
+	xs := mesh.Intersect(r, nil)
+	xs.Sort()
+	if len(xs) == 0 {
+		t.Fatalf("Subdivided octahedron intersections: got none")
+	}
+	if n := mesh.NormalAt(r.Position(xs[0].t), &xs[0]); !n.Equals(Vector(0, 1, 0)) {
+		t.Errorf("Subdivided octahedron normal: got %v, expected: %v", n, Vector(0, 1, 0))
+	}
+}
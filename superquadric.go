@@ -0,0 +1,106 @@
+package main
+
+import "math"
+
+// superquadricSteps is the number of samples taken along a ray inside the bounds of a superquadric
+// to bracket its intersections.
+const superquadricSteps = 64
+
+// Superquadric is a superellipsoid fitting in the cube from (-1, -1, -1) to (1, 1, 1), the surface
+//
+//	(|x|^(2/e2) + |z|^(2/e2))^(e2/e1) + |y|^(2/e1) = 1
+//
+// e1 shapes the profile along y and e2 the sections parallel to the xz plane, both in (0, 2].
+// Exponents of 1 give a sphere, exponents close to 0 a box with rounded edges, 2 an octahedron.
+// There is no closed form for its intersections, which are bracketed by sampling the ray and
+// then refined by bisection.
+type Superquadric struct {
+	BaseShape
+	e1, e2 float64
+}
+
+// NewSuperquadric returns a *Superquadric with the given exponents, Identity matrix as transform
+// and default material.
+func NewSuperquadric(e1, e2 float64) *Superquadric {
+	return &Superquadric{
+		BaseShape: NewBaseShape(),
+		e1:        e1,
+		e2:        e2,
+	}
+}
+
+// inside returns the inside-outside function of the superquadric, negative inside and positive outside.
+func (superquadric *Superquadric) inside(p Tuple) float64 {
+	xz := math.Pow(math.Abs(p.x), 2/superquadric.e2) + math.Pow(math.Abs(p.z), 2/superquadric.e2)
+	return math.Pow(xz, superquadric.e2/superquadric.e1) + math.Pow(math.Abs(p.y), 2/superquadric.e1) - 1
+}
+
+// LocalIntersect calculates the intersections between a ray in object space and the superquadric.
+func (superquadric *Superquadric) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	tMin, tMax, hit := rayUnitBox(localRay)
+	if !hit {
+		return xs
+	}
+
+	inside := func(t float64) float64 { return superquadric.inside(localRay.Position(t)) }
+	var rootsArray [8]float64
+	for _, t := range bracketRoots(inside, tMin, tMax, superquadricSteps, rootsArray[:0]) {
+		xs = append(xs, NewIntersection(t, superquadric))
+	}
+	return xs
+}
+
+// rayUnitBox returns the range of the ray inside the cube from (-1, -1, -1) to (1, 1, 1).
+func rayUnitBox(localRay Ray) (float64, float64, bool) {
+	xtmin, xtmax := checkAxis(localRay.origin.x, localRay.direction.x)
+	ytmin, ytmax := checkAxis(localRay.origin.y, localRay.direction.y)
+	ztmin, ztmax := checkAxis(localRay.origin.z, localRay.direction.z)
+
+	tMin := math.Max(xtmin, math.Max(ytmin, ztmin))
+	tMax := math.Min(xtmax, math.Min(ytmax, ztmax))
+	return tMin, tMax, tMin <= tMax
+}
+
+// Intersect calculates the intersections between a ray in world space and the superquadric.
+func (superquadric *Superquadric) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(superquadric, worldRay, xs)
+}
+
+// LocalNormalAt returns the gradient of the inside-outside function at the point.
+func (superquadric *Superquadric) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	e1, e2 := superquadric.e1, superquadric.e2
+	ax, az := math.Abs(localPoint.x), math.Abs(localPoint.z)
+	xz := math.Pow(ax, 2/e2) + math.Pow(az, 2/e2)
+
+	// d/dx (xz^(e2/e1)) = (2/e1) * xz^(e2/e1-1) * |x|^(2/e2-1) * sign(x), the common factor 2/e1 is dropped.
+	scale := 0.0
+	if xz > 0 {
+		scale = math.Pow(xz, e2/e1-1)
+	}
+	nx := scale * math.Pow(ax, 2/e2-1) * sign(localPoint.x)
+	nz := scale * math.Pow(az, 2/e2-1) * sign(localPoint.z)
+	ny := math.Pow(math.Abs(localPoint.y), 2/e1-1) * sign(localPoint.y)
+	return Vector(nx, ny, nz).Normalize()
+}
+
+// sign returns -1, 0 or 1 according to the sign of v.
+func sign(v float64) float64 {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NormalAt calculates the normal at a given point in world space.
+func (superquadric *Superquadric) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(superquadric, worldPoint, intersection)
+}
+
+// LocalBounds returns the bounding box of the superquadric in object space.
+func (superquadric *Superquadric) LocalBounds() *BoundingBox {
+	return NewBoundingBoxFloat(-1, -1, -1, 1, 1, 1)
+}
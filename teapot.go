@@ -0,0 +1,101 @@
+package main
+
+// teapotPatchIndices and teapotPoints describe a quarter of the body, rim, lid and bottom of the
+// Newell teapot and half of its handle and spout, with z up. The other parts are mirror images.
+var teapotPatchIndices = [10][16]int{
+	// Rim.
+	{102, 103, 104, 105, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	// Body.
+	{12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27},
+	{24, 25, 26, 27, 29, 30, 31, 32, 33, 34, 35, 36, 37, 38, 39, 40},
+	// Lid.
+	{96, 96, 96, 96, 97, 98, 99, 100, 101, 101, 101, 101, 0, 1, 2, 3},
+	{0, 1, 2, 3, 106, 107, 108, 109, 110, 111, 112, 113, 114, 115, 116, 117},
+	// Bottom.
+	{118, 118, 118, 118, 124, 122, 119, 121, 123, 126, 125, 120, 40, 39, 38, 37},
+	// Handle.
+	{41, 42, 43, 44, 45, 46, 47, 48, 49, 50, 51, 52, 53, 54, 55, 56},
+	{53, 54, 55, 56, 57, 58, 59, 60, 61, 62, 63, 64, 28, 65, 66, 67},
+	// Spout.
+	{68, 69, 70, 71, 72, 73, 74, 75, 76, 77, 78, 79, 80, 81, 82, 83},
+	{80, 81, 82, 83, 84, 85, 86, 87, 88, 89, 90, 91, 92, 93, 94, 95},
+}
+
+var teapotPoints = [127][3]float64{
+	{0.2, 0, 2.7}, {0.2, -0.112, 2.7}, {0.112, -0.2, 2.7}, {0, -0.2, 2.7},
+	{1.3375, 0, 2.53125}, {1.3375, -0.749, 2.53125}, {0.749, -1.3375, 2.53125}, {0, -1.3375, 2.53125},
+	{1.4375, 0, 2.53125}, {1.4375, -0.805, 2.53125}, {0.805, -1.4375, 2.53125}, {0, -1.4375, 2.53125},
+	{1.5, 0, 2.4}, {1.5, -0.84, 2.4}, {0.84, -1.5, 2.4}, {0, -1.5, 2.4},
+	{1.75, 0, 1.875}, {1.75, -0.98, 1.875}, {0.98, -1.75, 1.875}, {0, -1.75, 1.875},
+	{2, 0, 1.35}, {2, -1.12, 1.35}, {1.12, -2, 1.35}, {0, -2, 1.35},
+	{2, 0, 0.9}, {2, -1.12, 0.9}, {1.12, -2, 0.9}, {0, -2, 0.9},
+	{-2, 0, 0.9}, {2, 0, 0.45}, {2, -1.12, 0.45}, {1.12, -2, 0.45},
+	{0, -2, 0.45}, {1.5, 0, 0.225}, {1.5, -0.84, 0.225}, {0.84, -1.5, 0.225},
+	{0, -1.5, 0.225}, {1.5, 0, 0.15}, {1.5, -0.84, 0.15}, {0.84, -1.5, 0.15},
+	{0, -1.5, 0.15}, {-1.6, 0, 2.025}, {-1.6, -0.3, 2.025}, {-1.5, -0.3, 2.25},
+	{-1.5, 0, 2.25}, {-2.3, 0, 2.025}, {-2.3, -0.3, 2.025}, {-2.5, -0.3, 2.25},
+	{-2.5, 0, 2.25}, {-2.7, 0, 2.025}, {-2.7, -0.3, 2.025}, {-3, -0.3, 2.25},
+	{-3, 0, 2.25}, {-2.7, 0, 1.8}, {-2.7, -0.3, 1.8}, {-3, -0.3, 1.8},
+	{-3, 0, 1.8}, {-2.7, 0, 1.575}, {-2.7, -0.3, 1.575}, {-3, -0.3, 1.35},
+	{-3, 0, 1.35}, {-2.5, 0, 1.125}, {-2.5, -0.3, 1.125}, {-2.65, -0.3, 0.9375},
+	{-2.65, 0, 0.9375}, {-2, -0.3, 0.9}, {-1.9, -0.3, 0.6}, {-1.9, 0, 0.6},
+	{1.7, 0, 1.425}, {1.7, -0.66, 1.425}, {1.7, -0.66, 0.6}, {1.7, 0, 0.6},
+	{2.6, 0, 1.425}, {2.6, -0.66, 1.425}, {3.1, -0.66, 0.825}, {3.1, 0, 0.825},
+	{2.3, 0, 2.1}, {2.3, -0.25, 2.1}, {2.4, -0.25, 2.025}, {2.4, 0, 2.025},
+	{2.7, 0, 2.4}, {2.7, -0.25, 2.4}, {3.3, -0.25, 2.4}, {3.3, 0, 2.4},
+	{2.8, 0, 2.475}, {2.8, -0.25, 2.475}, {3.525, -0.25, 2.49375}, {3.525, 0, 2.49375},
+	{2.9, 0, 2.475}, {2.9, -0.15, 2.475}, {3.45, -0.15, 2.5125}, {3.45, 0, 2.5125},
+	{2.8, 0, 2.4}, {2.8, -0.15, 2.4}, {3.2, -0.15, 2.4}, {3.2, 0, 2.4},
+	{0, 0, 3.15}, {0.8, 0, 3.15}, {0.8, -0.45, 3.15}, {0.45, -0.8, 3.15},
+	{0, -0.8, 3.15}, {0, 0, 2.85}, {1.4, 0, 2.4}, {1.4, -0.784, 2.4},
+	{0.784, -1.4, 2.4}, {0, -1.4, 2.4}, {0.4, 0, 2.55}, {0.4, -0.224, 2.55},
+	{0.224, -0.4, 2.55}, {0, -0.4, 2.55}, {1.3, 0, 2.55}, {1.3, -0.728, 2.55},
+	{0.728, -1.3, 2.55}, {0, -1.3, 2.55}, {1.3, 0, 2.4}, {1.3, -0.728, 2.4},
+	{0.728, -1.3, 2.4}, {0, -1.3, 2.4}, {0, 0, 0}, {1.425, -0.798, 0},
+	{1.5, 0, 0.075}, {1.425, 0, 0}, {0.798, -1.425, 0}, {0, -1.5, 0.075},
+	{0, -1.425, 0}, {1.5, -0.84, 0.075}, {0.84, -1.5, 0.075},
+}
+
+// teapotPatches returns the 32 patches of the Newell teapot, 3.15 high with z up and its bottom
+// centered on the origin. Patches mirrored along a single axis have their columns reversed so
+// that all their normals point outward.
+func teapotPatches() []*BezierPatch {
+	patches := make([]*BezierPatch, 0, 32)
+	mirror := func(indices [16]int, sx, sy float64) *BezierPatch {
+		var cp [16]Tuple
+		for i := 0; i < 4; i++ {
+			for j := 0; j < 4; j++ {
+				k := j
+				if sx*sy < 0 {
+					k = 3 - j
+				}
+				p := teapotPoints[indices[4*i+k]]
+				cp[4*i+j] = Point(sx*p[0], sy*p[1], p[2])
+			}
+		}
+		return NewBezierPatch(cp)
+	}
+	for n, indices := range teapotPatchIndices {
+		patches = append(patches, mirror(indices, 1, 1), mirror(indices, 1, -1))
+		if n < 6 {
+			// The rim, body, lid and bottom have four quarters, the handle and the spout two halves.
+			patches = append(patches, mirror(indices, -1, 1), mirror(indices, -1, -1))
+		}
+	}
+	return patches
+}
+
+// NewTeapot returns a *Group of the patches of the Newell teapot turned y up, each tessellated in
+// resolution by resolution squares, or intersected directly when resolution is 0.
+func NewTeapot(resolution int) *Group {
+	teapot := NewGroup()
+	teapot.SetTransform(RotationX(-PI / 2))
+	for _, patch := range teapotPatches() {
+		if resolution > 0 {
+			teapot.AddChild(patch.Tessellate(resolution))
+		} else {
+			teapot.AddChild(patch)
+		}
+	}
+	return teapot
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// teapotWorld renders the Newell teapot twice, intersected directly on the left and tessellated
+// in smooth triangles on the right.
+func teapotWorld() *Canvas {
+	start := time.Now()
+	lights := []*PointLight{
+		NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1)),
+	}
+	floor := NewPlane()
+	floor.material.pattern = CheckersPattern(NewColor(0.6, 0.6, 0.6), NewColor(0.1, 0.1, 0.1))
+	floor.material.reflective = 0.2
+
+	direct := NewGroup()
+	direct.SetTransform(Translation(-2, 0, 0))
+	direct.ApplyTransform(RotationY(-PI / 6))
+	direct.ApplyTransform(Scaling(0.5, 0.5, 0.5))
+	direct.AddChild(NewTeapot(0))
+	direct.SetMaterial(NewMaterial(NewColor(0.8, 0.3, 0.2), 0.1, 0.7, 0.6, 100, 0.1, 0, 1, nil))
+
+	tessellated := NewGroup()
+	tessellated.SetTransform(Translation(2, 0, 0))
+	tessellated.ApplyTransform(RotationY(-PI / 6))
+	tessellated.ApplyTransform(Scaling(0.5, 0.5, 0.5))
+	tessellated.AddChild(NewTeapot(8))
+	tessellated.SetMaterial(NewMaterial(NewColor(0.2, 0.4, 0.8), 0.1, 0.7, 0.6, 100, 0.1, 0, 1, nil))
+
+	world := NewWorld(lights, []Shape{floor, direct, tessellated})
+
+	camera := NewCamera(600, 300, PI/3)
+	camera.SetTransform(ViewTransform(Point(0, 3, -6), Point(0, 0.7, 0), Vector(0, 1, 0)))
+
+	canvas := camera.RenderWithThreadPool(world, defaultRecursionDepth)
+
+	fmt.Println("Render time: ", time.Now().Sub(start))
+
+	return canvas
+}
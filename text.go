@@ -0,0 +1,186 @@
+package main
+
+import (
+	"math"
+	"strings"
+)
+
+// TextAlign places the lines of a text along x.
+type TextAlign int
+
+const (
+	// AlignLeft starts the lines at x = 0.
+	AlignLeft TextAlign = iota
+	// AlignCenter centers the lines on x = 0.
+	AlignCenter
+	// AlignRight ends the lines at x = 0.
+	AlignRight
+)
+
+// TextVerticalAlign places the lines of a text along y.
+type TextVerticalAlign int
+
+const (
+	// AlignBaseline puts the baseline of the first line at y = 0.
+	AlignBaseline TextVerticalAlign = iota
+	// AlignTop puts the top of the capitals of the first line at y = 0.
+	AlignTop
+	// AlignMiddle centers the lines, from the top of the capitals of the first one to the baseline
+	// of the last one, on y = 0.
+	AlignMiddle
+	// AlignBottom puts the baseline of the last line at y = 0.
+	AlignBottom
+)
+
+// TextConfig contains the parameters of the geometry of a text.
+type TextConfig struct {
+	// size is the height of the capitals.
+	size float64
+	// depth is the thickness of the letters along z, from their front at z = 0.
+	depth float64
+	// weight is the width of the strokes relative to size.
+	weight float64
+	// lineSpacing is the distance between the baselines of two lines relative to size.
+	lineSpacing   float64
+	align         TextAlign
+	verticalAlign TextVerticalAlign
+	// sides is the number of sides of the round ends and joints of the strokes.
+	sides int
+}
+
+// DefaultTextConfig returns the parameters of a text 1 high and 0.2 deep, aligned on the left of
+// the baseline.
+func DefaultTextConfig() *TextConfig {
+	return &TextConfig{
+		size:          1,
+		depth:         0.2,
+		weight:        0.12,
+		lineSpacing:   1.6,
+		align:         AlignLeft,
+		verticalAlign: AlignBaseline,
+		sides:         12,
+	}
+}
+
+// NewText returns a *Group of the characters of the text drawn with the built-in stroke font, each
+// extruded along z into a *Mesh. The text reads along x with y up and its front, at z = 0, faces
+// -z. Lines are separated by newlines and characters missing from the font are drawn as '?'. The
+// strokes of a character overlap where they meet, so the text is meant to be opaque.
+func NewText(text string, config *TextConfig) *Group {
+	scale := config.size / strokeCapHeight
+	lines := strings.Split(text, "\n")
+	spacing := config.lineSpacing * config.size
+
+	y := 0.0
+	switch config.verticalAlign {
+	case AlignTop:
+		y = -config.size
+	case AlignMiddle:
+		y = (float64(len(lines)-1)*spacing - config.size) / 2
+	case AlignBottom:
+		y = float64(len(lines)-1) * spacing
+	}
+
+	group := NewGroup()
+	for _, line := range lines {
+		glyphs := make([]*strokeGlyph, 0, len(line))
+		width := 0.0
+		for _, r := range line {
+			glyph, ok := strokeGlyphs[r]
+			if !ok {
+				glyph = strokeGlyphs['?']
+			}
+			glyphs = append(glyphs, glyph)
+			width += glyph.advance
+		}
+		// The advance of the last character holds the space before a next one.
+		if len(glyphs) > 0 {
+			width -= strokeSpacing
+		}
+
+		x := 0.0
+		switch config.align {
+		case AlignCenter:
+			x = -width / 2
+		case AlignRight:
+			x = -width
+		}
+		for _, glyph := range glyphs {
+			if len(glyph.strokes) > 0 {
+				mesh := newStrokeMesh(glyph.strokes, scale, config.weight*config.size/2, config.depth, config.sides)
+				mesh.SetTransform(Translation(x*scale, y, 0))
+				group.AddChild(mesh)
+			}
+			x += glyph.advance
+		}
+		y -= spacing
+	}
+	return group
+}
+
+// newStrokeMesh returns a *Mesh of the strokes scaled by scale, each segment a box of the width of
+// twice radius and each point a prism of sides sides around it, extruded from z = 0 to depth. The
+// sides of the prisms around the points are smooth, the other faces flat.
+func newStrokeMesh(strokes [][]Tuple, scale, radius, depth float64, sides int) *Mesh {
+	vertices := []Tuple{}
+	normals := []Tuple{}
+	vertexIndices := []int{}
+	normalIndices := []int{}
+
+	// prism adds the prism over a polygon of the xy plane, wound counterclockwise. The sides are
+	// smooth with the normals of the points of the polygon, or flat if polygonNormals is nil.
+	prism := func(polygon []Tuple, polygonNormals []Tuple) {
+		first := len(vertices)
+		for _, p := range polygon {
+			vertices = append(vertices, Point(p.x, p.y, 0), Point(p.x, p.y, depth))
+		}
+		firstNormal := len(normals)
+		normals = append(normals, polygonNormals...)
+
+		face := func(a, b, c, na, nb, nc int) {
+			vertexIndices = append(vertexIndices, first+a, first+b, first+c)
+			if polygonNormals == nil || na < 0 {
+				normalIndices = append(normalIndices, -1, -1, -1)
+			} else {
+				normalIndices = append(normalIndices, firstNormal+na, firstNormal+nb, firstNormal+nc)
+			}
+		}
+		n := len(polygon)
+		for i := 1; i+1 < n; i++ {
+			face(0, 2*i, 2*i+2, -1, -1, -1)
+			face(1, 2*i+3, 2*i+1, -1, -1, -1)
+		}
+		for i := 0; i < n; i++ {
+			j := (i + 1) % n
+			face(2*i, 2*j+1, 2*j, i, j, j)
+			face(2*i, 2*i+1, 2*j+1, i, i, j)
+		}
+	}
+
+	for _, stroke := range strokes {
+		for i, p := range stroke {
+			p = Point(p.x*scale, p.y*scale, 0)
+			circle := make([]Tuple, sides)
+			circleNormals := make([]Tuple, sides)
+			for k := range circle {
+				angle := 2 * PI * float64(k) / float64(sides)
+				circleNormals[k] = Vector(math.Cos(angle), math.Sin(angle), 0)
+				circle[k] = p.Add(circleNormals[k].Multiply(radius))
+			}
+			prism(circle, circleNormals)
+
+			if i+1 == len(stroke) {
+				continue
+			}
+			q := Point(stroke[i+1].x*scale, stroke[i+1].y*scale, 0)
+			direction := Vector(q.x-p.x, q.y-p.y, 0)
+			if direction.Magnitude() < EPSILON {
+				continue
+			}
+			direction = direction.Normalize()
+			left := Vector(-direction.y, direction.x, 0).Multiply(radius)
+			prism([]Tuple{p.Substract(left), q.Substract(left), q.Add(left), p.Add(left)}, nil)
+		}
+	}
+	return NewMesh(vertices, normals, nil, vertexIndices, normalIndices, nil)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// textWorld renders two lines of text standing on the floor, with the characters of the built-in
+// stroke font.
+func textWorld() *Canvas {
+	start := time.Now()
+	lights := []*PointLight{
+		NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1)),
+	}
+	floor := NewPlane()
+	floor.material.pattern = CheckersPattern(NewColor(0.6, 0.6, 0.6), NewColor(0.1, 0.1, 0.1))
+	floor.material.reflective = 0.2
+
+	config := DefaultTextConfig()
+	config.size = 0.5
+	config.depth = 0.3
+	config.align = AlignCenter
+	config.verticalAlign = AlignBottom
+	title := NewText("The quick brown fox\njumps over 13 lazy dogs!", config)
+	title.SetTransform(Translation(0, 0.35, 0))
+	title.ApplyTransform(RotationY(-PI / 12))
+	title.SetMaterial(NewMaterial(NewColor(0.8, 0.3, 0.2), 0.1, 0.7, 0.6, 100, 0.1, 0, 1, nil))
+
+	config = DefaultTextConfig()
+	config.size = 0.4
+	config.depth = 0.6
+	config.weight = 0.2
+	config.align = AlignRight
+	label := NewText("x = 42", config)
+	label.SetTransform(Translation(3.5, 0.01, -2.5))
+	label.ApplyTransform(RotationX(PI / 2))
+	label.SetMaterial(NewMaterial(NewColor(0.2, 0.4, 0.8), 0.1, 0.7, 0.6, 100, 0.1, 0, 1, nil))
+
+	world := NewWorld(lights, []Shape{floor, title, label})
+
+	camera := NewCamera(600, 300, PI/3)
+	camera.SetTransform(ViewTransform(Point(0, 3, -9), Point(0, 0.8, 0), Vector(0, 1, 0)))
+
+	canvas := camera.RenderWithThreadPool(world, defaultRecursionDepth)
+
+	fmt.Println("Render time: ", time.Now().Sub(start))
+
+	return canvas
+}
@@ -0,0 +1,79 @@
+package main
+
+import "testing"
+
+// The built-in font has the printable ASCII letters, digits and punctuation, all fitting its grid.
+func TestStrokeFont(t *testing.T) {
+	for r := rune(' '); r <= '~'; r++ {
+		if r == '@' || r == '^' || r == '`' || r == '{' || r == '|' || r == '}' || r == '~' {
+			continue
+		}
+		glyph, ok := strokeGlyphs[r]
+		if !ok {
+			t.Errorf("Stroke font: missing %q", r)
+			continue
+		}
+		for _, stroke := range glyph.strokes {
+			for _, p := range stroke {
+				if p.x < 0 || p.x > glyph.advance-strokeSpacing+0.5 || p.y < -3 || p.y > 9 {
+					t.Errorf("Stroke font: point %v of %q out of the glyph", p, r)
+				}
+			}
+		}
+	}
+}
+
+// A text is as high as its size and as deep as its depth, and is aligned around the origin.
+func TestText(t *testing.T) {
+	config := DefaultTextConfig()
+	config.size = 2
+	config.depth = 0.5
+	radius := config.weight * config.size / 2
+
+	text := NewText("HI", config)
+	box := text.LocalBounds()
+	// H is 6 wide, then 2 of space before the 4 of I, in units of 2/8.
+	expected := NewBoundingBox(Point(-radius, -radius, 0), Point(3+radius, 2+radius, 0.5))
+	if !box.min.Equals(expected.min) || !box.max.Equals(expected.max) {
+		t.Errorf("Text bounds: got %v, expected: %v", box, expected)
+	}
+
+	// The front of the left stroke of H faces -z, its side faces -x.
+	r := NewRay(Point(0, 1, -5), Vector(0, 0, 1))
+	xs := text.Intersect(r, nil)
+	xs.Sort()
+	if len(xs) == 0 || !floatEqual(xs[0].t, 5) {
+		t.Fatalf("Text intersections: got %v, expected the first one at: %v", xs, 5)
+	}
+	if n := xs[0].object.NormalAt(r.Position(xs[0].t), &xs[0]); !n.Equals(Vector(0, 0, -1)) {
+		t.Errorf("Text front normal: got %v, expected: %v", n, Vector(0, 0, -1))
+	}
+	r = NewRay(Point(-5, 1, 0.2), Vector(1, 0, 0))
+	xs = text.Intersect(r, nil)
+	xs.Sort()
+	if len(xs) == 0 || !floatEqual(xs[0].t, 5-radius) {
+		t.Fatalf("Text side intersections: got %v, expected the first one at: %v", xs, 5-radius)
+	}
+	if n := xs[0].object.NormalAt(r.Position(xs[0].t), &xs[0]); !n.Equals(Vector(-1, 0, 0)) {
+		t.Errorf("Text side normal: got %v, expected: %v", n, Vector(-1, 0, 0))
+	}
+
+	config.align = AlignCenter
+	config.verticalAlign = AlignMiddle
+	box = NewText("HI\nHI", config).LocalBounds()
+	if !floatEqual(box.min.x, -box.max.x) || !floatEqual(box.min.y, -box.max.y) {
+		t.Errorf("Centered text bounds: got %v, expected centered on the origin", box)
+	}
+	// Two lines are 2 high with 3.2 between their baselines.
+	if height := box.max.y - box.min.y; !floatEqual(height, 5.2+2*radius) {
+		t.Errorf("Centered text height: got %v, expected: %v", height, 5.2+2*radius)
+	}
+
+	config.align = AlignRight
+	config.verticalAlign = AlignTop
+	box = NewText("Hé", config).LocalBounds()
+	// The missing character is drawn as a question mark, 6 wide like H.
+	if !floatEqual(box.max.x, radius) || !floatEqual(box.min.x, -3.5-radius) || !floatEqual(box.max.y, radius) {
+		t.Errorf("Right aligned text bounds: got %v, expected from: %v to: %v", box, Point(-3.5-radius, -2-radius, 0), Point(radius, radius, 0))
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import "math"
+
+// Torus is a ring centered at the origin around the y axis. majorRadius is the distance from the
+// origin to the center of the tube and minorRadius the radius of the tube.
+type Torus struct {
+	BaseShape
+	majorRadius, minorRadius float64
+}
+
+// NewTorus returns a *Torus with the given radii, Identity matrix as transform and default material.
+func NewTorus(majorRadius, minorRadius float64) *Torus {
+	return &Torus{
+		BaseShape:   NewBaseShape(),
+		majorRadius: majorRadius,
+		minorRadius: minorRadius,
+	}
+}
+
+// LocalIntersect calculates the intersections between a ray in object space and the torus by solving
+// (|p|^2 + R^2 - r^2)^2 = 4R^2(x^2 + z^2) for p on the ray.
+func (torus *Torus) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	o, d := localRay.origin, localRay.direction
+	dd := d.DotProduct(d)
+	if dd == 0 {
+		return xs
+	}
+
+	// Move the origin to the point of the ray closest to the center: the coefficients stay small,
+	// which keeps the quartic well conditioned for rays starting far away, and rays passing
+	// beyond the bounding sphere are discarded at once.
+	shift := -(o.x*d.x + o.y*d.y + o.z*d.z) / dd
+	o = Point(o.x+shift*d.x, o.y+shift*d.y, o.z+shift*d.z)
+	outer := torus.majorRadius + torus.minorRadius
+	if o.x*o.x+o.y*o.y+o.z*o.z > outer*outer {
+		return xs
+	}
+
+	R2 := torus.majorRadius * torus.majorRadius
+	r2 := torus.minorRadius * torus.minorRadius
+	od := o.x*d.x + o.y*d.y + o.z*d.z
+	oo := o.x*o.x + o.y*o.y + o.z*o.z
+
+	// |p|^2 + R^2 - r^2 = dd*t^2 + b*t + c and x^2 + z^2 = e*t^2 + f*t + g.
+	b := 2 * od
+	c := oo + R2 - r2
+	e := d.x*d.x + d.z*d.z
+	f := 2 * (o.x*d.x + o.z*d.z)
+	g := o.x*o.x + o.z*o.z
+
+	var rootsArray [4]float64
+	roots := solveQuartic(
+		dd*dd,
+		2*dd*b,
+		b*b+2*dd*c-4*R2*e,
+		2*b*c-4*R2*f,
+		c*c-4*R2*g,
+		rootsArray[:0])
+
+	start := len(xs)
+	for _, t := range roots {
+		xs = append(xs, NewIntersection(t+shift, torus))
+	}
+	if len(xs)-start > 1 {
+		xs[start:].Sort()
+	}
+	return xs
+}
+
+// Intersect calculates the intersections between a ray in world space and the torus.
+func (torus *Torus) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(torus, worldRay, xs)
+}
+
+// LocalNormalAt returns the gradient of the torus equation at the point, in object space.
+func (torus *Torus) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	R2 := torus.majorRadius * torus.majorRadius
+	k := localPoint.x*localPoint.x + localPoint.y*localPoint.y + localPoint.z*localPoint.z -
+		R2 - torus.minorRadius*torus.minorRadius
+	return Vector(localPoint.x*k, localPoint.y*(k+2*R2), localPoint.z*k).Normalize()
+}
+
+// NormalAt calculates the normal (vector perpendicular to the surface) at a given point in world space.
+func (torus *Torus) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(torus, worldPoint, intersection)
+}
+
+// LocalBounds returns the bounding box of the torus in object space.
+func (torus *Torus) LocalBounds() *BoundingBox {
+	outer := torus.majorRadius + torus.minorRadius
+	return NewBoundingBoxFloat(-outer, -torus.minorRadius, -outer, outer, torus.minorRadius, outer)
+}
+
+// UVAt maps a point of the torus in object space to (u, v): u goes around the y axis like
+// cylindricalMap and v goes around the tube, starting from its inner side.
+// Use it as the uvMap of a textureMap to texture a torus.
+func (torus *Torus) UVAt(point Tuple) (u, v float64) {
+	theta := math.Atan2(point.x, point.z)
+	u = 1 - (theta/(2*PI) + 0.5)
+
+	phi := math.Atan2(point.y, math.Sqrt(point.x*point.x+point.z*point.z)-torus.majorRadius)
+	v = phi/(2*PI) + 0.5
+	return
+}
+
+// uvToroidalCheckersPattern returns a checkers pattern mapped on the torus with UVAt.
+// only the 2 first colors from the parameter slice are processed.
+func uvToroidalCheckersPattern(torus *Torus, colors ...Color) *Pattern {
+	checkers := textureMap(uvCheckers(16, 8, colors[0], colors[1]), torus.UVAt)
+	return NewPattern(nil, [][]Color{colors}, func(_ *Canvas, _ []Color, p Tuple) Color {
+		return patternAt(checkers, p)
+	})
+}
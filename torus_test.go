@@ -0,0 +1,100 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// A ray through the center of a torus crosses the tube twice on each side.
+func TestTorusIntersect(t *testing.T) {
+	torus := NewTorus(1, 0.25)
+	tests := []struct {
+		ray      Ray
+		expected []float64
+	}{
+		{NewRay(Point(-5, 0, 0), Vector(1, 0, 0)), []float64{3.75, 4.25, 5.75, 6.25}},
+		{NewRay(Point(1, 5, 0), Vector(0, -1, 0)), []float64{4.75, 5.25}},
+		{NewRay(Point(0, 0, -5), Vector(0, 0, 2)), []float64{1.875, 2.125, 2.875, 3.125}},
+		{NewRay(Point(0, 5, 0), Vector(0, -1, 0)), []float64{}},
+		{NewRay(Point(-5, 0.3, 0), Vector(1, 0, 0)), []float64{}},
+		// Far away rays keep their precision.
+		{NewRay(Point(-1e5, 0, 0), Vector(1, 0, 0)), []float64{1e5 - 1.25, 1e5 - 0.75, 1e5 + 0.75, 1e5 + 1.25}},
+	}
+
+	for _, test := range tests {
+		xs := torus.LocalIntersect(test.ray, nil)
+		if len(xs) != len(test.expected) {
+			t.Errorf("Torus intersections count: got %v, expected: %v", len(xs), len(test.expected))
+			continue
+		}
+		for i := range xs {
+			if math.Abs(xs[i].t-test.expected[i]) > 1e-6 {
+				t.Errorf("Torus intersection: got %v, expected: %v", xs[i].t, test.expected[i])
+			}
+		}
+	}
+}
+
+// The normal of a torus points away from the center of the tube.
+func TestTorusNormal(t *testing.T) {
+	torus := NewTorus(1, 0.25)
+	tests := []struct {
+		point, expected Tuple
+	}{
+		{Point(1.25, 0, 0), Vector(1, 0, 0)},
+		{Point(0.75, 0, 0), Vector(-1, 0, 0)},
+		{Point(0, 0.25, 1), Vector(0, 1, 0)},
+		{Point(0, -0.25, -1), Vector(0, -1, 0)},
+		{Point(1+0.25*math.Sqrt2/2, 0.25*math.Sqrt2/2, 0), Vector(math.Sqrt2/2, math.Sqrt2/2, 0)},
+	}
+	for _, test := range tests {
+		if n := torus.LocalNormalAt(test.point, nil); !n.Equals(test.expected) {
+			t.Errorf("Torus normal at %v: got %v, expected: %v", test.point, n, test.expected)
+		}
+	}
+}
+
+// The bounds and texture coordinates of a torus.
+func TestTorusBoundsAndUV(t *testing.T) {
+	torus := NewTorus(2, 0.5)
+	box := torus.LocalBounds()
+	if !box.min.Equals(Point(-2.5, -0.5, -2.5)) || !box.max.Equals(Point(2.5, 0.5, 2.5)) {
+		t.Errorf("Torus bounds: got %v, expected: %v", box, NewBoundingBoxFloat(-2.5, -0.5, -2.5, 2.5, 0.5, 2.5))
+	}
+
+	tests := []struct {
+		point Tuple
+		u, v  float64
+	}{
+		{Point(0, 0, 2.5), 0.5, 0.5},
+		{Point(0, 0.5, 2), 0.5, 0.75},
+		{Point(2.5, 0, 0), 0.25, 0.5},
+		{Point(0, 0, -1.5), 0, 1},
+	}
+	for _, test := range tests {
+		u, v := torus.UVAt(test.point)
+		if !floatEqual(u, test.u) || !floatEqual(v, test.v) {
+			t.Errorf("Torus UV at %v: got %v, %v, expected: %v, %v", test.point, u, v, test.u, test.v)
+		}
+	}
+}
+
+// A torus can be used in a CSG, here a ring notched by a cube.
+func TestTorusCSG(t *testing.T) {
+	torus := NewTorus(1, 0.25)
+	cube := NewCube()
+	cube.SetTransform(Translation(2, 0, 0))
+	csg := NewCSG("difference", torus, cube)
+	csg.Bounds()
+
+	xs := csg.LocalIntersect(NewRay(Point(-5, 0, 0), Vector(1, 0, 0)), nil)
+	expected := []float64{3.75, 4.25, 5.75, 6}
+	if len(xs) != len(expected) {
+		t.Fatalf("Torus CSG intersections count: got %v, expected: %v", len(xs), len(expected))
+	}
+	for i := range xs {
+		if !floatEqual(xs[i].t, expected[i]) {
+			t.Errorf("Torus CSG intersection: got %v, expected: %v", xs[i].t, expected[i])
+		}
+	}
+}
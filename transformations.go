@@ -3,7 +3,7 @@ package main
 import "math"
 
 // Translation Returns a translation matrix
-func Translation(x, y, z float64) Matrix {
+func Translation(x, y, z float64) Matrix4 {
 	matrix := NewIdentityMatrix()
 
 	matrix.Set(0, 3, x)
@@ -14,7 +14,7 @@ func Translation(x, y, z float64) Matrix {
 }
 
 // Scaling returns a scale matrix
-func Scaling(x, y, z float64) Matrix {
+func Scaling(x, y, z float64) Matrix4 {
 	matrix := NewIdentityMatrix()
 	matrix.Set(0, 0, x)
 	matrix.Set(1, 1, y)
@@ -23,7 +23,7 @@ func Scaling(x, y, z float64) Matrix {
 }
 
 // RotationX returns a rotation matrix of the given radians
-func RotationX(r float64) Matrix {
+func RotationX(r float64) Matrix4 {
 	matrix := NewIdentityMatrix()
 	matrix.Set(1, 1, math.Cos(r))
 	matrix.Set(1, 2, -math.Sin(r))
@@ -33,7 +33,7 @@ func RotationX(r float64) Matrix {
 }
 
 // RotationY returns a rotation matrix of the given radians
-func RotationY(r float64) Matrix {
+func RotationY(r float64) Matrix4 {
 	matrix := NewIdentityMatrix()
 	matrix.Set(0, 0, math.Cos(r))
 	matrix.Set(0, 2, math.Sin(r))
@@ -43,7 +43,7 @@ func RotationY(r float64) Matrix {
 }
 
 // RotationZ returns a rotation matrix of the given radians
-func RotationZ(r float64) Matrix {
+func RotationZ(r float64) Matrix4 {
 	matrix := NewIdentityMatrix()
 	matrix.Set(0, 0, math.Cos(r))
 	matrix.Set(0, 1, -math.Sin(r))
@@ -52,8 +52,24 @@ func RotationZ(r float64) Matrix {
 	return matrix
 }
 
+// RotationAxis returns a rotation matrix of the given radians around an arbitrary axis.
+func RotationAxis(axis Tuple, r float64) Matrix4 {
+	axis = Vector(axis.x, axis.y, axis.z).Normalize()
+	cos := math.Cos(r)
+	sin := math.Sin(r)
+	t := 1 - cos
+	x, y, z := axis.x, axis.y, axis.z
+
+	return Matrix4{
+		{t*x*x + cos, t*x*y - sin*z, t*x*z + sin*y, 0},
+		{t*x*y + sin*z, t*y*y + cos, t*y*z - sin*x, 0},
+		{t*x*z - sin*y, t*y*z + sin*x, t*z*z + cos, 0},
+		{0, 0, 0, 1},
+	}
+}
+
 // Shearing returns a shearing(for skewing) matrix
-func Shearing(xy, xz, yx, yz, zx, zy float64) Matrix {
+func Shearing(xy, xz, yx, yz, zx, zy float64) Matrix4 {
 	matrix := NewIdentityMatrix()
 
 	matrix.Set(0, 1, xy)
@@ -67,7 +83,7 @@ func Shearing(xy, xz, yx, yz, zx, zy float64) Matrix {
 }
 
 // ViewTransform returns a view transformation matrix taking into account the orientation vectors.
-func ViewTransform(from, to, up *Tuple) Matrix {
+func ViewTransform(from, to, up Tuple) Matrix4 {
 	forward := to.Substract(from).Normalize()
 	left := forward.CrossProduct(up.Normalize())
 	trueUp := left.CrossProduct(forward)
@@ -86,3 +102,145 @@ func ViewTransform(from, to, up *Tuple) Matrix {
 	return orientation.MultiplyMatrix(Translation(-from.x, -from.y, -from.z))
 
 }
+
+// LookAt returns the transformation placing an object at from, with its z axis pointing towards to
+// and its y axis as close as possible to up. Unlike ViewTransform, which moves the world in front
+// of the camera, this moves the object itself.
+func LookAt(from, to, up Tuple) Matrix4 {
+	forward := to.Substract(from).Normalize()
+	left := up.Normalize().CrossProduct(forward).Normalize()
+	trueUp := forward.CrossProduct(left)
+
+	return Matrix4{
+		{left.x, trueUp.x, forward.x, from.x},
+		{left.y, trueUp.y, forward.y, from.y},
+		{left.z, trueUp.z, forward.z, from.z},
+		{0, 0, 0, 1},
+	}
+}
+
+// TRS holds a transformation split into its translation, rotation and scale components,
+// so that each of them can be edited later on without rebuilding the whole matrix by hand.
+// The rotation is given in radians around the x, y and z axes, applied in that order.
+// The residual holds what the components cannot express, applied between the scale and the rotation:
+// the shearing of a decomposed matrix, or the whole matrix when it cannot be decomposed.
+type TRS struct {
+	translation, rotation, scale Tuple
+	residual                     Matrix4
+}
+
+// NewTRS returns the components of the identity transformation.
+func NewTRS() TRS {
+	return TRS{
+		translation: Vector(0, 0, 0),
+		rotation:    Vector(0, 0, 0),
+		scale:       Vector(1, 1, 1),
+		residual:    IdentityMatrix,
+	}
+}
+
+// TRSFromMatrix splits a matrix into its translation, rotation and scale components, keeping its
+// shearing as the residual. A matrix that cannot be decomposed is kept whole as the residual.
+func TRSFromMatrix(m Matrix4) TRS {
+	trs := NewTRS()
+	d, ok := m.Decompose()
+	if !ok {
+		trs.residual = m
+		return trs
+	}
+
+	// Euler angles of rotation = RotationZ(z) * RotationY(y) * RotationX(x).
+	r := d.rotation.Matrix()
+	y := math.Asin(math.Max(-1, math.Min(1, -r[2][0])))
+	var x, z float64
+	if math.Cos(y) > EPSILON*EPSILON {
+		x = math.Atan2(r[2][1], r[2][2])
+		z = math.Atan2(r[1][0], r[0][0])
+	} else {
+		// Gimbal lock: only x + z or x - z is known, so the rotation around z is left out.
+		x = math.Atan2(-r[1][2], r[1][1])
+	}
+
+	trs.translation = d.translation
+	trs.rotation = Vector(x, y, z)
+	trs.scale = d.scale
+	trs.residual = Shearing(d.shear.x, d.shear.y, 0, d.shear.z, 0, 0)
+	return trs
+}
+
+// Matrix composes the components into a single matrix: the scale is applied first, then the residual,
+// the rotation and finally the translation.
+func (trs TRS) Matrix() Matrix4 {
+	return Translation(trs.translation.x, trs.translation.y, trs.translation.z).
+		MultiplyMatrix(RotationZ(trs.rotation.z)).
+		MultiplyMatrix(RotationY(trs.rotation.y)).
+		MultiplyMatrix(RotationX(trs.rotation.x)).
+		MultiplyMatrix(trs.residual).
+		MultiplyMatrix(Scaling(trs.scale.x, trs.scale.y, trs.scale.z))
+}
+
+// Decomposition holds an affine transformation split into translation, rotation, shearing and scale,
+// the transformation being equal to translation * rotation * shear * scale.
+// The shear components are the xy, xz and yz factors of Shearing(), stored as x, y and z.
+type Decomposition struct {
+	translation Tuple
+	rotation    Quaternion
+	shear       Tuple
+	scale       Tuple
+}
+
+// Matrix composes the decomposition back into a single matrix.
+func (d Decomposition) Matrix() Matrix4 {
+	return Translation(d.translation.x, d.translation.y, d.translation.z).
+		MultiplyMatrix(d.rotation.Matrix()).
+		MultiplyMatrix(Shearing(d.shear.x, d.shear.y, 0, d.shear.z, 0, 0)).
+		MultiplyMatrix(Scaling(d.scale.x, d.scale.y, d.scale.z))
+}
+
+// Decompose splits an affine matrix into its translation, rotation, shearing and scale.
+// The columns of the upper 3x3 part are orthonormalized (Gram-Schmidt), which leaves the scale and
+// shearing in an upper triangular matrix. A mirroring transformation gets a negative z scale.
+// Returns false when the matrix is not affine or not invertible.
+func (m Matrix4) Decompose() (Decomposition, bool) {
+	if !m.IsAffine() || m.determinant3() == 0 {
+		return Decomposition{}, false
+	}
+
+	c0 := Vector(m[0][0], m[1][0], m[2][0])
+	c1 := Vector(m[0][1], m[1][1], m[2][1])
+	c2 := Vector(m[0][2], m[1][2], m[2][2])
+
+	sx := c0.Magnitude()
+	q0 := c0.Divide(sx)
+
+	u01 := q0.DotProduct(c1)
+	c1 = c1.Substract(q0.Multiply(u01))
+	sy := c1.Magnitude()
+	q1 := c1.Divide(sy)
+
+	u02 := q0.DotProduct(c2)
+	u12 := q1.DotProduct(c2)
+	c2 = c2.Substract(q0.Multiply(u02)).Substract(q1.Multiply(u12))
+	sz := c2.Magnitude()
+	q2 := c2.Divide(sz)
+
+	// Keep the rotation a proper rotation by moving any mirroring into the scale.
+	if m.determinant3() < 0 {
+		q2 = q2.Negate()
+		sz = -sz
+	}
+
+	rotation := Matrix4{
+		{q0.x, q1.x, q2.x, 0},
+		{q0.y, q1.y, q2.y, 0},
+		{q0.z, q1.z, q2.z, 0},
+		{0, 0, 0, 1},
+	}
+
+	return Decomposition{
+		translation: Vector(m[0][3], m[1][3], m[2][3]),
+		rotation:    QuaternionFromMatrix(rotation),
+		shear:       Vector(u01/sy, u02/sz, u12/sz),
+		scale:       Vector(sx, sy, sz),
+	}, true
+}
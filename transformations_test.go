@@ -310,13 +310,124 @@ func TestViewTransform(t *testing.T) {
 	to = Point(4, -2, 8)
 	up = Vector(1, 1, 0)
 	result = ViewTransform(from, to, up)
-	expected = [][]float64{
-		[]float64{-0.507093, 0.507093, 0.676123, -2.366432},
-		[]float64{0.767716, 0.606092, 0.121218, -2.828427},
-		[]float64{-0.358569, 0.597614, -0.717137, 0},
-		[]float64{0, 0, 0, 1},
+	expected = Matrix4{
+		{-0.507093, 0.507093, 0.676123, -2.366432},
+		{0.767716, 0.606092, 0.121218, -2.828427},
+		{-0.358569, 0.597614, -0.717137, 0},
+		{0, 0, 0, 1},
 	}
 	if !result.Equals(expected) {
 		t.Errorf("ViewTransform(arbitary): expected %v to equal %v", result, expected)
 	}
 }
+
+func TestRotationAxis(t *testing.T) {
+	// Rotating around the main axes matches the Euler rotation matrices.
+	if !RotationAxis(Vector(1, 0, 0), PI/3).Equals(RotationX(PI / 3)) {
+		t.Errorf("RotationAxis x: got %v, expected: %v", RotationAxis(Vector(1, 0, 0), PI/3), RotationX(PI/3))
+	}
+	if !RotationAxis(Vector(0, 3, 0), PI/3).Equals(RotationY(PI / 3)) {
+		t.Errorf("RotationAxis y: got %v, expected: %v", RotationAxis(Vector(0, 3, 0), PI/3), RotationY(PI/3))
+	}
+
+	// Rotating around the diagonal by a third of a turn swaps the axes.
+	r := RotationAxis(Vector(1, 1, 1), 2*PI/3).MultiplyMatrixByTuple(Point(1, 0, 0))
+	if !r.Equals(Point(0, 1, 0)) {
+		t.Errorf("RotationAxis diagonal: got %v, expected: %v", r, Point(0, 1, 0))
+	}
+}
+
+func TestLookAt(t *testing.T) {
+	// An object looking at a point has its z axis pointing towards that point.
+	from := Point(1, 2, 3)
+	to := Point(1, 2, -7)
+	m := LookAt(from, to, Vector(0, 1, 0))
+
+	if !m.MultiplyMatrixByTuple(Point(0, 0, 0)).Equals(from) {
+		t.Errorf("LookAt position: got %v, expected: %v", m.MultiplyMatrixByTuple(Point(0, 0, 0)), from)
+	}
+	if !m.MultiplyMatrixByTuple(Vector(0, 0, 1)).Equals(Vector(0, 0, -1)) {
+		t.Errorf("LookAt forward: got %v, expected: %v", m.MultiplyMatrixByTuple(Vector(0, 0, 1)), Vector(0, 0, -1))
+	}
+	if !m.MultiplyMatrixByTuple(Vector(0, 1, 0)).Equals(Vector(0, 1, 0)) {
+		t.Errorf("LookAt up: got %v, expected: %v", m.MultiplyMatrixByTuple(Vector(0, 1, 0)), Vector(0, 1, 0))
+	}
+
+	// Looking at the camera gives the inverse of the camera's view transformation, flipped around y.
+	view := ViewTransform(from, to, Vector(0, 1, 0))
+	expected := view.Inverse().MultiplyMatrix(RotationY(PI))
+	if !m.Equals(expected) {
+		t.Errorf("LookAt and ViewTransform: got %v, expected: %v", m, expected)
+	}
+}
+
+func TestDecompose(t *testing.T) {
+	// Decomposing a transformation gives back its components.
+	rotation := QuaternionFromAxisAngle(Vector(1, 2, -1), 0.7)
+	m := Translation(1, -2, 3).
+		MultiplyMatrix(rotation.Matrix()).
+		MultiplyMatrix(Shearing(0.5, 0, 0, 0.25, 0, 0)).
+		MultiplyMatrix(Scaling(2, 3, 4))
+
+	d, ok := m.Decompose()
+	if !ok {
+		t.Fatalf("Decompose: expected %v to be decomposable", m)
+	}
+	if !d.translation.Equals(Vector(1, -2, 3)) {
+		t.Errorf("Decompose translation: got %v, expected: %v", d.translation, Vector(1, -2, 3))
+	}
+	if !d.rotation.SameRotation(rotation) {
+		t.Errorf("Decompose rotation: got %v, expected: %v", d.rotation, rotation)
+	}
+	if !d.shear.Equals(Vector(0.5, 0, 0.25)) {
+		t.Errorf("Decompose shear: got %v, expected: %v", d.shear, Vector(0.5, 0, 0.25))
+	}
+	if !d.scale.Equals(Vector(2, 3, 4)) {
+		t.Errorf("Decompose scale: got %v, expected: %v", d.scale, Vector(2, 3, 4))
+	}
+	if !d.Matrix().Equals(m) {
+		t.Errorf("Decompose round trip: got %v, expected: %v", d.Matrix(), m)
+	}
+
+	// A mirroring transformation keeps a proper rotation.
+	mirror := RotationZ(PI / 5).MultiplyMatrix(Scaling(1, 1, -2))
+	d, _ = mirror.Decompose()
+	if !d.Matrix().Equals(mirror) || !floatEqual(d.rotation.Matrix().Determinant(), 1) {
+		t.Errorf("Decompose mirror: got %v, expected: %v", d.Matrix(), mirror)
+	}
+
+	// A singular matrix can't be decomposed.
+	if _, ok := Scaling(1, 0, 1).Decompose(); ok {
+		t.Errorf("Decompose singular: expected %v not to be decomposable", Scaling(1, 0, 1))
+	}
+}
+
+func TestTRSFromMatrix(t *testing.T) {
+	// The components of a matrix rebuild it.
+	m := Translation(1, -2, 3).
+		MultiplyMatrix(RotationZ(0.3)).
+		MultiplyMatrix(RotationY(-1.1)).
+		MultiplyMatrix(RotationX(2.5)).
+		MultiplyMatrix(Scaling(2, 3, 4))
+	trs := TRSFromMatrix(m)
+	if !trs.Matrix().Equals(m) {
+		t.Errorf("TRSFromMatrix round trip: got %v, expected: %v", trs.Matrix(), m)
+	}
+	if !trs.translation.Equals(Vector(1, -2, 3)) || !trs.scale.Equals(Vector(2, 3, 4)) {
+		t.Errorf("TRSFromMatrix components: got %v %v, expected: %v %v", trs.translation, trs.scale, Vector(1, -2, 3), Vector(2, 3, 4))
+	}
+	if !trs.residual.Equals(IdentityMatrix) {
+		t.Errorf("TRSFromMatrix residual: got %v, expected: %v", trs.residual, IdentityMatrix)
+	}
+
+	// Shearing and rotations in gimbal lock are kept.
+	for _, m := range []Matrix4{
+		RotationY(PI / 2).MultiplyMatrix(RotationX(0.4)).MultiplyMatrix(RotationZ(0.2)),
+		Translation(0, 1, 0).MultiplyMatrix(Shearing(0.5, 0, 0, 0.25, 0, 0)).MultiplyMatrix(Scaling(1, 1, -2)),
+		Scaling(1, 0, 1),
+	} {
+		if trs := TRSFromMatrix(m); !trs.Matrix().Equals(m) {
+			t.Errorf("TRSFromMatrix round trip: got %v, expected: %v", trs.Matrix(), m)
+		}
+	}
+}
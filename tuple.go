@@ -12,24 +12,24 @@ type Tuple struct {
 }
 
 // Point creates a tuple representing a point (w == 1)
-func Point(x, y, z float64) *Tuple {
-	return &Tuple{x, y, z, 1.0}
+func Point(x, y, z float64) Tuple {
+	return Tuple{x, y, z, 1.0}
 }
 
 // Vector creates a tuple representing a vector (w == 0)
-func Vector(x, y, z float64) *Tuple {
-	return &Tuple{x, y, z, 0.0}
+func Vector(x, y, z float64) Tuple {
+	return Tuple{x, y, z, 0.0}
 }
 
 // Equals returns true if x, y, z, w from tuples t and o are within the error margin Epsilon.
-func (t *Tuple) Equals(o *Tuple) bool {
+func (t Tuple) Equals(o Tuple) bool {
 
 	return floatEqual(t.x, o.x) && floatEqual(t.y, o.y) && floatEqual(t.z, o.z) && floatEqual(t.w, o.w)
 }
 
 // Add tuples.
-func (t *Tuple) Add(o *Tuple) *Tuple {
-	return &Tuple{
+func (t Tuple) Add(o Tuple) Tuple {
+	return Tuple{
 		x: t.x + o.x,
 		y: t.y + o.y,
 		z: t.z + o.z,
@@ -38,8 +38,8 @@ func (t *Tuple) Add(o *Tuple) *Tuple {
 }
 
 // Substract tuples.
-func (t *Tuple) Substract(o *Tuple) *Tuple {
-	return &Tuple{
+func (t Tuple) Substract(o Tuple) Tuple {
+	return Tuple{
 		t.x - o.x,
 		t.y - o.y,
 		t.z - o.z,
@@ -48,8 +48,8 @@ func (t *Tuple) Substract(o *Tuple) *Tuple {
 }
 
 // Negate values contained in tuple.
-func (t *Tuple) Negate() *Tuple {
-	return &Tuple{
+func (t Tuple) Negate() Tuple {
+	return Tuple{
 		x: -t.x,
 		y: -t.y,
 		z: -t.z,
@@ -58,8 +58,8 @@ func (t *Tuple) Negate() *Tuple {
 }
 
 // Multiply tuples.
-func (t *Tuple) Multiply(o float64) *Tuple {
-	return &Tuple{
+func (t Tuple) Multiply(o float64) Tuple {
+	return Tuple{
 		x: t.x * o,
 		y: t.y * o,
 		z: t.z * o,
@@ -68,8 +68,8 @@ func (t *Tuple) Multiply(o float64) *Tuple {
 }
 
 // Divide tuples.
-func (t *Tuple) Divide(o float64) *Tuple {
-	return &Tuple{
+func (t Tuple) Divide(o float64) Tuple {
+	return Tuple{
 		x: t.x / o,
 		y: t.y / o,
 		z: t.z / o,
@@ -79,16 +79,16 @@ func (t *Tuple) Divide(o float64) *Tuple {
 
 // square the value
 func square(v float64) float64 {
-	return math.Pow(v, 2.0)
+	return v * v
 }
 
 // Magnitude of a vector
-func (t *Tuple) Magnitude() float64 {
+func (t Tuple) Magnitude() float64 {
 	return math.Sqrt(square(t.x) + square(t.y) + square(t.z) + square(t.w))
 }
 
 // Normalize a vector (tuple with w == 0)
-func (t *Tuple) Normalize() *Tuple {
+func (t Tuple) Normalize() Tuple {
 	mag := t.Magnitude()
 	if mag == 0.0 {
 		return t
@@ -97,16 +97,16 @@ func (t *Tuple) Normalize() *Tuple {
 }
 
 // DotProduct from 2 tuples.
-func (t *Tuple) DotProduct(o *Tuple) float64 {
+func (t Tuple) DotProduct(o Tuple) float64 {
 	return ((t.x * o.x) + (t.y * o.y) + (t.z * o.z) + (t.w * o.w))
 }
 
 // CrossProduct from 2 vectors (tuple with w == 0).
-func (t *Tuple) CrossProduct(o *Tuple) *Tuple {
-	return Vector((*t).y*o.z-t.z*o.y, t.z*o.x-t.x*o.z, t.x*o.y-t.y*o.x)
+func (t Tuple) CrossProduct(o Tuple) Tuple {
+	return Vector(t.y*o.z-t.z*o.y, t.z*o.x-t.x*o.z, t.x*o.y-t.y*o.x)
 }
 
-// Reflect returns a pointer to a reflection vector based off an incoming vector and a normal vector.
-func (t *Tuple) Reflect(normal *Tuple) *Tuple {
+// Reflect returns a reflection vector based off an incoming vector and a normal vector.
+func (t Tuple) Reflect(normal Tuple) Tuple {
 	return t.Substract(normal.Multiply(2).Multiply(t.DotProduct(normal)))
 }
@@ -77,7 +77,7 @@ func min(values ...float64) float64 {
 
 // Transform returns the result of multiple chained transformations applied to a tuple in a customized order.
 // T ← C * B * A will be passed here as A * B * C arguments instead.
-func (t *Tuple) Transform(transformations ...Matrix) *Tuple {
+func (t Tuple) Transform(transformations ...Matrix4) Tuple {
 
 	if len(transformations) < 1 {
 		return t
@@ -4,8 +4,8 @@ import "math"
 
 // UVCheckers encapsulates the parameters for uvcheckers.
 type UVCheckers struct {
-	colorA *Color
-	colorB *Color
+	colorA Color
+	colorB Color
 	width  float64
 	height float64
 }
@@ -24,7 +24,7 @@ func (pattern *UVAlignCheck) isPattern() bool {
 }
 
 // uvCheckers will return a data structure that encapsulates the function's parameters.
-func uvCheckers(width, height float64, colorA, colorB *Color) *UVCheckers {
+func uvCheckers(width, height float64, colorA, colorB Color) *UVCheckers {
 
 	return &UVCheckers{
 		colorA: colorA,
@@ -36,7 +36,7 @@ func uvCheckers(width, height float64, colorA, colorB *Color) *UVCheckers {
 
 // uvPatternAt will return the pattern's color at the given u and v coordinates,
 // where both u and v are floating point numbers between 0 and 1, inclusive.
-func uvPatternAt(pattern patternType, u, v float64) *Color {
+func uvPatternAt(pattern patternType, u, v float64) Color {
 
 	switch p := pattern.(type) {
 
@@ -79,12 +79,12 @@ func uvPatternAt(pattern patternType, u, v float64) *Color {
 		return p.canvas.PixelAt(int(math.Round(x)), int(math.Round(y)))
 
 	default:
-		return nil
+		return Black
 	}
 }
 
 // sphericalMap maps a 3D point (x, y, z) on the surface of sphere to a 2D point (u, v) on the flattened surface.
-func sphericalMap(point *Tuple) (u, v float64) {
+func sphericalMap(point Tuple) (u, v float64) {
 
 	// Compute the azimuthal angle (-π < theta <= π).
 	// Angle increases clockwise as viewed from above, which is opposite of what we want, but we'll fix it later.
@@ -118,18 +118,18 @@ func sphericalMap(point *Tuple) (u, v float64) {
 // TextureMap encapsulates the given uv_pattern (like uv_checkers() ) and uv_map (like spherical_map() ).
 type TextureMap struct {
 	uvPattern patternType
-	uvMap     func(point *Tuple) (u, v float64)
+	uvMap     func(point Tuple) (u, v float64)
 }
 
 // textureMap returns a *TextureMap struct.
-func textureMap(uvPattern patternType, uvMap func(point *Tuple) (u, v float64)) *TextureMap {
+func textureMap(uvPattern patternType, uvMap func(point Tuple) (u, v float64)) *TextureMap {
 	return &TextureMap{
 		uvPattern: uvPattern,
 		uvMap:     uvMap,
 	}
 }
 
-func patternAt(textureMap *TextureMap, point *Tuple) *Color {
+func patternAt(textureMap *TextureMap, point Tuple) Color {
 
 	u, v := textureMap.uvMap(point)
 	return uvPatternAt(textureMap.uvPattern, u, v)
@@ -137,7 +137,7 @@ func patternAt(textureMap *TextureMap, point *Tuple) *Color {
 
 // uvSphericalCheckersFunc adapts the uvCheckers and textureMap to be set as a func to the *Pattern struct.
 // only the 2 first colors from the parameter slice are processed.
-func uvSphericalCheckersFunc(_ *Canvas, colors []*Color, p *Tuple) *Color {
+func uvSphericalCheckersFunc(_ *Canvas, colors []Color, p Tuple) Color {
 
 	checkers := uvCheckers(16, 8, colors[0], colors[1])
 	pattern := textureMap(checkers, sphericalMap)
@@ -145,13 +145,13 @@ func uvSphericalCheckersFunc(_ *Canvas, colors []*Color, p *Tuple) *Color {
 }
 
 // uvSphericalCheckersPattern returns the appropiate *Pattern struct.
-func uvSphericalCheckersPattern(colors ...*Color) *Pattern {
+func uvSphericalCheckersPattern(colors ...Color) *Pattern {
 
-	return NewPattern(nil, [][]*Color{colors}, uvSphericalCheckersFunc)
+	return NewPattern(nil, [][]Color{colors}, uvSphericalCheckersFunc)
 }
 
 // planarMap returns the u,v coordinates for a flattened surface.
-func planarMap(point *Tuple) (u, v float64) {
+func planarMap(point Tuple) (u, v float64) {
 
 	// Working Implementation different from:
 	/*
@@ -179,7 +179,7 @@ func planarMap(point *Tuple) (u, v float64) {
 
 // uvPlanarCheckersFunc adapts the uvCheckers and textureMap to be set as a func to the *Pattern struct.
 // only the 2 first colors from the parameter slice are processed.
-func uvPlanarCheckersFunc(_ *Canvas, colors []*Color, p *Tuple) *Color {
+func uvPlanarCheckersFunc(_ *Canvas, colors []Color, p Tuple) Color {
 
 	checkers := uvCheckers(16, 8, colors[0], colors[1])
 	pattern := textureMap(checkers, planarMap)
@@ -187,13 +187,13 @@ func uvPlanarCheckersFunc(_ *Canvas, colors []*Color, p *Tuple) *Color {
 }
 
 // uvPlanarCheckersPattern returns the appropiate *Pattern struct.
-func uvPlanarCheckersPattern(colors ...*Color) *Pattern {
+func uvPlanarCheckersPattern(colors ...Color) *Pattern {
 
-	return NewPattern(nil, [][]*Color{colors}, uvPlanarCheckersFunc)
+	return NewPattern(nil, [][]Color{colors}, uvPlanarCheckersFunc)
 }
 
 // cylindricalMap maps a 3D point (x, y, z) on the surface of cylindricalMap to a 2D point (u, v) on the flattened surface.
-func cylindricalMap(point *Tuple) (u, v float64) {
+func cylindricalMap(point Tuple) (u, v float64) {
 
 	// Compute the azimuthal angle (-π < theta <= π) same as with spherical_map().
 	theta := math.Atan2(point.x, point.z)
@@ -218,7 +218,7 @@ func cylindricalMap(point *Tuple) (u, v float64) {
 
 // uvCylindricalCheckersFunc adapts the uvCheckers and textureMap to be set as a func to the *Pattern struct.
 // only the 2 first colors from the parameter slice are processed.
-func uvCylindricalCheckersFunc(_ *Canvas, colors []*Color, p *Tuple) *Color {
+func uvCylindricalCheckersFunc(_ *Canvas, colors []Color, p Tuple) Color {
 
 	checkers := uvCheckers(16, 8, colors[0], colors[1])
 	pattern := textureMap(checkers, cylindricalMap)
@@ -226,29 +226,29 @@ func uvCylindricalCheckersFunc(_ *Canvas, colors []*Color, p *Tuple) *Color {
 }
 
 // uvCylindricalCheckersPattern returns the appropiate *Pattern struct.
-func uvCylindricalCheckersPattern(colors ...*Color) *Pattern {
+func uvCylindricalCheckersPattern(colors ...Color) *Pattern {
 
-	return NewPattern(nil, [][]*Color{colors}, uvCylindricalCheckersFunc)
+	return NewPattern(nil, [][]Color{colors}, uvCylindricalCheckersFunc)
 }
 
 // UVAlignCheck defines a struct for an align pattern.
 type UVAlignCheck struct {
-	main *Color
-	ul   *Color
-	ur   *Color
-	bl   *Color
-	br   *Color
+	main Color
+	ul   Color
+	ur   Color
+	bl   Color
+	br   Color
 }
 
 // uvAlignCheck returns a *UVAlignCheck.
-func uvAlignCheck(main, ul, ur, bl, br *Color) *UVAlignCheck {
+func uvAlignCheck(main, ul, ur, bl, br Color) *UVAlignCheck {
 
 	return &UVAlignCheck{main, ul, ur, bl, br}
 }
 
 // uvAlignCheckFunc adapts the patternType method and textureMap to be set as a func to the *Pattern struct.
 // only the 2 first colors from the parameter slice are processed.
-func uvAlignCheckFunc(_ *Canvas, _ []*Color, p *Tuple) *Color {
+func uvAlignCheckFunc(_ *Canvas, _ []Color, p Tuple) Color {
 
 	// Predefined colors for UVAlignCheck.
 	alignCheck := uvAlignCheck(White, Red, Yellow, Green, Cyan)
@@ -260,10 +260,10 @@ func uvAlignCheckFunc(_ *Canvas, _ []*Color, p *Tuple) *Color {
 func uvAlignCheckPattern() *Pattern {
 
 	// Predefined colors for UVAlignCheck.
-	return NewPattern(nil, [][]*Color{{White}, {Red}, {Yellow}, {Green}, {Cyan}}, uvAlignCheckFunc)
+	return NewPattern(nil, [][]Color{{White}, {Red}, {Yellow}, {Green}, {Cyan}}, uvAlignCheckFunc)
 }
 
-func faceFromPoint(point *Tuple) string {
+func faceFromPoint(point Tuple) string {
 
 	absX := math.Abs(point.x)
 	absY := math.Abs(point.y)
@@ -291,7 +291,7 @@ func faceFromPoint(point *Tuple) string {
 	}
 }
 
-func cubeUVFront(point *Tuple) (u, v float64) {
+func cubeUVFront(point Tuple) (u, v float64) {
 
 	u = math.Mod((point.x+1), 2) / 2
 	v = math.Mod((point.y+1), 2) / 2
@@ -299,7 +299,7 @@ func cubeUVFront(point *Tuple) (u, v float64) {
 	return
 }
 
-func cubeUVBack(point *Tuple) (u, v float64) {
+func cubeUVBack(point Tuple) (u, v float64) {
 
 	u = math.Mod((1-point.x), 2) / 2
 	v = math.Mod((point.y+1), 2) / 2
@@ -307,7 +307,7 @@ func cubeUVBack(point *Tuple) (u, v float64) {
 	return
 }
 
-func cubeUVLeft(point *Tuple) (u, v float64) {
+func cubeUVLeft(point Tuple) (u, v float64) {
 
 	u = math.Mod((point.z+1), 2) / 2
 	v = math.Mod((point.y+1), 2) / 2
@@ -315,7 +315,7 @@ func cubeUVLeft(point *Tuple) (u, v float64) {
 	return
 }
 
-func cubeUVRight(point *Tuple) (u, v float64) {
+func cubeUVRight(point Tuple) (u, v float64) {
 
 	u = math.Mod((1-point.z), 2) / 2
 	v = math.Mod((point.y+1), 2) / 2
@@ -323,7 +323,7 @@ func cubeUVRight(point *Tuple) (u, v float64) {
 	return
 }
 
-func cubeUVUp(point *Tuple) (u, v float64) {
+func cubeUVUp(point Tuple) (u, v float64) {
 
 	u = math.Mod((point.x+1), 2) / 2
 	v = math.Mod((1-point.z), 2) / 2
@@ -331,7 +331,7 @@ func cubeUVUp(point *Tuple) (u, v float64) {
 	return
 }
 
-func cubeUVDown(point *Tuple) (u, v float64) {
+func cubeUVDown(point Tuple) (u, v float64) {
 
 	u = math.Mod((point.x+1), 2) / 2
 	v = math.Mod((point.z+1), 2) / 2
@@ -368,9 +368,9 @@ func init() {
 var cubeMapObj *CubeMap
 
 // cubeMap returns a *TextureMap struct suited for mapping cubes.
-func cubeMap(point *Tuple) *TextureMap {
+func cubeMap(point Tuple) *TextureMap {
 
-	var uvMap func(point *Tuple) (u, v float64)
+	var uvMap func(point Tuple) (u, v float64)
 	var uvPattern *UVAlignCheck
 
 	switch faceFromPoint(point) {
@@ -403,7 +403,7 @@ func cubeMap(point *Tuple) *TextureMap {
 
 // cubeMapCheckFunc adapts the patternType method and textureMap to be set as a func to the *Pattern struct.
 // only the 2 first colors from the parameter slice are processed.
-func uvCubeMapAlignFunc(_ *Canvas, _ []*Color, p *Tuple) *Color {
+func uvCubeMapAlignFunc(_ *Canvas, _ []Color, p Tuple) Color {
 
 	pattern := cubeMap(p)
 	return patternAt(pattern, p)
@@ -413,7 +413,7 @@ func uvCubeMapAlignFunc(_ *Canvas, _ []*Color, p *Tuple) *Color {
 func uvCubeMapAlignPattern() *Pattern {
 
 	// Predefined colors for uvCubeMapAlignFunc.
-	return NewPattern(nil, [][]*Color{{White}, {Red}}, uvCubeMapAlignFunc)
+	return NewPattern(nil, [][]Color{{White}, {Red}}, uvCubeMapAlignFunc)
 }
 
 // UVImage encapsulates the parameters for uvImage.
@@ -436,7 +436,7 @@ func (pattern *UVImage) isPattern() bool {
 
 // uvSphericalCanvasFunc adapts the uvCheckers and textureMap to be set as a func to the *Pattern struct.
 // only the 2 first colors from the parameter slice are processed.
-func uvSphericalCanvasFunc(canvas *Canvas, _ []*Color, p *Tuple) *Color {
+func uvSphericalCanvasFunc(canvas *Canvas, _ []Color, p Tuple) Color {
 
 	// Create default canvas (Load PPM image into canvas later).
 	patternType := uvImage(canvas)
@@ -448,5 +448,17 @@ func uvSphericalCanvasFunc(canvas *Canvas, _ []*Color, p *Tuple) *Color {
 func uvSphericalCanvasPattern(canvas *Canvas) *Pattern {
 
 	// Pass default unused color.
-	return NewPattern(canvas, [][]*Color{{White}, {White}}, uvSphericalCanvasFunc)
+	return NewPattern(canvas, [][]Color{{White}, {White}}, uvSphericalCanvasFunc)
+}
+
+// uvSurfacePattern returns the appropiate *Pattern struct, reading the UV pattern at the texture
+// coordinates of the shapes that carry some, like a mesh loaded from an OBJ file with its "vt"
+// statements. The other shapes, or the faces without texture coordinates, use the planar map.
+func uvSurfacePattern(uvPattern patternType) *Pattern {
+
+	pattern := NewPattern(NewCanvas(0, 0), [][]Color{{White}}, func(_ *Canvas, _ []Color, p Tuple) Color {
+		return patternAt(textureMap(uvPattern, planarMap), p)
+	})
+	pattern.uvPattern = uvPattern
+	return pattern
 }
@@ -12,7 +12,7 @@ func Test2DCheckerPattern(t *testing.T) {
 	type testStruct struct {
 		u             float64
 		v             float64
-		expectedColor *Color
+		expectedColor Color
 	}
 
 	expectedTest := []testStruct{
@@ -39,7 +39,7 @@ func TestSphericalMappingOn3DPoint(t *testing.T) {
 	type testStruct struct {
 		expectedU float64
 		expectedV float64
-		point     *Tuple
+		point     Tuple
 	}
 
 	expectedTest := []testStruct{
@@ -74,8 +74,8 @@ func TestTextureMapPatterWithSphericalMap(t *testing.T) {
 	pattern := textureMap(checkers, sphericalMap)
 
 	type testStruct struct {
-		expectedColor *Color
-		point         *Tuple
+		expectedColor Color
+		point         Tuple
 	}
 
 	expectedTest := []testStruct{
@@ -106,7 +106,7 @@ func TestPlannarMappingOn3DPoint(t *testing.T) {
 	type testStruct struct {
 		expectedU float64
 		expectedV float64
-		point     *Tuple
+		point     Tuple
 	}
 
 	expectedTest := []testStruct{
@@ -139,7 +139,7 @@ func TestTextureMapPatterWithCylindricalMap(t *testing.T) {
 	type testStruct struct {
 		expectedU float64
 		expectedV float64
-		point     *Tuple
+		point     Tuple
 	}
 
 	expectedTest := []testStruct{
@@ -183,7 +183,7 @@ func TestAlignCheckPattern(t *testing.T) {
 	type testStruct struct {
 		expectedU float64
 		expectedV float64
-		color     *Color
+		color     Color
 	}
 
 	expectedTest := []testStruct{
@@ -208,7 +208,7 @@ func TestIdentifyFaceOfCubeFromPoint(t *testing.T) {
 	// Identifying the face of a cube from a point.
 
 	type testStruct struct {
-		point *Tuple
+		point Tuple
 		face  string
 	}
 
@@ -237,7 +237,7 @@ func TestUVMappingFrontFaceCube(t *testing.T) {
 	type testStruct struct {
 		expectedU float64
 		expectedV float64
-		point     *Tuple
+		point     Tuple
 	}
 
 	expectedTest := []testStruct{
@@ -265,7 +265,7 @@ func TestUVMappingBackFaceCube(t *testing.T) {
 	type testStruct struct {
 		expectedU float64
 		expectedV float64
-		point     *Tuple
+		point     Tuple
 	}
 
 	expectedTest := []testStruct{
@@ -293,7 +293,7 @@ func TestUVMappingLeftFaceCube(t *testing.T) {
 	type testStruct struct {
 		expectedU float64
 		expectedV float64
-		point     *Tuple
+		point     Tuple
 	}
 
 	expectedTest := []testStruct{
@@ -321,7 +321,7 @@ func TestUVMappingRightFaceCube(t *testing.T) {
 	type testStruct struct {
 		expectedU float64
 		expectedV float64
-		point     *Tuple
+		point     Tuple
 	}
 
 	expectedTest := []testStruct{
@@ -349,7 +349,7 @@ func TestUVMappingUpperFaceCube(t *testing.T) {
 	type testStruct struct {
 		expectedU float64
 		expectedV float64
-		point     *Tuple
+		point     Tuple
 	}
 
 	expectedTest := []testStruct{
@@ -377,7 +377,7 @@ func TestUVMappingLowerFaceCube(t *testing.T) {
 	type testStruct struct {
 		expectedU float64
 		expectedV float64
-		point     *Tuple
+		point     Tuple
 	}
 
 	expectedTest := []testStruct{
@@ -410,8 +410,8 @@ func TestFindColorsOnMappedCube(t *testing.T) {
 	// down := uvAlignCheck(Purple, Brown, Green, Blue, White)
 
 	type testStruct struct {
-		expectedColor *Color
-		point         *Tuple
+		expectedColor Color
+		point         Tuple
 	}
 
 	expectedTest := []testStruct{
@@ -485,7 +485,7 @@ func Test2DCheckPattern(t *testing.T) {
 	type testStruct struct {
 		u             float64
 		v             float64
-		expectedColor *Color
+		expectedColor Color
 	}
 
 	expectedTest := []testStruct{
@@ -0,0 +1,265 @@
+package main
+
+import "math"
+
+// VoxelGrid is a 3D array of unit cubes: the voxel (x, y, z) fills the cube from (x, y, z) to
+// (x+1, y+1, z+1) in object space and holds a material index, 0 for an empty voxel. Dense grids
+// store every voxel, sparse grids only the filled ones, which suits large and mostly empty scenes.
+// Rays walk through the voxels they cross with the Amanatides-Woo traversal and hit the grid
+// where they go from empty to filled voxels and back, so that filled voxels form solids.
+type VoxelGrid struct {
+	BaseShape
+	sizeX, sizeY, sizeZ int
+	dense               []uint8
+	sparse              map[int]uint8
+	// materials[index] is the material of the voxels holding index, the material of the grid if nil.
+	materials [256]*Material
+	// bounds is the box around the filled voxels, kept up to date by Set so that concurrent renders
+	// only ever read it.
+	bounds *BoundingBox
+}
+
+// NewVoxelGrid returns an empty dense *VoxelGrid of the given size, with Identity matrix as
+// transform and default material.
+func NewVoxelGrid(sizeX, sizeY, sizeZ int) *VoxelGrid {
+	return &VoxelGrid{
+		BaseShape: NewBaseShape(),
+		sizeX:     sizeX,
+		sizeY:     sizeY,
+		sizeZ:     sizeZ,
+		dense:     make([]uint8, sizeX*sizeY*sizeZ),
+		bounds:    NewEmptyBoundingBox(),
+	}
+}
+
+// NewSparseVoxelGrid returns an empty sparse *VoxelGrid of the given size, with Identity matrix
+// as transform and default material.
+func NewSparseVoxelGrid(sizeX, sizeY, sizeZ int) *VoxelGrid {
+	return &VoxelGrid{
+		BaseShape: NewBaseShape(),
+		sizeX:     sizeX,
+		sizeY:     sizeY,
+		sizeZ:     sizeZ,
+		sparse:    make(map[int]uint8),
+		bounds:    NewEmptyBoundingBox(),
+	}
+}
+
+// voxelIndex returns the index of the voxel (x, y, z), x varying first.
+func (grid *VoxelGrid) voxelIndex(x, y, z int) int {
+	return (z*grid.sizeY+y)*grid.sizeX + x
+}
+
+// voxelAt returns the coordinates of the voxel of the given index.
+func (grid *VoxelGrid) voxelAt(index int) (x, y, z int) {
+	return index % grid.sizeX, index / grid.sizeX % grid.sizeY, index / (grid.sizeX * grid.sizeY)
+}
+
+// Get returns the material index of the voxel (x, y, z), 0 outside of the grid.
+func (grid *VoxelGrid) Get(x, y, z int) uint8 {
+	if x < 0 || y < 0 || z < 0 || x >= grid.sizeX || y >= grid.sizeY || z >= grid.sizeZ {
+		return 0
+	}
+	if grid.sparse != nil {
+		return grid.sparse[grid.voxelIndex(x, y, z)]
+	}
+	return grid.dense[grid.voxelIndex(x, y, z)]
+}
+
+// Set sets the material index of the voxel (x, y, z), 0 to empty it. Voxels outside of the grid are ignored.
+func (grid *VoxelGrid) Set(x, y, z int, index uint8) {
+	if x < 0 || y < 0 || z < 0 || x >= grid.sizeX || y >= grid.sizeY || z >= grid.sizeZ {
+		return
+	}
+	i := grid.voxelIndex(x, y, z)
+	switch {
+	case grid.sparse == nil:
+		grid.dense[i] = index
+	case index == 0:
+		delete(grid.sparse, i)
+	default:
+		grid.sparse[i] = index
+	}
+
+	// A filled voxel grows the bounds, an emptied one on their faces may shrink them. Both bump the
+	// generation of the grid, so that the BVHs holding it are refitted.
+	if index != 0 {
+		bounds := &BoundingBox{min: grid.bounds.min, max: grid.bounds.max}
+		bounds.Add(Point(float64(x), float64(y), float64(z)))
+		bounds.Add(Point(float64(x+1), float64(y+1), float64(z+1)))
+		grid.bounds = bounds
+		grid.touch()
+	} else if float64(x) == grid.bounds.min.x || float64(y) == grid.bounds.min.y || float64(z) == grid.bounds.min.z ||
+		float64(x+1) == grid.bounds.max.x || float64(y+1) == grid.bounds.max.y || float64(z+1) == grid.bounds.max.z {
+		grid.bounds = grid.filledBounds()
+		grid.touch()
+	}
+}
+
+// SetVoxelMaterial sets the material of the voxels holding the material index.
+func (grid *VoxelGrid) SetVoxelMaterial(index uint8, material *Material) {
+	grid.materials[index] = material
+}
+
+// materialAt returns the material of the voxel that was hit.
+func (grid *VoxelGrid) materialAt(intersection *Intersection) *Material {
+	x, y, z := grid.voxelAt(intersection.index)
+	if material := grid.materials[grid.Get(x, y, z)]; material != nil {
+		return material
+	}
+	return grid.material
+}
+
+// LocalIntersect calculates the intersections between a ray in object space and the filled voxels.
+func (grid *VoxelGrid) LocalIntersect(localRay Ray, xs Intersections) Intersections {
+	bounds := grid.LocalBounds()
+	tMin, tMax, hit := rayBoxRange(localRay, bounds)
+	if !hit {
+		return xs
+	}
+
+	o, d := localRay.origin, localRay.direction
+	start := localRay.Position(tMin)
+	// The first voxel is the one the ray enters the bounds through, clamped against rounding errors.
+	first := func(v, min, max float64) int {
+		return int(math.Max(min, math.Min(max-1, math.Floor(v))))
+	}
+	x := first(start.x, bounds.min.x, bounds.max.x)
+	y := first(start.y, bounds.min.y, bounds.max.y)
+	z := first(start.z, bounds.min.z, bounds.max.z)
+
+	// For each axis, the step to the next voxel, the t where the ray reaches it and the t it takes
+	// to cross a whole voxel.
+	axis := func(voxel int, origin, direction float64) (int, float64, float64) {
+		switch {
+		case direction > 0:
+			return 1, (float64(voxel+1) - origin) / direction, 1 / direction
+		case direction < 0:
+			return -1, (float64(voxel) - origin) / direction, -1 / direction
+		default:
+			return 0, math.Inf(1), math.Inf(1)
+		}
+	}
+	stepX, nextX, deltaX := axis(x, o.x, d.x)
+	stepY, nextY, deltaY := axis(y, o.y, d.y)
+	stepZ, nextZ, deltaZ := axis(z, o.z, d.z)
+
+	minX, minY, minZ := int(bounds.min.x), int(bounds.min.y), int(bounds.min.z)
+	maxX, maxY, maxZ := int(bounds.max.x), int(bounds.max.y), int(bounds.max.z)
+	t := tMin
+	filled := false
+	previous := 0
+	for x >= minX && x < maxX && y >= minY && y < maxY && z >= minZ && z < maxZ && t <= tMax {
+		current := grid.voxelIndex(x, y, z)
+		if occupied := grid.Get(x, y, z) != 0; occupied != filled {
+			// Entering a voxel hits its face, leaving one hits the face of the voxel left behind.
+			index := previous
+			if occupied {
+				index = current
+			}
+			xs = append(xs, grid.hit(t, index))
+			filled = occupied
+		}
+		previous = current
+
+		switch {
+		case nextX <= nextY && nextX <= nextZ:
+			x += stepX
+			t = nextX
+			nextX += deltaX
+		case nextY <= nextZ:
+			y += stepY
+			t = nextY
+			nextY += deltaY
+		default:
+			z += stepZ
+			t = nextZ
+			nextZ += deltaZ
+		}
+	}
+	if filled {
+		xs = append(xs, grid.hit(math.Min(t, tMax), previous))
+	}
+	return xs
+}
+
+// hit returns the intersection at t with the voxel of the given index.
+func (grid *VoxelGrid) hit(t float64, index int) Intersection {
+	intersection := NewIntersection(t, grid)
+	intersection.index = index
+	return intersection
+}
+
+// Intersect calculates the intersections between a ray in world space and the filled voxels.
+func (grid *VoxelGrid) Intersect(worldRay Ray, xs Intersections) Intersections {
+	return Intersect(grid, worldRay, xs)
+}
+
+// LocalNormalAt returns the normal of the face of the voxel hit that contains the point, found
+// like the faces of a Cube from the point relative to the center of the voxel.
+func (grid *VoxelGrid) LocalNormalAt(localPoint Tuple, intersection *Intersection) Tuple {
+	x, y, z := grid.voxelAt(intersection.index)
+	dx := localPoint.x - float64(x) - 0.5
+	dy := localPoint.y - float64(y) - 0.5
+	dz := localPoint.z - float64(z) - 0.5
+	ax, ay, az := math.Abs(dx), math.Abs(dy), math.Abs(dz)
+
+	switch {
+	case ax >= ay && ax >= az:
+		return Vector(sign(dx), 0, 0)
+	case ay >= az:
+		return Vector(0, sign(dy), 0)
+	default:
+		return Vector(0, 0, sign(dz))
+	}
+}
+
+// NormalAt calculates the normal at a given point in world space.
+func (grid *VoxelGrid) NormalAt(worldPoint Tuple, intersection *Intersection) Tuple {
+	return NormalAt(grid, worldPoint, intersection)
+}
+
+// LocalBounds returns the bounding box of the filled voxels, empty for an empty grid.
+func (grid *VoxelGrid) LocalBounds() *BoundingBox {
+	return grid.bounds
+}
+
+// filledBounds computes the bounding box of the filled voxels.
+func (grid *VoxelGrid) filledBounds() *BoundingBox {
+	bounds := NewEmptyBoundingBox()
+	add := func(index int) {
+		x, y, z := grid.voxelAt(index)
+		bounds.Add(Point(float64(x), float64(y), float64(z)))
+		bounds.Add(Point(float64(x+1), float64(y+1), float64(z+1)))
+	}
+	if grid.sparse != nil {
+		for index := range grid.sparse {
+			add(index)
+		}
+	} else {
+		for index, v := range grid.dense {
+			if v != 0 {
+				add(index)
+			}
+		}
+	}
+	return bounds
+}
+
+// UVAt maps a point on a face of a voxel to (u, v) between 0 and 1 across the face: faces across
+// x use (z, y), faces across y use (x, z) and faces across z use (x, y). The face is the one whose
+// plane is the closest to the point.
+func (grid *VoxelGrid) UVAt(point Tuple) (u, v float64) {
+	offset := func(c float64) float64 { return math.Abs(c - math.Round(c)) }
+	fraction := func(c float64) float64 { return c - math.Floor(c) }
+
+	ox, oy, oz := offset(point.x), offset(point.y), offset(point.z)
+	switch {
+	case ox <= oy && ox <= oz:
+		return fraction(point.z), fraction(point.y)
+	case oy <= oz:
+		return fraction(point.x), fraction(point.z)
+	default:
+		return fraction(point.x), fraction(point.y)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+)
+
+// A single voxel is intersected like a cube of the same size, in dense and sparse grids.
+func TestVoxelGridSingleVoxel(t *testing.T) {
+	dense := NewVoxelGrid(3, 3, 3)
+	dense.Set(1, 2, 0, 1)
+	sparse := NewSparseVoxelGrid(3, 3, 3)
+	sparse.Set(1, 2, 0, 1)
+	cube := NewCube()
+	cube.SetTransform(Translation(1.5, 2.5, 0.5))
+	cube.ApplyTransform(Scaling(0.5, 0.5, 0.5))
+
+	rng := rand.New(rand.NewSource(5))
+	for n := 0; n < 200; n++ {
+		origin := Point(rng.Float64()*8-2.5, rng.Float64()*8-1.5, rng.Float64()*8-3.5)
+		target := Point(1+rng.Float64(), 2+rng.Float64(), rng.Float64())
+		r := NewRay(origin, target.Substract(origin).Normalize())
+
+		expected := cube.Intersect(r, nil)
+		for _, grid := range []*VoxelGrid{dense, sparse} {
+			xs := grid.Intersect(r, nil)
+			if len(xs) != len(expected) {
+				t.Errorf("Voxel intersections count: got %v, expected: %v", len(xs), len(expected))
+				continue
+			}
+			for i := range xs {
+				if !floatEqual(xs[i].t, expected[i].t) {
+					t.Errorf("Voxel intersection: got %v, expected: %v", xs[i].t, expected[i].t)
+				}
+				point := r.Position(xs[i].t)
+				if n, want := grid.NormalAt(point, &xs[i]), cube.NormalAt(point, &expected[i]); !n.Equals(want) {
+					t.Errorf("Voxel normal: got %v, expected: %v", n, want)
+				}
+			}
+		}
+	}
+
+	box := sparse.LocalBounds()
+	if !box.min.Equals(Point(1, 2, 0)) || !box.max.Equals(Point(2, 3, 1)) {
+		t.Errorf("Voxel grid bounds: got %v, expected: %v", box, NewBoundingBoxFloat(1, 2, 0, 2, 3, 1))
+	}
+	sparse.Set(1, 2, 0, 0)
+	if xs := sparse.Intersect(NewRay(Point(1.5, 2.5, -5), Vector(0, 0, 1)), nil); len(xs) != 0 {
+		t.Errorf("Emptied voxel grid intersections: got %v, expected none", xs)
+	}
+	if area := sparse.LocalBounds().SurfaceArea(); area != 0 {
+		t.Errorf("Emptied voxel grid bounds: got an area of %v, expected: %v", area, 0)
+	}
+}
+
+// Voxels set outside of the bounds of a grid in a world refit the BVH of the world.
+func TestVoxelGridWorldBVH(t *testing.T) {
+	grid := NewSparseVoxelGrid(8, 8, 8)
+	grid.Set(0, 0, 0, 1)
+	sphere := NewSphere()
+	sphere.SetTransform(Translation(-5, 0, 0))
+	w := NewWorld([]*PointLight{NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1))}, []Shape{grid, sphere})
+	w.UpdateBVH()
+	bvh := w.bvh
+
+	ray := NewRay(Point(6.5, 6.5, -5), Vector(0, 0, 1))
+	grid.Set(6, 6, 0, 1)
+	if w.hasBVH() {
+		t.Errorf("World BVH: expected growing a voxel grid to leave the BVH out of date")
+	}
+	w.UpdateBVH()
+	if w.bvh != bvh || !w.hasBVH() {
+		t.Errorf("World BVH after growing a voxel grid: got %v, expected the BVH to be refitted", w.bvh)
+	}
+	if xs := w.Intersect(ray); len(xs) != 2 || xs[0].object != grid || !floatEqual(xs[0].t, 5) {
+		t.Errorf("World grown voxel grid intersections: got %v, expected the grid at t=%v", len(xs), 5)
+	}
+
+	grid.Set(6, 6, 0, 0)
+	w.UpdateBVH()
+	if xs := w.Intersect(ray); len(xs) != 0 {
+		t.Errorf("World shrunk voxel grid intersections: got %v, expected none", xs)
+	}
+}
+
+// Adjacent voxels form a single solid whose faces take the material of their voxel.
+func TestVoxelGridMaterialsAndUV(t *testing.T) {
+	grid := NewVoxelGrid(4, 1, 1)
+	grid.Set(0, 0, 0, 1)
+	grid.Set(1, 0, 0, 2)
+	grid.Set(3, 0, 0, 1)
+	red, blue := DefaultMaterial(), DefaultMaterial()
+	red.color = Red
+	blue.color = Blue
+	grid.SetVoxelMaterial(1, red)
+	grid.SetVoxelMaterial(2, blue)
+
+	r := NewRay(Point(-1, 0.5, 0.5), Vector(1, 0, 0))
+	xs := grid.Intersect(r, nil)
+	expected := []struct {
+		t        float64
+		normal   Tuple
+		material *Material
+	}{
+		{1, Vector(-1, 0, 0), red},
+		{3, Vector(1, 0, 0), blue},
+		{4, Vector(-1, 0, 0), red},
+		{5, Vector(1, 0, 0), red},
+	}
+	if len(xs) != len(expected) {
+		t.Fatalf("Voxel intersections count: got %v, expected: %v", len(xs), len(expected))
+	}
+	for i, want := range expected {
+		if !floatEqual(xs[i].t, want.t) {
+			t.Errorf("Voxel intersection: got %v, expected: %v", xs[i].t, want.t)
+		}
+		if n := grid.NormalAt(r.Position(xs[i].t), &xs[i]); !n.Equals(want.normal) {
+			t.Errorf("Voxel normal: got %v, expected: %v", n, want.normal)
+		}
+		if m := xs[i].material(); m != want.material {
+			t.Errorf("Voxel material: got %v, expected: %v", m.color, want.material.color)
+		}
+	}
+
+	uvs := []struct {
+		point Tuple
+		u, v  float64
+	}{
+		{Point(1, 0.25, 0.75), 0.75, 0.25},
+		{Point(1.25, 1, 0.75), 0.25, 0.75},
+		{Point(1.25, 0.5, 0), 0.25, 0.5},
+	}
+	for _, test := range uvs {
+		if u, v := grid.UVAt(test.point); !floatEqual(u, test.u) || !floatEqual(v, test.v) {
+			t.Errorf("Voxel UV at %v: got (%v, %v), expected: (%v, %v)", test.point, u, v, test.u, test.v)
+		}
+	}
+}
+
+// Grids are written and read back in the voxel format.
+func TestVoxelData(t *testing.T) {
+	grid := NewSparseVoxelGrid(2, 3, 4)
+	grid.Set(1, 2, 3, 7)
+	grid.Set(0, 1, 0, 2)
+
+	parsed, err := parseVoxelData(voxelData(grid))
+	if err != nil {
+		t.Fatalf("Voxel data error: %v", err)
+	}
+	if parsed.sizeX != 2 || parsed.sizeY != 3 || parsed.sizeZ != 4 {
+		t.Errorf("Voxel data size: got %vx%vx%v, expected: %vx%vx%v", parsed.sizeX, parsed.sizeY, parsed.sizeZ, 2, 3, 4)
+	}
+	if parsed.Get(1, 2, 3) != 7 || parsed.Get(0, 1, 0) != 2 || parsed.Get(1, 1, 1) != 0 {
+		t.Errorf("Voxel data voxels: got %v, %v and %v, expected: %v, %v and %v",
+			parsed.Get(1, 2, 3), parsed.Get(0, 1, 0), parsed.Get(1, 1, 1), 7, 2, 0)
+	}
+	if box := parsed.LocalBounds(); !box.min.Equals(Point(0, 1, 0)) || !box.max.Equals(Point(2, 3, 4)) {
+		t.Errorf("Voxel data bounds: got %v, expected: %v", box, NewBoundingBoxFloat(0, 1, 0, 2, 3, 4))
+	}
+
+	if _, err := parseVoxelData([]byte("VXL1 truncated")); err == nil {
+		t.Errorf("Truncated voxel data: got no error, expected an error")
+	}
+
+	// Sizes whose product overflows are rejected before allocating the grid.
+	huge := []byte(voxelMagic + "\x00\x00\x00\x80\x00\x00\x00\x80\x04\x00\x00\x00")
+	if _, err := parseVoxelData(huge); err == nil {
+		t.Errorf("Huge voxel data: got no error, expected an error")
+	}
+}
+
+// voxChunk returns a chunk of a .vox file without children.
+func voxChunk(id string, content []byte) []byte {
+	chunk := make([]byte, 12, 12+len(content))
+	copy(chunk, id)
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(content)))
+	return append(chunk, content...)
+}
+
+// MagicaVoxel files are read with z up turned into y up and their palette as materials.
+func TestParseMagicaVoxelData(t *testing.T) {
+	size := make([]byte, 12)
+	binary.LittleEndian.PutUint32(size, 2)
+	binary.LittleEndian.PutUint32(size[4:], 3)
+	binary.LittleEndian.PutUint32(size[8:], 4)
+	voxels := []byte{2, 0, 0, 0, 1, 1, 2, 3, 0, 2, 3, 1}
+	binary.LittleEndian.PutUint32(voxels, 2)
+	palette := make([]byte, 256*4)
+	copy(palette, []byte{255, 0, 0, 255, 0, 0, 255, 255, 0, 255, 0, 255})
+
+	children := append(voxChunk("SIZE", size), voxChunk("XYZI", voxels)...)
+	children = append(children, voxChunk("nTRN", []byte{1, 2, 3, 4})...)
+	children = append(children, voxChunk("RGBA", palette)...)
+	main := voxChunk("MAIN", nil)
+	binary.LittleEndian.PutUint32(main[8:], uint32(len(children)))
+	data := append([]byte("VOX \x96\x00\x00\x00"), main...)
+	data = append(data, children...)
+
+	grid, err := parseMagicaVoxelData(data)
+	if err != nil {
+		t.Fatalf("MagicaVoxel data error: %v", err)
+	}
+	if grid.sizeX != 2 || grid.sizeY != 4 || grid.sizeZ != 3 {
+		t.Errorf("MagicaVoxel size: got %vx%vx%v, expected: %vx%vx%v", grid.sizeX, grid.sizeY, grid.sizeZ, 2, 4, 3)
+	}
+	if grid.Get(1, 2, 1) != 3 || grid.Get(0, 3, 2) != 1 {
+		t.Errorf("MagicaVoxel voxels: got %v and %v, expected: %v and %v", grid.Get(1, 2, 1), grid.Get(0, 3, 2), 3, 1)
+	}
+	if c := grid.materials[1].color; !c.Equals(Red) {
+		t.Errorf("MagicaVoxel palette: got %v, expected: %v", c, Red)
+	}
+	if c := grid.materials[3].color; !c.Equals(Green) {
+		t.Errorf("MagicaVoxel palette: got %v, expected: %v", c, Green)
+	}
+
+	// Without a RGBA chunk, the default palette of MagicaVoxel gives the materials.
+	children = append(voxChunk("SIZE", size), voxChunk("XYZI", voxels)...)
+	binary.LittleEndian.PutUint32(main[8:], uint32(len(children)))
+	data = append([]byte("VOX \x96\x00\x00\x00"), main...)
+	grid, err = parseMagicaVoxelData(append(data, children...))
+	if err != nil {
+		t.Fatalf("MagicaVoxel data without palette error: %v", err)
+	}
+	for index, expected := range map[uint8]Color{1: White, 36: Red, 255: NewColor(17.0/255, 17.0/255, 17.0/255)} {
+		if c := grid.materials[index].color; !c.Equals(expected) {
+			t.Errorf("MagicaVoxel default palette %v: got %v, expected: %v", index, c, expected)
+		}
+	}
+
+	if _, err := parseMagicaVoxelData([]byte("OBJ file")); err == nil {
+		t.Errorf("Invalid MagicaVoxel data: got no error, expected an error")
+	}
+
+	binary.LittleEndian.PutUint32(size, 1<<31)
+	huge := append([]byte("VOX \x96\x00\x00\x00"), main...)
+	huge = append(huge, voxChunk("SIZE", size)...)
+	if _, err := parseMagicaVoxelData(huge); err == nil {
+		t.Errorf("Huge MagicaVoxel size: got no error, expected an error")
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// voxelMagic starts the files of the voxel format of this package: the magic, the size of the
+// grid along x, y and z as little endian uint32, then the material index of every voxel, x
+// varying first, then y, then z.
+const voxelMagic = "VXL1"
+
+// maxVoxelGridSize is the largest size of a parsed grid along any axis, which keeps the number of
+// voxels of a corrupted or hostile file from overflowing or exhausting memory. MagicaVoxel models
+// are at most 256 voxels along each axis.
+const maxVoxelGridSize = 2048
+
+// checkVoxelGridSize returns an error if a size read from a file is empty or above maxVoxelGridSize.
+func checkVoxelGridSize(sizeX, sizeY, sizeZ uint32) error {
+	for _, size := range []uint32{sizeX, sizeY, sizeZ} {
+		if size == 0 || size > maxVoxelGridSize {
+			return fmt.Errorf("voxel grid size %vx%vx%v is not between 1 and %v along every axis", sizeX, sizeY, sizeZ, maxVoxelGridSize)
+		}
+	}
+	return nil
+}
+
+// parseVoxelData returns the dense *VoxelGrid stored in data in the voxel format of this package.
+func parseVoxelData(data []byte) (*VoxelGrid, error) {
+	if len(data) < 16 || string(data[:4]) != voxelMagic {
+		return nil, errors.New("voxel data does not start with " + voxelMagic)
+	}
+	sizeX, sizeY, sizeZ := binary.LittleEndian.Uint32(data[4:]), binary.LittleEndian.Uint32(data[8:]), binary.LittleEndian.Uint32(data[12:])
+	if err := checkVoxelGridSize(sizeX, sizeY, sizeZ); err != nil {
+		return nil, err
+	}
+	voxels := data[16:]
+	if int(sizeX)*int(sizeY)*int(sizeZ) != len(voxels) {
+		return nil, errors.New("voxel data does not match the size of the grid")
+	}
+
+	grid := NewVoxelGrid(int(sizeX), int(sizeY), int(sizeZ))
+	copy(grid.dense, voxels)
+	grid.bounds = grid.filledBounds()
+	return grid, nil
+}
+
+// voxelData returns the grid in the voxel format of this package, read back by parseVoxelData.
+func voxelData(grid *VoxelGrid) []byte {
+	data := make([]byte, 16, 16+grid.sizeX*grid.sizeY*grid.sizeZ)
+	copy(data, voxelMagic)
+	binary.LittleEndian.PutUint32(data[4:], uint32(grid.sizeX))
+	binary.LittleEndian.PutUint32(data[8:], uint32(grid.sizeY))
+	binary.LittleEndian.PutUint32(data[12:], uint32(grid.sizeZ))
+	if grid.sparse == nil {
+		return append(data, grid.dense...)
+	}
+	voxels := make([]byte, grid.sizeX*grid.sizeY*grid.sizeZ)
+	for index, v := range grid.sparse {
+		voxels[index] = v
+	}
+	return append(data, voxels...)
+}
+
+// magicaVoxelDefaultPalette is the palette of the MagicaVoxel files without a RGBA chunk, as
+// 0xAABBGGRR colors indexed by the material index of the voxels.
+var magicaVoxelDefaultPalette = [256]uint32{
+	0x00000000, 0xffffffff, 0xffccffff, 0xff99ffff, 0xff66ffff, 0xff33ffff, 0xff00ffff, 0xffffccff,
+	0xffccccff, 0xff99ccff, 0xff66ccff, 0xff33ccff, 0xff00ccff, 0xffff99ff, 0xffcc99ff, 0xff9999ff,
+	0xff6699ff, 0xff3399ff, 0xff0099ff, 0xffff66ff, 0xffcc66ff, 0xff9966ff, 0xff6666ff, 0xff3366ff,
+	0xff0066ff, 0xffff33ff, 0xffcc33ff, 0xff9933ff, 0xff6633ff, 0xff3333ff, 0xff0033ff, 0xffff00ff,
+	0xffcc00ff, 0xff9900ff, 0xff6600ff, 0xff3300ff, 0xff0000ff, 0xffffffcc, 0xffccffcc, 0xff99ffcc,
+	0xff66ffcc, 0xff33ffcc, 0xff00ffcc, 0xffffcccc, 0xffcccccc, 0xff99cccc, 0xff66cccc, 0xff33cccc,
+	0xff00cccc, 0xffff99cc, 0xffcc99cc, 0xff9999cc, 0xff6699cc, 0xff3399cc, 0xff0099cc, 0xffff66cc,
+	0xffcc66cc, 0xff9966cc, 0xff6666cc, 0xff3366cc, 0xff0066cc, 0xffff33cc, 0xffcc33cc, 0xff9933cc,
+	0xff6633cc, 0xff3333cc, 0xff0033cc, 0xffff00cc, 0xffcc00cc, 0xff9900cc, 0xff6600cc, 0xff3300cc,
+	0xff0000cc, 0xffffff99, 0xffccff99, 0xff99ff99, 0xff66ff99, 0xff33ff99, 0xff00ff99, 0xffffcc99,
+	0xffcccc99, 0xff99cc99, 0xff66cc99, 0xff33cc99, 0xff00cc99, 0xffff9999, 0xffcc9999, 0xff999999,
+	0xff669999, 0xff339999, 0xff009999, 0xffff6699, 0xffcc6699, 0xff996699, 0xff666699, 0xff336699,
+	0xff006699, 0xffff3399, 0xffcc3399, 0xff993399, 0xff663399, 0xff333399, 0xff003399, 0xffff0099,
+	0xffcc0099, 0xff990099, 0xff660099, 0xff330099, 0xff000099, 0xffffff66, 0xffccff66, 0xff99ff66,
+	0xff66ff66, 0xff33ff66, 0xff00ff66, 0xffffcc66, 0xffcccc66, 0xff99cc66, 0xff66cc66, 0xff33cc66,
+	0xff00cc66, 0xffff9966, 0xffcc9966, 0xff999966, 0xff669966, 0xff339966, 0xff009966, 0xffff6666,
+	0xffcc6666, 0xff996666, 0xff666666, 0xff336666, 0xff006666, 0xffff3366, 0xffcc3366, 0xff993366,
+	0xff663366, 0xff333366, 0xff003366, 0xffff0066, 0xffcc0066, 0xff990066, 0xff660066, 0xff330066,
+	0xff000066, 0xffffff33, 0xffccff33, 0xff99ff33, 0xff66ff33, 0xff33ff33, 0xff00ff33, 0xffffcc33,
+	0xffcccc33, 0xff99cc33, 0xff66cc33, 0xff33cc33, 0xff00cc33, 0xffff9933, 0xffcc9933, 0xff999933,
+	0xff669933, 0xff339933, 0xff009933, 0xffff6633, 0xffcc6633, 0xff996633, 0xff666633, 0xff336633,
+	0xff006633, 0xffff3333, 0xffcc3333, 0xff993333, 0xff663333, 0xff333333, 0xff003333, 0xffff0033,
+	0xffcc0033, 0xff990033, 0xff660033, 0xff330033, 0xff000033, 0xffffff00, 0xffccff00, 0xff99ff00,
+	0xff66ff00, 0xff33ff00, 0xff00ff00, 0xffffcc00, 0xffcccc00, 0xff99cc00, 0xff66cc00, 0xff33cc00,
+	0xff00cc00, 0xffff9900, 0xffcc9900, 0xff999900, 0xff669900, 0xff339900, 0xff009900, 0xffff6600,
+	0xffcc6600, 0xff996600, 0xff666600, 0xff336600, 0xff006600, 0xffff3300, 0xffcc3300, 0xff993300,
+	0xff663300, 0xff333300, 0xff003300, 0xffff0000, 0xffcc0000, 0xff990000, 0xff660000, 0xff330000,
+	0xff0000ee, 0xff0000dd, 0xff0000bb, 0xff0000aa, 0xff000088, 0xff000077, 0xff000055, 0xff000044,
+	0xff000022, 0xff000011, 0xff00ee00, 0xff00dd00, 0xff00bb00, 0xff00aa00, 0xff008800, 0xff007700,
+	0xff005500, 0xff004400, 0xff002200, 0xff001100, 0xffee0000, 0xffdd0000, 0xffbb0000, 0xffaa0000,
+	0xff880000, 0xff770000, 0xff550000, 0xff440000, 0xff220000, 0xff110000, 0xffeeeeee, 0xffdddddd,
+	0xffbbbbbb, 0xffaaaaaa, 0xff888888, 0xff777777, 0xff555555, 0xff444444, 0xff222222, 0xff111111,
+}
+
+// parseMagicaVoxelData returns the first model of a MagicaVoxel .vox file as a sparse *VoxelGrid.
+// MagicaVoxel has z up, its (x, y, z) voxel becomes the (x, z, y) voxel of the grid, which also
+// turns its right handed coordinates into the left handed ones of the world. The colors of the
+// palette, or of the default palette of MagicaVoxel, become the materials of the voxels. Other chunks, such as
+// the scene graph and the materials of newer versions, are skipped.
+func parseMagicaVoxelData(data []byte) (*VoxelGrid, error) {
+	if len(data) < 8 || string(data[:4]) != "VOX " {
+		return nil, errors.New("vox data does not start with VOX")
+	}
+
+	var grid *VoxelGrid
+	var palette []byte
+	// The MAIN chunk holds every other chunk as its children, they are read in sequence.
+	offset := 8
+	for offset+12 <= len(data) {
+		id := string(data[offset : offset+4])
+		contentSize := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		content := offset + 12
+		if id == "MAIN" {
+			offset = content + contentSize
+			continue
+		}
+		if content+contentSize > len(data) {
+			return nil, errors.New("vox chunk " + id + " is truncated")
+		}
+		chunk := data[content : content+contentSize]
+
+		switch id {
+		case "SIZE":
+			if grid != nil {
+				// Only the first model is read.
+				break
+			}
+			if len(chunk) < 12 {
+				return nil, errors.New("vox SIZE chunk is truncated")
+			}
+			sizeX, sizeY, sizeZ := binary.LittleEndian.Uint32(chunk), binary.LittleEndian.Uint32(chunk[4:]), binary.LittleEndian.Uint32(chunk[8:])
+			if err := checkVoxelGridSize(sizeX, sizeY, sizeZ); err != nil {
+				return nil, err
+			}
+			grid = NewSparseVoxelGrid(int(sizeX), int(sizeZ), int(sizeY))
+		case "XYZI":
+			if grid == nil {
+				return nil, errors.New("vox XYZI chunk before SIZE")
+			}
+			if len(grid.sparse) > 0 {
+				break
+			}
+			if len(chunk) < 4 {
+				return nil, errors.New("vox XYZI chunk is truncated")
+			}
+			count := int(binary.LittleEndian.Uint32(chunk))
+			if len(chunk) < 4+4*count {
+				return nil, errors.New("vox XYZI chunk is truncated")
+			}
+			for i := 0; i < count; i++ {
+				v := chunk[4+4*i : 8+4*i]
+				grid.Set(int(v[0]), int(v[2]), int(v[1]), v[3])
+			}
+		case "RGBA":
+			palette = chunk
+		}
+		// Children of other chunks follow their content and are read in sequence as well.
+		offset = content + contentSize
+	}
+
+	if grid == nil {
+		return nil, errors.New("vox data has no model")
+	}
+	// Without a RGBA chunk, the model uses the default palette, whose entry 0 is the empty voxel.
+	if palette == nil {
+		palette = make([]byte, 0, 4*255)
+		for _, color := range magicaVoxelDefaultPalette[1:] {
+			palette = append(palette, byte(color), byte(color>>8), byte(color>>16), byte(color>>24))
+		}
+	}
+	// The color of the index i is the entry i-1 of the palette.
+	for i := 0; i+3 < len(palette) && i/4 < 255; i += 4 {
+		material := DefaultMaterial()
+		material.color = NewColor(float64(palette[i])/255, float64(palette[i+1])/255, float64(palette[i+2])/255)
+		grid.SetVoxelMaterial(uint8(i/4+1), material)
+	}
+	return grid, nil
+}
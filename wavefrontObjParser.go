@@ -1,17 +1,30 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 )
 
 // Obj contains the information processed from the wavefront OBJ file.
+// The faces are kept as index buffers used to build a Mesh: three 0-based indices per triangle and
+// a material ID per triangle, -1 for missing indices. Each group holds a Mesh of its faces.
 type Obj struct {
-	vertices     []*Tuple
-	normals      []*Tuple
+	vertices     []Tuple
+	normals      []Tuple
+	uvs          []Tuple
 	ignoredLines int
 	groups       map[string]*Group
+	// groupFaces holds the triangles of each group, as indices of the faces of the index buffers.
+	groupFaces    map[string][]int
+	vertexIndices []int
+	normalIndices []int
+	uvIndices     []int
+	materialIDs   []int
+	materialNames []string
+	// polygonSizes holds the number of corners of each face before it was split into triangles.
+	polygonSizes []int
 }
 
 func handlepanic() {
@@ -25,23 +38,60 @@ func (o *Obj) defaultGroup() *Group {
 	return o.groups["defaultGroup"]
 }
 
-// parseObjData parses the data in wavefront OBJ file
+// parseObjData parses the data in wavefront OBJ file, with a Mesh of the faces of each group.
 func parseObjData(data string) *Obj {
+	return parseObj(data, true)
+}
+
+// parseObjMesh parses the data in wavefront OBJ file into a single Mesh, without creating
+// a shape per triangle. The usemtl statements name the material IDs of the faces.
+// When subdivision is not nil, the faces are subdivided with it into a smooth surface and the
+// normals of the file are replaced by the ones of the subdivided surface.
+// Returns an error when the data can't be parsed or a face refers to a missing vertex, normal or
+// texture coordinates.
+func parseObjMesh(data string, subdivision *SubdivisionConfig) (*Mesh, error) {
+	o := parseObj(data, false)
+	if o == nil {
+		return nil, errors.New("wavefront OBJ data could not be parsed")
+	}
+	if err := o.checkIndices(); err != nil {
+		return nil, err
+	}
+	if subdivision == nil {
+		return o.objToMesh(), nil
+	}
+
+	mesh := o.objToPolygonMesh().subdivide(subdivision)
+	mesh.SetFaceMaterials(make([]*Material, len(o.materialNames)), mesh.materialIDs)
+	mesh.materialNames = o.materialNames
+	return mesh, nil
+}
+
+// parseObj parses the data in wavefront OBJ file, adding a Mesh of their faces to the groups if
+// groups is true.
+func parseObj(data string, groups bool) *Obj {
 
 	defer handlepanic()
 
 	result := &Obj{
-		vertices:     make([]*Tuple, 0),
-		normals:      make([]*Tuple, 0),
-		ignoredLines: 0,
-		groups:       make(map[string]*Group),
+		vertices:      make([]Tuple, 0),
+		normals:       make([]Tuple, 0),
+		uvs:           make([]Tuple, 0),
+		ignoredLines:  0,
+		groups:        make(map[string]*Group),
+		groupFaces:    make(map[string][]int),
+		vertexIndices: make([]int, 0),
+		normalIndices: make([]int, 0),
+		uvIndices:     make([]int, 0),
+		materialIDs:   make([]int, 0),
+		materialNames: make([]string, 0),
 	}
 
 	lines := strings.Split(data, "\n")
 
 	var x, y, z float64
-	var index1, index2, index3, normalIndex1, normalIndex2, normalIndex3 int
 	var err error
+	material := 0
 	currentGroup := "defaultGroup"
 	result.groups[currentGroup] = NewGroup()
 
@@ -49,6 +99,8 @@ func parseObjData(data string) *Obj {
 	// refer to these vertices by their index, starting with 1.
 	result.vertices = append(result.vertices, Point(0, 0, 0))
 	result.normals = append(result.normals, Vector(0, 0, 0))
+	result.uvs = append(result.uvs, Point(0, 0, 0))
+	result.materialNames = append(result.materialNames, "")
 	for _, line := range lines {
 		if strings.TrimSpace(line) != "" {
 			tokenSlice := strings.Fields(strings.TrimSpace(line))
@@ -67,59 +119,32 @@ func parseObjData(data string) *Obj {
 				result.vertices = append(result.vertices, Point(x, y, z))
 
 			case "f":
-				if len(result.normals) == 1 {
-					for i := 2; i < len(tokenSlice)-1; i++ {
-						if index1, err = strconv.Atoi(tokenSlice[1]); err != nil {
-							panic(err)
-						}
-						if index2, err = strconv.Atoi(tokenSlice[i]); err != nil {
-							panic(err)
-						}
-						if index3, err = strconv.Atoi(tokenSlice[i+1]); err != nil {
-							panic(err)
-						}
-						triangle := NewTriangle(
-							result.vertices[index1],
-							result.vertices[index2],
-							result.vertices[index3])
-						result.groups[currentGroup].AddChild(triangle)
-					}
-				} else {
-					for i := 2; i < len(tokenSlice)-1; i++ {
-						subparts1 := strings.Split(tokenSlice[1], "/")
-						subparts2 := strings.Split(tokenSlice[i], "/")
-						subparts3 := strings.Split(tokenSlice[i+1], "/")
-
-						if index1, err = strconv.Atoi(subparts1[0]); err != nil {
-							panic(err)
-						}
-						if index2, err = strconv.Atoi(subparts2[0]); err != nil {
-							panic(err)
-						}
-						if index3, err = strconv.Atoi(subparts3[0]); err != nil {
-							panic(err)
-						}
-
-						if normalIndex1, err = strconv.Atoi(subparts1[2]); err != nil {
-							panic(err)
-						}
-						if normalIndex2, err = strconv.Atoi(subparts2[2]); err != nil {
-							panic(err)
-						}
-						if normalIndex3, err = strconv.Atoi(subparts3[2]); err != nil {
-							panic(err)
-						}
-
-						smoothTriangle := newSmoothTriangle(
-							result.vertices[index1],
-							result.vertices[index2],
-							result.vertices[index3],
-							result.normals[normalIndex1],
-							result.normals[normalIndex2],
-							result.normals[normalIndex3])
-						result.groups[currentGroup].AddChild(smoothTriangle)
+				indices := make([][3]int, len(tokenSlice)-1)
+				for i, token := range tokenSlice[1:] {
+					indices[i] = parseFaceVertex(token)
+				}
+				if len(indices) >= 3 {
+					result.polygonSizes = append(result.polygonSizes, len(indices))
+				}
+				for i := 1; i < len(indices)-1; i++ {
+					result.groupFaces[currentGroup] = append(result.groupFaces[currentGroup], len(result.materialIDs))
+					result.addFace(indices[0], indices[i], indices[i+1], material)
+				}
+
+			case "vt":
+				if x, err = strconv.ParseFloat(tokenSlice[1], 64); err != nil {
+					panic(err)
+				}
+				y = 0
+				if len(tokenSlice) > 2 {
+					if y, err = strconv.ParseFloat(tokenSlice[2], 64); err != nil {
+						panic(err)
 					}
 				}
+				result.uvs = append(result.uvs, Point(x, y, 0))
+
+			case "usemtl":
+				material = result.materialID(tokenSlice[1])
 
 			case "vn":
 				if x, err = strconv.ParseFloat(tokenSlice[1], 64); err != nil {
@@ -150,20 +175,146 @@ func parseObjData(data string) *Obj {
 		}
 	}
 
-	triangles := 0
-	for i := range result.groups {
-		triangles += len(result.groups[i].children)
+	if groups {
+		if err := result.checkIndices(); err != nil {
+			panic(err)
+		}
+		for name, faces := range result.groupFaces {
+			result.groups[name].AddChild(result.facesToMesh(faces))
+		}
 	}
 
 	fmt.Println("Wavefront OBJ loaded:")
 	fmt.Printf("Groups:    %d\n", len(result.groups))
 	fmt.Printf("Vertices: %d\n", len(result.vertices)-1)
-	fmt.Printf("Triangles: %d\n", triangles)
+	fmt.Printf("Triangles: %d\n", len(result.materialIDs))
 	fmt.Printf("Normals:   %d\n", len(result.normals))
 
 	return result
 }
 
+// parseFaceVertex parses a "v", "v/vt", "v//vn" or "v/vt/vn" face token into the vertex, texture
+// coordinate and normal indices, 0 standing for a missing index.
+func parseFaceVertex(token string) [3]int {
+	var indices [3]int
+	var err error
+	for i, part := range strings.Split(token, "/") {
+		if i > 2 || part == "" {
+			continue
+		}
+		if indices[i], err = strconv.Atoi(part); err != nil {
+			panic(err)
+		}
+	}
+	return indices
+}
+
+// addFace records a triangle in the index buffers, converting the 1-based indices to 0-based ones.
+func (o *Obj) addFace(a, b, c [3]int, material int) {
+	for _, indices := range [][3]int{a, b, c} {
+		o.vertexIndices = append(o.vertexIndices, indices[0]-1)
+		o.uvIndices = append(o.uvIndices, indices[1]-1)
+		o.normalIndices = append(o.normalIndices, indices[2]-1)
+	}
+	o.materialIDs = append(o.materialIDs, material)
+}
+
+// checkIndices returns an error if a face refers to a vertex, texture coordinates or a normal that
+// the file doesn't define. Texture coordinates indices are ignored, like by the meshes, when the
+// file has no texture coordinates.
+func (o *Obj) checkIndices() error {
+	for i := range o.vertexIndices {
+		if o.vertexIndices[i] < 0 || o.vertexIndices[i] >= len(o.vertices)-1 {
+			return fmt.Errorf("wavefront OBJ face refers to missing vertex %d", o.vertexIndices[i]+1)
+		}
+		if len(o.uvs) > 1 && (o.uvIndices[i] < -1 || o.uvIndices[i] >= len(o.uvs)-1) {
+			return fmt.Errorf("wavefront OBJ face refers to missing texture coordinates %d", o.uvIndices[i]+1)
+		}
+		if o.normalIndices[i] < -1 || o.normalIndices[i] >= len(o.normals)-1 {
+			return fmt.Errorf("wavefront OBJ face refers to missing normal %d", o.normalIndices[i]+1)
+		}
+	}
+	return nil
+}
+
+// materialID returns the ID of a material name, adding it if it is new.
+func (o *Obj) materialID(name string) int {
+	for id, materialName := range o.materialNames {
+		if materialName == name {
+			return id
+		}
+	}
+	o.materialNames = append(o.materialNames, name)
+	return len(o.materialNames) - 1
+}
+
+// objToMesh returns a Mesh of all the faces, ignoring the groups.
+func (o *Obj) objToMesh() *Mesh {
+	return o.newMesh(o.vertexIndices, o.normalIndices, o.uvIndices, o.materialIDs)
+}
+
+// facesToMesh returns a Mesh of some of the faces, sharing the vertices, normals and texture
+// coordinates of the whole file.
+func (o *Obj) facesToMesh(faces []int) *Mesh {
+	vertexIndices := make([]int, 0, 3*len(faces))
+	normalIndices := make([]int, 0, 3*len(faces))
+	uvIndices := make([]int, 0, 3*len(faces))
+	materialIDs := make([]int, 0, len(faces))
+	for _, face := range faces {
+		vertexIndices = append(vertexIndices, o.vertexIndices[3*face:3*face+3]...)
+		normalIndices = append(normalIndices, o.normalIndices[3*face:3*face+3]...)
+		uvIndices = append(uvIndices, o.uvIndices[3*face:3*face+3]...)
+		materialIDs = append(materialIDs, o.materialIDs[face])
+	}
+	return o.newMesh(vertexIndices, normalIndices, uvIndices, materialIDs)
+}
+
+// newMesh returns a Mesh of the faces of the index buffers, leaving out the normals or texture
+// coordinates when the file has none.
+func (o *Obj) newMesh(vertexIndices, normalIndices, uvIndices, materialIDs []int) *Mesh {
+	if len(o.normals) == 1 {
+		normalIndices = nil
+	}
+	if len(o.uvs) == 1 {
+		uvIndices = nil
+	}
+	mesh := NewMesh(o.vertices[1:], o.normals[1:], o.uvs[1:], vertexIndices, normalIndices, uvIndices)
+	mesh.SetFaceMaterials(make([]*Material, len(o.materialNames)), materialIDs)
+	mesh.materialNames = o.materialNames
+	return mesh
+}
+
+// objToPolygonMesh returns the faces before they were split into triangles, for subdivision.
+func (o *Obj) objToPolygonMesh() *polygonMesh {
+	mesh := &polygonMesh{vertices: o.vertices[1:]}
+	if len(o.uvs) > 1 {
+		mesh.uvs = o.uvs[1:]
+		mesh.uvFaces = make([][]int, 0, len(o.polygonSizes))
+	}
+	triangle := 0
+	for _, size := range o.polygonSizes {
+		// The triangles of a face fan from its first corner, each adding the next corner.
+		corners := []int{3 * triangle, 3*triangle + 1, 3*triangle + 2}
+		for i := 1; i < size-2; i++ {
+			corners = append(corners, 3*(triangle+i)+2)
+		}
+		face := make([]int, len(corners))
+		uvFace := make([]int, len(corners))
+		for i, corner := range corners {
+			face[i] = o.vertexIndices[corner]
+			uvFace[i] = o.uvIndices[corner]
+		}
+		mesh.faces = append(mesh.faces, face)
+		if mesh.uvFaces != nil {
+			mesh.uvFaces = append(mesh.uvFaces, uvFace)
+		}
+		mesh.materialIDs = append(mesh.materialIDs, o.materialIDs[triangle])
+		triangle += size - 2
+	}
+	return mesh
+}
+
+// objToGroup returns a Group holding the groups of the file, each holding a Mesh of its faces.
 func (o *Obj) objToGroup() *Group {
 	g := NewGroup()
 	for _, v := range o.groups {
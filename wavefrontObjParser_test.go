@@ -1,9 +1,6 @@
 package main
 
-import (
-	"reflect"
-	"testing"
-)
+import "testing"
 
 func TestParseGibberish(t *testing.T) {
 	// Ignoring unrecognized lines.
@@ -28,7 +25,7 @@ v -1.0000 0.5000 0.0000
 v 1 0 0
 v 1 1 0
 `
-	expectedVertices := []*Tuple{
+	expectedVertices := []Tuple{
 		Point(-1, 1, 0),
 		Point(-1, 0.5, 0),
 		Point(1, 0, 0),
@@ -45,7 +42,7 @@ v 1 1 0
 }
 
 func TestParseTriangleFaces(t *testing.T) {
-	// Parsing triangle faces.
+	// Parsing triangle faces into the mesh of the default group.
 	data := `
 v -1 1 0
 v -1 0 0
@@ -55,28 +52,8 @@ f 1 2 3
 f 1 3 4
 `
 	parser := parseObjData(data)
-	group := parser.defaultGroup()
-	t1 := group.children[0].(*Triangle)
-	t2 := group.children[1].(*Triangle)
-
-	if !(t1.p1.Equals(parser.vertices[1])) {
-		t.Errorf("Parsing triangle faces, got: %v and expected to be %v", t1.p1, parser.vertices[1])
-	}
-	if !(t1.p2.Equals(parser.vertices[2])) {
-		t.Errorf("Parsing triangle faces, got: %v and expected to be %v", t1.p2, parser.vertices[2])
-	}
-	if !(t1.p3.Equals(parser.vertices[3])) {
-		t.Errorf("Parsing triangle faces, got: %v and expected to be %v", t1.p3, parser.vertices[3])
-	}
-	if !(t2.p1.Equals(parser.vertices[1])) {
-		t.Errorf("Parsing triangle faces, got: %v and expected to be %v", t2.p1, parser.vertices[1])
-	}
-	if !(t2.p2.Equals(parser.vertices[3])) {
-		t.Errorf("Parsing triangle faces, got: %v and expected to be %v", t2.p2, parser.vertices[3])
-	}
-	if !(t2.p3.Equals(parser.vertices[4])) {
-		t.Errorf("Parsing triangle faces, got: %v and expected to be %v", t2.p3, parser.vertices[4])
-	}
+	mesh := parser.defaultGroup().children[0].(*Mesh)
+	checkFaceVertices(t, "Parsing triangle faces", parser, mesh, [][3]int{{1, 2, 3}, {1, 3, 4}})
 }
 
 func TestTriangulatePolygon(t *testing.T) {
@@ -89,38 +66,8 @@ v 1 1 0
 v 0 2 0
 f 1 2 3 4 5`
 	parser := parseObjData(data)
-	group := parser.defaultGroup()
-	t1 := group.children[0].(*Triangle)
-	t2 := group.children[1].(*Triangle)
-	t3 := group.children[2].(*Triangle)
-
-	if !(t1.p1.Equals(parser.vertices[1])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t1.p1, parser.vertices[1])
-	}
-	if !(t1.p2.Equals(parser.vertices[2])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t1.p2, parser.vertices[2])
-	}
-	if !(t1.p3.Equals(parser.vertices[3])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t1.p3, parser.vertices[3])
-	}
-	if !(t2.p1.Equals(parser.vertices[1])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t2.p1, parser.vertices[1])
-	}
-	if !(t2.p2.Equals(parser.vertices[3])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t2.p2, parser.vertices[3])
-	}
-	if !(t2.p3.Equals(parser.vertices[4])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t2.p3, parser.vertices[4])
-	}
-	if !(t3.p1.Equals(parser.vertices[1])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t3.p1, parser.vertices[1])
-	}
-	if !(t3.p2.Equals(parser.vertices[4])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t3.p2, parser.vertices[4])
-	}
-	if !(t3.p3.Equals(parser.vertices[5])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t2.p3, parser.vertices[5])
-	}
+	mesh := parser.defaultGroup().children[0].(*Mesh)
+	checkFaceVertices(t, "Triangulating polygons", parser, mesh, [][3]int{{1, 2, 3}, {1, 3, 4}, {1, 4, 5}})
 }
 
 func TestTrianglesInGroups(t *testing.T) {
@@ -136,28 +83,27 @@ g SecondGroup
 f 1 3 4`
 
 	parser := parseObjData(data)
-	g1 := parser.groups["FirstGroup"]
-	g2 := parser.groups["SecondGroup"]
-	t1 := g1.children[0].(*Triangle)
-	t2 := g2.children[0].(*Triangle)
-
-	if !(t1.p1.Equals(parser.vertices[1])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t1.p1, parser.vertices[1])
-	}
-	if !(t1.p2.Equals(parser.vertices[2])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t1.p2, parser.vertices[2])
-	}
-	if !(t1.p3.Equals(parser.vertices[3])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t1.p3, parser.vertices[3])
+	m1 := parser.groups["FirstGroup"].children[0].(*Mesh)
+	m2 := parser.groups["SecondGroup"].children[0].(*Mesh)
+	checkFaceVertices(t, "Triangles in groups", parser, m1, [][3]int{{1, 2, 3}})
+	checkFaceVertices(t, "Triangles in groups", parser, m2, [][3]int{{1, 3, 4}})
+	if n := len(parser.defaultGroup().children); n != 0 {
+		t.Errorf("Triangles in groups, got: %v meshes in the default group and expected to be %v", n, 0)
 	}
-	if !(t2.p1.Equals(parser.vertices[1])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t2.p1, parser.vertices[1])
-	}
-	if !(t2.p2.Equals(parser.vertices[3])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t2.p2, parser.vertices[3])
-	}
-	if !(t2.p3.Equals(parser.vertices[4])) {
-		t.Errorf("Triangulating polygons, got: %v and expected to be %v", t2.p3, parser.vertices[4])
+}
+
+// checkFaceVertices checks that the faces of the mesh are the vertices of the parser with the
+// expected 1-based indices.
+func checkFaceVertices(t *testing.T, name string, parser *Obj, mesh *Mesh, expected [][3]int) {
+	if mesh.FaceCount() != len(expected) {
+		t.Fatalf("%v, got: %v faces and expected to be %v", name, mesh.FaceCount(), len(expected))
+	}
+	for face, indices := range expected {
+		for corner, index := range indices {
+			if vertex := mesh.vertices[mesh.vertexIndices[3*face+corner]]; !vertex.Equals(parser.vertices[index]) {
+				t.Errorf("%v, got: %v and expected to be %v", name, vertex, parser.vertices[index])
+			}
+		}
 	}
 }
 
@@ -170,7 +116,7 @@ vn 1 2 3`
 
 	parser := parseObjData(data)
 
-	expectedNormals := []*Tuple{
+	expectedNormals := []Tuple{
 		Vector(0, 0, 1),
 		Vector(0.707, 0, -0.707),
 		Vector(1, 2, 3),
@@ -197,29 +143,17 @@ f 1//3 2//1 3//2
 f 1/0/3 2/102/1 3/14/2`
 	parser := parseObjData(data)
 
-	g := parser.defaultGroup()
-	t1 := g.children[0].(*smoothTriangle)
-	t2 := g.children[1].(*smoothTriangle)
+	mesh := parser.defaultGroup().children[0].(*Mesh)
+	checkFaceVertices(t, "Faces with normals", parser, mesh, [][3]int{{1, 2, 3}, {1, 2, 3}})
 
-	if !(t1.p1.Equals(parser.vertices[1])) {
-		t.Errorf("Faces with normals, got: %v and expected to be %v", t1.p1, parser.vertices[1])
-	}
-	if !(t1.p2.Equals(parser.vertices[2])) {
-		t.Errorf("Faces with normals, got: %v and expected to be %v", t1.p2, parser.vertices[2])
-	}
-	if !(t1.p3.Equals(parser.vertices[3])) {
-		t.Errorf("Faces with normals, got: %v and expected to be %v", t1.p3, parser.vertices[3])
-	}
-	if !(t1.n1.Equals(parser.normals[3])) {
-		t.Errorf("Faces with normals, got: %v and expected to be %v", t1.n1, parser.normals[3])
-	}
-	if !(t1.n2.Equals(parser.normals[1])) {
-		t.Errorf("Faces with normals, got: %v and expected to be %v", t1.n2, parser.normals[1])
-	}
-	if !(t1.n3.Equals(parser.normals[2])) {
-		t.Errorf("Faces with normals, got: %v and expected to be %v", t1.n3, parser.normals[2])
+	expectedNormals := []int{3, 1, 2, 3, 1, 2}
+	for i, index := range expectedNormals {
+		if normal := mesh.normals[mesh.normalIndices[i]]; !normal.Equals(parser.normals[index]) {
+			t.Errorf("Faces with normals, got: %v and expected to be %v", normal, parser.normals[index])
+		}
 	}
-	if !(reflect.DeepEqual(t1, t2)) {
-		t.Errorf("Faces with normals, t1 is not equal to t2")
+	// The texture coordinates indices are ignored without texture coordinates in the file.
+	if mesh.uvIndices != nil {
+		t.Errorf("Faces with normals, got: %v texture coordinates indices and expected none", mesh.uvIndices)
 	}
 }
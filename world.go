@@ -2,13 +2,22 @@ package main
 
 import (
 	"math"
-	"sort"
 )
 
 // World creates an struct containing slices of Shape and PointLight.
 type World struct {
 	lights  []*PointLight
 	objects []Shape
+
+	// bvh holds the objects with finite bounds once BuildBVH has been called, the objects with
+	// infinite bounds, such as planes, are kept in unbounded and tested against every ray.
+	bvh       *FlatBVH
+	unbounded []Shape
+	// bvhObjects holds the objects when the BVH was built, used to detect added, removed or replaced
+	// objects, and bvhGenerations their generations when it was built or refitted, used to detect
+	// moved shapes.
+	bvhObjects     []Shape
+	bvhGenerations []uint64
 }
 
 // DefaultWorld creates a world with default values.
@@ -26,46 +35,202 @@ func DefaultWorld() *World {
 
 // NewWorld returns a World pointer.
 func NewWorld(lights []*PointLight, objects []Shape) *World {
-	return &World{lights, objects}
+	return &World{lights: lights, objects: objects}
 }
 
-// Intersect returns the intersections between a ray and the objects of the world struct.
-func (world *World) Intersect(ray *Ray) Intersections {
-	intersections := []*Intersection{}
+// AddObject adds objects to the world. The BVH of the world no longer covers all the objects, so
+// every object is tested until it is built again.
+func (world *World) AddObject(objects ...Shape) {
+	world.objects = append(world.objects, objects...)
+}
+
+// BuildBVH builds a bounding volume hierarchy over the objects of the world with the Surface Area
+// Heuristic, so that rays only test the objects whose bounds they cross. Objects with infinite bounds
+// are always tested. Once objects are added or shapes moved, every object is tested until UpdateBVH
+// or BuildBVH is called again, which a render does when it starts.
+func (world *World) BuildBVH() {
+	bounded := make([]Shape, 0, len(world.objects))
+	world.unbounded = make([]Shape, 0)
+	for _, object := range world.objects {
+		if ParentSpaceBounds(object).IsFinite() {
+			bounded = append(bounded, object)
+		} else {
+			world.unbounded = append(world.unbounded, object)
+		}
+	}
+	world.bvh = NewFlatBVHFromShapes(bounded, DefaultSAHConfig())
+	world.bvhObjects = append([]Shape(nil), world.objects...)
+	world.bvhGenerations = objectGenerations(world.objects)
+}
+
+// UpdateBVH brings the BVH of the world up to date with its objects. It is built again when
+// objects were added, removed or replaced since it was built, and refitted when shapes only moved.
+func (world *World) UpdateBVH() {
+	if world.bvh == nil || !sameShapes(world.objects, world.bvhObjects) {
+		world.BuildBVH()
+		return
+	}
+	if !world.moved() {
+		return
+	}
 
+	config := DefaultSAHConfig()
 	for _, object := range world.objects {
-		intersections = append(intersections, object.Intersect(ray)...)
+		Refit(object, config)
+	}
+	world.bvh.Refit()
+	world.bvhGenerations = objectGenerations(world.objects)
+}
+
+// objectGenerations returns the generations of the objects.
+func objectGenerations(objects []Shape) []uint64 {
+	generations := make([]uint64, len(objects))
+	for i, object := range objects {
+		generations[i] = object.Generation()
+	}
+	return generations
+}
+
+// moved returns true if any object changed since the BVH was built or refitted. The objects must be
+// the ones the BVH was built over.
+func (world *World) moved() bool {
+	for i, object := range world.objects {
+		if object.Generation() != world.bvhGenerations[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// sameShapes returns true if both slices hold the same shapes in the same order.
+func sameShapes(a, b []Shape) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasBVH returns true if the BVH of the world is built and still matches the objects and their
+// transformations.
+func (world *World) hasBVH() bool {
+	return world.bvh != nil && sameShapes(world.objects, world.bvhObjects) && !world.moved()
+}
+
+// intersectionBuffer holds the slices reused while computing the color of a ray, so that once they
+// have grown large enough no further allocation is needed. Each render worker owns its own buffer.
+type intersectionBuffer struct {
+	xs         Intersections
+	containers []Intersection
+}
+
+// newIntersectionBuffer returns an empty intersectionBuffer.
+func newIntersectionBuffer() *intersectionBuffer {
+	return &intersectionBuffer{
+		xs:         make(Intersections, 0, 16),
+		containers: make([]Intersection, 0, 8),
+	}
+}
+
+// Intersect returns the intersections between a ray and the objects of the world struct.
+func (world *World) Intersect(ray Ray) Intersections {
+	return world.intersect(ray, nil)
+}
+
+// intersect stores the sorted intersections between a ray and the objects of the world into xs,
+// reusing its backing array.
+func (world *World) intersect(ray Ray, xs Intersections) Intersections {
+	xs = xs[:0]
+	if world.hasBVH() {
+		for _, object := range world.unbounded {
+			xs = object.Intersect(ray, xs)
+		}
+		xs = world.bvh.Intersect(ray, xs)
+	} else {
+		for _, object := range world.objects {
+			xs = object.Intersect(ray, xs)
+		}
 	}
 
-	xs := NewIntersections(intersections)
+	if len(xs) > 1 {
+		xs.Sort()
+	}
+
+	return xs
+}
+
+// intersectNearest stores the intersections between a ray and the objects of the world into xs like
+// intersect, but the compiled groups skip the parts of their hierarchy beyond the closest intersection.
+// Only the intersections up to the hit are guaranteed to be present, which is enough to shade it.
+func (world *World) intersectNearest(ray Ray, xs Intersections) Intersections {
+	xs = xs[:0]
+	closest := math.Inf(1)
+	objects := world.objects
+	if world.hasBVH() {
+		objects = world.unbounded
+	}
+	for _, object := range objects {
+		if g, ok := object.(*Group); ok {
+			xs, closest = g.intersectNearest(ray, xs, closest)
+			continue
+		}
+		start := len(xs)
+		xs = object.Intersect(ray, xs)
+		for i := start; i < len(xs); i++ {
+			if xs[i].t >= 0 && xs[i].t < closest {
+				closest = xs[i].t
+			}
+		}
+	}
+	if world.hasBVH() {
+		xs, _ = world.bvh.IntersectNearest(ray, xs, closest)
+	}
 
 	if len(xs) > 1 {
-		sort.Slice(xs, func(i, j int) bool { return xs[i].t < xs[j].t })
+		xs.Sort()
 	}
 
 	return xs
 }
 
 // Computation is a struct for storing some precomputed values.
+// tangentv is the direction of the fiber that was hit, a zero vector for shapes not shaded as fibers.
+// color is the color of the material at the hit.
 type Computation struct {
-	t, n1, n2                                             float64
-	object                                                Shape
-	point, eyev, normalv, reflectv, overPoint, underPoint *Tuple
-	inside                                                bool
+	t, n1, n2                                                       float64
+	object                                                          Shape
+	material                                                        *Material
+	color                                                           Color
+	point, eyev, normalv, tangentv, reflectv, overPoint, underPoint Tuple
+	inside                                                          bool
 }
 
 // PrepareComputations precomputes the point (in world space)
 // where the intersection occurred, the eye vector (pointing
 // back toward the eye, or camera), and the normal vector.
-func PrepareComputations(hit *Intersection, ray *Ray, xs Intersections) *Computation {
+func PrepareComputations(hit *Intersection, ray Ray, xs Intersections) *Computation {
+	containers := []Intersection{}
+	comps := prepareComputations(hit, ray, xs, &containers)
+	return &comps
+}
+
+// prepareComputations implements PrepareComputations, using containers as scratch space
+// for the list of objects the ray is currently inside of.
+func prepareComputations(hit *Intersection, ray Ray, xs Intersections, containers *[]Intersection) Computation {
 	point := ray.Position(hit.t)
-	comps := &Computation{
-		t:       hit.t,
-		object:  hit.object,
-		point:   point,
-		eyev:    ray.direction.Negate(),
-		normalv: hit.object.NormalAt(point, hit),
-		inside:  false,
+	comps := Computation{
+		t:        hit.t,
+		object:   hit.object,
+		material: hit.material(),
+		point:    point,
+		eyev:     ray.direction.Negate(),
+		normalv:  hit.object.NormalAt(point, hit),
+		tangentv: hit.tangent(point),
+		inside:   false,
 	}
 	if comps.normalv.DotProduct(comps.eyev) < 0 {
 		comps.inside = true
@@ -75,25 +240,26 @@ func PrepareComputations(hit *Intersection, ray *Ray, xs Intersections) *Computa
 	comps.reflectv = ray.direction.Reflect(comps.normalv)
 	comps.overPoint = comps.point.Add(comps.normalv.Multiply(EPSILON))
 	comps.underPoint = comps.point.Substract(comps.normalv.Multiply(EPSILON))
+	comps.color = hit.color(comps.material, comps.overPoint)
 
-	containers := []Shape{}
+	*containers = (*containers)[:0]
 
 	for _, inters := range xs {
-		if inters == hit {
-			if len(containers) == 0 {
+		if inters == *hit {
+			if len(*containers) == 0 {
 				comps.n1 = 1
 			} else {
-				comps.n1 = containers[len(containers)-1].Material().refractiveIndex
+				comps.n1 = (*containers)[len(*containers)-1].material().refractiveIndex
 			}
 		}
-		if !removeIfContains(&containers, inters.object) {
-			containers = append(containers, inters.object)
+		if !removeIfContains(containers, &inters) {
+			*containers = append(*containers, inters)
 		}
-		if inters == hit {
-			if len(containers) == 0 {
+		if inters == *hit {
+			if len(*containers) == 0 {
 				comps.n2 = 1
 			} else {
-				comps.n2 = containers[len(containers)-1].Material().refractiveIndex
+				comps.n2 = (*containers)[len(*containers)-1].material().refractiveIndex
 			}
 			break
 		}
@@ -106,10 +272,11 @@ func PrepareComputations(hit *Intersection, ray *Ray, xs Intersections) *Computa
 // then this intersection must be exiting the object. Remove the object from the containers
 // list in this case. Otherwise, the intersection is entering the object, and
 // the object should be added to the end of the list.
-func removeIfContains(containers *[]Shape, obj Shape) bool {
+// Intersections with the same geometry through different instances are different objects.
+func removeIfContains(containers *[]Intersection, inters *Intersection) bool {
 	C := *containers
-	for i, shape := range C {
-		if shape == obj {
+	for i := range C {
+		if C[i].object == inters.object && C[i].inner == inters.inner {
 			for i < len(C)-1 {
 				C[i] = C[i+1]
 				i++
@@ -122,61 +289,97 @@ func removeIfContains(containers *[]Shape, obj Shape) bool {
 }
 
 // ShadeHit returns the color encapsulated by the Computation struct of the world.
-func (world *World) ShadeHit(comps *Computation, remaining int) *Color {
+func (world *World) ShadeHit(comps *Computation, remaining int) Color {
+	return world.shadeHit(comps, remaining, newIntersectionBuffer())
+}
+
+// shadeHit implements ShadeHit, reusing buf for the shadow, reflected and refracted rays.
+func (world *World) shadeHit(comps *Computation, remaining int, buf *intersectionBuffer) Color {
 	light := Black
-	material := comps.object.Material()
+	material := comps.material
 	reflectance := 1.0
 	refractance := 1.0
 	if material.reflective > 0 && material.transparency > 0 {
 		reflectance = comps.Schlick()
 		refractance = 1 - reflectance
 	}
+	fiber := !comps.tangentv.Equals(Vector(0, 0, 0))
 	for i := 0; i < len(world.lights); i++ {
 
-		light = light.Add(
-			Lighting(
-				comps.object.Material(),
-				comps.object,
+		var surface Color
+		if fiber {
+			surface = hairLighting(
+				comps.material,
+				comps.color,
+				world.lights[i],
+				comps.overPoint,
+				comps.eyev,
+				comps.tangentv,
+				world.isShadowed(comps.overPoint, i, buf))
+		} else {
+			surface = lighting(
+				comps.material,
+				comps.color,
 				world.lights[i],
 				comps.overPoint,
 				comps.eyev,
 				comps.normalv,
-				world.IsShadowed(comps.overPoint, i)),
+				world.isShadowed(comps.overPoint, i, buf))
+		}
+		light = light.Add(
+			surface,
 		).Add(
-			world.ReflectedColor(comps, remaining).MultiplyByScalar(reflectance),
+			world.reflectedColor(comps, remaining, buf).MultiplyByScalar(reflectance),
 		).Add(
-			world.RefractedColor(comps, remaining).MultiplyByScalar(refractance),
+			world.refractedColor(comps, remaining, buf).MultiplyByScalar(refractance),
 		)
 	}
 	return light
 }
 
 // ReflectedColor creates a new ray, originating at the hit’s location and pointing in the direction of reflectv.
-func (world *World) ReflectedColor(comps *Computation, remaining int) *Color {
-	if comps.object.Material().reflective == 0.0 || remaining < 1 {
+func (world *World) ReflectedColor(comps *Computation, remaining int) Color {
+	return world.reflectedColor(comps, remaining, newIntersectionBuffer())
+}
+
+// reflectedColor implements ReflectedColor, reusing buf for the reflected ray.
+func (world *World) reflectedColor(comps *Computation, remaining int, buf *intersectionBuffer) Color {
+	if comps.material.reflective == 0.0 || remaining < 1 {
 		return Black
 	}
 	reflectRay := NewRay(comps.overPoint, comps.reflectv)
-	color := world.ColorAt(reflectRay, remaining-1)
+	color := world.colorAt(reflectRay, remaining-1, buf)
 
-	return color.MultiplyByScalar(comps.object.Material().reflective)
+	return color.MultiplyByScalar(comps.material.reflective)
 }
 
 // ColorAt will combine intersect(), prepare_computations() and shade_hit() functions and will
 // intersect the world with the given ray and then return the color at the resulting intersection.
-func (world *World) ColorAt(ray *Ray, remaining int) *Color {
-	xs := world.Intersect(ray)
-	hit := xs.Hit()
+func (world *World) ColorAt(ray Ray, remaining int) Color {
+	return world.colorAt(ray, remaining, newIntersectionBuffer())
+}
+
+// colorAt implements ColorAt, storing the intersections into buf instead of allocating them.
+// The intersections are no longer needed once the computations are prepared,
+// so the recursive calls for reflection and refraction can reuse the same buffer.
+func (world *World) colorAt(ray Ray, remaining int, buf *intersectionBuffer) Color {
+	buf.xs = world.intersectNearest(ray, buf.xs)
+	hit := buf.xs.Hit()
 	if hit == nil {
 		return Black
 	}
-	comps := PrepareComputations(hit, ray, xs)
-	return world.ShadeHit(comps, remaining)
+	comps := prepareComputations(hit, ray, buf.xs, &buf.containers)
+	return world.shadeHit(&comps, remaining, buf)
 }
 
 // RefractedColor calculates the resulting color from a simulated refraction mathematical model.
-func (world *World) RefractedColor(comps *Computation, remaining int) *Color {
-	if comps.object.Material().transparency == 0.0 || remaining < 1 {
+func (world *World) RefractedColor(comps *Computation, remaining int) Color {
+	return world.refractedColor(comps, remaining, newIntersectionBuffer())
+}
+
+// refractedColor implements RefractedColor, reusing buf for the refracted ray.
+func (world *World) refractedColor(comps *Computation, remaining int, buf *intersectionBuffer) Color {
+	if comps.material.transparency == 0.0 || remaining < 1 {
 		return Black
 	}
 	nRatio := comps.n1 / comps.n2
@@ -195,7 +398,7 @@ func (world *World) RefractedColor(comps *Computation, remaining int) *Color {
 
 	refractRay := NewRay(comps.underPoint, direction)
 
-	color := world.ColorAt(refractRay, remaining-1).MultiplyByScalar(comps.object.Material().transparency)
+	color := world.colorAt(refractRay, remaining-1, buf).MultiplyByScalar(comps.material.transparency)
 
 	return color
 }
@@ -219,16 +422,21 @@ func (comps *Computation) Schlick() float64 {
 }
 
 // IsShadowed returns whether a point is considered to be under a shadow.
-func (world *World) IsShadowed(point *Tuple, light int) bool {
+func (world *World) IsShadowed(point Tuple, light int) bool {
+	return world.isShadowed(point, light, newIntersectionBuffer())
+}
+
+// isShadowed implements IsShadowed, reusing buf for the shadow ray.
+func (world *World) isShadowed(point Tuple, light int, buf *intersectionBuffer) bool {
 	v := world.lights[light].position.Substract(point)
 	distance := v.Magnitude()
 	direction := v.Normalize()
 
 	ray := NewRay(point, direction)
 
-	intersections := world.Intersect(ray)
+	buf.xs = world.intersectNearest(ray, buf.xs)
 
-	hit := intersections.Hit()
+	hit := buf.xs.Hit()
 
 	return hit != nil && hit.t < distance
 }
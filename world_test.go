@@ -2,6 +2,7 @@ package main
 
 import (
 	"math"
+	"math/rand"
 	"testing"
 )
 
@@ -31,8 +32,8 @@ func TestPrepareComputation(t *testing.T) {
 	//  Precomputing the state of an intersection.
 	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
 	shape := NewSphere()
-	i := &Intersection{t: 4, object: shape}
-	comps := PrepareComputations(i, r, NewIntersections([]*Intersection{i}))
+	i := Intersection{t: 4, object: shape}
+	comps := PrepareComputations(&i, r, NewIntersections(Intersections{i}))
 
 	if !floatEqual(comps.t, i.t) {
 		t.Errorf("PrepareComputations failed")
@@ -57,8 +58,8 @@ func TestPrepareComputation(t *testing.T) {
 	// The hit, when an intersection occurs on the inside.
 	r = NewRay(Point(0, 0, 0), Vector(0, 0, 1))
 	shape = NewSphere()
-	i = &Intersection{t: 1, object: shape}
-	comps = PrepareComputations(i, r, NewIntersections([]*Intersection{i}))
+	i = Intersection{t: 1, object: shape}
+	comps = PrepareComputations(&i, r, NewIntersections(Intersections{i}))
 
 	if !comps.point.Equals(Point(0, 0, 1)) {
 		t.Errorf("PrepareComputations failed")
@@ -80,7 +81,7 @@ func TestShadeHit(t *testing.T) {
 	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
 	shape := w.objects[0]
 	i := NewIntersection(4, shape)
-	comps := PrepareComputations(i, r, NewIntersections([]*Intersection{i}))
+	comps := PrepareComputations(&i, r, NewIntersections(Intersections{i}))
 	result := w.ShadeHit(comps, 10)
 	expected := NewColor(0.38066, 0.47583, 0.2855)
 
@@ -93,7 +94,7 @@ func TestShadeHit(t *testing.T) {
 	r = NewRay(Point(0, 0, 0), Vector(0, 0, 1))
 	shape = w.objects[1]
 	i = NewIntersection(0.5, shape)
-	comps = PrepareComputations(i, r, NewIntersections([]*Intersection{i}))
+	comps = PrepareComputations(&i, r, NewIntersections(Intersections{i}))
 	result = w.ShadeHit(comps, 10)
 	expected = NewColor(0.90498, 0.90498, 0.90498)
 
@@ -113,7 +114,7 @@ func TestShadeHit(t *testing.T) {
 	r = NewRay(Point(0, 0, 5), Vector(0, 0, 1))
 	i = NewIntersection(4, s2)
 
-	comps = PrepareComputations(i, r, NewIntersections([]*Intersection{i}))
+	comps = PrepareComputations(&i, r, NewIntersections(Intersections{i}))
 	result = w.ShadeHit(comps, 10)
 	expected = NewColor(0.1, 0.1, 0.1)
 
@@ -189,7 +190,7 @@ func TestComputeReflect(t *testing.T) {
 	shape := NewPlane()
 	r := NewRay(Point(0, 1, -1), Vector(0, -math.Sqrt(2)/2, math.Sqrt(2)/2))
 	i := NewIntersection(math.Sqrt(2), shape)
-	comps := PrepareComputations(i, r, NewIntersections([]*Intersection{i}))
+	comps := PrepareComputations(&i, r, NewIntersections(Intersections{i}))
 	expected := Vector(0, math.Sqrt(2)/2, math.Sqrt(2)/2)
 
 	if !comps.reflectv.Equals(expected) {
@@ -207,7 +208,7 @@ func TestWorldReflect(t *testing.T) {
 
 	shape.Material().ambient = 1
 	i := NewIntersection(1, shape)
-	comps := PrepareComputations(i, r, NewIntersections([]*Intersection{i}))
+	comps := PrepareComputations(&i, r, NewIntersections(Intersections{i}))
 
 	color := w.ReflectedColor(comps, 10)
 	if !color.Equals(Black) {
@@ -221,7 +222,7 @@ func TestWorldReflect(t *testing.T) {
 	w.objects = append(w.objects, shape)
 	r = NewRay(Point(0, 0, -3), Vector(0, -math.Sqrt(2)/2, math.Sqrt(2)/2))
 	i = NewIntersection(math.Sqrt(2), shape)
-	comps = PrepareComputations(i, r, NewIntersections([]*Intersection{i}))
+	comps = PrepareComputations(&i, r, NewIntersections(Intersections{i}))
 
 	color = w.ReflectedColor(comps, 10)
 	expected := NewColor(0.19033, 0.237915, 0.142749)
@@ -240,7 +241,7 @@ func TestShadeHitWithReflect(t *testing.T) {
 	w.objects = append(w.objects, shape)
 	r := NewRay(Point(0, 0, -3), Vector(0, -math.Sqrt(2)/2, math.Sqrt(2)/2))
 	i := NewIntersection(math.Sqrt(2), shape)
-	comps := PrepareComputations(i, r, NewIntersections([]*Intersection{i}))
+	comps := PrepareComputations(&i, r, NewIntersections(Intersections{i}))
 
 	color := w.ShadeHit(comps, 10)
 	expected := NewColor(0.876758, 0.924341, 0.829175)
@@ -284,7 +285,7 @@ func TestPrepareComputationWithRefraction(t *testing.T) {
 	C.Material().refractiveIndex = 2.5
 
 	r := NewRay(Point(0, 0, -4), Vector(0, 0, 1))
-	xs := NewIntersections([]*Intersection{NewIntersection(2, A), NewIntersection(2.75, B), NewIntersection(3.25, C), NewIntersection(4.75, B), NewIntersection(5.25, C), NewIntersection(6, A)})
+	xs := NewIntersections(Intersections{NewIntersection(2, A), NewIntersection(2.75, B), NewIntersection(3.25, C), NewIntersection(4.75, B), NewIntersection(5.25, C), NewIntersection(6, A)})
 
 	examples := map[int][2]float64{
 		0: [2]float64{1.0, 1.5},
@@ -296,7 +297,7 @@ func TestPrepareComputationWithRefraction(t *testing.T) {
 	}
 
 	for idx, N := range examples {
-		comps := PrepareComputations(xs[idx], r, xs)
+		comps := PrepareComputations(&xs[idx], r, xs)
 		n1, n2 := N[0], N[1]
 		if !floatEqual(comps.n1, n1) || !floatEqual(comps.n2, n2) {
 			t.Errorf("PrepareComputationWithRefraction: Expected %v,%v to be %v,%v", comps.n1, comps.n2, n1, n2)
@@ -308,8 +309,8 @@ func TestPrepareComputationWithRefraction(t *testing.T) {
 	shape := GlassSphere()
 	shape.SetTransform(Translation(0, 0, 1))
 	i := NewIntersection(5, shape)
-	xs = NewIntersections([]*Intersection{i})
-	comps := PrepareComputations(i, r, xs)
+	xs = NewIntersections(Intersections{i})
+	comps := PrepareComputations(&i, r, xs)
 
 	if !(comps.underPoint.z > EPSILON/2.0 && comps.point.z < comps.underPoint.z) {
 		t.Errorf("PrepareComputationWithRefraction: underPoint %v not valid", comps.underPoint)
@@ -332,9 +333,9 @@ func TestWorldShadeHitWithRefraction(t *testing.T) {
 	w.objects = append(w.objects, ball)
 
 	r := NewRay(Point(0, 0, -3), Vector(0, -math.Sqrt(2)/2, math.Sqrt(2)/2))
-	xs := NewIntersections([]*Intersection{NewIntersection(math.Sqrt(2), floor)})
+	xs := NewIntersections(Intersections{NewIntersection(math.Sqrt(2), floor)})
 
-	comps := PrepareComputations(xs[0], r, xs)
+	comps := PrepareComputations(&xs[0], r, xs)
 	color := w.ShadeHit(comps, 5)
 	expected := NewColor(0.936425, 0.686425, 0.686425)
 
@@ -344,7 +345,7 @@ func TestWorldShadeHitWithRefraction(t *testing.T) {
 	// shade_hit() with a reflective, transparent material.
 	floor.Material().reflective = 0.5
 
-	comps = PrepareComputations(xs[0], r, xs)
+	comps = PrepareComputations(&xs[0], r, xs)
 	color = w.ShadeHit(comps, 5)
 	expected = NewColor(0.933915, 0.696434, 0.692430)
 	if !color.Equals(expected) {
@@ -357,9 +358,9 @@ func TestWorldRefractedColor(t *testing.T) {
 	w := DefaultWorld()
 	shape := w.objects[0]
 	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
-	xs := NewIntersections([]*Intersection{NewIntersection(4, shape), NewIntersection(6, shape)})
+	xs := NewIntersections(Intersections{NewIntersection(4, shape), NewIntersection(6, shape)})
 
-	comps := PrepareComputations(xs[0], r, xs)
+	comps := PrepareComputations(&xs[0], r, xs)
 	c := w.RefractedColor(comps, 5)
 
 	if !c.Equals(Black) {
@@ -370,7 +371,7 @@ func TestWorldRefractedColor(t *testing.T) {
 	shape.Material().transparency = 1.0
 	shape.Material().refractiveIndex = 1.5
 
-	comps = PrepareComputations(xs[0], r, xs)
+	comps = PrepareComputations(&xs[0], r, xs)
 
 	c = w.RefractedColor(comps, 0)
 	if !c.Equals(Black) {
@@ -379,9 +380,9 @@ func TestWorldRefractedColor(t *testing.T) {
 
 	//  The refracted color under total internal reflection.
 	r = NewRay(Point(0, 0, math.Sqrt(2)/2), Vector(0, 1, 0))
-	xs = NewIntersections([]*Intersection{NewIntersection(-math.Sqrt(2)/2, shape), NewIntersection(math.Sqrt(2)/2, shape)})
+	xs = NewIntersections(Intersections{NewIntersection(-math.Sqrt(2)/2, shape), NewIntersection(math.Sqrt(2)/2, shape)})
 
-	comps = PrepareComputations(xs[1], r, xs)
+	comps = PrepareComputations(&xs[1], r, xs)
 
 	c = w.RefractedColor(comps, 5)
 
@@ -394,16 +395,16 @@ func TestWorldRefractedColor(t *testing.T) {
 	A := w.objects[0]
 
 	A.Material().ambient = 1.0
-	A.Material().pattern = NewPattern(nil, [][]*Color{[]*Color{}}, func(_ *Canvas, colors []*Color, point *Tuple) *Color { return NewColor(point.x, point.y, point.z) })
+	A.Material().pattern = NewPattern(nil, [][]Color{[]Color{}}, func(_ *Canvas, colors []Color, point Tuple) Color { return NewColor(point.x, point.y, point.z) })
 
 	B := w.objects[1]
 	B.Material().transparency = 1.0
 	B.Material().refractiveIndex = 1.5
 
 	r = NewRay(Point(0, 0, 0.1), Vector(0, 1, 0))
-	xs = NewIntersections([]*Intersection{NewIntersection(-.9899, A), NewIntersection(-.4899, B), NewIntersection(.4899, B), NewIntersection(.9899, A)})
+	xs = NewIntersections(Intersections{NewIntersection(-.9899, A), NewIntersection(-.4899, B), NewIntersection(.4899, B), NewIntersection(.9899, A)})
 
-	comps = PrepareComputations(xs[2], r, xs)
+	comps = PrepareComputations(&xs[2], r, xs)
 
 	c = w.RefractedColor(comps, 5)
 
@@ -418,9 +419,9 @@ func TestComputationSchlick(t *testing.T) {
 	// The Schlick approximation under total internal reflection.
 	shape := GlassSphere()
 	r := NewRay(Point(0, 0, math.Sqrt(2)/2), Vector(0, 1, 0))
-	xs := NewIntersections([]*Intersection{NewIntersection(-math.Sqrt(2)/2, shape), NewIntersection(math.Sqrt(2)/2, shape)})
+	xs := NewIntersections(Intersections{NewIntersection(-math.Sqrt(2)/2, shape), NewIntersection(math.Sqrt(2)/2, shape)})
 	xs.Hit()
-	comps := PrepareComputations(xs[1], r, xs)
+	comps := PrepareComputations(&xs[1], r, xs)
 	reflectance := comps.Schlick()
 	expected := 1.0
 
@@ -430,8 +431,8 @@ func TestComputationSchlick(t *testing.T) {
 
 	// The Schlick approximation with a perpendicular viewing angle.
 	r = NewRay(Point(0, 0, 0), Vector(0, 1, 0))
-	xs = NewIntersections([]*Intersection{NewIntersection(-1, shape), NewIntersection(1, shape)})
-	comps = PrepareComputations(xs[1], r, xs)
+	xs = NewIntersections(Intersections{NewIntersection(-1, shape), NewIntersection(1, shape)})
+	comps = PrepareComputations(&xs[1], r, xs)
 	reflectance = comps.Schlick()
 	expected = 0.04
 
@@ -441,8 +442,8 @@ func TestComputationSchlick(t *testing.T) {
 
 	// The Schlick approximation with small angle and n2 > n1.
 	r = NewRay(Point(0, 0.99, -2), Vector(0, 0, 1))
-	xs = NewIntersections([]*Intersection{NewIntersection(1.8589, shape)})
-	comps = PrepareComputations(xs[0], r, xs)
+	xs = NewIntersections(Intersections{NewIntersection(1.8589, shape)})
+	comps = PrepareComputations(&xs[0], r, xs)
 	reflectance = comps.Schlick()
 	expected = 0.48873
 
@@ -450,3 +451,163 @@ func TestComputationSchlick(t *testing.T) {
 		t.Errorf("Schlick(small angle, n2 > n1): expected %v to be %v", reflectance, expected)
 	}
 }
+
+func TestColorAtReusesIntersectionBuffer(t *testing.T) {
+	// Computing the color of a ray with a warmed up intersection buffer does not allocate.
+	w := DefaultWorld()
+	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
+	buf := newIntersectionBuffer()
+	expected := w.ColorAt(r, defaultRecursionDepth)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		c := w.colorAt(r, defaultRecursionDepth, buf)
+		if !c.Equals(expected) {
+			t.Errorf("colorAt with buffer: got %v, expected: %v", c, expected)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("colorAt with buffer: got %v allocations, expected: %v", allocs, 0)
+	}
+}
+
+func BenchmarkWorldColorAt(b *testing.B) {
+	w := DefaultWorld()
+	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.ColorAt(r, defaultRecursionDepth)
+	}
+}
+
+func BenchmarkWorldColorAtWithBuffer(b *testing.B) {
+	w := DefaultWorld()
+	r := NewRay(Point(0, 0, -5), Vector(0, 0, 1))
+	buf := newIntersectionBuffer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.colorAt(r, defaultRecursionDepth, buf)
+	}
+}
+
+// The BVH of the world reports the same intersections and hits as testing every object.
+func TestWorldBVH(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	floor := NewPlane()
+	floor.SetTransform(Translation(0, -12, 0))
+	objects := []Shape{floor}
+	objects = append(objects, randomSpheresGroup(rng, 200, 20).children...)
+	w := NewWorld([]*PointLight{NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1))}, objects)
+
+	rays := make([]Ray, 200)
+	expected := make([]Intersections, len(rays))
+	for i := range rays {
+		rays[i] = NewRay(Point(rng.Float64()*20-10, rng.Float64()*20-10, -20), Vector(rng.Float64()-0.5, rng.Float64()-0.5, 1).Normalize())
+		expected[i] = w.Intersect(rays[i])
+	}
+
+	w.BuildBVH()
+	if len(w.unbounded) != 1 || w.unbounded[0] != floor {
+		t.Errorf("World BVH unbounded objects: got %v, expected: %v", w.unbounded, []Shape{floor})
+	}
+	if len(w.bvh.shapes) != 200 {
+		t.Errorf("World BVH bounded objects: got %v, expected: %v", len(w.bvh.shapes), 200)
+	}
+
+	for i := range rays {
+		xs := w.Intersect(rays[i])
+		if len(xs) != len(expected[i]) {
+			t.Fatalf("World BVH intersections: got %v, expected: %v", len(xs), len(expected[i]))
+		}
+		for j := range xs {
+			if xs[j] != expected[i][j] {
+				t.Errorf("World BVH intersections: got %v, expected: %v", xs[j], expected[i][j])
+			}
+		}
+
+		nearest := w.intersectNearest(rays[i], nil)
+		hit, expectedHit := nearest.Hit(), expected[i].Hit()
+		if (hit == nil) != (expectedHit == nil) || (hit != nil && *hit != *expectedHit) {
+			t.Errorf("World BVH nearest hit: got %v, expected: %v", hit, expectedHit)
+		}
+	}
+
+	// Objects added after the BVH was built are still intersected.
+	s := NewSphere()
+	s.SetTransform(Translation(0, 0, 30))
+	w.AddObject(s)
+	xs := w.Intersect(NewRay(Point(0, 0, 100), Vector(0, 0, -1)))
+	if len(xs) == 0 || xs[0].object != s {
+		t.Errorf("World BVH added object: got %v, expected: %v", xs, s)
+	}
+}
+
+func TestWorldUpdateBVH(t *testing.T) {
+	// Moved shapes refit the BVH of the world, added or replaced objects rebuild it.
+	s1, s2 := NewSphere(), NewSphere()
+	s1.SetTransform(Translation(-5, 0, 0))
+	s2.SetTransform(Translation(5, 0, 0))
+	w := NewWorld([]*PointLight{NewPointLight(Point(-10, 10, -10), NewColor(1, 1, 1))}, []Shape{s1, s2})
+	w.UpdateBVH()
+	bvh := w.bvh
+	if !w.hasBVH() {
+		t.Fatalf("World BVH: expected the BVH to be built")
+	}
+
+	// Rendering doesn't change the shapes, so the BVH stays in use.
+	camera := NewCamera(4, 4, PI/3)
+	camera.SetTransform(ViewTransform(Point(0, 0, -10), Point(0, 0, 0), Vector(0, 1, 0)))
+	camera.Render(w, 1)
+	if w.bvh != bvh || !w.hasBVH() {
+		t.Errorf("World BVH after a render: got %v, expected: %v", w.bvh, bvh)
+	}
+
+	ray := NewRay(Point(0, 20, 0), Vector(0, -1, 0))
+	s1.SetTranslation(0, 0, 0)
+	if w.hasBVH() {
+		t.Errorf("World BVH: expected moving a shape to leave the BVH out of date")
+	}
+	if xs := w.Intersect(ray); len(xs) != 2 || xs[0].object != s1 {
+		t.Errorf("World moved object: got %v, expected: %v", xs, s1)
+	}
+	w.UpdateBVH()
+	if w.bvh != bvh || !w.hasBVH() {
+		t.Errorf("World BVH after moving a shape: got %v, expected the BVH to be refitted", w.bvh)
+	}
+	if xs := w.Intersect(ray); len(xs) != 2 || xs[0].object != s1 {
+		t.Errorf("World refitted BVH: got %v, expected: %v", xs, s1)
+	}
+
+	// Shapes outside of the world don't affect its BVH.
+	other := NewSphere()
+	other.SetTranslation(1, 2, 3)
+	if !w.hasBVH() {
+		t.Errorf("World BVH: expected moving a shape of another world to keep the BVH")
+	}
+
+	s3 := NewSphere()
+	w.objects[0] = s3
+	if w.hasBVH() {
+		t.Errorf("World BVH: expected replacing an object to leave the BVH out of date")
+	}
+	if xs := w.Intersect(ray); len(xs) != 2 || xs[0].object != s3 {
+		t.Errorf("World replaced object: got %v, expected: %v", xs, s3)
+	}
+	w.UpdateBVH()
+	if w.bvh == bvh || !w.hasBVH() {
+		t.Errorf("World BVH after replacing an object: expected the BVH to be built again")
+	}
+	if xs := w.Intersect(ray); len(xs) != 2 || xs[0].object != s3 {
+		t.Errorf("World replaced object: got %v, expected: %v", xs, s3)
+	}
+
+	// Moving a shape inside a group of the world moves the group.
+	child := NewSphere()
+	group := NewGroup()
+	group.AddChild(child)
+	w.AddObject(group)
+	w.UpdateBVH()
+	child.SetTranslation(0, 0, 10)
+	if w.hasBVH() {
+		t.Errorf("World BVH: expected moving the child of a group to leave the BVH out of date")
+	}
+}